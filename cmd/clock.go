@@ -0,0 +1,28 @@
+//go:build !production
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"gymondo/db/postgres/connection"
+	"gymondo/internal/api/rest"
+	"gymondo/internal/clock"
+)
+
+// newClock returns the clock of the service and a function that exposes it
+// on the server. Non-production builds use a clock that admins can move
+// forward in time. The offset applies to every user of the database, so
+// they refuse to start against a database that another instance uses.
+func newClock(conn *sql.DB) (clock.Clock, func(server *rest.Server), error) {
+	if err := connection.ClaimDatabase(context.Background(), conn); err != nil {
+		return nil, nil, fmt.Errorf("time travel needs a database of its own: %w", err)
+	}
+
+	traveler := clock.NewTraveler(clock.System())
+	return traveler, func(server *rest.Server) {
+		server.EnableTimeTravel(traveler)
+	}, nil
+}
@@ -15,9 +15,65 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/v1/product/subscribe": {
+        "/api/v1/admin/jobs": {
+            "get": {
+                "description": "Returns the most recent runs of background jobs such as renewals, dunning and reminders, newest first, optionally filtered by status (pending, running, succeeded or failed). Requires the admin token.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "List background jobs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Admin token",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job status",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Job"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Unsupported status",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/organizations/{organization_id}/admins": {
             "post": {
-                "description": "Allows users to subscribe to a product. This endpoint creates a new subscription for a user, including selecting a product and setting the subscription parameters (e.g., trial period, voucher code).",
+                "description": "Lets the user manage the organization and subscribe for it.",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,17 +81,94 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Product"
+                    "Admin"
                 ],
-                "summary": "Subscribe to a product",
+                "summary": "Add an organization admin",
                 "parameters": [
                     {
-                        "description": "Subscription Request",
+                        "type": "string",
+                        "description": "Admin token",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "organization_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New admin",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/rest.SubscriptionRequest"
+                            "$ref": "#/definitions/rest.OrganizationUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/organizations/{organization_id}/subscribe": {
+            "post": {
+                "description": "Subscribes the organization to a seat based product on behalf of one of its admins, who owns the subscription and takes one of its seats. The first period of all seats is charged right away. An organization has one subscription at a time.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Subscribe an organization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Admin token",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "organization_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Subscription",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rest.OrganizationSubscriptionRequest"
                         }
                     }
                 ],
@@ -52,6 +185,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/rest.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal error",
                         "schema": {
@@ -61,21 +200,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/product/{product_id}": {
+        "/api/v1/admin/price-migrations/{migration_id}": {
             "get": {
-                "description": "Retrieves detailed information about a specific product using the unique product_id. This includes pricing, description, and other attributes.",
+                "description": "Returns the price migration with its subscriptions counted by whether the new price is scheduled, applied or was declined, and the annual net revenue before and after. Requires the admin token.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Product"
+                    "Admin"
                 ],
-                "summary": "Get a specific product",
+                "summary": "Get a price migration",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Product ID",
-                        "name": "product_id",
+                        "description": "Admin token",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Price migration ID",
+                        "name": "migration_id",
                         "in": "path",
                         "required": true
                     }
@@ -84,11 +230,17 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Product"
+                            "$ref": "#/definitions/model.PriceMigration"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Product not found",
+                        "description": "Price migration not found",
                         "schema": {
                             "$ref": "#/definitions/rest.ErrorResponse"
                         }
@@ -96,28 +248,77 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/products": {
-            "get": {
-                "description": "Retrieves a list of all available products. This endpoint provides information about the products that users can subscribe to.",
+        "/api/v1/admin/products/{product_id}/price-migrations": {
+            "post": {
+                "description": "Sets a new price for the product. Subscriptions that have not ended get it from their first renewal after the notice period, their subscribers are notified and can decline it with the decline_price_change action, which ends the subscription at that renewal instead. The product keeps its price until the notice period has passed, so new, reactivated and gifted subscriptions pay the old price until then; those that started in the meantime get the new price after a notice period of their own. With dry_run nothing changes, the response only reports the affected subscriptions and the annual net revenue before and after. Only one migration per product can be pending at a time. Requires the admin token.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Products"
+                    "Admin"
+                ],
+                "summary": "Migrate the price of a product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Admin token",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "product_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Price Migration Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rest.PriceMigrationRequest"
+                        }
+                    }
                 ],
-                "summary": "Get all products",
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Dry run",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.Product"
-                            }
+                            "$ref": "#/definitions/model.PriceMigration"
                         }
                     },
-                    "404": {
-                        "description": "Products not found",
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.PriceMigration"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "An earlier migration of the product is still pending",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
                         "schema": {
                             "$ref": "#/definitions/rest.ErrorResponse"
                         }
@@ -125,37 +326,89 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/products/{voucher_code}": {
-            "get": {
-                "description": "Fetches details of a specific product associated with a given voucher code. The voucher code is used to apply discounts or offers to the product.",
+        "/api/v1/admin/subscriptions/{subscription_id}/seats": {
+            "put": {
+                "description": "Sets the number of seats of an organization's subscription. Its price changes right away, the added or removed seats are prorated to the rest of the current period. Added seats are charged on the next renewal invoice, removed seats are credited to the owner's credit balance right away. Seats taken by the owner and members can't be removed.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Products"
+                    "Admin"
                 ],
-                "summary": "Get all products with a voucher",
+                "summary": "Change the seats of a subscription",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Voucher Code",
-                        "name": "voucher_code",
+                        "description": "Admin token",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "subscription_id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the subscription the change is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Seats",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rest.ChangeSeatsRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.Product"
+                            "$ref": "#/definitions/model.SeatChange"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the subscription after the change"
                             }
                         }
                     },
-                    "404": {
-                        "description": "Products with voucher not found",
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Subscription was modified concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Subscription does not match If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
                         "schema": {
                             "$ref": "#/definitions/rest.ErrorResponse"
                         }
@@ -163,22 +416,22 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/subscription/{subscription_id}": {
+        "/api/v1/admin/time": {
             "get": {
-                "description": "Provides details of an active subscription. The subscription_id is used to fetch information about a specific subscription, such as its status, start date, end date, and other relevant information.",
+                "description": "Returns the time the service currently works with for all users and how far it is ahead of the real time. Only available in non-production builds. Requires the admin token.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Subscription"
+                    "Admin"
                 ],
-                "summary": "Get subscription details",
+                "summary": "Get the service time",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Subscription ID",
-                        "name": "subscription_id",
-                        "in": "path",
+                        "description": "Admin token",
+                        "name": "X-Admin-Token",
+                        "in": "header",
                         "required": true
                     }
                 ],
@@ -186,11 +439,11 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Subscription"
+                            "$ref": "#/definitions/rest.TimeResponse"
                         }
                     },
-                    "404": {
-                        "description": "Subscription not found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/rest.ErrorResponse"
                         }
@@ -198,9 +451,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/subscription/{subscription_id}/manage": {
+        "/api/v1/admin/time/travel": {
             "post": {
-                "description": "Manages an existing subscription. This endpoint allows users to update or modify their subscription, such as pausing, canceling, or changing other settings related to the subscription.",
+                "description": "Moves the service time forward by the given days and duration, so trials expire and renewals become due without waiting. The next background job runs pick up the new time. The offset is global: it applies to every user and subscription of the instance and its database, not only to the caller's. Non-production builds therefore refuse to start against a database another instance uses. Only available in non-production builds. Requires the admin token.",
                 "consumes": [
                     "application/json"
                 ],
@@ -208,24 +461,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Subscription"
+                    "Admin"
                 ],
-                "summary": "Manage subscription",
+                "summary": "Travel forward in time",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Subscription ID",
-                        "name": "subscription_id",
-                        "in": "path",
+                        "description": "Admin token",
+                        "name": "X-Admin-Token",
+                        "in": "header",
                         "required": true
                     },
                     {
-                        "description": "Manage Action",
+                        "description": "Time Travel Request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/rest.ManageSubscriptionRequest"
+                            "$ref": "#/definitions/rest.TimeTravelRequest"
                         }
                     }
                 ],
@@ -233,112 +486,2827 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/rest.ManageSubscriptionResponse"
+                            "$ref": "#/definitions/rest.TimeResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid action",
+                        "description": "Validation error",
                         "schema": {
                             "$ref": "#/definitions/rest.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/rest.ErrorResponse"
                         }
                     }
                 }
-            }
-        }
-    },
+            },
+            "delete": {
+                "description": "Resets the service time to the real time. Background jobs are rescheduled from the real time, data written while traveling keeps its dates. Only available in non-production builds. Requires the admin token.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Return to the present",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Admin token",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rest.TimeResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/users/{user_id}/credit": {
+            "post": {
+                "description": "Adds credit to a user's balance, for example as a goodwill gesture. The credit is used up automatically before the payment method is charged on the next subscribe or renewal. Requires the admin token.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Grant credit to a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Admin token",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Grant Credit Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rest.GrantCreditRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.CreditTransaction"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/webhooks/deliveries": {
+            "get": {
+                "description": "Returns webhook deliveries, newest first, optionally filtered by status (pending, delivered or dead). Requires the admin token.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "List webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Admin token",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Delivery status",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Unsupported status",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/webhooks/deliveries/{delivery_id}/replay": {
+            "post": {
+                "description": "Queues a delivered or dead-lettered webhook delivery again with a fresh set of attempts. Requires the admin token.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Replay a webhook delivery",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Admin token",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Delivery ID",
+                        "name": "delivery_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.WebhookDelivery"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook delivery not found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/webhooks/endpoints": {
+            "get": {
+                "description": "Returns all registered webhook endpoints without their secrets. Requires the admin token.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "List webhook endpoints",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Admin token",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.WebhookEndpoint"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Registers an endpoint that receives subscription lifecycle events (subscription.created, subscription.paused, subscription.unpaused, subscription.pause_scheduled, subscription.canceled, subscription.reactivated, subscription.updated, subscription.activated). Without event types the endpoint receives every event. Payloads are signed with the returned secret, which is not shown again. Requires the admin token.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Register a webhook endpoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Admin token",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Register Webhook Endpoint Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rest.RegisterWebhookEndpointRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.WebhookEndpoint"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/webhooks/endpoints/{endpoint_id}": {
+            "delete": {
+                "description": "Deactivates the endpoint. Deliveries that are still pending are dead-lettered. Requires the admin token.",
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Delete a webhook endpoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Admin token",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Endpoint ID",
+                        "name": "endpoint_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook endpoint not found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/gifts": {
+            "post": {
+                "description": "Charges the buyer for the given number of periods of the product and returns a gift code, which anyone can redeem until it expires. The purchase is invoiced to the buyer and the code is sent to them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Gift"
+                ],
+                "summary": "Purchase a gift",
+                "parameters": [
+                    {
+                        "description": "Gift",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rest.PurchaseGiftRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Gift"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/gifts/{code}": {
+            "get": {
+                "description": "Returns the gift with the given code, including whether it is still redeemable.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Gift"
+                ],
+                "summary": "Get a gift",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Gift code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Gift"
+                        }
+                    },
+                    "404": {
+                        "description": "Gift not found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/gifts/{code}/redeem": {
+            "post": {
+                "description": "Gives the user the prepaid period of the gift without charging them. An active subscription of the user to the gifted product is extended, otherwise a new subscription starts today and renews at the regular price after the gifted period. A gift is redeemed once and only until it expires.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Gift"
+                ],
+                "summary": "Redeem a gift",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Gift code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Recipient",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rest.RedeemGiftRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Subscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Subscription was modified concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Gift was already redeemed or has expired",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/invoices/{invoice_id}": {
+            "get": {
+                "description": "Retrieves an invoice with its line items. The invoice is returned as JSON by default; pass format=html or format=pdf, or send a matching Accept header, to get a printable document.",
+                "produces": [
+                    "application/json",
+                    "text/html",
+                    "application/pdf"
+                ],
+                "tags": [
+                    "Invoice"
+                ],
+                "summary": "Get an invoice",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invoice ID",
+                        "name": "invoice_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "html",
+                            "pdf"
+                        ],
+                        "type": "string",
+                        "description": "Output format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Invoice"
+                        }
+                    },
+                    "400": {
+                        "description": "Unsupported format",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Invoice not found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/organizations": {
+            "post": {
+                "description": "Registers a company with its billing details, which are printed on the invoices of its subscriptions. The given user becomes its first admin. Employees whose email address is in the domain can join its subscription without an invitation.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organization"
+                ],
+                "summary": "Create an organization",
+                "parameters": [
+                    {
+                        "description": "Organization",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rest.CreateOrganizationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Organization"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/organizations/{organization_id}": {
+            "get": {
+                "description": "Returns the billing details, domain and admins of the organization.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organization"
+                ],
+                "summary": "Get an organization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "organization_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Organization"
+                        }
+                    },
+                    "404": {
+                        "description": "Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/organizations/{organization_id}/join": {
+            "post": {
+                "description": "Makes the user an active member of the organization's subscription without an invitation, if their email address is in the organization's domain and a seat is free.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organization"
+                ],
+                "summary": "Join an organization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "organization_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Employee",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rest.OrganizationUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SubscriptionMember"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Subscription was modified concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "All seats are taken",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/product/subscribe": {
+            "post": {
+                "description": "Allows users to subscribe to a product. This endpoint creates a new subscription for a user, including selecting a product and setting the subscription parameters (e.g., trial period, voucher code, referral code). Referral codes are only accepted from new customers and not from the referrer themselves. A start_date after today schedules the subscription: it is charged and grants access from that day on, and canceling it before then refunds nothing since nothing was charged.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Product"
+                ],
+                "summary": "Subscribe to a product",
+                "parameters": [
+                    {
+                        "description": "Subscription Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rest.SubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rest.SubscriptionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Referral code can't be used",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/product/{product_id}": {
+            "get": {
+                "description": "Retrieves detailed information about a specific product using the unique product_id. This includes pricing, description, and other attributes.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Product"
+                ],
+                "summary": "Get a specific product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "product_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Product"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/products": {
+            "get": {
+                "description": "Retrieves a list of all available products. This endpoint provides information about the products that users can subscribe to.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Get all products",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Product"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Products not found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/products/{voucher_code}": {
+            "get": {
+                "description": "Fetches details of a specific product associated with a given voucher code. The voucher code is used to apply discounts or offers to the product.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Get all products with a voucher",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Voucher Code",
+                        "name": "voucher_code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Product"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Products with voucher not found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subscription/{subscription_id}": {
+            "get": {
+                "description": "Provides details of an active subscription. The subscription_id is used to fetch information about a specific subscription, such as its status, start date, end date, and other relevant information.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Subscription"
+                ],
+                "summary": "Get subscription details",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "subscription_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Subscription"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the subscription, to be sent as If-Match when managing it"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Subscription not found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subscription/{subscription_id}/add-ons": {
+            "post": {
+                "description": "Attaches an add-on product to an active subscription to one of the add-on's base products. The add-on is priced per period of the subscription and billed with its next renewal, together with the prorated days until then. It is paused and canceled together with the subscription.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Subscription"
+                ],
+                "summary": "Attach an add-on",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "subscription_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the subscription the change is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Add-on product",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rest.AttachAddOnRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SubscriptionAddOn"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the subscription after the change"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Subscription was modified concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Subscription does not match If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subscription/{subscription_id}/add-ons/{add_on_id}": {
+            "delete": {
+                "description": "Cancels an add-on of the subscription right away. If the add-on was paid for the current period, its unused days are credited to the user's credit balance.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Subscription"
+                ],
+                "summary": "Detach an add-on",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "subscription_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Add-on ID",
+                        "name": "add_on_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the subscription the change is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rest.DetachAddOnResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the subscription after the change"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid If-Match header",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Subscription was modified concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Subscription does not match If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subscription/{subscription_id}/invoices": {
+            "get": {
+                "description": "Lists the invoices issued for a subscription, oldest first. Line items are only included when fetching a single invoice.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invoice"
+                ],
+                "summary": "Get subscription invoices",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "subscription_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Invoice"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subscription/{subscription_id}/manage": {
+            "post": {
+                "description": "Manages an existing subscription. This endpoint allows users to update or modify their subscription, such as pausing, canceling, or changing other settings related to the subscription.\nSupported actions are pause, unpause, cancel_pause, cancel, accept_offer, reactivate and decline_price_change. A pause starts right away unless pause_from names a later day, and lasts until unpaused unless resume_on names the day it ends. Pausing again replaces a scheduled pause; for a paused subscription it changes resume_on. cancel_pause removes a pause that has not started yet. reactivate restores a subscription canceled within the reactivation window with a new period, charged at the current or the locked price as the product's reactivation pricing says. cancel takes an optional reason code (too_expensive, not_using, temporary_break, found_alternative, technical_issues, other) and reason_text. accept_offer takes one of the retention offers listed for a reason instead of canceling. decline_price_change declines the new price announced for the subscription, which then ends at the renewal the new price would have applied to.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Subscription"
+                ],
+                "summary": "Manage subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "subscription_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the subscription the action is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Manage Action",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rest.ManageSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ManageSubscriptionResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the subscription after the action"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid action",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Subscription was modified concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Subscription does not match If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Pause limit of the product exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/rest.PauseLimitErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subscription/{subscription_id}/members": {
+            "post": {
+                "description": "Invites a user to share the subscription. The invitation takes one of the seats of the product until the member is removed, and the user is notified about it. Members are granted the product's entitlements while the subscription grants them to its owner.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Subscription"
+                ],
+                "summary": "Invite a member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "subscription_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the subscription the change is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "User to invite",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rest.InviteMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SubscriptionMember"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the subscription after the change"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Subscription was modified concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Subscription does not match If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "All seats are taken",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subscription/{subscription_id}/members/{member_id}": {
+            "delete": {
+                "description": "Revokes an invitation or removes a member from the subscription, which frees the seat and ends the member's access right away.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Subscription"
+                ],
+                "summary": "Remove a member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "subscription_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "member_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the subscription the change is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rest.RemoveMemberResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the subscription after the change"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid If-Match header",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Subscription was modified concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Subscription does not match If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subscription/{subscription_id}/members/{member_id}/accept": {
+            "post": {
+                "description": "Makes the invited user an active member of the subscription. A user can be an active member of one subscription at a time.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Subscription"
+                ],
+                "summary": "Accept an invitation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "subscription_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "member_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SubscriptionMember"
+                        }
+                    },
+                    "409": {
+                        "description": "Subscription was modified concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subscription/{subscription_id}/payments": {
+            "get": {
+                "description": "Lists every payment attempt made for a subscription, including declined renewal charges and dunning retries, so support can see why a subscription became past due or was canceled.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Subscription"
+                ],
+                "summary": "Get subscription payment attempts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "subscription_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.PaymentAttempt"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subscription/{subscription_id}/reactivations": {
+            "get": {
+                "description": "Lists when a canceled subscription was reactivated and at which price.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Subscription"
+                ],
+                "summary": "Get subscription reactivations",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "subscription_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Reactivation"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/subscription/{subscription_id}/retention-offers": {
+            "get": {
+                "description": "Lists the alternatives to canceling that are configured for the cancellation reason and available for the subscription: a pause, a downgrade to a cheaper product or a discount voucher. An offer is taken with the accept_offer action of the manage endpoint.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Subscription"
+                ],
+                "summary": "Get retention offers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "subscription_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "too_expensive",
+                            "not_using",
+                            "temporary_break",
+                            "found_alternative",
+                            "technical_issues",
+                            "other"
+                        ],
+                        "type": "string",
+                        "description": "Cancellation reason",
+                        "name": "reason",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.RetentionOffer"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid cancellation reason",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/{user_id}/credit": {
+            "get": {
+                "description": "Returns the user's current credit balance together with all credit transactions, newest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Credit"
+                ],
+                "summary": "Get a user's credit balance",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.CreditBalance"
+                        }
+                    },
+                    "404": {
+                        "description": "Credit balance not found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/{user_id}/entitlements": {
+            "get": {
+                "description": "Resolves the user's active, trialing and grace period subscriptions into the feature keys the user may use right now, for example to check access to premium classes. Paused and canceled subscriptions grant nothing. Results are cached for a few seconds.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Entitlements"
+                ],
+                "summary": "Get a user's entitlements",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Entitlements"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/{user_id}/referral-code": {
+            "post": {
+                "description": "Returns the code the user shares to refer friends, creating it on first use. Friends pass it as referral_code when subscribing.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Referral"
+                ],
+                "summary": "Get or create a user's referral code",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ReferralCode"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/users/{user_id}/referrals": {
+            "get": {
+                "description": "Counts the referrals the user made by status and sums up the credit and extra days they got for referring and for being referred.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Referral"
+                ],
+                "summary": "Get a user's referral stats",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ReferralStats"
+                        }
+                    },
+                    "404": {
+                        "description": "Referral stats not found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
     "definitions": {
+        "model.AddOnStatus": {
+            "type": "string",
+            "enum": [
+                "active",
+                "paused",
+                "canceled"
+            ],
+            "x-enum-varnames": [
+                "AddOnActive",
+                "AddOnPaused",
+                "AddOnCanceled"
+            ]
+        },
+        "model.CancellationReason": {
+            "type": "string",
+            "enum": [
+                "too_expensive",
+                "not_using",
+                "temporary_break",
+                "found_alternative",
+                "technical_issues",
+                "other"
+            ],
+            "x-enum-varnames": [
+                "TooExpensive",
+                "NotUsing",
+                "TemporaryBreak",
+                "FoundAlternative",
+                "TechnicalIssues",
+                "OtherReason"
+            ]
+        },
+        "model.CreditAccount": {
+            "type": "string",
+            "enum": [
+                "customer_credit",
+                "goodwill",
+                "refunds",
+                "prorations",
+                "revenue",
+                "referrals"
+            ],
+            "x-enum-varnames": [
+                "CustomerCreditAccount",
+                "GoodwillAccount",
+                "RefundsAccount",
+                "ProrationsAccount",
+                "RevenueAccount",
+                "ReferralsAccount"
+            ]
+        },
+        "model.CreditBalance": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "number"
+                },
+                "transactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.CreditTransaction"
+                    }
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CreditEntry": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "$ref": "#/definitions/model.CreditAccount"
+                },
+                "amount": {
+                    "type": "number"
+                }
+            }
+        },
+        "model.CreditTransaction": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.CreditEntry"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "$ref": "#/definitions/model.CreditTransactionKind"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "subscription_id": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CreditTransactionKind": {
+            "type": "string",
+            "enum": [
+                "grant",
+                "refund",
+                "proration",
+                "consumption",
+                "referral"
+            ],
+            "x-enum-varnames": [
+                "CreditGrant",
+                "CreditRefund",
+                "CreditProration",
+                "CreditConsumption",
+                "CreditReferral"
+            ]
+        },
+        "model.EntitlementGrant": {
+            "type": "object",
+            "properties": {
+                "entitlements": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "owner_id": {
+                    "description": "OwnerID is set when the user is granted the entitlements as a member\nof a subscription owned by another user.",
+                    "type": "string"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/model.EntitlementStatus"
+                },
+                "subscription_id": {
+                    "type": "string"
+                },
+                "until": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.EntitlementStatus": {
+            "type": "string",
+            "enum": [
+                "active",
+                "trialing",
+                "grace_period"
+            ],
+            "x-enum-varnames": [
+                "EntitledActive",
+                "EntitledTrialing",
+                "EntitledGracePeriod"
+            ]
+        },
+        "model.Entitlements": {
+            "type": "object",
+            "properties": {
+                "entitlements": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "grants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.EntitlementGrant"
+                    }
+                },
+                "resolved_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Gift": {
+            "type": "object",
+            "properties": {
+                "buyer_id": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "duration_days": {
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "purchased_at": {
+                    "type": "string"
+                },
+                "redeemed_at": {
+                    "type": "string"
+                },
+                "redeemed_by": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/model.GiftStatus"
+                },
+                "subscription_id": {
+                    "type": "string"
+                },
+                "tax": {
+                    "type": "number"
+                },
+                "total_price": {
+                    "type": "number"
+                },
+                "transaction_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.GiftStatus": {
+            "type": "string",
+            "enum": [
+                "purchased",
+                "redeemed",
+                "expired"
+            ],
+            "x-enum-varnames": [
+                "GiftPurchased",
+                "GiftRedeemed",
+                "GiftExpired"
+            ]
+        },
+        "model.Invoice": {
+            "type": "object",
+            "properties": {
+                "billing_address": {
+                    "type": "string"
+                },
+                "company_name": {
+                    "description": "CompanyName, VATID and BillingAddress are set on invoices of\norganizations.",
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "customer_email": {
+                    "type": "string"
+                },
+                "customer_name": {
+                    "type": "string"
+                },
+                "gift_id": {
+                    "description": "GiftID is set instead of SubscriptionID on invoices of gift purchases.",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "issue_date": {
+                    "type": "string"
+                },
+                "line_items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.InvoiceLineItem"
+                    }
+                },
+                "net_amount": {
+                    "type": "number"
+                },
+                "number": {
+                    "type": "string"
+                },
+                "period_end": {
+                    "type": "string"
+                },
+                "period_start": {
+                    "type": "string"
+                },
+                "subscription_id": {
+                    "type": "string"
+                },
+                "tax_amount": {
+                    "type": "number"
+                },
+                "total_amount": {
+                    "type": "number"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "vat_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.InvoiceLineItem": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "tax": {
+                    "type": "number"
+                },
+                "total_price": {
+                    "type": "number"
+                },
+                "unit_price": {
+                    "type": "number"
+                }
+            }
+        },
+        "model.Job": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "max_attempts": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "run_at": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/model.JobStatus"
+                }
+            }
+        },
+        "model.JobStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "running",
+                "succeeded",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "JobPending",
+                "JobRunning",
+                "JobSucceeded",
+                "JobFailed"
+            ]
+        },
+        "model.MemberStatus": {
+            "type": "string",
+            "enum": [
+                "invited",
+                "active",
+                "removed"
+            ],
+            "x-enum-varnames": [
+                "MemberInvited",
+                "MemberActive",
+                "MemberRemoved"
+            ]
+        },
+        "model.Organization": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "admin_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "domain": {
+                    "description": "Domain is the email domain of the employees, who can join the\norganization's subscription without an invitation.",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "vat_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.PauseLimit": {
+            "type": "string",
+            "enum": [
+                "max_pause_days",
+                "max_pauses_per_period",
+                "max_paused_days_per_year",
+                "min_active_days_between_pauses"
+            ],
+            "x-enum-varnames": [
+                "MaxPauseDaysLimit",
+                "MaxPausesPerPeriodLimit",
+                "MaxPausedDaysPerYearLimit",
+                "MinActiveDaysBetweenPausesLimit"
+            ]
+        },
+        "model.PausePolicy": {
+            "type": "object",
+            "properties": {
+                "max_pause_days": {
+                    "description": "MaxPauseDays is the longest a single pause may last. Pauses without a\nresume date end after it.",
+                    "type": "integer"
+                },
+                "max_paused_days_per_year": {
+                    "description": "MaxPausedDaysPerYear caps the paused days of a subscription within a\ncalendar year.",
+                    "type": "integer"
+                },
+                "max_pauses_per_period": {
+                    "description": "MaxPausesPerPeriod is how often a subscription may pause within one\nbilling period.",
+                    "type": "integer"
+                },
+                "min_active_days_between_pauses": {
+                    "description": "MinActiveDaysBetweenPauses is how long a subscription has to be active\nafter a pause before it may pause again.",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.PaymentAttempt": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "attempt_number": {
+                    "type": "integer"
+                },
+                "attempted_at": {
+                    "type": "string"
+                },
+                "credit_applied": {
+                    "type": "number"
+                },
+                "failure_reason": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "next_retry_date": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/model.PaymentAttemptStatus"
+                },
+                "subscription_id": {
+                    "type": "string"
+                },
+                "transaction_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.PaymentAttemptStatus": {
+            "type": "string",
+            "enum": [
+                "succeeded",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "PaymentSucceeded",
+                "PaymentFailed"
+            ]
+        },
+        "model.PriceMigration": {
+            "type": "object",
+            "properties": {
+                "annual_revenue_impact": {
+                    "type": "number"
+                },
+                "applied": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "current_annual_revenue": {
+                    "type": "number"
+                },
+                "dry_run": {
+                    "description": "DryRun migrations only report what a migration would change, they\nare not stored.",
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "new_annual_revenue": {
+                    "type": "number"
+                },
+                "notice_days": {
+                    "type": "integer"
+                },
+                "opted_out": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "product_price_applied_at": {
+                    "description": "ProductPriceAppliedAt is when the product moved to the new price.",
+                    "type": "string"
+                },
+                "scheduled": {
+                    "type": "integer"
+                },
+                "subscriptions": {
+                    "type": "integer"
+                },
+                "tax": {
+                    "type": "number"
+                },
+                "total_price": {
+                    "type": "number"
+                }
+            }
+        },
         "model.Product": {
             "type": "object",
             "properties": {
+                "base_product_ids": {
+                    "description": "BaseProductIDs are the products an add-on can be attached to.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "duration_days": {
+                    "type": "integer"
+                },
+                "entitlements": {
+                    "description": "Entitlements are the feature keys a subscription to the product grants,\nsuch as premium_classes.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "$ref": "#/definitions/model.ProductKind"
+                },
+                "max_members": {
+                    "description": "MaxMembers is the number of users the owner of a subscription to the\nproduct can share it with.",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "pause_policy": {
+                    "$ref": "#/definitions/model.PausePolicy"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "reactivation_pricing": {
+                    "$ref": "#/definitions/model.ReactivationPricing"
+                },
+                "refund_policy": {
+                    "$ref": "#/definitions/model.RefundPolicy"
+                },
+                "seat_based": {
+                    "description": "SeatBased products are subscribed to by organizations and priced per\nseat, every seat is one user.",
+                    "type": "boolean"
+                },
+                "tax": {
+                    "type": "number"
+                },
+                "total_price": {
+                    "type": "number"
+                },
+                "withdrawal_period_days": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.ProductKind": {
+            "type": "string",
+            "enum": [
+                "base",
+                "add_on"
+            ],
+            "x-enum-varnames": [
+                "BaseProduct",
+                "AddOnProduct"
+            ]
+        },
+        "model.Reactivation": {
+            "type": "object",
+            "properties": {
+                "canceled_date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "pricing": {
+                    "$ref": "#/definitions/model.ReactivationPricing"
+                },
+                "reactivated_at": {
+                    "type": "string"
+                },
+                "subscription_id": {
+                    "type": "string"
+                },
+                "tax": {
+                    "type": "number"
+                },
+                "total_price": {
+                    "type": "number"
+                }
+            }
+        },
+        "model.ReactivationPricing": {
+            "type": "string",
+            "enum": [
+                "current",
+                "locked"
+            ],
+            "x-enum-varnames": [
+                "CurrentPriceReactivation",
+                "LockedPriceReactivation"
+            ]
+        },
+        "model.ReferralCode": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ReferralStats": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "credit_earned": {
+                    "type": "number"
+                },
+                "days_earned": {
+                    "type": "integer"
+                },
+                "pending": {
+                    "type": "integer"
+                },
+                "referrals": {
+                    "type": "integer"
+                },
+                "rewarded": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "void": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.RefundPolicy": {
+            "type": "string",
+            "enum": [
+                "none",
+                "withdrawal_period",
+                "prorated"
+            ],
+            "x-enum-varnames": [
+                "NoRefund",
+                "WithdrawalPeriodRefund",
+                "ProratedRefund"
+            ]
+        },
+        "model.RefundStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "succeeded",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "RefundPending",
+                "RefundSucceeded",
+                "RefundFailed"
+            ]
+        },
+        "model.RetentionOffer": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "$ref": "#/definitions/model.RetentionOfferKind"
+                },
+                "pause_days": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "reason": {
+                    "$ref": "#/definitions/model.CancellationReason"
+                },
+                "voucher_code": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.RetentionOfferKind": {
+            "type": "string",
+            "enum": [
+                "pause",
+                "downgrade",
+                "voucher"
+            ],
+            "x-enum-varnames": [
+                "PauseOffer",
+                "DowngradeOffer",
+                "VoucherOffer"
+            ]
+        },
+        "model.SeatChange": {
+            "type": "object",
+            "properties": {
+                "change_date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "seats_after": {
+                    "type": "integer"
+                },
+                "seats_before": {
+                    "type": "integer"
+                },
+                "subscription_id": {
+                    "type": "string"
+                },
+                "tax": {
+                    "type": "number"
+                },
+                "total_price": {
+                    "type": "number"
+                }
+            }
+        },
+        "model.Subscription": {
+            "type": "object",
+            "properties": {
+                "add_ons": {
+                    "description": "AddOns are the add-ons attached to the subscription, including\ncanceled ones.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SubscriptionAddOn"
+                    }
+                },
+                "canceled_date": {
+                    "type": "string"
+                },
                 "duration_days": {
                     "type": "integer"
                 },
+                "end_date": {
+                    "type": "string"
+                },
+                "grace_end_date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "members": {
+                    "description": "Members are the invited and active members sharing the subscription.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SubscriptionMember"
+                    }
+                },
+                "next_payment_retry_date": {
+                    "type": "string"
+                },
+                "organization_id": {
+                    "description": "OrganizationID is set for subscriptions of an organization, which\nare priced per seat.",
+                    "type": "string"
+                },
+                "past_due_date": {
+                    "type": "string"
+                },
+                "pause_from": {
+                    "type": "string"
+                },
+                "paused_date": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "resume_on": {
+                    "type": "string"
+                },
+                "seat_changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SeatChange"
+                    }
+                },
+                "seats": {
+                    "type": "integer"
+                },
+                "start_date": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/model.SubscriptionStatus"
+                },
+                "tax": {
+                    "type": "number"
+                },
+                "time_zone": {
+                    "type": "string"
+                },
+                "total_price": {
+                    "type": "number"
+                },
+                "trial_end_date": {
+                    "type": "string"
+                },
+                "trial_start_date": {
+                    "type": "string"
+                },
+                "unpaused_date": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.SubscriptionAddOn": {
+            "type": "object",
+            "properties": {
+                "billed_until": {
+                    "description": "BilledUntil is the day up to which the add-on was charged, nil until\nit is charged for the first time.",
+                    "type": "string"
+                },
+                "canceled_date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "start_date": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/model.AddOnStatus"
+                },
+                "subscription_id": {
+                    "type": "string"
+                },
+                "tax": {
+                    "type": "number"
+                },
+                "total_price": {
+                    "type": "number"
+                }
+            }
+        },
+        "model.SubscriptionMember": {
+            "type": "object",
+            "properties": {
+                "accepted_at": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "string"
                 },
-                "name": {
+                "invited_at": {
                     "type": "string"
                 },
-                "price": {
-                    "type": "number"
+                "removed_at": {
+                    "type": "string"
                 },
-                "tax": {
-                    "type": "number"
+                "status": {
+                    "$ref": "#/definitions/model.MemberStatus"
                 },
-                "total_price": {
-                    "type": "number"
+                "subscription_id": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
                 }
             }
         },
-        "model.Subscription": {
+        "model.SubscriptionStatus": {
+            "type": "string",
+            "enum": [
+                "active",
+                "paused",
+                "canceled",
+                "past_due",
+                "scheduled"
+            ],
+            "x-enum-varnames": [
+                "Active",
+                "Paused",
+                "Canceled",
+                "PastDue",
+                "Scheduled"
+            ]
+        },
+        "model.WebhookDelivery": {
             "type": "object",
             "properties": {
-                "canceled_date": {
-                    "type": "string"
-                },
-                "duration_days": {
+                "attempts": {
                     "type": "integer"
                 },
-                "end_date": {
+                "created_at": {
                     "type": "string"
                 },
-                "id": {
+                "delivered_at": {
                     "type": "string"
                 },
-                "paused_date": {
+                "endpoint_id": {
                     "type": "string"
                 },
-                "price": {
-                    "type": "number"
-                },
-                "product_id": {
+                "event_id": {
                     "type": "string"
                 },
-                "start_date": {
+                "id": {
                     "type": "string"
                 },
-                "status": {
-                    "$ref": "#/definitions/model.SubscriptionStatus"
+                "last_error": {
+                    "type": "string"
                 },
-                "tax": {
-                    "type": "number"
+                "next_attempt_at": {
+                    "type": "string"
                 },
-                "total_price": {
-                    "type": "number"
+                "status": {
+                    "$ref": "#/definitions/model.WebhookDeliveryStatus"
+                }
+            }
+        },
+        "model.WebhookDeliveryStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "delivered",
+                "dead"
+            ],
+            "x-enum-varnames": [
+                "WebhookPending",
+                "WebhookDelivered",
+                "WebhookDeadLettered"
+            ]
+        },
+        "model.WebhookEndpoint": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
                 },
-                "trial_end_date": {
+                "created_at": {
                     "type": "string"
                 },
-                "trial_start_date": {
+                "event_types": {
+                    "description": "EventTypes limits the events delivered to the endpoint, an empty list\nsubscribes it to every event.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.WebhookEventType"
+                    }
+                },
+                "id": {
                     "type": "string"
                 },
-                "unpaused_date": {
+                "secret": {
+                    "description": "Secret signs every payload sent to the endpoint. It is only returned\nwhen the endpoint is registered.",
                     "type": "string"
                 },
-                "user_id": {
+                "url": {
                     "type": "string"
                 }
             }
         },
-        "model.SubscriptionStatus": {
+        "model.WebhookEventType": {
             "type": "string",
             "enum": [
-                "active",
-                "paused",
-                "canceled"
+                "subscription.created",
+                "subscription.paused",
+                "subscription.unpaused",
+                "subscription.canceled",
+                "subscription.reactivated",
+                "subscription.pause_scheduled",
+                "subscription.updated",
+                "subscription.activated"
             ],
             "x-enum-varnames": [
-                "Active",
-                "Paused",
-                "Canceled"
+                "SubscriptionCreated",
+                "SubscriptionPaused",
+                "SubscriptionUnpaused",
+                "SubscriptionCanceled",
+                "SubscriptionReactivated",
+                "SubscriptionPauseScheduled",
+                "SubscriptionUpdated",
+                "SubscriptionActivated"
             ]
         },
+        "rest.AttachAddOnRequest": {
+            "type": "object",
+            "required": [
+                "product_id"
+            ],
+            "properties": {
+                "product_id": {
+                    "type": "string",
+                    "example": "5e3a1c7d-92b4-4f0e-8d6a-1b2c3d4e5f60"
+                }
+            }
+        },
+        "rest.ChangeSeatsRequest": {
+            "type": "object",
+            "required": [
+                "seats"
+            ],
+            "properties": {
+                "seats": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 12
+                }
+            }
+        },
+        "rest.CreateOrganizationRequest": {
+            "type": "object",
+            "required": [
+                "address",
+                "admin_id",
+                "name"
+            ],
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "Hauptstraße 1\n10115 Berlin"
+                },
+                "admin_id": {
+                    "type": "string"
+                },
+                "domain": {
+                    "type": "string",
+                    "example": "acme.com"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Acme GmbH"
+                },
+                "vat_id": {
+                    "type": "string",
+                    "example": "DE123456789"
+                }
+            }
+        },
+        "rest.DetachAddOnResponse": {
+            "type": "object",
+            "properties": {
+                "add_on_id": {
+                    "type": "string"
+                },
+                "credit_amount": {
+                    "type": "number"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "subscription_id": {
+                    "type": "string"
+                }
+            }
+        },
         "rest.ErrorResponse": {
             "type": "object",
             "properties": {
@@ -350,6 +3318,33 @@ const docTemplate = `{
                 }
             }
         },
+        "rest.GrantCreditRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "reason"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "rest.InviteMemberRequest": {
+            "type": "object",
+            "required": [
+                "user_id"
+            ],
+            "properties": {
+                "user_id": {
+                    "type": "string",
+                    "example": "2f6c3b8e-4d1a-4b7e-9c2d-8a5f0e1b3c4d"
+                }
+            }
+        },
         "rest.ManageSubscriptionRequest": {
             "type": "object",
             "required": [
@@ -357,13 +3352,192 @@ const docTemplate = `{
             ],
             "properties": {
                 "action": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "pause"
+                },
+                "offer_id": {
+                    "description": "OfferID names the retention offer taken by the accept_offer action.",
+                    "type": "string",
+                    "example": "0b7e5d21-3c9a-4e8f-b6d2-7f1a4c8e2d35"
+                },
+                "pause_from": {
+                    "description": "PauseFrom and ResumeOn schedule a pause, as days in the time zone of\nthe subscription. Both are optional and only used by the pause action.",
+                    "type": "string",
+                    "example": "2024-07-01"
+                },
+                "reason": {
+                    "description": "Reason and ReasonText answer why the subscription is canceled. Both are\noptional and only used by the cancel action.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.CancellationReason"
+                        }
+                    ],
+                    "example": "too_expensive"
+                },
+                "reason_text": {
+                    "type": "string",
+                    "example": "I don't train enough to justify the price"
+                },
+                "resume_on": {
+                    "type": "string",
+                    "example": "2024-07-29"
                 }
             }
         },
         "rest.ManageSubscriptionResponse": {
             "type": "object",
             "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "refund_amount": {
+                    "type": "number"
+                },
+                "refund_status": {
+                    "$ref": "#/definitions/model.RefundStatus"
+                },
+                "subscription_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "rest.OrganizationSubscriptionRequest": {
+            "type": "object",
+            "required": [
+                "product_id",
+                "seats",
+                "user_id"
+            ],
+            "properties": {
+                "product_id": {
+                    "type": "string",
+                    "example": "e4a7c2b9-5d3f-4b8e-a1c6-7f9d0e2b3a58"
+                },
+                "seats": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 10
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "rest.OrganizationUserRequest": {
+            "type": "object",
+            "required": [
+                "user_id"
+            ],
+            "properties": {
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "rest.PauseLimitErrorResponse": {
+            "type": "object",
+            "properties": {
+                "actual": {
+                    "type": "integer",
+                    "example": 41
+                },
+                "allowed": {
+                    "type": "integer",
+                    "example": 30
+                },
+                "details": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "limit": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.PauseLimit"
+                        }
+                    ],
+                    "example": "max_pause_days"
+                }
+            }
+        },
+        "rest.PriceMigrationRequest": {
+            "type": "object",
+            "required": [
+                "price"
+            ],
+            "properties": {
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "price": {
+                    "type": "number",
+                    "example": 12.99
+                },
+                "tax": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 2.47
+                }
+            }
+        },
+        "rest.PurchaseGiftRequest": {
+            "type": "object",
+            "required": [
+                "buyer_id",
+                "periods",
+                "product_id"
+            ],
+            "properties": {
+                "buyer_id": {
+                    "type": "string"
+                },
+                "periods": {
+                    "type": "integer",
+                    "maximum": 12,
+                    "minimum": 1,
+                    "example": 3
+                },
+                "product_id": {
+                    "type": "string",
+                    "example": "ab97234d-6b4a-4a70-823e-68b7a80ef6d4"
+                }
+            }
+        },
+        "rest.RedeemGiftRequest": {
+            "type": "object",
+            "required": [
+                "user_id"
+            ],
+            "properties": {
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "rest.RegisterWebhookEndpointRequest": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.WebhookEventType"
+                    }
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "rest.RemoveMemberResponse": {
+            "type": "object",
+            "properties": {
+                "member_id": {
+                    "type": "string"
+                },
                 "message": {
                     "type": "string"
                 },
@@ -382,6 +3556,15 @@ const docTemplate = `{
                 "product_id": {
                     "type": "string"
                 },
+                "referral_code": {
+                    "description": "ReferralCode attributes the subscription to the user who referred the\nsubscriber.",
+                    "type": "string"
+                },
+                "start_date": {
+                    "description": "StartDate schedules the subscription to start on a later day, in the\ntime zone of the user.",
+                    "type": "string",
+                    "example": "2025-01-01"
+                },
                 "trial_period": {
                     "type": "boolean"
                 },
@@ -403,6 +3586,29 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "rest.TimeResponse": {
+            "type": "object",
+            "properties": {
+                "now": {
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "string"
+                }
+            }
+        },
+        "rest.TimeTravelRequest": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "type": "integer"
+                },
+                "duration": {
+                    "type": "string",
+                    "example": "36h"
+                }
+            }
         }
     }
 }`
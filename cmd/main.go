@@ -1,19 +1,43 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/joho/godotenv"
 	"gymondo/db/postgres/connection"
 	"gymondo/internal/api/rest"
+	"gymondo/internal/jobs"
+	"gymondo/internal/model"
+	"gymondo/internal/notification"
+	"gymondo/internal/outbox"
+	"gymondo/internal/payment"
 	"gymondo/internal/repository"
 	"gymondo/internal/service"
+	"gymondo/internal/webhook"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	_ "gymondo/cmd/docs"
+	// time zones of the users must resolve without zoneinfo in the image
+	_ "time/tzdata"
 )
 
-const serverPort = "80"
+const (
+	serverPort            = "80"
+	webhookInterval       = 30 * time.Second
+	webhookRequestTimeout = 10 * time.Second
+	outboxInterval        = 5 * time.Second
+	outboxSinkTimeout     = 10 * time.Second
+	shutdownTimeout       = 10 * time.Second
+	defaultMailFrom       = "Gymondo <no-reply@gymondo.de>"
+)
 
 func init() {
 	err := godotenv.Load(".env")
@@ -29,17 +53,192 @@ func main() {
 	}
 	defer conn.Close()
 
+	config, err := loadConfig()
+	if err != nil {
+		log.Fatalf("Could not load service config: %v", err)
+	}
+
+	notifier, err := loadNotifier()
+	if err != nil {
+		log.Fatalf("Could not load notifier: %v", err)
+	}
+
+	clk, exposeClock, err := newClock(conn)
+	if err != nil {
+		log.Fatalf("Could not set up clock: %v", err)
+	}
+
 	repo := repository.New(conn)
-	serv := service.New(repo)
+	serv := service.New(
+		repo,
+		payment.NewLogGateway(),
+		webhook.NewHTTPSender(webhookRequestTimeout),
+		notifier,
+		clk,
+		config,
+	)
 
-	apiRoutes := rest.New(serv)
+	sink, err := loadOutboxSink()
+	if err != nil {
+		log.Fatalf("Could not load outbox sink: %v", err)
+	}
+	relay := outbox.NewRelay(repo, outbox.MultiSink{outbox.SinkFunc(serv.PublishWebhooks), sink}, clk)
+
+	runner := jobs.NewRunner(repo, clk)
+	runner.Register("renewals", jobs.MustParseSchedule("@hourly"), serv.RenewSubscriptions)
+	runner.Register("dunning", jobs.MustParseSchedule("@hourly"), serv.RetryFailedPayments)
+	runner.Register("pauses", jobs.MustParseSchedule("@hourly"), serv.ApplyScheduledPauses)
+	runner.Register("activations", jobs.MustParseSchedule("@hourly"), serv.ActivateScheduledSubscriptions)
+	runner.Register("reminders", jobs.MustParseSchedule("@hourly"), serv.SendReminders)
+	runner.Register("gifts", jobs.MustParseSchedule("@hourly"), serv.ExpireGifts)
+	runner.Register("referrals", jobs.MustParseSchedule("@hourly"), serv.GrantReferralRewards)
+	runner.Register("price-migrations", jobs.MustParseSchedule("@hourly"), serv.ApplyPriceMigrations)
+	runner.Register("outbox", jobs.Every(outboxInterval), relay.RelayPending)
+	runner.Register("webhooks", jobs.Every(webhookInterval), serv.DeliverWebhooks)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	runnerDone := make(chan struct{})
+	go func() {
+		runner.Run(ctx)
+		close(runnerDone)
+	}()
+
+	apiRoutes := rest.New(serv, os.Getenv("ADMIN_TOKEN"))
+	exposeClock(apiRoutes)
 	log.Printf("Starting balance service on port %s\n", serverPort)
 	srv := &http.Server{
 		Addr:    fmt.Sprintf(":%s", serverPort),
 		Handler: apiRoutes.NewRoutes(),
 	}
 
-	if err := srv.ListenAndServe(); err != nil {
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Error shutting down server: %v", err)
+		}
+	}()
+
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatalf("Error starting server: %v", err)
 	}
+
+	log.Printf("Waiting for running jobs to finish")
+	<-runnerDone
+}
+
+func loadConfig() (service.Config, error) {
+	config := service.DefaultConfig()
+
+	if value := os.Getenv("DUNNING_RETRY_DAYS"); value != "" {
+		retryDays := make([]int, 0)
+		for _, day := range strings.Split(value, ",") {
+			retryDay, err := strconv.Atoi(strings.TrimSpace(day))
+			if err != nil {
+				return config, fmt.Errorf("invalid DUNNING_RETRY_DAYS value %q: %w", value, err)
+			}
+			retryDays = append(retryDays, retryDay)
+		}
+		config.DunningRetryDays = retryDays
+	}
+
+	days := map[string]*int{
+		"TRIAL_REMINDER_DAYS":                &config.TrialReminderLeadDays,
+		"RENEWAL_REMINDER_DAYS":              &config.RenewalReminderLeadDays,
+		"RENEWAL_REMINDER_MIN_DURATION_DAYS": &config.RenewalReminderMinDurationDays,
+		"REACTIVATION_WINDOW_DAYS":           &config.ReactivationWindowDays,
+		"GIFT_VALIDITY_DAYS":                 &config.GiftValidityDays,
+		"REFERRAL_REWARD_DAYS":               &config.ReferralRewardDays,
+		"PRICE_CHANGE_NOTICE_DAYS":           &config.PriceChangeNoticeDays,
+	}
+	for name, target := range days {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		day, err := strconv.Atoi(value)
+		if err != nil {
+			return config, fmt.Errorf("invalid %s value %q: %w", name, value, err)
+		}
+		*target = day
+	}
+
+	if value := os.Getenv("ENTITLEMENT_CACHE_TTL"); value != "" {
+		ttl, err := time.ParseDuration(value)
+		if err != nil {
+			return config, fmt.Errorf("invalid ENTITLEMENT_CACHE_TTL value %q: %w", value, err)
+		}
+		config.EntitlementCacheTTL = ttl
+	}
+
+	if value := os.Getenv("REFERRAL_REWARD"); value != "" {
+		reward := model.ReferralRewardKind(value)
+		if !reward.Valid() {
+			return config, fmt.Errorf("invalid REFERRAL_REWARD value %q, must be credit or days", value)
+		}
+		config.ReferralReward = reward
+	}
+
+	if value := os.Getenv("REFERRAL_REWARD_CREDIT"); value != "" {
+		credit, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return config, fmt.Errorf("invalid REFERRAL_REWARD_CREDIT value %q: %w", value, err)
+		}
+		config.ReferralRewardCredit = credit
+	}
+
+	return config, nil
+}
+
+// loadOutboxSink returns the sink selected by OUTBOX_SINK, which is one of
+// "log" (the default), "memory" or "http". The http sink posts to
+// OUTBOX_SINK_URL.
+func loadOutboxSink() (outbox.Sink, error) {
+	switch value := os.Getenv("OUTBOX_SINK"); value {
+	case "", "log":
+		return outbox.NewLogSink(), nil
+	case "memory":
+		return outbox.NewMemorySink(), nil
+	case "http":
+		url := os.Getenv("OUTBOX_SINK_URL")
+		if url == "" {
+			return nil, fmt.Errorf("OUTBOX_SINK_URL is required for the http outbox sink")
+		}
+		return outbox.NewHTTPSink(url, outboxSinkTimeout), nil
+	default:
+		return nil, fmt.Errorf("unknown OUTBOX_SINK value %q", value)
+	}
+}
+
+// loadNotifier returns the notifier selected by NOTIFIER, which is one of
+// "log" (the default), "file" or "smtp". The file notifier writes to
+// NOTIFIER_DIR, the smtp notifier sends through SMTP_HOST and SMTP_PORT,
+// authenticating with SMTP_USERNAME and SMTP_PASSWORD when they are set.
+func loadNotifier() (service.Notifier, error) {
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = defaultMailFrom
+	}
+
+	switch value := os.Getenv("NOTIFIER"); value {
+	case "", "log":
+		return notification.NewLogNotifier(), nil
+	case "file":
+		dir := os.Getenv("NOTIFIER_DIR")
+		if dir == "" {
+			return nil, fmt.Errorf("NOTIFIER_DIR is required for the file notifier")
+		}
+		return notification.NewFileNotifier(dir, from), nil
+	case "smtp":
+		host, port := os.Getenv("SMTP_HOST"), os.Getenv("SMTP_PORT")
+		if host == "" || port == "" {
+			return nil, fmt.Errorf("SMTP_HOST and SMTP_PORT are required for the smtp notifier")
+		}
+		return notification.NewSMTPNotifier(host, port, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), from), nil
+	default:
+		return nil, fmt.Errorf("unknown NOTIFIER value %q", value)
+	}
 }
@@ -0,0 +1,51 @@
+package connection
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// clockLockKey is the session-level advisory lock that keeps instances whose
+// clock travels in time off databases that other instances use.
+const clockLockKey int64 = 0x636c6f636b
+
+// ErrDatabaseShared is returned when the clock lock is held in a mode that
+// conflicts with the one requested.
+var ErrDatabaseShared = errors.New("database is used by another instance")
+
+// ClaimDatabase takes the clock lock exclusively and holds it until the
+// process exits. Instances that travel in time claim their database, since
+// their clock is not the one other instances work with.
+func ClaimDatabase(ctx context.Context, db *sql.DB) error {
+	return holdClockLock(ctx, db, `select pg_try_advisory_lock($1)`)
+}
+
+// ShareDatabase takes the clock lock shared and holds it until the process
+// exits. Any number of instances on the system clock can share a database,
+// but none that travels in time can claim it meanwhile.
+func ShareDatabase(ctx context.Context, db *sql.DB) error {
+	return holdClockLock(ctx, db, `select pg_try_advisory_lock_shared($1)`)
+}
+
+func holdClockLock(ctx context.Context, db *sql.DB, query string) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to reserve connection for clock lock: %w", err)
+	}
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, query, clockLockKey).Scan(&locked); err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to take clock lock: %w", err)
+	}
+	if !locked {
+		conn.Close()
+		return ErrDatabaseShared
+	}
+
+	// the connection is never returned to the pool, so the session and the
+	// lock it holds last as long as the process
+	return nil
+}
@@ -0,0 +1,46 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upDunning, downDunning)
+}
+
+func upDunning(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		alter type subscription_status add value if not exists 'past_due';
+
+		alter table service.subscriptions
+			add column past_due_date timestamp,
+			add column grace_end_date timestamp,
+			add column next_payment_retry_date timestamp;
+
+		create type payment_attempt_status as enum ('succeeded', 'failed');
+		create table service.payment_attempts (
+			id uuid not null primary key,
+			subscription_id uuid references service.subscriptions(id) on delete cascade,
+			attempt_number int not null,
+			amount decimal(15,2) default 0 not null,
+			status payment_attempt_status not null,
+			transaction_id varchar(255),
+			failure_reason text,
+			attempted_at timestamp not null,
+			next_retry_date timestamp
+		);
+
+		create index payment_attempts_subscription_id_idx on service.payment_attempts (subscription_id);
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downDunning(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,61 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upInvoices, downInvoices)
+}
+
+func upInvoices(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		create table service.invoice_sequences (
+			year int not null primary key,
+			last_number int not null default 0
+		);
+
+		create table service.invoices (
+			id uuid not null primary key,
+			number varchar(32) not null unique,
+			year int not null,
+			sequence_number int not null,
+			subscription_id uuid references service.subscriptions(id) on delete restrict,
+			user_id uuid references service.users(id) on delete restrict,
+			customer_name varchar(511) not null,
+			customer_email varchar(255) not null,
+			issue_date timestamp not null,
+			period_start timestamp not null,
+			period_end timestamp not null,
+			net_amount decimal(15,2) default 0 not null,
+			tax_amount decimal(15,2) default 0 not null,
+			total_amount decimal(15,2) default 0 not null,
+			currency varchar(3) default 'EUR' not null,
+			unique (year, sequence_number)
+		);
+
+		create index invoices_subscription_id_idx on service.invoices (subscription_id);
+
+		create table service.invoice_line_items (
+			invoice_id uuid not null references service.invoices(id) on delete cascade,
+			position int not null,
+			description varchar(255) not null,
+			quantity int not null,
+			unit_price decimal(15,2) default 0 not null,
+			tax decimal(15,2) default 0 not null,
+			total_price decimal(15,2) default 0 not null,
+			primary key (invoice_id, position)
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downInvoices(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,66 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upRefunds, downRefunds)
+}
+
+func upRefunds(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		create type refund_policy as enum ('none', 'withdrawal_period', 'prorated');
+		alter table service.products
+			add column refund_policy refund_policy default 'withdrawal_period' not null,
+			add column withdrawal_period_days int default 14 not null;
+
+		update service.products set refund_policy = 'prorated'
+		where id = '29fdcb93-b52f-48a9-9e7e-b3e60d63d8a3';
+
+		create table service.credit_note_sequences (
+			year int not null primary key,
+			last_number int not null default 0
+		);
+
+		create table service.credit_notes (
+			id uuid not null primary key,
+			number varchar(32) not null unique,
+			year int not null,
+			sequence_number int not null,
+			invoice_id uuid not null references service.invoices(id) on delete restrict,
+			subscription_id uuid references service.subscriptions(id) on delete restrict,
+			user_id uuid references service.users(id) on delete restrict,
+			issue_date timestamp not null,
+			reason varchar(255) not null,
+			net_amount decimal(15,2) default 0 not null,
+			tax_amount decimal(15,2) default 0 not null,
+			total_amount decimal(15,2) default 0 not null,
+			currency varchar(3) default 'EUR' not null,
+			unique (year, sequence_number)
+		);
+
+		create type refund_status as enum ('succeeded', 'failed');
+		create table service.refunds (
+			id uuid not null primary key,
+			subscription_id uuid references service.subscriptions(id) on delete cascade,
+			credit_note_id uuid not null references service.credit_notes(id) on delete restrict,
+			amount decimal(15,2) default 0 not null,
+			status refund_status not null,
+			transaction_id varchar(255),
+			failure_reason text,
+			created_at timestamp not null
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downRefunds(tx *sql.Tx) error {
+	return nil
+}
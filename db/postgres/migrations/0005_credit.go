@@ -0,0 +1,52 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upCredit, downCredit)
+}
+
+func upCredit(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		create type credit_transaction_kind as enum ('grant', 'refund', 'proration', 'consumption');
+		create table service.credit_transactions (
+			id uuid not null primary key,
+			user_id uuid not null references service.users(id) on delete cascade,
+			kind credit_transaction_kind not null,
+			reason varchar(255) not null,
+			subscription_id uuid references service.subscriptions(id) on delete set null,
+			created_at timestamp not null
+		);
+
+		create index credit_transactions_user_id_idx on service.credit_transactions (user_id);
+
+		create table service.credit_entries (
+			id bigserial primary key,
+			transaction_id uuid not null references service.credit_transactions(id) on delete cascade,
+			account varchar(64) not null,
+			user_id uuid references service.users(id) on delete cascade,
+			amount decimal(15,2) not null
+		);
+
+		create index credit_entries_account_user_id_idx on service.credit_entries (account, user_id);
+
+		alter table service.payment_attempts
+			add column credit_applied decimal(15,2) default 0 not null;
+
+		alter table service.refunds
+			add column credit_amount decimal(15,2) default 0 not null;
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downCredit(tx *sql.Tx) error {
+	return nil
+}
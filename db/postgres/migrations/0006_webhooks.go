@@ -0,0 +1,57 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upWebhooks, downWebhooks)
+}
+
+func upWebhooks(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		create table service.webhook_endpoints (
+			id uuid not null primary key,
+			url varchar(2048) not null,
+			secret varchar(255) not null,
+			event_types varchar(255) default '' not null,
+			active boolean default true not null,
+			created_at timestamp not null
+		);
+
+		create table service.webhook_events (
+			id uuid not null primary key,
+			type varchar(64) not null,
+			subscription_id uuid not null references service.subscriptions(id) on delete cascade,
+			payload jsonb not null,
+			created_at timestamp not null
+		);
+
+		create type webhook_delivery_status as enum ('pending', 'delivered', 'dead');
+		create table service.webhook_deliveries (
+			id uuid not null primary key,
+			event_id uuid not null references service.webhook_events(id) on delete cascade,
+			endpoint_id uuid not null references service.webhook_endpoints(id) on delete cascade,
+			status webhook_delivery_status default 'pending' not null,
+			attempts integer default 0 not null,
+			next_attempt_at timestamp,
+			last_error text,
+			delivered_at timestamp,
+			created_at timestamp not null
+		);
+
+		create index webhook_deliveries_pending_idx on service.webhook_deliveries (next_attempt_at)
+			where status = 'pending';
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downWebhooks(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upOutbox, downOutbox)
+}
+
+func upOutbox(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		create table service.outbox_messages (
+			id bigserial primary key,
+			event_id uuid not null unique,
+			subscription_id uuid not null references service.subscriptions(id) on delete cascade,
+			type varchar(64) not null,
+			payload jsonb not null,
+			created_at timestamp not null,
+			published_at timestamp
+		);
+
+		create index outbox_messages_unpublished_idx on service.outbox_messages (id)
+			where published_at is null;
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downOutbox(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upSubscriptionVersion, downSubscriptionVersion)
+}
+
+func upSubscriptionVersion(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		alter table service.subscriptions
+			add column version integer not null default 1;
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downSubscriptionVersion(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upUserLocale, downUserLocale)
+}
+
+func upUserLocale(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		alter table service.users
+			add column locale varchar(8) not null default 'de';
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downUserLocale(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upReminders, downReminders)
+}
+
+func upReminders(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		create table service.reminders (
+			id uuid primary key,
+			subscription_id uuid not null references service.subscriptions(id) on delete cascade,
+			kind varchar(64) not null,
+			event_date timestamp not null,
+			sent_at timestamp not null,
+			unique (subscription_id, kind, event_date)
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downReminders(tx *sql.Tx) error {
+	return nil
+}
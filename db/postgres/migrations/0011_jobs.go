@@ -0,0 +1,47 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upJobs, downJobs)
+}
+
+func upJobs(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		create type service.job_status as enum ('pending', 'running', 'succeeded', 'failed');
+
+		create table service.jobs (
+			id uuid primary key,
+			name varchar(64) not null,
+			status service.job_status not null default 'pending',
+			attempts integer not null default 0,
+			max_attempts integer not null,
+			run_at timestamp not null,
+			started_at timestamp,
+			finished_at timestamp,
+			last_error text not null default '',
+			created_at timestamp not null
+		);
+
+		-- at most one queued or running job per name, so a job never overlaps
+		-- with its next run
+		create unique index jobs_active_name_idx on service.jobs (name)
+			where status in ('pending', 'running');
+
+		create index jobs_run_at_idx on service.jobs (run_at)
+			where status in ('pending', 'running');
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downJobs(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,88 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upTimeZones, downTimeZones)
+}
+
+// upTimeZones stores every date as an instant. The existing values were
+// written in UTC, and existing subscriptions keep computing their dates in
+// UTC, while new ones use the time zone of their user.
+func upTimeZones(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		alter table service.subscriptions
+			alter column start_date type timestamptz using start_date at time zone 'UTC',
+			alter column end_date type timestamptz using end_date at time zone 'UTC',
+			alter column trial_start_date type timestamptz using trial_start_date at time zone 'UTC',
+			alter column trial_end_date type timestamptz using trial_end_date at time zone 'UTC',
+			alter column canceled_date type timestamptz using canceled_date at time zone 'UTC',
+			alter column paused_date type timestamptz using paused_date at time zone 'UTC',
+			alter column unpaused_date type timestamptz using unpaused_date at time zone 'UTC',
+			alter column past_due_date type timestamptz using past_due_date at time zone 'UTC',
+			alter column grace_end_date type timestamptz using grace_end_date at time zone 'UTC',
+			alter column next_payment_retry_date type timestamptz using next_payment_retry_date at time zone 'UTC';
+
+		alter table service.payment_attempts
+			alter column attempted_at type timestamptz using attempted_at at time zone 'UTC',
+			alter column next_retry_date type timestamptz using next_retry_date at time zone 'UTC';
+
+		alter table service.invoices
+			alter column issue_date type timestamptz using issue_date at time zone 'UTC',
+			alter column period_start type timestamptz using period_start at time zone 'UTC',
+			alter column period_end type timestamptz using period_end at time zone 'UTC';
+
+		alter table service.credit_notes
+			alter column issue_date type timestamptz using issue_date at time zone 'UTC';
+
+		alter table service.refunds
+			alter column created_at type timestamptz using created_at at time zone 'UTC';
+
+		alter table service.credit_transactions
+			alter column created_at type timestamptz using created_at at time zone 'UTC';
+
+		alter table service.webhook_endpoints
+			alter column created_at type timestamptz using created_at at time zone 'UTC';
+
+		alter table service.webhook_events
+			alter column created_at type timestamptz using created_at at time zone 'UTC';
+
+		alter table service.webhook_deliveries
+			alter column next_attempt_at type timestamptz using next_attempt_at at time zone 'UTC',
+			alter column delivered_at type timestamptz using delivered_at at time zone 'UTC',
+			alter column created_at type timestamptz using created_at at time zone 'UTC';
+
+		alter table service.outbox_messages
+			alter column created_at type timestamptz using created_at at time zone 'UTC',
+			alter column published_at type timestamptz using published_at at time zone 'UTC';
+
+		alter table service.reminders
+			alter column event_date type timestamptz using event_date at time zone 'UTC',
+			alter column sent_at type timestamptz using sent_at at time zone 'UTC';
+
+		alter table service.jobs
+			alter column run_at type timestamptz using run_at at time zone 'UTC',
+			alter column started_at type timestamptz using started_at at time zone 'UTC',
+			alter column finished_at type timestamptz using finished_at at time zone 'UTC',
+			alter column created_at type timestamptz using created_at at time zone 'UTC';
+
+		alter table service.users
+			add column time_zone varchar(64) not null default 'Europe/Berlin';
+
+		alter table service.subscriptions
+			add column time_zone varchar(64) not null default 'UTC';
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downTimeZones(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upScheduledPause, downScheduledPause)
+}
+
+func upScheduledPause(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		alter table service.subscriptions
+			add column pause_from timestamptz,
+			add column resume_on timestamptz;
+
+		create index subscriptions_pause_from_idx on service.subscriptions (pause_from)
+			where pause_from is not null;
+		create index subscriptions_resume_on_idx on service.subscriptions (resume_on)
+			where resume_on is not null;
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downScheduledPause(tx *sql.Tx) error {
+	return nil
+}
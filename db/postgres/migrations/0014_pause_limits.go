@@ -0,0 +1,52 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upPauseLimits, downPauseLimits)
+}
+
+func upPauseLimits(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		alter table service.products
+			add column max_pause_days int not null default 0,
+			add column max_pauses_per_period int not null default 0,
+			add column max_paused_days_per_year int not null default 0,
+			add column min_active_days_between_pauses int not null default 0;
+
+		update service.products
+		set
+			max_pause_days = 30,
+			max_pauses_per_period = 2,
+			max_paused_days_per_year = 60,
+			min_active_days_between_pauses = 30
+		where id = '29fdcb93-b52f-48a9-9e7e-b3e60d63d8a3';
+
+		create table service.subscription_pauses (
+			id uuid not null primary key,
+			subscription_id uuid not null references service.subscriptions(id) on delete cascade,
+			start_date timestamptz not null,
+			end_date timestamptz
+		);
+
+		create index subscription_pauses_subscription_id_idx on service.subscription_pauses (subscription_id);
+
+		insert into service.subscription_pauses (id, subscription_id, start_date)
+		select gen_random_uuid(), id, paused_date
+		from service.subscriptions
+		where status = 'paused' and paused_date is not null;
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downPauseLimits(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,45 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upReactivations, downReactivations)
+}
+
+func upReactivations(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		create type reactivation_pricing as enum ('current', 'locked');
+		alter table service.products
+			add column reactivation_pricing reactivation_pricing not null default 'current';
+
+		update service.products set reactivation_pricing = 'locked'
+		where id = '29fdcb93-b52f-48a9-9e7e-b3e60d63d8a3';
+
+		create table service.subscription_reactivations (
+			id uuid not null primary key,
+			subscription_id uuid not null references service.subscriptions(id) on delete cascade,
+			canceled_date timestamptz not null,
+			reactivated_at timestamptz not null,
+			pricing reactivation_pricing not null,
+			price decimal(15,2) not null,
+			tax decimal(15,2) not null,
+			total_price decimal(15,2) not null
+		);
+
+		create index subscription_reactivations_subscription_id_idx
+			on service.subscription_reactivations (subscription_id);
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downReactivations(tx *sql.Tx) error {
+	return nil
+}
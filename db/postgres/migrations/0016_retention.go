@@ -0,0 +1,69 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upRetention, downRetention)
+}
+
+func upRetention(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		create type cancellation_reason as enum (
+			'too_expensive', 'not_using', 'temporary_break', 'found_alternative', 'technical_issues', 'other'
+		);
+		create type retention_offer_kind as enum ('pause', 'downgrade', 'voucher');
+
+		create table service.cancellations (
+			id uuid not null primary key,
+			subscription_id uuid not null references service.subscriptions(id) on delete cascade,
+			reason cancellation_reason,
+			reason_text text,
+			canceled_at timestamptz not null
+		);
+
+		create index cancellations_reason_idx on service.cancellations (reason);
+
+		create table service.retention_offers (
+			id uuid not null primary key,
+			reason cancellation_reason not null,
+			kind retention_offer_kind not null,
+			description text not null,
+			pause_days integer,
+			product_id uuid references service.products(id),
+			voucher_code varchar(255) references service.vouchers(code),
+			active boolean not null default true
+		);
+
+		insert into service.retention_offers (id, reason, kind, description, pause_days, product_id, voucher_code) values
+			('6a0f2c3e-8d4b-4f6e-9a51-2b7c1d9e4f10', 'too_expensive', 'downgrade', 'Switch to the basic plan', null, 'a72d8c5c-cb57-42d2-b3b2-13e9ed06403b', null),
+			('0b7e5d21-3c9a-4e8f-b6d2-7f1a4c8e2d35', 'too_expensive', 'voucher', '10% off your next periods', null, null, 'discount10'),
+			('d3c81f47-5a2e-4b9d-8e60-1f4b7a2c9e58', 'not_using', 'pause', 'Take a break for 30 days', 30, null, null),
+			('9e4a6b12-7f3d-4c85-a1e9-5d2b8c7f0a63', 'temporary_break', 'pause', 'Take a break for 60 days', 60, null, null),
+			('4f2d9c85-1b6e-4a37-9d08-3e5c7a1b2f94', 'found_alternative', 'voucher', '25% off your next periods', null, null, 'summer25');
+
+		create table service.retention_offer_acceptances (
+			id uuid not null primary key,
+			subscription_id uuid not null references service.subscriptions(id) on delete cascade,
+			offer_id uuid not null references service.retention_offers(id),
+			reason cancellation_reason not null,
+			kind retention_offer_kind not null,
+			accepted_at timestamptz not null
+		);
+
+		create index retention_offer_acceptances_offer_id_idx
+			on service.retention_offer_acceptances (offer_id);
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downRetention(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upEntitlements, downEntitlements)
+}
+
+func upEntitlements(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		alter table service.products add column entitlements varchar(1024) default '' not null;
+
+		update service.products set entitlements = 'workouts'
+		where id = 'a72d8c5c-cb57-42d2-b3b2-13e9ed06403b';
+		update service.products set entitlements = 'workouts,nutrition_plans'
+		where id = '9b1e0b0b-3c34-4cfa-8f63-5d12b3feff34';
+		update service.products set entitlements = 'workouts,nutrition_plans,premium_classes'
+		where id = 'ab97234d-6b4a-4a70-823e-68b7a80ef6d4';
+		update service.products set entitlements = 'workouts,nutrition_plans,premium_classes,personal_coaching'
+		where id = '29fdcb93-b52f-48a9-9e7e-b3e60d63d8a3';
+
+		create index subscriptions_user_id_idx on service.subscriptions (user_id);
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downEntitlements(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,60 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upAddOns, downAddOns)
+}
+
+func upAddOns(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		create type product_kind as enum ('base', 'add_on');
+		alter table service.products add column kind product_kind not null default 'base';
+
+		create table service.product_add_ons (
+			add_on_product_id uuid not null references service.products(id) on delete cascade,
+			base_product_id uuid not null references service.products(id) on delete cascade,
+			primary key (add_on_product_id, base_product_id)
+		);
+
+		insert into service.products (id, name, duration_days, price, tax, total_price, kind, entitlements) values
+			('5e3a1c7d-92b4-4f0e-8d6a-1b2c3d4e5f60', 'nutrition coaching', 30, 5.00, 0.50, 5.50, 'add_on', 'nutrition_coaching'),
+			('b8f4d2e6-3a1c-4e7b-9f05-6c7d8e9f0a12', 'live classes', 30, 8.00, 0.80, 8.80, 'add_on', 'live_classes');
+
+		insert into service.product_add_ons (add_on_product_id, base_product_id) values
+			('5e3a1c7d-92b4-4f0e-8d6a-1b2c3d4e5f60', '9b1e0b0b-3c34-4cfa-8f63-5d12b3feff34'),
+			('5e3a1c7d-92b4-4f0e-8d6a-1b2c3d4e5f60', 'ab97234d-6b4a-4a70-823e-68b7a80ef6d4'),
+			('b8f4d2e6-3a1c-4e7b-9f05-6c7d8e9f0a12', 'a72d8c5c-cb57-42d2-b3b2-13e9ed06403b'),
+			('b8f4d2e6-3a1c-4e7b-9f05-6c7d8e9f0a12', '9b1e0b0b-3c34-4cfa-8f63-5d12b3feff34'),
+			('b8f4d2e6-3a1c-4e7b-9f05-6c7d8e9f0a12', 'ab97234d-6b4a-4a70-823e-68b7a80ef6d4');
+
+		create type add_on_status as enum ('active', 'paused', 'canceled');
+
+		create table service.subscription_add_ons (
+			id uuid not null primary key,
+			subscription_id uuid not null references service.subscriptions(id) on delete cascade,
+			product_id uuid not null references service.products(id),
+			price decimal(15,2) not null,
+			tax decimal(15,2) not null,
+			total_price decimal(15,2) not null,
+			status add_on_status not null,
+			start_date timestamptz not null,
+			canceled_date timestamptz
+		);
+
+		create index subscription_add_ons_subscription_id_idx on service.subscription_add_ons (subscription_id);
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downAddOns(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,44 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upFamilyPlans, downFamilyPlans)
+}
+
+func upFamilyPlans(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		alter table service.products add column max_members int not null default 0;
+
+		insert into service.products (id, name, duration_days, price, tax, total_price, entitlements, max_members) values
+			('c3f1e2d4-7a6b-4c5d-9e8f-0a1b2c3d4e5f', 'family', 30, 20.00, 2.00, 22.00, 'workouts,nutrition_plans', 4);
+
+		create type member_status as enum ('invited', 'active', 'removed');
+
+		create table service.subscription_members (
+			id uuid not null primary key,
+			subscription_id uuid not null references service.subscriptions(id) on delete cascade,
+			user_id uuid not null references service.users(id),
+			status member_status not null,
+			invited_at timestamptz not null,
+			accepted_at timestamptz,
+			removed_at timestamptz
+		);
+
+		create index subscription_members_subscription_id_idx on service.subscription_members (subscription_id);
+		create index subscription_members_user_id_idx on service.subscription_members (user_id);
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downFamilyPlans(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,66 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upOrganizations, downOrganizations)
+}
+
+func upOrganizations(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		create table service.organizations (
+			id uuid not null primary key,
+			name varchar(255) not null,
+			vat_id varchar(32),
+			address varchar(1024) not null,
+			domain varchar(255) unique,
+			created_at timestamptz not null
+		);
+
+		create table service.organization_admins (
+			organization_id uuid not null references service.organizations(id) on delete cascade,
+			user_id uuid not null references service.users(id),
+			primary key (organization_id, user_id)
+		);
+
+		alter table service.products add column seat_based boolean not null default false;
+		update service.products set seat_based = true where id = '29fdcb93-b52f-48a9-9e7e-b3e60d63d8a3';
+
+		alter table service.subscriptions
+			add column organization_id uuid references service.organizations(id),
+			add column seats int not null default 1;
+
+		create index subscriptions_organization_id_idx on service.subscriptions (organization_id);
+
+		create table service.seat_changes (
+			id uuid not null primary key,
+			subscription_id uuid not null references service.subscriptions(id) on delete cascade,
+			seats_before int not null,
+			seats_after int not null,
+			change_date timestamptz not null,
+			price decimal(15,2) not null,
+			tax decimal(15,2) not null,
+			total_price decimal(15,2) not null
+		);
+
+		create index seat_changes_subscription_id_idx on service.seat_changes (subscription_id);
+
+		alter table service.invoices
+			add column company_name varchar(255),
+			add column vat_id varchar(32),
+			add column billing_address varchar(1024);
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downOrganizations(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,50 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upGifts, downGifts)
+}
+
+func upGifts(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		create type gift_status as enum ('purchased', 'redeemed', 'expired');
+
+		create table service.gifts (
+			id uuid not null primary key,
+			code varchar(32) not null unique,
+			buyer_id uuid not null references service.users(id) on delete restrict,
+			product_id uuid not null references service.products(id) on delete restrict,
+			duration_days int not null,
+			price decimal(15,2) not null,
+			tax decimal(15,2) not null,
+			total_price decimal(15,2) not null,
+			transaction_id varchar(255) not null,
+			status gift_status not null default 'purchased',
+			purchased_at timestamptz not null,
+			expires_at timestamptz not null,
+			redeemed_by uuid references service.users(id) on delete restrict,
+			redeemed_at timestamptz,
+			subscription_id uuid references service.subscriptions(id) on delete set null
+		);
+
+		create index gifts_buyer_id_idx on service.gifts (buyer_id);
+		create index gifts_expires_at_idx on service.gifts (expires_at) where status = 'purchased';
+
+		alter table service.invoices
+			add column gift_id uuid references service.gifts(id) on delete restrict;
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downGifts(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,61 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upReferrals, downReferrals)
+}
+
+func upReferrals(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		alter type credit_transaction_kind add value if not exists 'referral';
+
+		create table service.referral_codes (
+			user_id uuid not null primary key references service.users(id) on delete cascade,
+			code varchar(32) not null unique,
+			created_at timestamptz not null
+		);
+
+		create type referral_status as enum ('pending', 'rewarded', 'void');
+		create table service.referrals (
+			id uuid not null primary key,
+			code varchar(32) not null,
+			referrer_id uuid not null references service.users(id) on delete restrict,
+			referee_id uuid not null unique references service.users(id) on delete restrict,
+			subscription_id uuid not null references service.subscriptions(id) on delete cascade,
+			status referral_status not null default 'pending',
+			created_at timestamptz not null,
+			settled_at timestamptz
+		);
+
+		create index referrals_referrer_id_idx on service.referrals (referrer_id);
+		create index referrals_pending_idx on service.referrals (created_at) where status = 'pending';
+
+		create type referral_reward_kind as enum ('credit', 'days');
+		create table service.referral_rewards (
+			id uuid not null primary key,
+			referral_id uuid not null references service.referrals(id) on delete cascade,
+			user_id uuid not null references service.users(id) on delete restrict,
+			kind referral_reward_kind not null,
+			credit decimal(15,2) not null default 0,
+			days int not null default 0,
+			subscription_id uuid references service.subscriptions(id) on delete set null,
+			created_at timestamptz not null
+		);
+
+		create index referral_rewards_user_id_idx on service.referral_rewards (user_id);
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downReferrals(tx *sql.Tx) error {
+	return nil
+}
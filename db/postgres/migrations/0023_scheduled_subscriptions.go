@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upScheduledSubscriptions, downScheduledSubscriptions)
+}
+
+func upScheduledSubscriptions(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		alter type subscription_status add value if not exists 'scheduled';
+
+		create index subscriptions_start_date_idx on service.subscriptions (start_date);
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downScheduledSubscriptions(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,56 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upPriceMigrations, downPriceMigrations)
+}
+
+func upPriceMigrations(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		create table service.price_migrations (
+			id uuid not null primary key,
+			product_id uuid not null references service.products(id) on delete cascade,
+			price decimal(15,2) not null,
+			tax decimal(15,2) not null,
+			total_price decimal(15,2) not null,
+			notice_days int not null,
+			created_at timestamptz not null
+		);
+		create index price_migrations_product_id_idx on service.price_migrations (product_id);
+
+		create type price_change_status as enum ('scheduled', 'applied', 'opted_out');
+		create table service.price_changes (
+			id uuid not null primary key,
+			migration_id uuid not null references service.price_migrations(id) on delete cascade,
+			subscription_id uuid not null references service.subscriptions(id) on delete cascade,
+			user_id uuid not null references service.users(id) on delete cascade,
+			duration_days int not null,
+			old_price decimal(15,2) not null,
+			old_tax decimal(15,2) not null,
+			old_total_price decimal(15,2) not null,
+			price decimal(15,2) not null,
+			tax decimal(15,2) not null,
+			total_price decimal(15,2) not null,
+			effective_date timestamptz not null,
+			status price_change_status not null default 'scheduled',
+			applied_at timestamptz,
+			opted_out_at timestamptz
+		);
+		create index price_changes_migration_id_idx on service.price_changes (migration_id);
+		create index price_changes_subscription_id_idx on service.price_changes (subscription_id);
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downPriceMigrations(tx *sql.Tx) error {
+	return nil
+}
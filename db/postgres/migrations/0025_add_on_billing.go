@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upAddOnBilling, downAddOnBilling)
+}
+
+func upAddOnBilling(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		alter table service.subscription_add_ons add column billed_until timestamptz;
+
+		-- add-ons attached before the current period of their subscription
+		-- were charged with its renewal
+		update service.subscription_add_ons a
+		set billed_until = s.end_date
+		from service.subscriptions s
+		where s.id = a.subscription_id and a.status <> 'canceled' and a.start_date < s.start_date;
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downAddOnBilling(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,47 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upSeatRemovalCredits, downSeatRemovalCredits)
+}
+
+// removed seats used to be credited on the next renewal invoice and are now
+// credited to the owner's balance right away, so removals that no invoice
+// has credited yet are credited now
+func upSeatRemovalCredits(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		with removals as (
+			select
+				gen_random_uuid() as transaction_id,
+				s.user_id,
+				s.id as subscription_id,
+				c.seats_before - c.seats_after as seats,
+				-c.total_price as amount
+			from service.seat_changes c
+			join service.subscriptions s on s.id = c.subscription_id
+			where c.total_price < 0 and c.change_date >= s.start_date and s.status <> 'canceled'
+		), transactions as (
+			insert into service.credit_transactions (id, user_id, kind, reason, subscription_id, created_at)
+			select transaction_id, user_id, 'proration', 'Unused days of ' || seats || ' removed seats', subscription_id, now()
+			from removals
+		)
+		insert into service.credit_entries (transaction_id, account, user_id, amount)
+		select transaction_id, 'customer_credit', user_id, amount from removals
+		union all
+		select transaction_id, 'prorations', null, -amount from removals;
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downSeatRemovalCredits(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upPendingRefunds, downPendingRefunds)
+}
+
+// refunds are recorded together with the cancellation and paid back through
+// the payment gateway afterwards
+func upPendingRefunds(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		alter type refund_status add value if not exists 'pending' before 'succeeded';
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downPendingRefunds(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upPriceMigrationProductPrice, downPriceMigrationProductPrice)
+}
+
+// products used to move to the new price right away and now only once the
+// notice period of the migration has passed
+func upPriceMigrationProductPrice(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		alter table service.price_migrations add column product_price_applied_at timestamptz;
+
+		update service.price_migrations set product_price_applied_at = created_at;
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downPriceMigrationProductPrice(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/pressly/goose"
+)
+
+func init() {
+	goose.AddMigration(upTeamPlan, downTeamPlan)
+}
+
+// the enterprise plan was made seat based for organizations, which locked out
+// its individual subscribers; organizations get a seat based plan of their own
+func upTeamPlan(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		insert into service.products (id, name, duration_days, price, tax, total_price, refund_policy,
+			withdrawal_period_days, max_pause_days, max_pauses_per_period, max_paused_days_per_year,
+			min_active_days_between_pauses, reactivation_pricing, entitlements, kind, max_members, seat_based)
+		select 'e4a7c2b9-5d3f-4b8e-a1c6-7f9d0e2b3a58', 'team', duration_days, price, tax, total_price, refund_policy,
+			withdrawal_period_days, max_pause_days, max_pauses_per_period, max_paused_days_per_year,
+			min_active_days_between_pauses, reactivation_pricing, entitlements, kind, max_members, true
+		from service.products where id = '29fdcb93-b52f-48a9-9e7e-b3e60d63d8a3';
+
+		update service.subscriptions set product_id = 'e4a7c2b9-5d3f-4b8e-a1c6-7f9d0e2b3a58'
+		where product_id = '29fdcb93-b52f-48a9-9e7e-b3e60d63d8a3' and organization_id is not null;
+
+		update service.products set seat_based = false where id = '29fdcb93-b52f-48a9-9e7e-b3e60d63d8a3';
+	`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func downTeamPlan(tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,129 @@
+package rest
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gymondo/internal/model"
+	"net/http"
+	"testing"
+)
+
+func Test_AttachAddOn(t *testing.T) {
+	t.Parallel()
+
+	subscriptionID := uuid.New()
+	productID := uuid.New()
+
+	t.Run("successful", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().AttachAddOn(gomock.Any(), subscriptionID.String(), 0, productID.String()).Return(model.SubscriptionAddOn{
+			ID:             uuid.New(),
+			SubscriptionID: subscriptionID,
+			ProductID:      productID,
+			Name:           "live classes",
+			TotalPrice:     8.8,
+			Status:         model.AddOnActive,
+		}, nil)
+		mockService.EXPECT().FindSubscription(gomock.Any(), subscriptionID.String()).Return(model.Subscription{Version: 3}, nil)
+
+		r := gin.Default()
+		r.POST("/api/subscription/:subscription_id/add-ons", server.attachAddOn)
+
+		requestBody := `{"product_id": "` + productID.String() + `"}`
+		w := performPostRequest(r, "/api/subscription/"+subscriptionID.String()+"/add-ons", requestBody)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"name":"live classes"`)
+		assert.Contains(t, w.Body.String(), `"status":"active"`)
+	})
+
+	t.Run("missing product ID", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		r := gin.Default()
+		r.POST("/api/subscription/:subscription_id/add-ons", server.attachAddOn)
+
+		w := performPostRequest(r, "/api/subscription/"+subscriptionID.String()+"/add-ons", `{}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("stale If-Match", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().AttachAddOn(gomock.Any(), subscriptionID.String(), 2, productID.String()).
+			Return(model.SubscriptionAddOn{}, fmt.Errorf("failed to attach add-on: %w", model.ErrSubscriptionConflict))
+
+		r := gin.Default()
+		r.POST("/api/subscription/:subscription_id/add-ons", server.attachAddOn)
+
+		requestBody := `{"product_id": "` + productID.String() + `"}`
+		w := performPostRequestWithIfMatch(r, "/api/subscription/"+subscriptionID.String()+"/add-ons", requestBody, `"2"`)
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+}
+
+func Test_DetachAddOn(t *testing.T) {
+	t.Parallel()
+
+	subscriptionID := uuid.New()
+	addOnID := uuid.New()
+
+	t.Run("successful", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().DetachAddOn(gomock.Any(), subscriptionID.String(), 0, addOnID.String()).Return(4.4, nil)
+		mockService.EXPECT().FindSubscription(gomock.Any(), subscriptionID.String()).Return(model.Subscription{Version: 3}, nil)
+
+		r := gin.Default()
+		r.DELETE("/api/subscription/:subscription_id/add-ons/:add_on_id", server.detachAddOn)
+
+		w := performRequest(r, "DELETE", "/api/subscription/"+subscriptionID.String()+"/add-ons/"+addOnID.String())
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"credit_amount":4.4`)
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().DetachAddOn(gomock.Any(), subscriptionID.String(), 0, addOnID.String()).Return(0.0, fmt.Errorf("database error"))
+
+		r := gin.Default()
+		r.DELETE("/api/subscription/:subscription_id/add-ons/:add_on_id", server.detachAddOn)
+
+		w := performRequest(r, "DELETE", "/api/subscription/"+subscriptionID.String()+"/add-ons/"+addOnID.String())
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
@@ -0,0 +1,119 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AttachAddOnRequest struct {
+	ProductID string `json:"product_id" binding:"required" example:"5e3a1c7d-92b4-4f0e-8d6a-1b2c3d4e5f60"`
+}
+
+type DetachAddOnResponse struct {
+	SubscriptionID string  `json:"subscription_id"`
+	AddOnID        string  `json:"add_on_id"`
+	Message        string  `json:"message"`
+	CreditAmount   float64 `json:"credit_amount"`
+}
+
+// @Summary Attach an add-on
+// @Description Attaches an add-on product to an active subscription to one of the add-on's base products. The add-on is priced per period of the subscription and billed with its next renewal, together with the prorated days until then. It is paused and canceled together with the subscription.
+// @Tags Subscription
+// @Accept json
+// @Produce json
+// @Param subscription_id path string true "Subscription ID"
+// @Param If-Match header string false "ETag of the subscription the change is based on"
+// @Param request body AttachAddOnRequest true "Add-on product"
+// @Success 200 {object} model.SubscriptionAddOn
+// @Header 200 {string} ETag "Version of the subscription after the change"
+// @Failure 400 {object} ErrorResponse "Validation error"
+// @Failure 409 {object} ErrorResponse "Subscription was modified concurrently"
+// @Failure 412 {object} ErrorResponse "Subscription does not match If-Match"
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /api/v1/subscription/{subscription_id}/add-ons [post]
+func (s *Server) attachAddOn(c *gin.Context) {
+	ctx := context.Background()
+	subscriptionID := c.Param("subscription_id")
+
+	var request AttachAddOnRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	version, err := parseIfMatch(c.GetHeader("If-Match"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid If-Match header",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	addOn, err := s.service.AttachAddOn(ctx, subscriptionID, version, request.ProductID)
+	if err != nil {
+		log.Printf("Error attaching add-on %s to subscription %s: %v", request.ProductID, subscriptionID, err)
+		c.JSON(manageErrorStatus(err, version), ErrorResponse{
+			Error:   "Failed to attach add-on",
+			Details: fmt.Sprintf("Error attaching add-on: %v", err),
+		})
+		return
+	}
+
+	s.setSubscriptionETag(ctx, c, subscriptionID)
+	c.JSON(http.StatusOK, addOn)
+}
+
+// @Summary Detach an add-on
+// @Description Cancels an add-on of the subscription right away. If the add-on was paid for the current period, its unused days are credited to the user's credit balance.
+// @Tags Subscription
+// @Produce json
+// @Param subscription_id path string true "Subscription ID"
+// @Param add_on_id path string true "Add-on ID"
+// @Param If-Match header string false "ETag of the subscription the change is based on"
+// @Success 200 {object} DetachAddOnResponse
+// @Header 200 {string} ETag "Version of the subscription after the change"
+// @Failure 400 {object} ErrorResponse "Invalid If-Match header"
+// @Failure 409 {object} ErrorResponse "Subscription was modified concurrently"
+// @Failure 412 {object} ErrorResponse "Subscription does not match If-Match"
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /api/v1/subscription/{subscription_id}/add-ons/{add_on_id} [delete]
+func (s *Server) detachAddOn(c *gin.Context) {
+	ctx := context.Background()
+	subscriptionID := c.Param("subscription_id")
+	addOnID := c.Param("add_on_id")
+
+	version, err := parseIfMatch(c.GetHeader("If-Match"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid If-Match header",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	credit, err := s.service.DetachAddOn(ctx, subscriptionID, version, addOnID)
+	if err != nil {
+		log.Printf("Error detaching add-on %s from subscription %s: %v", addOnID, subscriptionID, err)
+		c.JSON(manageErrorStatus(err, version), ErrorResponse{
+			Error:   "Failed to detach add-on",
+			Details: fmt.Sprintf("Error detaching add-on: %v", err),
+		})
+		return
+	}
+
+	s.setSubscriptionETag(ctx, c, subscriptionID)
+	c.JSON(http.StatusOK, DetachAddOnResponse{
+		SubscriptionID: subscriptionID,
+		AddOnID:        addOnID,
+		Message:        "Add-on detached",
+		CreditAmount:   credit,
+	})
+}
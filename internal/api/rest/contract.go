@@ -3,6 +3,7 @@ package rest
 
 import (
 	"context"
+	"time"
 
 	"gymondo/internal/model"
 )
@@ -16,10 +17,74 @@ type service interface {
 		userID string,
 		productID string,
 		voucherCode string,
+		referralCode string,
+		startDate *time.Time,
 		trialPeriod bool,
 	) (subscriptionID string, err error)
 	FindSubscription(ctx context.Context, subscriptionID string) (model.Subscription, error)
-	PauseSubscription(ctx context.Context, subscriptionID string) error
-	UnpauseSubscription(ctx context.Context, subscriptionID string) error
-	CancelSubscription(ctx context.Context, subscriptionID string) error
+	PauseSubscription(ctx context.Context, subscriptionID string, version int, schedule model.PauseSchedule) error
+	CancelScheduledPause(ctx context.Context, subscriptionID string, version int) error
+	UnpauseSubscription(ctx context.Context, subscriptionID string, version int) error
+	CancelSubscription(
+		ctx context.Context,
+		subscriptionID string,
+		version int,
+		survey model.CancellationSurvey,
+	) (model.Refund, error)
+	FindRetentionOffers(
+		ctx context.Context,
+		subscriptionID string,
+		reason model.CancellationReason,
+	) ([]model.RetentionOffer, error)
+	AcceptRetentionOffer(ctx context.Context, subscriptionID string, version int, offerID string) error
+	ReactivateSubscription(ctx context.Context, subscriptionID string, version int) error
+	DeclinePriceChange(ctx context.Context, subscriptionID string, version int) (model.PriceChange, error)
+	AttachAddOn(ctx context.Context, subscriptionID string, version int, productID string) (model.SubscriptionAddOn, error)
+	DetachAddOn(ctx context.Context, subscriptionID string, version int, addOnID string) (float64, error)
+	InviteMember(ctx context.Context, subscriptionID string, version int, userID string) (model.SubscriptionMember, error)
+	AcceptInvitation(ctx context.Context, subscriptionID string, memberID string) (model.SubscriptionMember, error)
+	RemoveMember(ctx context.Context, subscriptionID string, version int, memberID string) error
+	CreateOrganization(ctx context.Context, organization model.Organization, adminID string) (model.Organization, error)
+	FindOrganization(ctx context.Context, organizationID string) (model.Organization, error)
+	AddOrganizationAdmin(ctx context.Context, organizationID string, userID string) error
+	SubscribeOrganization(ctx context.Context, organizationID string, adminID string, productID string, seats int) (string, error)
+	ChangeSeats(ctx context.Context, subscriptionID string, version int, seats int) (model.SeatChange, error)
+	JoinOrganization(ctx context.Context, organizationID string, userID string) (model.SubscriptionMember, error)
+	PurchaseGift(ctx context.Context, buyerID string, productID string, periods int) (model.Gift, error)
+	FindGift(ctx context.Context, code string) (model.Gift, error)
+	RedeemGift(ctx context.Context, code string, userID string) (model.Subscription, error)
+	CreateReferralCode(ctx context.Context, userID string) (model.ReferralCode, error)
+	FindReferralStats(ctx context.Context, userID string) (model.ReferralStats, error)
+	FindReactivations(ctx context.Context, subscriptionID string) ([]model.Reactivation, error)
+	FindPaymentAttempts(ctx context.Context, subscriptionID string) ([]model.PaymentAttempt, error)
+	FindInvoice(ctx context.Context, invoiceID string) (model.Invoice, error)
+	FindSubscriptionInvoices(ctx context.Context, subscriptionID string) ([]model.Invoice, error)
+	GrantCredit(ctx context.Context, userID string, amount float64, reason string) (model.CreditTransaction, error)
+	FindCreditBalance(ctx context.Context, userID string) (model.CreditBalance, error)
+	MigratePrice(
+		ctx context.Context,
+		productID string,
+		price float64,
+		tax float64,
+		dryRun bool,
+	) (model.PriceMigration, error)
+	FindPriceMigration(ctx context.Context, migrationID string) (model.PriceMigration, error)
+	FindEntitlements(ctx context.Context, userID string) (model.Entitlements, error)
+	RegisterWebhookEndpoint(
+		ctx context.Context,
+		url string,
+		eventTypes []model.WebhookEventType,
+	) (model.WebhookEndpoint, error)
+	FindWebhookEndpoints(ctx context.Context) ([]model.WebhookEndpoint, error)
+	DeleteWebhookEndpoint(ctx context.Context, endpointID string) error
+	FindWebhookDeliveries(ctx context.Context, status model.WebhookDeliveryStatus) ([]model.WebhookDelivery, error)
+	ReplayWebhookDelivery(ctx context.Context, deliveryID string) (model.WebhookDelivery, error)
+	FindJobs(ctx context.Context, status model.JobStatus) ([]model.Job, error)
+}
+
+type timeTraveler interface {
+	Now() time.Time
+	Offset() time.Duration
+	Travel(d time.Duration) error
+	Reset()
 }
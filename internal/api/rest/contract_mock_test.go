@@ -13,6 +13,7 @@ import (
 	context "context"
 	model "gymondo/internal/model"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -40,18 +41,300 @@ func (m *Mockservice) EXPECT() *MockserviceMockRecorder {
 	return m.recorder
 }
 
-// CancelSubscription mocks base method.
-func (m *Mockservice) CancelSubscription(ctx context.Context, subscriptionID string) error {
+// AcceptInvitation mocks base method.
+func (m *Mockservice) AcceptInvitation(ctx context.Context, subscriptionID, memberID string) (model.SubscriptionMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptInvitation", ctx, subscriptionID, memberID)
+	ret0, _ := ret[0].(model.SubscriptionMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptInvitation indicates an expected call of AcceptInvitation.
+func (mr *MockserviceMockRecorder) AcceptInvitation(ctx, subscriptionID, memberID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptInvitation", reflect.TypeOf((*Mockservice)(nil).AcceptInvitation), ctx, subscriptionID, memberID)
+}
+
+// AcceptRetentionOffer mocks base method.
+func (m *Mockservice) AcceptRetentionOffer(ctx context.Context, subscriptionID string, version int, offerID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptRetentionOffer", ctx, subscriptionID, version, offerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AcceptRetentionOffer indicates an expected call of AcceptRetentionOffer.
+func (mr *MockserviceMockRecorder) AcceptRetentionOffer(ctx, subscriptionID, version, offerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptRetentionOffer", reflect.TypeOf((*Mockservice)(nil).AcceptRetentionOffer), ctx, subscriptionID, version, offerID)
+}
+
+// AddOrganizationAdmin mocks base method.
+func (m *Mockservice) AddOrganizationAdmin(ctx context.Context, organizationID, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddOrganizationAdmin", ctx, organizationID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddOrganizationAdmin indicates an expected call of AddOrganizationAdmin.
+func (mr *MockserviceMockRecorder) AddOrganizationAdmin(ctx, organizationID, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOrganizationAdmin", reflect.TypeOf((*Mockservice)(nil).AddOrganizationAdmin), ctx, organizationID, userID)
+}
+
+// AttachAddOn mocks base method.
+func (m *Mockservice) AttachAddOn(ctx context.Context, subscriptionID string, version int, productID string) (model.SubscriptionAddOn, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CancelSubscription", ctx, subscriptionID)
+	ret := m.ctrl.Call(m, "AttachAddOn", ctx, subscriptionID, version, productID)
+	ret0, _ := ret[0].(model.SubscriptionAddOn)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AttachAddOn indicates an expected call of AttachAddOn.
+func (mr *MockserviceMockRecorder) AttachAddOn(ctx, subscriptionID, version, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AttachAddOn", reflect.TypeOf((*Mockservice)(nil).AttachAddOn), ctx, subscriptionID, version, productID)
+}
+
+// CancelScheduledPause mocks base method.
+func (m *Mockservice) CancelScheduledPause(ctx context.Context, subscriptionID string, version int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelScheduledPause", ctx, subscriptionID, version)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
+// CancelScheduledPause indicates an expected call of CancelScheduledPause.
+func (mr *MockserviceMockRecorder) CancelScheduledPause(ctx, subscriptionID, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelScheduledPause", reflect.TypeOf((*Mockservice)(nil).CancelScheduledPause), ctx, subscriptionID, version)
+}
+
+// CancelSubscription mocks base method.
+func (m *Mockservice) CancelSubscription(ctx context.Context, subscriptionID string, version int, survey model.CancellationSurvey) (model.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelSubscription", ctx, subscriptionID, version, survey)
+	ret0, _ := ret[0].(model.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // CancelSubscription indicates an expected call of CancelSubscription.
-func (mr *MockserviceMockRecorder) CancelSubscription(ctx, subscriptionID any) *gomock.Call {
+func (mr *MockserviceMockRecorder) CancelSubscription(ctx, subscriptionID, version, survey any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelSubscription", reflect.TypeOf((*Mockservice)(nil).CancelSubscription), ctx, subscriptionID, version, survey)
+}
+
+// ChangeSeats mocks base method.
+func (m *Mockservice) ChangeSeats(ctx context.Context, subscriptionID string, version, seats int) (model.SeatChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeSeats", ctx, subscriptionID, version, seats)
+	ret0, _ := ret[0].(model.SeatChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeSeats indicates an expected call of ChangeSeats.
+func (mr *MockserviceMockRecorder) ChangeSeats(ctx, subscriptionID, version, seats any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeSeats", reflect.TypeOf((*Mockservice)(nil).ChangeSeats), ctx, subscriptionID, version, seats)
+}
+
+// CreateOrganization mocks base method.
+func (m *Mockservice) CreateOrganization(ctx context.Context, organization model.Organization, adminID string) (model.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrganization", ctx, organization, adminID)
+	ret0, _ := ret[0].(model.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOrganization indicates an expected call of CreateOrganization.
+func (mr *MockserviceMockRecorder) CreateOrganization(ctx, organization, adminID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrganization", reflect.TypeOf((*Mockservice)(nil).CreateOrganization), ctx, organization, adminID)
+}
+
+// CreateReferralCode mocks base method.
+func (m *Mockservice) CreateReferralCode(ctx context.Context, userID string) (model.ReferralCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReferralCode", ctx, userID)
+	ret0, _ := ret[0].(model.ReferralCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateReferralCode indicates an expected call of CreateReferralCode.
+func (mr *MockserviceMockRecorder) CreateReferralCode(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReferralCode", reflect.TypeOf((*Mockservice)(nil).CreateReferralCode), ctx, userID)
+}
+
+// DeclinePriceChange mocks base method.
+func (m *Mockservice) DeclinePriceChange(ctx context.Context, subscriptionID string, version int) (model.PriceChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeclinePriceChange", ctx, subscriptionID, version)
+	ret0, _ := ret[0].(model.PriceChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeclinePriceChange indicates an expected call of DeclinePriceChange.
+func (mr *MockserviceMockRecorder) DeclinePriceChange(ctx, subscriptionID, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeclinePriceChange", reflect.TypeOf((*Mockservice)(nil).DeclinePriceChange), ctx, subscriptionID, version)
+}
+
+// DeleteWebhookEndpoint mocks base method.
+func (m *Mockservice) DeleteWebhookEndpoint(ctx context.Context, endpointID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhookEndpoint", ctx, endpointID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhookEndpoint indicates an expected call of DeleteWebhookEndpoint.
+func (mr *MockserviceMockRecorder) DeleteWebhookEndpoint(ctx, endpointID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhookEndpoint", reflect.TypeOf((*Mockservice)(nil).DeleteWebhookEndpoint), ctx, endpointID)
+}
+
+// DetachAddOn mocks base method.
+func (m *Mockservice) DetachAddOn(ctx context.Context, subscriptionID string, version int, addOnID string) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DetachAddOn", ctx, subscriptionID, version, addOnID)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DetachAddOn indicates an expected call of DetachAddOn.
+func (mr *MockserviceMockRecorder) DetachAddOn(ctx, subscriptionID, version, addOnID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetachAddOn", reflect.TypeOf((*Mockservice)(nil).DetachAddOn), ctx, subscriptionID, version, addOnID)
+}
+
+// FindCreditBalance mocks base method.
+func (m *Mockservice) FindCreditBalance(ctx context.Context, userID string) (model.CreditBalance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindCreditBalance", ctx, userID)
+	ret0, _ := ret[0].(model.CreditBalance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindCreditBalance indicates an expected call of FindCreditBalance.
+func (mr *MockserviceMockRecorder) FindCreditBalance(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindCreditBalance", reflect.TypeOf((*Mockservice)(nil).FindCreditBalance), ctx, userID)
+}
+
+// FindEntitlements mocks base method.
+func (m *Mockservice) FindEntitlements(ctx context.Context, userID string) (model.Entitlements, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindEntitlements", ctx, userID)
+	ret0, _ := ret[0].(model.Entitlements)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindEntitlements indicates an expected call of FindEntitlements.
+func (mr *MockserviceMockRecorder) FindEntitlements(ctx, userID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelSubscription", reflect.TypeOf((*Mockservice)(nil).CancelSubscription), ctx, subscriptionID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindEntitlements", reflect.TypeOf((*Mockservice)(nil).FindEntitlements), ctx, userID)
+}
+
+// FindGift mocks base method.
+func (m *Mockservice) FindGift(ctx context.Context, code string) (model.Gift, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindGift", ctx, code)
+	ret0, _ := ret[0].(model.Gift)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindGift indicates an expected call of FindGift.
+func (mr *MockserviceMockRecorder) FindGift(ctx, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindGift", reflect.TypeOf((*Mockservice)(nil).FindGift), ctx, code)
+}
+
+// FindInvoice mocks base method.
+func (m *Mockservice) FindInvoice(ctx context.Context, invoiceID string) (model.Invoice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindInvoice", ctx, invoiceID)
+	ret0, _ := ret[0].(model.Invoice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindInvoice indicates an expected call of FindInvoice.
+func (mr *MockserviceMockRecorder) FindInvoice(ctx, invoiceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindInvoice", reflect.TypeOf((*Mockservice)(nil).FindInvoice), ctx, invoiceID)
+}
+
+// FindJobs mocks base method.
+func (m *Mockservice) FindJobs(ctx context.Context, status model.JobStatus) ([]model.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindJobs", ctx, status)
+	ret0, _ := ret[0].([]model.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindJobs indicates an expected call of FindJobs.
+func (mr *MockserviceMockRecorder) FindJobs(ctx, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindJobs", reflect.TypeOf((*Mockservice)(nil).FindJobs), ctx, status)
+}
+
+// FindOrganization mocks base method.
+func (m *Mockservice) FindOrganization(ctx context.Context, organizationID string) (model.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindOrganization", ctx, organizationID)
+	ret0, _ := ret[0].(model.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindOrganization indicates an expected call of FindOrganization.
+func (mr *MockserviceMockRecorder) FindOrganization(ctx, organizationID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOrganization", reflect.TypeOf((*Mockservice)(nil).FindOrganization), ctx, organizationID)
+}
+
+// FindPaymentAttempts mocks base method.
+func (m *Mockservice) FindPaymentAttempts(ctx context.Context, subscriptionID string) ([]model.PaymentAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPaymentAttempts", ctx, subscriptionID)
+	ret0, _ := ret[0].([]model.PaymentAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPaymentAttempts indicates an expected call of FindPaymentAttempts.
+func (mr *MockserviceMockRecorder) FindPaymentAttempts(ctx, subscriptionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPaymentAttempts", reflect.TypeOf((*Mockservice)(nil).FindPaymentAttempts), ctx, subscriptionID)
+}
+
+// FindPriceMigration mocks base method.
+func (m *Mockservice) FindPriceMigration(ctx context.Context, migrationID string) (model.PriceMigration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPriceMigration", ctx, migrationID)
+	ret0, _ := ret[0].(model.PriceMigration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPriceMigration indicates an expected call of FindPriceMigration.
+func (mr *MockserviceMockRecorder) FindPriceMigration(ctx, migrationID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPriceMigration", reflect.TypeOf((*Mockservice)(nil).FindPriceMigration), ctx, migrationID)
 }
 
 // FindProduct mocks base method.
@@ -99,6 +382,51 @@ func (mr *MockserviceMockRecorder) FindProductsWithVoucher(ctx, voucherCode any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindProductsWithVoucher", reflect.TypeOf((*Mockservice)(nil).FindProductsWithVoucher), ctx, voucherCode)
 }
 
+// FindReactivations mocks base method.
+func (m *Mockservice) FindReactivations(ctx context.Context, subscriptionID string) ([]model.Reactivation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindReactivations", ctx, subscriptionID)
+	ret0, _ := ret[0].([]model.Reactivation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindReactivations indicates an expected call of FindReactivations.
+func (mr *MockserviceMockRecorder) FindReactivations(ctx, subscriptionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindReactivations", reflect.TypeOf((*Mockservice)(nil).FindReactivations), ctx, subscriptionID)
+}
+
+// FindReferralStats mocks base method.
+func (m *Mockservice) FindReferralStats(ctx context.Context, userID string) (model.ReferralStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindReferralStats", ctx, userID)
+	ret0, _ := ret[0].(model.ReferralStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindReferralStats indicates an expected call of FindReferralStats.
+func (mr *MockserviceMockRecorder) FindReferralStats(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindReferralStats", reflect.TypeOf((*Mockservice)(nil).FindReferralStats), ctx, userID)
+}
+
+// FindRetentionOffers mocks base method.
+func (m *Mockservice) FindRetentionOffers(ctx context.Context, subscriptionID string, reason model.CancellationReason) ([]model.RetentionOffer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRetentionOffers", ctx, subscriptionID, reason)
+	ret0, _ := ret[0].([]model.RetentionOffer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRetentionOffers indicates an expected call of FindRetentionOffers.
+func (mr *MockserviceMockRecorder) FindRetentionOffers(ctx, subscriptionID, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRetentionOffers", reflect.TypeOf((*Mockservice)(nil).FindRetentionOffers), ctx, subscriptionID, reason)
+}
+
 // FindSubscription mocks base method.
 func (m *Mockservice) FindSubscription(ctx context.Context, subscriptionID string) (model.Subscription, error) {
 	m.ctrl.T.Helper()
@@ -114,45 +442,330 @@ func (mr *MockserviceMockRecorder) FindSubscription(ctx, subscriptionID any) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSubscription", reflect.TypeOf((*Mockservice)(nil).FindSubscription), ctx, subscriptionID)
 }
 
+// FindSubscriptionInvoices mocks base method.
+func (m *Mockservice) FindSubscriptionInvoices(ctx context.Context, subscriptionID string) ([]model.Invoice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSubscriptionInvoices", ctx, subscriptionID)
+	ret0, _ := ret[0].([]model.Invoice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSubscriptionInvoices indicates an expected call of FindSubscriptionInvoices.
+func (mr *MockserviceMockRecorder) FindSubscriptionInvoices(ctx, subscriptionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSubscriptionInvoices", reflect.TypeOf((*Mockservice)(nil).FindSubscriptionInvoices), ctx, subscriptionID)
+}
+
+// FindWebhookDeliveries mocks base method.
+func (m *Mockservice) FindWebhookDeliveries(ctx context.Context, status model.WebhookDeliveryStatus) ([]model.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindWebhookDeliveries", ctx, status)
+	ret0, _ := ret[0].([]model.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindWebhookDeliveries indicates an expected call of FindWebhookDeliveries.
+func (mr *MockserviceMockRecorder) FindWebhookDeliveries(ctx, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindWebhookDeliveries", reflect.TypeOf((*Mockservice)(nil).FindWebhookDeliveries), ctx, status)
+}
+
+// FindWebhookEndpoints mocks base method.
+func (m *Mockservice) FindWebhookEndpoints(ctx context.Context) ([]model.WebhookEndpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindWebhookEndpoints", ctx)
+	ret0, _ := ret[0].([]model.WebhookEndpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindWebhookEndpoints indicates an expected call of FindWebhookEndpoints.
+func (mr *MockserviceMockRecorder) FindWebhookEndpoints(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindWebhookEndpoints", reflect.TypeOf((*Mockservice)(nil).FindWebhookEndpoints), ctx)
+}
+
+// GrantCredit mocks base method.
+func (m *Mockservice) GrantCredit(ctx context.Context, userID string, amount float64, reason string) (model.CreditTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrantCredit", ctx, userID, amount, reason)
+	ret0, _ := ret[0].(model.CreditTransaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GrantCredit indicates an expected call of GrantCredit.
+func (mr *MockserviceMockRecorder) GrantCredit(ctx, userID, amount, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantCredit", reflect.TypeOf((*Mockservice)(nil).GrantCredit), ctx, userID, amount, reason)
+}
+
+// InviteMember mocks base method.
+func (m *Mockservice) InviteMember(ctx context.Context, subscriptionID string, version int, userID string) (model.SubscriptionMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InviteMember", ctx, subscriptionID, version, userID)
+	ret0, _ := ret[0].(model.SubscriptionMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InviteMember indicates an expected call of InviteMember.
+func (mr *MockserviceMockRecorder) InviteMember(ctx, subscriptionID, version, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InviteMember", reflect.TypeOf((*Mockservice)(nil).InviteMember), ctx, subscriptionID, version, userID)
+}
+
+// JoinOrganization mocks base method.
+func (m *Mockservice) JoinOrganization(ctx context.Context, organizationID, userID string) (model.SubscriptionMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JoinOrganization", ctx, organizationID, userID)
+	ret0, _ := ret[0].(model.SubscriptionMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// JoinOrganization indicates an expected call of JoinOrganization.
+func (mr *MockserviceMockRecorder) JoinOrganization(ctx, organizationID, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinOrganization", reflect.TypeOf((*Mockservice)(nil).JoinOrganization), ctx, organizationID, userID)
+}
+
+// MigratePrice mocks base method.
+func (m *Mockservice) MigratePrice(ctx context.Context, productID string, price, tax float64, dryRun bool) (model.PriceMigration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MigratePrice", ctx, productID, price, tax, dryRun)
+	ret0, _ := ret[0].(model.PriceMigration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MigratePrice indicates an expected call of MigratePrice.
+func (mr *MockserviceMockRecorder) MigratePrice(ctx, productID, price, tax, dryRun any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigratePrice", reflect.TypeOf((*Mockservice)(nil).MigratePrice), ctx, productID, price, tax, dryRun)
+}
+
 // PauseSubscription mocks base method.
-func (m *Mockservice) PauseSubscription(ctx context.Context, subscriptionID string) error {
+func (m *Mockservice) PauseSubscription(ctx context.Context, subscriptionID string, version int, schedule model.PauseSchedule) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PauseSubscription", ctx, subscriptionID)
+	ret := m.ctrl.Call(m, "PauseSubscription", ctx, subscriptionID, version, schedule)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // PauseSubscription indicates an expected call of PauseSubscription.
-func (mr *MockserviceMockRecorder) PauseSubscription(ctx, subscriptionID any) *gomock.Call {
+func (mr *MockserviceMockRecorder) PauseSubscription(ctx, subscriptionID, version, schedule any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PauseSubscription", reflect.TypeOf((*Mockservice)(nil).PauseSubscription), ctx, subscriptionID, version, schedule)
+}
+
+// PurchaseGift mocks base method.
+func (m *Mockservice) PurchaseGift(ctx context.Context, buyerID, productID string, periods int) (model.Gift, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurchaseGift", ctx, buyerID, productID, periods)
+	ret0, _ := ret[0].(model.Gift)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurchaseGift indicates an expected call of PurchaseGift.
+func (mr *MockserviceMockRecorder) PurchaseGift(ctx, buyerID, productID, periods any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurchaseGift", reflect.TypeOf((*Mockservice)(nil).PurchaseGift), ctx, buyerID, productID, periods)
+}
+
+// ReactivateSubscription mocks base method.
+func (m *Mockservice) ReactivateSubscription(ctx context.Context, subscriptionID string, version int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReactivateSubscription", ctx, subscriptionID, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReactivateSubscription indicates an expected call of ReactivateSubscription.
+func (mr *MockserviceMockRecorder) ReactivateSubscription(ctx, subscriptionID, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReactivateSubscription", reflect.TypeOf((*Mockservice)(nil).ReactivateSubscription), ctx, subscriptionID, version)
+}
+
+// RedeemGift mocks base method.
+func (m *Mockservice) RedeemGift(ctx context.Context, code, userID string) (model.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedeemGift", ctx, code, userID)
+	ret0, _ := ret[0].(model.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RedeemGift indicates an expected call of RedeemGift.
+func (mr *MockserviceMockRecorder) RedeemGift(ctx, code, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeemGift", reflect.TypeOf((*Mockservice)(nil).RedeemGift), ctx, code, userID)
+}
+
+// RegisterWebhookEndpoint mocks base method.
+func (m *Mockservice) RegisterWebhookEndpoint(ctx context.Context, url string, eventTypes []model.WebhookEventType) (model.WebhookEndpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterWebhookEndpoint", ctx, url, eventTypes)
+	ret0, _ := ret[0].(model.WebhookEndpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegisterWebhookEndpoint indicates an expected call of RegisterWebhookEndpoint.
+func (mr *MockserviceMockRecorder) RegisterWebhookEndpoint(ctx, url, eventTypes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterWebhookEndpoint", reflect.TypeOf((*Mockservice)(nil).RegisterWebhookEndpoint), ctx, url, eventTypes)
+}
+
+// RemoveMember mocks base method.
+func (m *Mockservice) RemoveMember(ctx context.Context, subscriptionID string, version int, memberID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", ctx, subscriptionID, version, memberID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockserviceMockRecorder) RemoveMember(ctx, subscriptionID, version, memberID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PauseSubscription", reflect.TypeOf((*Mockservice)(nil).PauseSubscription), ctx, subscriptionID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*Mockservice)(nil).RemoveMember), ctx, subscriptionID, version, memberID)
+}
+
+// ReplayWebhookDelivery mocks base method.
+func (m *Mockservice) ReplayWebhookDelivery(ctx context.Context, deliveryID string) (model.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplayWebhookDelivery", ctx, deliveryID)
+	ret0, _ := ret[0].(model.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplayWebhookDelivery indicates an expected call of ReplayWebhookDelivery.
+func (mr *MockserviceMockRecorder) ReplayWebhookDelivery(ctx, deliveryID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplayWebhookDelivery", reflect.TypeOf((*Mockservice)(nil).ReplayWebhookDelivery), ctx, deliveryID)
 }
 
 // Subscribe mocks base method.
-func (m *Mockservice) Subscribe(ctx context.Context, userID, productID, voucherCode string, trialPeriod bool) (string, error) {
+func (m *Mockservice) Subscribe(ctx context.Context, userID, productID, voucherCode, referralCode string, startDate *time.Time, trialPeriod bool) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Subscribe", ctx, userID, productID, voucherCode, trialPeriod)
+	ret := m.ctrl.Call(m, "Subscribe", ctx, userID, productID, voucherCode, referralCode, startDate, trialPeriod)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Subscribe indicates an expected call of Subscribe.
-func (mr *MockserviceMockRecorder) Subscribe(ctx, userID, productID, voucherCode, trialPeriod any) *gomock.Call {
+func (mr *MockserviceMockRecorder) Subscribe(ctx, userID, productID, voucherCode, referralCode, startDate, trialPeriod any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*Mockservice)(nil).Subscribe), ctx, userID, productID, voucherCode, trialPeriod)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*Mockservice)(nil).Subscribe), ctx, userID, productID, voucherCode, referralCode, startDate, trialPeriod)
+}
+
+// SubscribeOrganization mocks base method.
+func (m *Mockservice) SubscribeOrganization(ctx context.Context, organizationID, adminID, productID string, seats int) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribeOrganization", ctx, organizationID, adminID, productID, seats)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubscribeOrganization indicates an expected call of SubscribeOrganization.
+func (mr *MockserviceMockRecorder) SubscribeOrganization(ctx, organizationID, adminID, productID, seats any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeOrganization", reflect.TypeOf((*Mockservice)(nil).SubscribeOrganization), ctx, organizationID, adminID, productID, seats)
 }
 
 // UnpauseSubscription mocks base method.
-func (m *Mockservice) UnpauseSubscription(ctx context.Context, subscriptionID string) error {
+func (m *Mockservice) UnpauseSubscription(ctx context.Context, subscriptionID string, version int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UnpauseSubscription", ctx, subscriptionID)
+	ret := m.ctrl.Call(m, "UnpauseSubscription", ctx, subscriptionID, version)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UnpauseSubscription indicates an expected call of UnpauseSubscription.
-func (mr *MockserviceMockRecorder) UnpauseSubscription(ctx, subscriptionID any) *gomock.Call {
+func (mr *MockserviceMockRecorder) UnpauseSubscription(ctx, subscriptionID, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnpauseSubscription", reflect.TypeOf((*Mockservice)(nil).UnpauseSubscription), ctx, subscriptionID, version)
+}
+
+// MocktimeTraveler is a mock of timeTraveler interface.
+type MocktimeTraveler struct {
+	ctrl     *gomock.Controller
+	recorder *MocktimeTravelerMockRecorder
+}
+
+// MocktimeTravelerMockRecorder is the mock recorder for MocktimeTraveler.
+type MocktimeTravelerMockRecorder struct {
+	mock *MocktimeTraveler
+}
+
+// NewMocktimeTraveler creates a new mock instance.
+func NewMocktimeTraveler(ctrl *gomock.Controller) *MocktimeTraveler {
+	mock := &MocktimeTraveler{ctrl: ctrl}
+	mock.recorder = &MocktimeTravelerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocktimeTraveler) EXPECT() *MocktimeTravelerMockRecorder {
+	return m.recorder
+}
+
+// Now mocks base method.
+func (m *MocktimeTraveler) Now() time.Time {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Now")
+	ret0, _ := ret[0].(time.Time)
+	return ret0
+}
+
+// Now indicates an expected call of Now.
+func (mr *MocktimeTravelerMockRecorder) Now() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Now", reflect.TypeOf((*MocktimeTraveler)(nil).Now))
+}
+
+// Offset mocks base method.
+func (m *MocktimeTraveler) Offset() time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Offset")
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// Offset indicates an expected call of Offset.
+func (mr *MocktimeTravelerMockRecorder) Offset() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Offset", reflect.TypeOf((*MocktimeTraveler)(nil).Offset))
+}
+
+// Reset mocks base method.
+func (m *MocktimeTraveler) Reset() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Reset")
+}
+
+// Reset indicates an expected call of Reset.
+func (mr *MocktimeTravelerMockRecorder) Reset() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MocktimeTraveler)(nil).Reset))
+}
+
+// Travel mocks base method.
+func (m *MocktimeTraveler) Travel(d time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Travel", d)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Travel indicates an expected call of Travel.
+func (mr *MocktimeTravelerMockRecorder) Travel(d any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnpauseSubscription", reflect.TypeOf((*Mockservice)(nil).UnpauseSubscription), ctx, subscriptionID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Travel", reflect.TypeOf((*MocktimeTraveler)(nil).Travel), d)
 }
@@ -0,0 +1,163 @@
+package rest
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gymondo/internal/model"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_GrantCredit(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+
+	t.Run("successful grant", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().GrantCredit(gomock.Any(), userID.String(), 15.0, "goodwill").
+			Return(model.CreditTransaction{ID: uuid.New(), UserID: userID, Kind: model.CreditGrant}, nil)
+
+		r := gin.Default()
+		r.POST("/api/admin/users/:user_id/credit", server.grantCredit)
+
+		w := performPostRequest(r, "/api/admin/users/"+userID.String()+"/credit", `{"amount": 15, "reason": "goodwill"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"kind":"grant"`)
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		r := gin.Default()
+		r.POST("/api/admin/users/:user_id/credit", server.grantCredit)
+
+		w := performPostRequest(r, "/api/admin/users/"+userID.String()+"/credit", `{"amount": -5}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().GrantCredit(gomock.Any(), userID.String(), 15.0, "goodwill").
+			Return(model.CreditTransaction{}, fmt.Errorf("failed to fetch user"))
+
+		r := gin.Default()
+		r.POST("/api/admin/users/:user_id/credit", server.grantCredit)
+
+		w := performPostRequest(r, "/api/admin/users/"+userID.String()+"/credit", `{"amount": 15, "reason": "goodwill"}`)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func Test_GetCreditBalance(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+
+	t.Run("successful balance fetch", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().FindCreditBalance(gomock.Any(), userID.String()).
+			Return(model.CreditBalance{UserID: userID, Balance: 12.5, Transactions: []model.CreditTransaction{}}, nil)
+
+		r := gin.Default()
+		r.GET("/api/users/:user_id/credit", server.getCreditBalance)
+
+		w := performRequest(r, "GET", "/api/users/"+userID.String()+"/credit")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"balance":12.5`)
+	})
+
+	t.Run("balance not found", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().FindCreditBalance(gomock.Any(), "999").Return(model.CreditBalance{}, fmt.Errorf("user not found"))
+
+		r := gin.Default()
+		r.GET("/api/users/:user_id/credit", server.getCreditBalance)
+
+		w := performRequest(r, "GET", "/api/users/999/credit")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func Test_RequireAdmin(t *testing.T) {
+	t.Parallel()
+
+	newRouter := func(adminToken string) *gin.Engine {
+		server := &Server{adminToken: adminToken}
+
+		r := gin.Default()
+		r.GET("/api/admin/ping", server.requireAdmin, func(c *gin.Context) {
+			c.Status(http.StatusNoContent)
+		})
+		return r
+	}
+
+	performAdminRequest := func(r *gin.Engine, token string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/api/admin/ping", nil)
+		if token != "" {
+			req.Header.Set(adminTokenHeader, token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("valid token", func(t *testing.T) {
+		t.Parallel()
+
+		w := performAdminRequest(newRouter("secret"), "secret")
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("wrong token", func(t *testing.T) {
+		t.Parallel()
+
+		w := performAdminRequest(newRouter("secret"), "guess")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("no token configured", func(t *testing.T) {
+		t.Parallel()
+
+		w := performAdminRequest(newRouter(""), "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
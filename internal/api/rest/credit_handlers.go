@@ -0,0 +1,79 @@
+package rest
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GrantCreditRequest struct {
+	Amount float64 `json:"amount" binding:"required,gt=0"`
+	Reason string  `json:"reason" binding:"required"`
+}
+
+// @Summary Grant credit to a user
+// @Description Adds credit to a user's balance, for example as a goodwill gesture. The credit is used up automatically before the payment method is charged on the next subscribe or renewal. Requires the admin token.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param user_id path string true "User ID"
+// @Param request body GrantCreditRequest true "Grant Credit Request"
+// @Success 200 {object} model.CreditTransaction
+// @Failure 400 {object} ErrorResponse "Validation error"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /api/v1/admin/users/{user_id}/credit [post]
+func (s *Server) grantCredit(c *gin.Context) {
+	ctx := context.Background()
+	userID := c.Param("user_id")
+
+	var request GrantCreditRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Println("Validation error: ", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	transaction, err := s.service.GrantCredit(ctx, userID, request.Amount, request.Reason)
+	if err != nil {
+		log.Printf("Error granting credit to user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal error",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, transaction)
+}
+
+// @Summary Get a user's credit balance
+// @Description Returns the user's current credit balance together with all credit transactions, newest first.
+// @Tags Credit
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Success 200 {object} model.CreditBalance
+// @Failure 404 {object} ErrorResponse "Credit balance not found"
+// @Router /api/v1/users/{user_id}/credit [get]
+func (s *Server) getCreditBalance(c *gin.Context) {
+	ctx := context.Background()
+	userID := c.Param("user_id")
+
+	balance, err := s.service.FindCreditBalance(ctx, userID)
+	if err != nil {
+		log.Printf("Error finding credit balance of user %s: %v", userID, err)
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Credit balance not found",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, balance)
+}
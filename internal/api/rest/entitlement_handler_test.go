@@ -0,0 +1,78 @@
+package rest
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gymondo/internal/model"
+	"net/http"
+	"testing"
+)
+
+func Test_GetEntitlements(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+
+	t.Run("successful", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().FindEntitlements(gomock.Any(), userID.String()).Return(model.Entitlements{
+			UserID:       userID,
+			Entitlements: []string{"premium_classes", "workouts"},
+			Grants: []model.EntitlementGrant{
+				{SubscriptionID: uuid.New(), Status: model.EntitledTrialing, Entitlements: []string{"premium_classes", "workouts"}},
+			},
+		}, nil)
+
+		r := gin.Default()
+		r.GET("/api/users/:user_id/entitlements", server.getEntitlements)
+
+		w := performRequest(r, "GET", "/api/users/"+userID.String()+"/entitlements")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"entitlements":["premium_classes","workouts"]`)
+		assert.Contains(t, w.Body.String(), `"status":"trialing"`)
+	})
+
+	t.Run("invalid user ID", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		r := gin.Default()
+		r.GET("/api/users/:user_id/entitlements", server.getEntitlements)
+
+		w := performRequest(r, "GET", "/api/users/not-a-user/entitlements")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().FindEntitlements(gomock.Any(), userID.String()).Return(model.Entitlements{}, fmt.Errorf("database error"))
+
+		r := gin.Default()
+		r.GET("/api/users/:user_id/entitlements", server.getEntitlements)
+
+		w := performRequest(r, "GET", "/api/users/"+userID.String()+"/entitlements")
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
@@ -0,0 +1,44 @@
+package rest
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// @Summary Get a user's entitlements
+// @Description Resolves the user's active, trialing and grace period subscriptions into the feature keys the user may use right now, for example to check access to premium classes. Paused and canceled subscriptions grant nothing. Results are cached for a few seconds.
+// @Tags Entitlements
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Success 200 {object} model.Entitlements
+// @Failure 400 {object} ErrorResponse "Invalid user ID"
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /api/v1/users/{user_id}/entitlements [get]
+func (s *Server) getEntitlements(c *gin.Context) {
+	ctx := context.Background()
+	userID := c.Param("user_id")
+
+	if _, err := uuid.Parse(userID); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid user ID",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	entitlements, err := s.service.FindEntitlements(ctx, userID)
+	if err != nil {
+		log.Printf("Error finding entitlements of user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal error",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, entitlements)
+}
@@ -0,0 +1,131 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gymondo/internal/model"
+	"net/http"
+	"testing"
+)
+
+func Test_PurchaseGift(t *testing.T) {
+	t.Parallel()
+
+	buyerID := uuid.New()
+	productID := uuid.New()
+
+	t.Run("successful", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().PurchaseGift(gomock.Any(), buyerID.String(), productID.String(), 3).Return(model.Gift{
+			ID:      uuid.New(),
+			Code:    "GIFT-7K3M-Q9XP",
+			BuyerID: buyerID,
+			Status:  model.GiftPurchased,
+		}, nil)
+
+		r := gin.Default()
+		r.POST("/api/gifts", server.purchaseGift)
+
+		requestBody := `{"buyer_id": "` + buyerID.String() + `", "product_id": "` + productID.String() + `", "periods": 3}`
+		w := performPostRequest(r, "/api/gifts", requestBody)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"GIFT-7K3M-Q9XP"`)
+	})
+
+	t.Run("too many periods", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		r := gin.Default()
+		r.POST("/api/gifts", server.purchaseGift)
+
+		requestBody := `{"buyer_id": "` + buyerID.String() + `", "product_id": "` + productID.String() + `", "periods": 13}`
+		w := performPostRequest(r, "/api/gifts", requestBody)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func Test_GetGift(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := NewMockservice(ctrl)
+	server := &Server{service: mockService}
+
+	mockService.EXPECT().FindGift(gomock.Any(), "GIFT-0000-0000").
+		Return(model.Gift{}, errors.New("gift with code GIFT-0000-0000 not found"))
+
+	r := gin.Default()
+	r.GET("/api/gifts/:code", server.getGift)
+
+	w := performRequest(r, "GET", "/api/gifts/GIFT-0000-0000")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_RedeemGift(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+
+	t.Run("successful", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().RedeemGift(gomock.Any(), "GIFT-7K3M-Q9XP", userID.String()).Return(model.Subscription{
+			ID:     uuid.New(),
+			UserID: userID,
+			Status: model.Active,
+		}, nil)
+
+		r := gin.Default()
+		r.POST("/api/gifts/:code/redeem", server.redeemGift)
+
+		requestBody := `{"user_id": "` + userID.String() + `"}`
+		w := performPostRequest(r, "/api/gifts/GIFT-7K3M-Q9XP/redeem", requestBody)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"active"`)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().RedeemGift(gomock.Any(), "GIFT-7K3M-Q9XP", userID.String()).
+			Return(model.Subscription{}, fmt.Errorf("gift expired on 20.06.2024: %w", model.ErrGiftNotRedeemable))
+
+		r := gin.Default()
+		r.POST("/api/gifts/:code/redeem", server.redeemGift)
+
+		requestBody := `{"user_id": "` + userID.String() + `"}`
+		w := performPostRequest(r, "/api/gifts/GIFT-7K3M-Q9XP/redeem", requestBody)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+}
@@ -0,0 +1,125 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gymondo/internal/model"
+)
+
+type PurchaseGiftRequest struct {
+	BuyerID   string `json:"buyer_id" binding:"required"`
+	ProductID string `json:"product_id" binding:"required" example:"ab97234d-6b4a-4a70-823e-68b7a80ef6d4"`
+	Periods   int    `json:"periods" binding:"required,min=1,max=12" example:"3"`
+}
+
+type RedeemGiftRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+}
+
+// @Summary Purchase a gift
+// @Description Charges the buyer for the given number of periods of the product and returns a gift code, which anyone can redeem until it expires. The purchase is invoiced to the buyer and the code is sent to them.
+// @Tags Gift
+// @Accept json
+// @Produce json
+// @Param request body PurchaseGiftRequest true "Gift"
+// @Success 201 {object} model.Gift
+// @Failure 400 {object} ErrorResponse "Validation error"
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /api/v1/gifts [post]
+func (s *Server) purchaseGift(c *gin.Context) {
+	ctx := context.Background()
+
+	var request PurchaseGiftRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	gift, err := s.service.PurchaseGift(ctx, request.BuyerID, request.ProductID, request.Periods)
+	if err != nil {
+		log.Printf("Error purchasing gift of product %s for user %s: %v", request.ProductID, request.BuyerID, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to purchase gift",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gift)
+}
+
+// @Summary Get a gift
+// @Description Returns the gift with the given code, including whether it is still redeemable.
+// @Tags Gift
+// @Produce json
+// @Param code path string true "Gift code"
+// @Success 200 {object} model.Gift
+// @Failure 404 {object} ErrorResponse "Gift not found"
+// @Router /api/v1/gifts/{code} [get]
+func (s *Server) getGift(c *gin.Context) {
+	ctx := context.Background()
+	code := c.Param("code")
+
+	gift, err := s.service.FindGift(ctx, code)
+	if err != nil {
+		log.Printf("Error finding gift with code %s: %v", code, err)
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Gift not found",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gift)
+}
+
+// @Summary Redeem a gift
+// @Description Gives the user the prepaid period of the gift without charging them. An active subscription of the user to the gifted product is extended, otherwise a new subscription starts today and renews at the regular price after the gifted period. A gift is redeemed once and only until it expires.
+// @Tags Gift
+// @Accept json
+// @Produce json
+// @Param code path string true "Gift code"
+// @Param request body RedeemGiftRequest true "Recipient"
+// @Success 200 {object} model.Subscription
+// @Failure 400 {object} ErrorResponse "Validation error"
+// @Failure 409 {object} ErrorResponse "Subscription was modified concurrently"
+// @Failure 422 {object} ErrorResponse "Gift was already redeemed or has expired"
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /api/v1/gifts/{code}/redeem [post]
+func (s *Server) redeemGift(c *gin.Context) {
+	ctx := context.Background()
+	code := c.Param("code")
+
+	var request RedeemGiftRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	subscription, err := s.service.RedeemGift(ctx, code, request.UserID)
+	if err != nil {
+		log.Printf("Error redeeming gift %s for user %s: %v", code, request.UserID, err)
+		status := manageErrorStatus(err, 0)
+		if errors.Is(err, model.ErrGiftNotRedeemable) {
+			status = http.StatusUnprocessableEntity
+		}
+		c.JSON(status, ErrorResponse{
+			Error:   "Failed to redeem gift",
+			Details: fmt.Sprintf("Error redeeming gift: %v", err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
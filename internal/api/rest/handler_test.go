@@ -11,6 +11,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 func performRequest(r *gin.Engine, method, path string) *httptest.ResponseRecorder {
@@ -216,7 +217,7 @@ func Test_Subscribe(t *testing.T) {
 						}`
 		expectedSubscriptionID := uuid.New().String()
 
-		mockService.EXPECT().Subscribe(gomock.Any(), "123", "456", "ABC123", true).Return(expectedSubscriptionID, nil)
+		mockService.EXPECT().Subscribe(gomock.Any(), "123", "456", "ABC123", "", gomock.Nil(), true).Return(expectedSubscriptionID, nil)
 
 		r := gin.Default()
 		r.POST("/api/subscribe", server.subscribe)
@@ -226,6 +227,46 @@ func Test_Subscribe(t *testing.T) {
 		assert.Contains(t, w.Body.String(), expectedSubscriptionID)
 	})
 
+	t.Run("scheduled start date", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		requestBody := `{"user_id": "123", "product_id": "456", "start_date": "2025-01-01"}`
+		startDate := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+		mockService.EXPECT().Subscribe(gomock.Any(), "123", "456", "", "", &startDate, false).Return("789", nil)
+
+		r := gin.Default()
+		r.POST("/api/subscribe", server.subscribe)
+
+		w := performPostRequest(r, "/api/subscribe", requestBody)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("invalid start date", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		requestBody := `{"user_id": "123", "product_id": "456", "start_date": "01.01.2025"}`
+
+		r := gin.Default()
+		r.POST("/api/subscribe", server.subscribe)
+
+		w := performPostRequest(r, "/api/subscribe", requestBody)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "start_date must be a date like 2024-07-01")
+	})
+
 	t.Run("missing required fields", func(t *testing.T) {
 		t.Parallel()
 
@@ -261,7 +302,7 @@ func Test_Subscribe(t *testing.T) {
 							"trial_period": true
 						}`
 
-		mockService.EXPECT().Subscribe(gomock.Any(), "123", "456", "ABC123", true).
+		mockService.EXPECT().Subscribe(gomock.Any(), "123", "456", "ABC123", "", gomock.Nil(), true).
 			Return("", fmt.Errorf("internal service error"))
 
 		r := gin.Default()
@@ -286,7 +327,7 @@ func Test_GetSubscription(t *testing.T) {
 		server := &Server{service: mockService}
 
 		subscriptionID := uuid.New()
-		expectedSubscription := model.Subscription{ID: subscriptionID, UserID: uuid.New(), ProductID: uuid.New()}
+		expectedSubscription := model.Subscription{ID: subscriptionID, UserID: uuid.New(), ProductID: uuid.New(), Version: 3}
 
 		mockService.EXPECT().FindSubscription(gomock.Any(), subscriptionID.String()).Return(expectedSubscription, nil)
 
@@ -296,6 +337,7 @@ func Test_GetSubscription(t *testing.T) {
 		w := performRequest(r, "GET", "/api/subscription/"+subscriptionID.String())
 		assert.Equal(t, http.StatusOK, w.Code)
 		assert.Contains(t, w.Body.String(), subscriptionID.String())
+		assert.Equal(t, `"3"`, w.Header().Get("ETag"))
 	})
 
 	t.Run("subscription not found", func(t *testing.T) {
@@ -350,13 +392,156 @@ func Test_ManageSubscription(t *testing.T) {
 		subscriptionID := uuid.New().String()
 		requestBody := `{"action": "pause"}`
 
-		mockService.EXPECT().PauseSubscription(gomock.Any(), subscriptionID).Return(nil)
+		mockService.EXPECT().PauseSubscription(gomock.Any(), subscriptionID, 0, model.PauseSchedule{}).Return(nil)
+		mockService.EXPECT().FindSubscription(gomock.Any(), subscriptionID).Return(model.Subscription{Version: 3}, nil)
 
 		r := gin.Default()
 		r.POST("/api/subscription/:subscription_id/manage", server.manageSubscription)
 		w := performPostRequest(r, "/api/subscription/"+subscriptionID+"/manage", requestBody)
 		assert.Equal(t, http.StatusOK, w.Code)
 		assert.Contains(t, w.Body.String(), "Subscription paused")
+		assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	})
+
+	t.Run("schedule pause", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		subscriptionID := uuid.New().String()
+		requestBody := `{"action": "pause", "pause_from": "2024-07-01", "resume_on": "2024-07-29"}`
+
+		pauseFrom := time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC)
+		resumeOn := time.Date(2024, time.July, 29, 0, 0, 0, 0, time.UTC)
+		mockService.EXPECT().
+			PauseSubscription(gomock.Any(), subscriptionID, 0, model.PauseSchedule{PauseFrom: &pauseFrom, ResumeOn: &resumeOn}).
+			Return(nil)
+		mockService.EXPECT().FindSubscription(gomock.Any(), subscriptionID).Return(model.Subscription{Version: 3}, nil)
+
+		r := gin.Default()
+		r.POST("/api/subscription/:subscription_id/manage", server.manageSubscription)
+		w := performPostRequest(r, "/api/subscription/"+subscriptionID+"/manage", requestBody)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Subscription pause scheduled")
+	})
+
+	t.Run("invalid pause date", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		subscriptionID := uuid.New().String()
+		requestBody := `{"action": "pause", "resume_on": "next monday"}`
+
+		r := gin.Default()
+		r.POST("/api/subscription/:subscription_id/manage", server.manageSubscription)
+		w := performPostRequest(r, "/api/subscription/"+subscriptionID+"/manage", requestBody)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "resume_on must be a date")
+	})
+
+	t.Run("pause limit exceeded", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		subscriptionID := uuid.New().String()
+		requestBody := `{"action": "pause"}`
+
+		mockService.EXPECT().PauseSubscription(gomock.Any(), subscriptionID, 0, model.PauseSchedule{}).
+			Return(fmt.Errorf("failed to pause: %w", &model.PauseLimitError{
+				Limit:   model.MaxPausesPerPeriodLimit,
+				Allowed: 2,
+				Actual:  2,
+			}))
+
+		r := gin.Default()
+		r.POST("/api/subscription/:subscription_id/manage", server.manageSubscription)
+		w := performPostRequest(r, "/api/subscription/"+subscriptionID+"/manage", requestBody)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), `"limit":"max_pauses_per_period"`)
+		assert.Contains(t, w.Body.String(), `"allowed":2`)
+	})
+
+	t.Run("reactivate subscription", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		subscriptionID := uuid.New().String()
+		requestBody := `{"action": "reactivate"}`
+
+		mockService.EXPECT().ReactivateSubscription(gomock.Any(), subscriptionID, 0).Return(nil)
+		mockService.EXPECT().FindSubscription(gomock.Any(), subscriptionID).Return(model.Subscription{Version: 3}, nil)
+
+		r := gin.Default()
+		r.POST("/api/subscription/:subscription_id/manage", server.manageSubscription)
+		w := performPostRequest(r, "/api/subscription/"+subscriptionID+"/manage", requestBody)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Subscription reactivated")
+	})
+
+	t.Run("decline price change", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		subscriptionID := uuid.New().String()
+		requestBody := `{"action": "decline_price_change"}`
+
+		mockService.EXPECT().DeclinePriceChange(gomock.Any(), subscriptionID, 0).Return(model.PriceChange{
+			EffectiveDate: time.Date(2025, time.February, 19, 0, 0, 0, 0, time.UTC),
+			Status:        model.PriceChangeOptedOut,
+		}, nil)
+		mockService.EXPECT().FindSubscription(gomock.Any(), subscriptionID).Return(model.Subscription{Version: 3}, nil)
+
+		r := gin.Default()
+		r.POST("/api/subscription/:subscription_id/manage", server.manageSubscription)
+		w := performPostRequest(r, "/api/subscription/"+subscriptionID+"/manage", requestBody)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Price change declined, the subscription ends on 2025-02-19")
+	})
+
+	t.Run("cancel scheduled pause", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		subscriptionID := uuid.New().String()
+		requestBody := `{"action": "cancel_pause"}`
+
+		mockService.EXPECT().CancelScheduledPause(gomock.Any(), subscriptionID, 0).Return(nil)
+		mockService.EXPECT().FindSubscription(gomock.Any(), subscriptionID).Return(model.Subscription{Version: 3}, nil)
+
+		r := gin.Default()
+		r.POST("/api/subscription/:subscription_id/manage", server.manageSubscription)
+		w := performPostRequest(r, "/api/subscription/"+subscriptionID+"/manage", requestBody)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Scheduled pause canceled")
 	})
 
 	t.Run("unpause subscription successfully", func(t *testing.T) {
@@ -371,7 +556,8 @@ func Test_ManageSubscription(t *testing.T) {
 		subscriptionID := uuid.New().String()
 		requestBody := `{"action": "unpause"}`
 
-		mockService.EXPECT().UnpauseSubscription(gomock.Any(), subscriptionID).Return(nil)
+		mockService.EXPECT().UnpauseSubscription(gomock.Any(), subscriptionID, 0).Return(nil)
+		mockService.EXPECT().FindSubscription(gomock.Any(), subscriptionID).Return(model.Subscription{Version: 3}, nil)
 
 		r := gin.Default()
 		r.POST("/api/subscription/:subscription_id/manage", server.manageSubscription)
@@ -392,7 +578,33 @@ func Test_ManageSubscription(t *testing.T) {
 		subscriptionID := uuid.New().String()
 		requestBody := `{"action": "cancel"}`
 
-		mockService.EXPECT().CancelSubscription(gomock.Any(), subscriptionID).Return(nil)
+		mockService.EXPECT().CancelSubscription(gomock.Any(), subscriptionID, 0, model.CancellationSurvey{}).Return(model.Refund{Amount: 88, Status: model.RefundSucceeded}, nil)
+		mockService.EXPECT().FindSubscription(gomock.Any(), subscriptionID).Return(model.Subscription{Version: 3}, nil)
+
+		r := gin.Default()
+		r.POST("/api/subscription/:subscription_id/manage", server.manageSubscription)
+		w := performPostRequest(r, "/api/subscription/"+subscriptionID+"/manage", requestBody)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Subscription canceled")
+		assert.Contains(t, w.Body.String(), `"refund_amount":88`)
+		assert.Contains(t, w.Body.String(), `"refund_status":"succeeded"`)
+	})
+
+	t.Run("cancel subscription with a reason", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		subscriptionID := uuid.New().String()
+		requestBody := `{"action": "cancel", "reason": "too_expensive", "reason_text": "too pricey"}`
+
+		survey := model.CancellationSurvey{Reason: model.TooExpensive, Text: "too pricey"}
+		mockService.EXPECT().CancelSubscription(gomock.Any(), subscriptionID, 0, survey).Return(model.Refund{}, nil)
+		mockService.EXPECT().FindSubscription(gomock.Any(), subscriptionID).Return(model.Subscription{Version: 3}, nil)
 
 		r := gin.Default()
 		r.POST("/api/subscription/:subscription_id/manage", server.manageSubscription)
@@ -401,6 +613,66 @@ func Test_ManageSubscription(t *testing.T) {
 		assert.Contains(t, w.Body.String(), "Subscription canceled")
 	})
 
+	t.Run("cancel subscription with an unknown reason", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		subscriptionID := uuid.New().String()
+		requestBody := `{"action": "cancel", "reason": "bored"}`
+
+		r := gin.Default()
+		r.POST("/api/subscription/:subscription_id/manage", server.manageSubscription)
+		w := performPostRequest(r, "/api/subscription/"+subscriptionID+"/manage", requestBody)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid cancellation reason")
+	})
+
+	t.Run("accept retention offer", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		subscriptionID := uuid.New().String()
+		offerID := uuid.New().String()
+		requestBody := `{"action": "accept_offer", "offer_id": "` + offerID + `"}`
+
+		mockService.EXPECT().AcceptRetentionOffer(gomock.Any(), subscriptionID, 0, offerID).Return(nil)
+		mockService.EXPECT().FindSubscription(gomock.Any(), subscriptionID).Return(model.Subscription{Version: 3}, nil)
+
+		r := gin.Default()
+		r.POST("/api/subscription/:subscription_id/manage", server.manageSubscription)
+		w := performPostRequest(r, "/api/subscription/"+subscriptionID+"/manage", requestBody)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Retention offer accepted")
+	})
+
+	t.Run("accept retention offer without offer", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		subscriptionID := uuid.New().String()
+		requestBody := `{"action": "accept_offer"}`
+
+		r := gin.Default()
+		r.POST("/api/subscription/:subscription_id/manage", server.manageSubscription)
+		w := performPostRequest(r, "/api/subscription/"+subscriptionID+"/manage", requestBody)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
 	t.Run("invalid action", func(t *testing.T) {
 		t.Parallel()
 
@@ -451,7 +723,7 @@ func Test_ManageSubscription(t *testing.T) {
 		subscriptionID := uuid.New().String()
 		requestBody := `{"action": "pause"}`
 
-		mockService.EXPECT().PauseSubscription(gomock.Any(), subscriptionID).
+		mockService.EXPECT().PauseSubscription(gomock.Any(), subscriptionID, 0, model.PauseSchedule{}).
 			Return(fmt.Errorf("internal error"))
 
 		r := gin.Default()
@@ -460,4 +732,250 @@ func Test_ManageSubscription(t *testing.T) {
 		assert.Equal(t, http.StatusInternalServerError, w.Code)
 		assert.Contains(t, w.Body.String(), "internal error")
 	})
+
+	t.Run("stale if-match fails the precondition", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		subscriptionID := uuid.New().String()
+		requestBody := `{"action": "cancel"}`
+
+		mockService.EXPECT().CancelSubscription(gomock.Any(), subscriptionID, 2, model.CancellationSurvey{}).
+			Return(model.Refund{}, fmt.Errorf("subscription is at version 3, not 2: %w", model.ErrSubscriptionConflict))
+
+		r := gin.Default()
+		r.POST("/api/subscription/:subscription_id/manage", server.manageSubscription)
+		w := performPostRequestWithIfMatch(r, "/api/subscription/"+subscriptionID+"/manage", requestBody, `"2"`)
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+
+	t.Run("concurrent update without if-match conflicts", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		subscriptionID := uuid.New().String()
+		requestBody := `{"action": "unpause"}`
+
+		mockService.EXPECT().UnpauseSubscription(gomock.Any(), subscriptionID, 0).
+			Return(fmt.Errorf("failed to pause subscription: %w", model.ErrSubscriptionConflict))
+
+		r := gin.Default()
+		r.POST("/api/subscription/:subscription_id/manage", server.manageSubscription)
+		w := performPostRequest(r, "/api/subscription/"+subscriptionID+"/manage", requestBody)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("malformed if-match", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		subscriptionID := uuid.New().String()
+		requestBody := `{"action": "pause"}`
+
+		r := gin.Default()
+		r.POST("/api/subscription/:subscription_id/manage", server.manageSubscription)
+		w := performPostRequestWithIfMatch(r, "/api/subscription/"+subscriptionID+"/manage", requestBody, `"abc"`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid If-Match header")
+	})
+}
+
+func performPostRequestWithIfMatch(r *gin.Engine, path, body, ifMatch string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", ifMatch)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func Test_GetPaymentAttempts(t *testing.T) {
+	t.Parallel()
+
+	t.Run("successful test", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		subscriptionID := uuid.New()
+		mockService.EXPECT().FindPaymentAttempts(gomock.Any(), subscriptionID.String()).Return([]model.PaymentAttempt{
+			{ID: uuid.New(), SubscriptionID: subscriptionID, AttemptNumber: 1, Status: model.PaymentFailed, FailureReason: "card declined"},
+		}, nil)
+
+		r := gin.Default()
+		r.GET("/api/subscription/:subscription_id/payments", server.getPaymentAttempts)
+
+		w := performRequest(r, "GET", "/api/subscription/"+subscriptionID.String()+"/payments")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "card declined")
+	})
+
+	t.Run("internal service error", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		subscriptionID := uuid.New().String()
+		mockService.EXPECT().FindPaymentAttempts(gomock.Any(), subscriptionID).Return(nil, fmt.Errorf("database error"))
+
+		r := gin.Default()
+		r.GET("/api/subscription/:subscription_id/payments", server.getPaymentAttempts)
+
+		w := performRequest(r, "GET", "/api/subscription/"+subscriptionID+"/payments")
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Contains(t, w.Body.String(), "Internal error")
+	})
+}
+
+func Test_GetReactivations(t *testing.T) {
+	t.Parallel()
+
+	t.Run("successful test", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		subscriptionID := uuid.New()
+		mockService.EXPECT().FindReactivations(gomock.Any(), subscriptionID.String()).Return([]model.Reactivation{
+			{ID: uuid.New(), SubscriptionID: subscriptionID, Pricing: model.LockedPriceReactivation, TotalPrice: 8.8},
+		}, nil)
+
+		r := gin.Default()
+		r.GET("/api/subscription/:subscription_id/reactivations", server.getReactivations)
+
+		w := performRequest(r, "GET", "/api/subscription/"+subscriptionID.String()+"/reactivations")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"pricing":"locked"`)
+	})
+
+	t.Run("internal service error", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		subscriptionID := uuid.New()
+		mockService.EXPECT().FindReactivations(gomock.Any(), subscriptionID.String()).Return(nil, fmt.Errorf("database error"))
+
+		r := gin.Default()
+		r.GET("/api/subscription/:subscription_id/reactivations", server.getReactivations)
+
+		w := performRequest(r, "GET", "/api/subscription/"+subscriptionID.String()+"/reactivations")
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func Test_GetRetentionOffers(t *testing.T) {
+	t.Parallel()
+
+	t.Run("successful", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		subscriptionID := uuid.New()
+		mockService.EXPECT().FindRetentionOffers(gomock.Any(), subscriptionID.String(), model.NotUsing).Return([]model.RetentionOffer{
+			{ID: uuid.New(), Reason: model.NotUsing, Kind: model.PauseOffer, PauseDays: 30},
+		}, nil)
+
+		r := gin.Default()
+		r.GET("/api/subscription/:subscription_id/retention-offers", server.getRetentionOffers)
+
+		w := performRequest(r, "GET", "/api/subscription/"+subscriptionID.String()+"/retention-offers?reason=not_using")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"kind":"pause"`)
+		assert.Contains(t, w.Body.String(), `"pause_days":30`)
+	})
+
+	t.Run("unknown reason", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		r := gin.Default()
+		r.GET("/api/subscription/:subscription_id/retention-offers", server.getRetentionOffers)
+
+		w := performRequest(r, "GET", "/api/subscription/"+uuid.NewString()+"/retention-offers?reason=bored")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func Test_CORS(t *testing.T) {
+	t.Parallel()
+
+	t.Run("preflight allows If-Match", func(t *testing.T) {
+		t.Parallel()
+
+		server := &Server{}
+
+		req, _ := http.NewRequest("OPTIONS", "/api/v1/subscription/123/manage", nil)
+		req.Header.Set("Origin", "https://app.gymondo.de")
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		req.Header.Set("Access-Control-Request-Headers", "if-match")
+		w := httptest.NewRecorder()
+		server.NewRoutes().ServeHTTP(w, req)
+
+		assert.Equal(t, "https://app.gymondo.de", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Contains(t, strings.ToLower(w.Header().Get("Access-Control-Allow-Headers")), "if-match")
+	})
+
+	t.Run("ETag is exposed", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().FindSubscription(gomock.Any(), "123").Return(model.Subscription{Version: 2}, nil)
+
+		req, _ := http.NewRequest("GET", "/api/v1/subscription/123", nil)
+		req.Header.Set("Origin", "https://app.gymondo.de")
+		w := httptest.NewRecorder()
+		server.NewRoutes().ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+		assert.Contains(t, w.Header().Get("Access-Control-Expose-Headers"), "Etag")
+	})
 }
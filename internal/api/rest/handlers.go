@@ -2,11 +2,16 @@ package rest
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"gymondo/internal/model"
 )
 
 type ErrorResponse struct {
@@ -91,6 +96,12 @@ type SubscriptionRequest struct {
 	UserID      string `json:"user_id" binding:"required"`
 	ProductID   string `json:"product_id" binding:"required"`
 	VoucherCode string `json:"voucher_code,omitempty"`
+	// ReferralCode attributes the subscription to the user who referred the
+	// subscriber.
+	ReferralCode string `json:"referral_code,omitempty"`
+	// StartDate schedules the subscription to start on a later day, in the
+	// time zone of the user.
+	StartDate   string `json:"start_date,omitempty" example:"2025-01-01"`
 	TrialPeriod bool   `json:"trial_period"`
 }
 
@@ -100,13 +111,14 @@ type SubscriptionResponse struct {
 }
 
 // @Summary Subscribe to a product
-// @Description Allows users to subscribe to a product. This endpoint creates a new subscription for a user, including selecting a product and setting the subscription parameters (e.g., trial period, voucher code).
+// @Description Allows users to subscribe to a product. This endpoint creates a new subscription for a user, including selecting a product and setting the subscription parameters (e.g., trial period, voucher code, referral code). Referral codes are only accepted from new customers and not from the referrer themselves. A start_date after today schedules the subscription: it is charged and grants access from that day on, and canceling it before then refunds nothing since nothing was charged.
 // @Tags Product
 // @Accept json
 // @Produce json
 // @Param request body SubscriptionRequest true "Subscription Request"
 // @Success 200 {object} SubscriptionResponse
 // @Failure 400 {object} ErrorResponse "Validation error"
+// @Failure 422 {object} ErrorResponse "Referral code can't be used"
 // @Failure 500 {object} ErrorResponse "Internal error"
 // @Router /api/v1/product/subscribe [post]
 func (s *Server) subscribe(c *gin.Context) {
@@ -122,9 +134,34 @@ func (s *Server) subscribe(c *gin.Context) {
 		return
 	}
 
-	subscriptionID, err := s.service.Subscribe(ctx, request.UserID, request.ProductID, request.VoucherCode, request.TrialPeriod)
+	startDate, err := parseDate("start_date", request.StartDate)
+	if err != nil {
+		log.Println("Validation error: ", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	subscriptionID, err := s.service.Subscribe(
+		ctx,
+		request.UserID,
+		request.ProductID,
+		request.VoucherCode,
+		request.ReferralCode,
+		startDate,
+		request.TrialPeriod,
+	)
 	if err != nil {
 		log.Printf("Error subscribing user %s to product %s: %v", request.UserID, request.ProductID, err)
+		if errors.Is(err, model.ErrReferralRejected) {
+			c.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+				Error:   "Validation error",
+				Details: err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:   "Internal error",
 			Details: err.Error(),
@@ -144,6 +181,7 @@ func (s *Server) subscribe(c *gin.Context) {
 // @Produce json
 // @Param subscription_id path string true "Subscription ID"
 // @Success 200 {object} model.Subscription
+// @Header 200 {string} ETag "Version of the subscription, to be sent as If-Match when managing it"
 // @Failure 404 {object} ErrorResponse "Subscription not found"
 // @Router /api/v1/subscription/{subscription_id} [get]
 func (s *Server) getSubscription(c *gin.Context) {
@@ -160,27 +198,133 @@ func (s *Server) getSubscription(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", subscriptionETag(subscription.Version))
 	c.JSON(http.StatusOK, subscription)
 }
 
+func subscriptionETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// setSubscriptionETag sets the ETag of the subscription as it is after a
+// successful change, so the client can base its next change on it. The
+// header is left out when the subscription can't be fetched.
+func (s *Server) setSubscriptionETag(ctx context.Context, c *gin.Context, subscriptionID string) {
+	subscription, err := s.service.FindSubscription(ctx, subscriptionID)
+	if err != nil {
+		log.Printf("Error finding subscription with ID %s: %v", subscriptionID, err)
+		return
+	}
+
+	c.Header("ETag", subscriptionETag(subscription.Version))
+}
+
+// parseIfMatch returns the subscription version required by the If-Match
+// header, or 0 when the header is absent or matches any version.
+func parseIfMatch(header string) (int, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+
+	tag := strings.Trim(strings.TrimPrefix(header, "W/"), `"`)
+	version, err := strconv.Atoi(tag)
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("If-Match must be a subscription ETag, got %s", header)
+	}
+
+	return version, nil
+}
+
+// manageErrorStatus maps a failed manage action to its status code. A
+// version conflict fails the precondition when the client sent If-Match and
+// is a plain conflict with a concurrent writer otherwise.
+func manageErrorStatus(err error, version int) int {
+	if !errors.Is(err, model.ErrSubscriptionConflict) {
+		return http.StatusInternalServerError
+	}
+	if version != 0 {
+		return http.StatusPreconditionFailed
+	}
+	return http.StatusConflict
+}
+
 type ManageSubscriptionRequest struct {
-	Action string `json:"action" binding:"required"`
+	Action string `json:"action" binding:"required" example:"pause"`
+	// PauseFrom and ResumeOn schedule a pause, as days in the time zone of
+	// the subscription. Both are optional and only used by the pause action.
+	PauseFrom string `json:"pause_from,omitempty" example:"2024-07-01"`
+	ResumeOn  string `json:"resume_on,omitempty" example:"2024-07-29"`
+	// Reason and ReasonText answer why the subscription is canceled. Both are
+	// optional and only used by the cancel action.
+	Reason     model.CancellationReason `json:"reason,omitempty" example:"too_expensive"`
+	ReasonText string                   `json:"reason_text,omitempty" example:"I don't train enough to justify the price"`
+	// OfferID names the retention offer taken by the accept_offer action.
+	OfferID string `json:"offer_id,omitempty" example:"0b7e5d21-3c9a-4e8f-b6d2-7f1a4c8e2d35"`
+}
+
+const dateLayout = "2006-01-02"
+
+// pauseSchedule parses the pause dates of the request.
+func (r ManageSubscriptionRequest) pauseSchedule() (model.PauseSchedule, error) {
+	pauseFrom, err := parseDate("pause_from", r.PauseFrom)
+	if err != nil {
+		return model.PauseSchedule{}, err
+	}
+
+	resumeOn, err := parseDate("resume_on", r.ResumeOn)
+	if err != nil {
+		return model.PauseSchedule{}, err
+	}
+
+	return model.PauseSchedule{PauseFrom: pauseFrom, ResumeOn: resumeOn}, nil
+}
+
+func parseDate(name, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	date, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a date like 2024-07-01, got %s", name, value)
+	}
+
+	return &date, nil
+}
+
+// PauseLimitErrorResponse tells which limit of the product's pause policy a
+// pause would break.
+type PauseLimitErrorResponse struct {
+	Error   string           `json:"error"`
+	Details string           `json:"details"`
+	Limit   model.PauseLimit `json:"limit" example:"max_pause_days"`
+	Allowed int              `json:"allowed" example:"30"`
+	Actual  int              `json:"actual" example:"41"`
 }
 
 type ManageSubscriptionResponse struct {
-	SubscriptionID string `json:"subscription_id"`
-	Message        string `json:"message"`
+	SubscriptionID string             `json:"subscription_id"`
+	Message        string             `json:"message"`
+	RefundAmount   *float64           `json:"refund_amount,omitempty"`
+	RefundStatus   model.RefundStatus `json:"refund_status,omitempty"`
 }
 
 // @Summary Manage subscription
 // @Description Manages an existing subscription. This endpoint allows users to update or modify their subscription, such as pausing, canceling, or changing other settings related to the subscription.
+// @Description Supported actions are pause, unpause, cancel_pause, cancel, accept_offer, reactivate and decline_price_change. A pause starts right away unless pause_from names a later day, and lasts until unpaused unless resume_on names the day it ends. Pausing again replaces a scheduled pause; for a paused subscription it changes resume_on. cancel_pause removes a pause that has not started yet. reactivate restores a subscription canceled within the reactivation window with a new period, charged at the current or the locked price as the product's reactivation pricing says. cancel takes an optional reason code (too_expensive, not_using, temporary_break, found_alternative, technical_issues, other) and reason_text. accept_offer takes one of the retention offers listed for a reason instead of canceling. decline_price_change declines the new price announced for the subscription, which then ends at the renewal the new price would have applied to.
 // @Tags Subscription
 // @Accept json
 // @Produce json
 // @Param subscription_id path string true "Subscription ID"
+// @Param If-Match header string false "ETag of the subscription the action is based on"
 // @Param request body ManageSubscriptionRequest true "Manage Action"
 // @Success 200 {object} ManageSubscriptionResponse
+// @Header 200 {string} ETag "Version of the subscription after the action"
 // @Failure 400 {object} ErrorResponse "Invalid action"
+// @Failure 409 {object} ErrorResponse "Subscription was modified concurrently"
+// @Failure 412 {object} ErrorResponse "Subscription does not match If-Match"
+// @Failure 422 {object} PauseLimitErrorResponse "Pause limit of the product exceeded"
 // @Failure 500 {object} ErrorResponse "Internal error"
 // @Router /api/v1/subscription/{subscription_id}/manage [post]
 func (s *Server) manageSubscription(c *gin.Context) {
@@ -196,48 +340,154 @@ func (s *Server) manageSubscription(c *gin.Context) {
 		return
 	}
 
+	version, err := parseIfMatch(c.GetHeader("If-Match"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid If-Match header",
+			Details: err.Error(),
+		})
+		return
+	}
+
 	switch request.Action {
 	case "pause":
-		err := s.service.PauseSubscription(ctx, subscriptionID)
+		schedule, err := request.pauseSchedule()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid pause dates",
+				Details: err.Error(),
+			})
+			return
+		}
+
+		err = s.service.PauseSubscription(ctx, subscriptionID, version, schedule)
+		if respondPauseLimit(c, err) {
+			return
+		}
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, ErrorResponse{
+			c.JSON(manageErrorStatus(err, version), ErrorResponse{
 				Error:   "Failed to pause subscription",
 				Details: fmt.Sprintf("Error pausing subscription: %v", err),
 			})
 			return
 		}
 
+		s.setSubscriptionETag(ctx, c, subscriptionID)
 		c.JSON(http.StatusOK, SubscriptionResponse{
 			SubscriptionID: subscriptionID,
-			Message:        "Subscription paused",
+			Message:        pausedMessage(schedule),
+		})
+	case "cancel_pause":
+		err := s.service.CancelScheduledPause(ctx, subscriptionID, version)
+		if err != nil {
+			c.JSON(manageErrorStatus(err, version), ErrorResponse{
+				Error:   "Failed to cancel scheduled pause",
+				Details: fmt.Sprintf("Error canceling scheduled pause: %v", err),
+			})
+			return
+		}
+
+		s.setSubscriptionETag(ctx, c, subscriptionID)
+		c.JSON(http.StatusOK, SubscriptionResponse{
+			SubscriptionID: subscriptionID,
+			Message:        "Scheduled pause canceled",
 		})
 	case "unpause":
-		err := s.service.UnpauseSubscription(ctx, subscriptionID)
+		err := s.service.UnpauseSubscription(ctx, subscriptionID, version)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, ErrorResponse{
+			c.JSON(manageErrorStatus(err, version), ErrorResponse{
 				Error:   "Failed to unpause subscription",
 				Details: fmt.Sprintf("Error unpausing subscription: %v", err),
 			})
 			return
 		}
 
+		s.setSubscriptionETag(ctx, c, subscriptionID)
 		c.JSON(http.StatusOK, SubscriptionResponse{
 			SubscriptionID: subscriptionID,
 			Message:        "Subscription unpaused",
 		})
 	case "cancel":
-		err := s.service.CancelSubscription(ctx, subscriptionID)
+		if request.Reason != "" && !request.Reason.Valid() {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid cancellation reason",
+				Details: fmt.Sprintf("Reason '%s' is not supported", request.Reason),
+			})
+			return
+		}
+
+		survey := model.CancellationSurvey{Reason: request.Reason, Text: request.ReasonText}
+		refund, err := s.service.CancelSubscription(ctx, subscriptionID, version, survey)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, ErrorResponse{
+			c.JSON(manageErrorStatus(err, version), ErrorResponse{
 				Error:   "Failed to cancel subscription",
 				Details: fmt.Sprintf("Error canceling subscription: %v", err),
 			})
 			return
 		}
 
-		c.JSON(http.StatusOK, SubscriptionResponse{
+		s.setSubscriptionETag(ctx, c, subscriptionID)
+		c.JSON(http.StatusOK, ManageSubscriptionResponse{
 			SubscriptionID: subscriptionID,
 			Message:        "Subscription canceled",
+			RefundAmount:   &refund.Amount,
+			RefundStatus:   refund.Status,
+		})
+	case "accept_offer":
+		if request.OfferID == "" {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Missing offer",
+				Details: "accept_offer requires offer_id",
+			})
+			return
+		}
+
+		err := s.service.AcceptRetentionOffer(ctx, subscriptionID, version, request.OfferID)
+		if respondPauseLimit(c, err) {
+			return
+		}
+		if err != nil {
+			c.JSON(manageErrorStatus(err, version), ErrorResponse{
+				Error:   "Failed to accept retention offer",
+				Details: fmt.Sprintf("Error accepting retention offer: %v", err),
+			})
+			return
+		}
+
+		s.setSubscriptionETag(ctx, c, subscriptionID)
+		c.JSON(http.StatusOK, SubscriptionResponse{
+			SubscriptionID: subscriptionID,
+			Message:        "Retention offer accepted",
+		})
+	case "reactivate":
+		err := s.service.ReactivateSubscription(ctx, subscriptionID, version)
+		if err != nil {
+			c.JSON(manageErrorStatus(err, version), ErrorResponse{
+				Error:   "Failed to reactivate subscription",
+				Details: fmt.Sprintf("Error reactivating subscription: %v", err),
+			})
+			return
+		}
+
+		s.setSubscriptionETag(ctx, c, subscriptionID)
+		c.JSON(http.StatusOK, SubscriptionResponse{
+			SubscriptionID: subscriptionID,
+			Message:        "Subscription reactivated",
+		})
+	case "decline_price_change":
+		change, err := s.service.DeclinePriceChange(ctx, subscriptionID, version)
+		if err != nil {
+			c.JSON(manageErrorStatus(err, version), ErrorResponse{
+				Error:   "Failed to decline price change",
+				Details: fmt.Sprintf("Error declining price change: %v", err),
+			})
+			return
+		}
+
+		s.setSubscriptionETag(ctx, c, subscriptionID)
+		c.JSON(http.StatusOK, SubscriptionResponse{
+			SubscriptionID: subscriptionID,
+			Message:        fmt.Sprintf("Price change declined, the subscription ends on %s", change.EffectiveDate.Format(dateLayout)),
 		})
 	default:
 		c.JSON(http.StatusBadRequest, ErrorResponse{
@@ -246,3 +496,114 @@ func (s *Server) manageSubscription(c *gin.Context) {
 		})
 	}
 }
+
+// respondPauseLimit answers with 422 and reports true when err is a broken
+// pause limit.
+func respondPauseLimit(c *gin.Context, err error) bool {
+	var limitErr *model.PauseLimitError
+	if !errors.As(err, &limitErr) {
+		return false
+	}
+
+	c.JSON(http.StatusUnprocessableEntity, PauseLimitErrorResponse{
+		Error:   "Pause limit exceeded",
+		Details: limitErr.Error(),
+		Limit:   limitErr.Limit,
+		Allowed: limitErr.Allowed,
+		Actual:  limitErr.Actual,
+	})
+	return true
+}
+
+func pausedMessage(schedule model.PauseSchedule) string {
+	if schedule.PauseFrom != nil || schedule.ResumeOn != nil {
+		return "Subscription pause scheduled"
+	}
+	return "Subscription paused"
+}
+
+// @Summary Get subscription payment attempts
+// @Description Lists every payment attempt made for a subscription, including declined renewal charges and dunning retries, so support can see why a subscription became past due or was canceled.
+// @Tags Subscription
+// @Produce json
+// @Param subscription_id path string true "Subscription ID"
+// @Success 200 {array} model.PaymentAttempt
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /api/v1/subscription/{subscription_id}/payments [get]
+func (s *Server) getPaymentAttempts(c *gin.Context) {
+	ctx := context.Background()
+	subscriptionID := c.Param("subscription_id")
+
+	attempts, err := s.service.FindPaymentAttempts(ctx, subscriptionID)
+	if err != nil {
+		log.Printf("Error finding payment attempts for subscription %s: %v", subscriptionID, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal error",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, attempts)
+}
+
+// @Summary Get subscription reactivations
+// @Description Lists when a canceled subscription was reactivated and at which price.
+// @Tags Subscription
+// @Produce json
+// @Param subscription_id path string true "Subscription ID"
+// @Success 200 {array} model.Reactivation
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /api/v1/subscription/{subscription_id}/reactivations [get]
+func (s *Server) getReactivations(c *gin.Context) {
+	ctx := context.Background()
+	subscriptionID := c.Param("subscription_id")
+
+	reactivations, err := s.service.FindReactivations(ctx, subscriptionID)
+	if err != nil {
+		log.Printf("Error finding reactivations for subscription %s: %v", subscriptionID, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal error",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, reactivations)
+}
+
+// @Summary Get retention offers
+// @Description Lists the alternatives to canceling that are configured for the cancellation reason and available for the subscription: a pause, a downgrade to a cheaper product or a discount voucher. An offer is taken with the accept_offer action of the manage endpoint.
+// @Tags Subscription
+// @Produce json
+// @Param subscription_id path string true "Subscription ID"
+// @Param reason query string true "Cancellation reason" Enums(too_expensive, not_using, temporary_break, found_alternative, technical_issues, other)
+// @Success 200 {array} model.RetentionOffer
+// @Failure 400 {object} ErrorResponse "Invalid cancellation reason"
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /api/v1/subscription/{subscription_id}/retention-offers [get]
+func (s *Server) getRetentionOffers(c *gin.Context) {
+	ctx := context.Background()
+	subscriptionID := c.Param("subscription_id")
+
+	reason := model.CancellationReason(c.Query("reason"))
+	if !reason.Valid() {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid cancellation reason",
+			Details: fmt.Sprintf("Reason '%s' is not supported", reason),
+		})
+		return
+	}
+
+	offers, err := s.service.FindRetentionOffers(ctx, subscriptionID, reason)
+	if err != nil {
+		log.Printf("Error finding retention offers for subscription %s: %v", subscriptionID, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal error",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, offers)
+}
@@ -0,0 +1,154 @@
+package rest
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gymondo/internal/model"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_GetInvoice(t *testing.T) {
+	t.Parallel()
+
+	invoiceID := uuid.New()
+	expectedInvoice := model.Invoice{
+		ID:          invoiceID,
+		Number:      "2026-000001",
+		TotalAmount: 11,
+		Currency:    "EUR",
+		LineItems:   []model.InvoiceLineItem{{Description: "basic plan", Quantity: 1, TotalPrice: 11}},
+	}
+
+	t.Run("json by default", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().FindInvoice(gomock.Any(), invoiceID.String()).Return(expectedInvoice, nil)
+
+		r := gin.Default()
+		r.GET("/api/invoices/:invoice_id", server.getInvoice)
+
+		w := performRequest(r, "GET", "/api/invoices/"+invoiceID.String())
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+		assert.Contains(t, w.Body.String(), "2026-000001")
+	})
+
+	t.Run("html via format parameter", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().FindInvoice(gomock.Any(), invoiceID.String()).Return(expectedInvoice, nil)
+
+		r := gin.Default()
+		r.GET("/api/invoices/:invoice_id", server.getInvoice)
+
+		w := performRequest(r, "GET", "/api/invoices/"+invoiceID.String()+"?format=html")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+		assert.Contains(t, w.Body.String(), "Rechnung 2026-000001")
+	})
+
+	t.Run("pdf via accept header", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().FindInvoice(gomock.Any(), invoiceID.String()).Return(expectedInvoice, nil)
+
+		r := gin.Default()
+		r.GET("/api/invoices/:invoice_id", server.getInvoice)
+
+		req, _ := http.NewRequest("GET", "/api/invoices/"+invoiceID.String(), nil)
+		req.Header.Set("Accept", "application/pdf")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+		assert.True(t, strings.HasPrefix(w.Body.String(), "%PDF-"))
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		r := gin.Default()
+		r.GET("/api/invoices/:invoice_id", server.getInvoice)
+
+		w := performRequest(r, "GET", "/api/invoices/"+invoiceID.String()+"?format=xml")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Unsupported format")
+	})
+
+	t.Run("invoice not found", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().FindInvoice(gomock.Any(), invoiceID.String()).Return(model.Invoice{}, fmt.Errorf("invoice not found"))
+
+		r := gin.Default()
+		r.GET("/api/invoices/:invoice_id", server.getInvoice)
+
+		w := performRequest(r, "GET", "/api/invoices/"+invoiceID.String())
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "Invoice not found")
+	})
+}
+
+func Test_GetSubscriptionInvoices(t *testing.T) {
+	t.Parallel()
+
+	t.Run("successful test", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		subscriptionID := uuid.New()
+		mockService.EXPECT().FindSubscriptionInvoices(gomock.Any(), subscriptionID.String()).Return([]model.Invoice{
+			{ID: uuid.New(), Number: "2026-000001"},
+			{ID: uuid.New(), Number: "2026-000002"},
+		}, nil)
+
+		r := gin.Default()
+		r.GET("/api/subscription/:subscription_id/invoices", server.getSubscriptionInvoices)
+
+		w := performRequest(r, "GET", "/api/subscription/"+subscriptionID.String()+"/invoices")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "2026-000002")
+	})
+}
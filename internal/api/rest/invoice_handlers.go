@@ -0,0 +1,136 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gymondo/internal/invoice"
+	"gymondo/internal/model"
+)
+
+const (
+	invoiceFormatJSON = "json"
+	invoiceFormatHTML = "html"
+	invoiceFormatPDF  = "pdf"
+)
+
+// @Summary Get an invoice
+// @Description Retrieves an invoice with its line items. The invoice is returned as JSON by default; pass format=html or format=pdf, or send a matching Accept header, to get a printable document.
+// @Tags Invoice
+// @Produce json
+// @Produce html
+// @Produce application/pdf
+// @Param invoice_id path string true "Invoice ID"
+// @Param format query string false "Output format" Enums(json, html, pdf)
+// @Success 200 {object} model.Invoice
+// @Failure 400 {object} ErrorResponse "Unsupported format"
+// @Failure 404 {object} ErrorResponse "Invoice not found"
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /api/v1/invoices/{invoice_id} [get]
+func (s *Server) getInvoice(c *gin.Context) {
+	ctx := context.Background()
+	invoiceID := c.Param("invoice_id")
+
+	format := invoiceFormat(c)
+	if format == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Unsupported format",
+			Details: fmt.Sprintf("Format '%s' is not supported", c.Query("format")),
+		})
+		return
+	}
+
+	foundInvoice, err := s.service.FindInvoice(ctx, invoiceID)
+	if err != nil {
+		log.Printf("Error finding invoice with ID %s: %v", invoiceID, err)
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Invoice not found",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	switch format {
+	case invoiceFormatHTML:
+		s.renderInvoice(c, foundInvoice, "text/html; charset=utf-8", invoice.RenderHTML)
+	case invoiceFormatPDF:
+		c.Header("Content-Disposition", fmt.Sprintf("inline; filename=\"invoice-%s.pdf\"", foundInvoice.Number))
+		s.renderInvoice(c, foundInvoice, "application/pdf", invoice.RenderPDF)
+	default:
+		c.JSON(http.StatusOK, foundInvoice)
+	}
+}
+
+func (s *Server) renderInvoice(
+	c *gin.Context,
+	foundInvoice model.Invoice,
+	contentType string,
+	render func(w io.Writer, invoice model.Invoice) error,
+) {
+	var document bytes.Buffer
+	if err := render(&document, foundInvoice); err != nil {
+		log.Printf("Error rendering invoice %s: %v", foundInvoice.Number, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal error",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, document.Bytes())
+}
+
+// invoiceFormat picks the output format from the format query parameter,
+// falling back to the Accept header. It returns an empty string for an
+// unsupported format.
+func invoiceFormat(c *gin.Context) string {
+	if format := c.Query("format"); format != "" {
+		switch format {
+		case invoiceFormatJSON, invoiceFormatHTML, invoiceFormatPDF:
+			return format
+		default:
+			return ""
+		}
+	}
+
+	accept := c.GetHeader("Accept")
+	switch {
+	case strings.Contains(accept, "application/pdf"):
+		return invoiceFormatPDF
+	case strings.Contains(accept, "text/html"):
+		return invoiceFormatHTML
+	default:
+		return invoiceFormatJSON
+	}
+}
+
+// @Summary Get subscription invoices
+// @Description Lists the invoices issued for a subscription, oldest first. Line items are only included when fetching a single invoice.
+// @Tags Invoice
+// @Produce json
+// @Param subscription_id path string true "Subscription ID"
+// @Success 200 {array} model.Invoice
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /api/v1/subscription/{subscription_id}/invoices [get]
+func (s *Server) getSubscriptionInvoices(c *gin.Context) {
+	ctx := context.Background()
+	subscriptionID := c.Param("subscription_id")
+
+	invoices, err := s.service.FindSubscriptionInvoices(ctx, subscriptionID)
+	if err != nil {
+		log.Printf("Error finding invoices for subscription %s: %v", subscriptionID, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal error",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, invoices)
+}
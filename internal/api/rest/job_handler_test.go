@@ -0,0 +1,71 @@
+package rest
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gymondo/internal/model"
+	"net/http"
+	"testing"
+)
+
+func Test_GetJobs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("filtered by status", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().FindJobs(gomock.Any(), model.JobFailed).
+			Return([]model.Job{{ID: uuid.New(), Name: "renewals", Status: model.JobFailed, LastError: "database error"}}, nil)
+
+		r := gin.Default()
+		r.GET("/api/admin/jobs", server.getJobs)
+
+		w := performRequest(r, "GET", "/api/admin/jobs?status=failed")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"name":"renewals"`)
+		assert.Contains(t, w.Body.String(), `"last_error":"database error"`)
+	})
+
+	t.Run("unsupported status", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		r := gin.Default()
+		r.GET("/api/admin/jobs", server.getJobs)
+
+		w := performRequest(r, "GET", "/api/admin/jobs?status=stuck")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("internal error", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().FindJobs(gomock.Any(), model.JobStatus("")).Return(nil, fmt.Errorf("database error"))
+
+		r := gin.Default()
+		r.GET("/api/admin/jobs", server.getJobs)
+
+		w := performRequest(r, "GET", "/api/admin/jobs")
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
@@ -0,0 +1,48 @@
+package rest
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gymondo/internal/model"
+)
+
+// @Summary List background jobs
+// @Description Returns the most recent runs of background jobs such as renewals, dunning and reminders, newest first, optionally filtered by status (pending, running, succeeded or failed). Requires the admin token.
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param status query string false "Job status"
+// @Success 200 {array} model.Job
+// @Failure 400 {object} ErrorResponse "Unsupported status"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /api/v1/admin/jobs [get]
+func (s *Server) getJobs(c *gin.Context) {
+	ctx := context.Background()
+	status := model.JobStatus(c.Query("status"))
+
+	switch status {
+	case "", model.JobPending, model.JobRunning, model.JobSucceeded, model.JobFailed:
+	default:
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Unsupported status",
+			Details: string(status),
+		})
+		return
+	}
+
+	jobs, err := s.service.FindJobs(ctx, status)
+	if err != nil {
+		log.Printf("Error finding jobs: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal error",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, jobs)
+}
@@ -0,0 +1,153 @@
+package rest
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gymondo/internal/model"
+	"net/http"
+	"testing"
+)
+
+func Test_InviteMember(t *testing.T) {
+	t.Parallel()
+
+	subscriptionID := uuid.New()
+	userID := uuid.New()
+
+	t.Run("successful", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().InviteMember(gomock.Any(), subscriptionID.String(), 0, userID.String()).Return(model.SubscriptionMember{
+			ID:             uuid.New(),
+			SubscriptionID: subscriptionID,
+			UserID:         userID,
+			Status:         model.MemberInvited,
+		}, nil)
+		mockService.EXPECT().FindSubscription(gomock.Any(), subscriptionID.String()).Return(model.Subscription{Version: 3}, nil)
+
+		r := gin.Default()
+		r.POST("/api/subscription/:subscription_id/members", server.inviteMember)
+
+		requestBody := `{"user_id": "` + userID.String() + `"}`
+		w := performPostRequest(r, "/api/subscription/"+subscriptionID.String()+"/members", requestBody)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"invited"`)
+	})
+
+	t.Run("all seats taken", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().InviteMember(gomock.Any(), subscriptionID.String(), 0, userID.String()).
+			Return(model.SubscriptionMember{}, fmt.Errorf("4 of 4 seats are taken: %w", model.ErrNoSeatsLeft))
+
+		r := gin.Default()
+		r.POST("/api/subscription/:subscription_id/members", server.inviteMember)
+
+		requestBody := `{"user_id": "` + userID.String() + `"}`
+		w := performPostRequest(r, "/api/subscription/"+subscriptionID.String()+"/members", requestBody)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("missing user ID", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		r := gin.Default()
+		r.POST("/api/subscription/:subscription_id/members", server.inviteMember)
+
+		w := performPostRequest(r, "/api/subscription/"+subscriptionID.String()+"/members", `{}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func Test_AcceptInvitation(t *testing.T) {
+	t.Parallel()
+
+	subscriptionID := uuid.New()
+	memberID := uuid.New()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := NewMockservice(ctrl)
+	server := &Server{service: mockService}
+
+	mockService.EXPECT().AcceptInvitation(gomock.Any(), subscriptionID.String(), memberID.String()).Return(model.SubscriptionMember{
+		ID:             memberID,
+		SubscriptionID: subscriptionID,
+		Status:         model.MemberActive,
+	}, nil)
+
+	r := gin.Default()
+	r.POST("/api/subscription/:subscription_id/members/:member_id/accept", server.acceptInvitation)
+
+	w := performPostRequest(r, "/api/subscription/"+subscriptionID.String()+"/members/"+memberID.String()+"/accept", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"active"`)
+}
+
+func Test_RemoveMember(t *testing.T) {
+	t.Parallel()
+
+	subscriptionID := uuid.New()
+	memberID := uuid.New()
+
+	t.Run("successful", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().RemoveMember(gomock.Any(), subscriptionID.String(), 0, memberID.String()).Return(nil)
+		mockService.EXPECT().FindSubscription(gomock.Any(), subscriptionID.String()).Return(model.Subscription{Version: 3}, nil)
+
+		r := gin.Default()
+		r.DELETE("/api/subscription/:subscription_id/members/:member_id", server.removeMember)
+
+		w := performRequest(r, "DELETE", "/api/subscription/"+subscriptionID.String()+"/members/"+memberID.String())
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"message":"Member removed"`)
+	})
+
+	t.Run("concurrent change", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().RemoveMember(gomock.Any(), subscriptionID.String(), 0, memberID.String()).
+			Return(fmt.Errorf("failed to remove member: %w", model.ErrSubscriptionConflict))
+
+		r := gin.Default()
+		r.DELETE("/api/subscription/:subscription_id/members/:member_id", server.removeMember)
+
+		w := performRequest(r, "DELETE", "/api/subscription/"+subscriptionID.String()+"/members/"+memberID.String())
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
@@ -0,0 +1,151 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gymondo/internal/model"
+)
+
+type InviteMemberRequest struct {
+	UserID string `json:"user_id" binding:"required" example:"2f6c3b8e-4d1a-4b7e-9c2d-8a5f0e1b3c4d"`
+}
+
+type RemoveMemberResponse struct {
+	SubscriptionID string `json:"subscription_id"`
+	MemberID       string `json:"member_id"`
+	Message        string `json:"message"`
+}
+
+// @Summary Invite a member
+// @Description Invites a user to share the subscription. The invitation takes one of the seats of the product until the member is removed, and the user is notified about it. Members are granted the product's entitlements while the subscription grants them to its owner.
+// @Tags Subscription
+// @Accept json
+// @Produce json
+// @Param subscription_id path string true "Subscription ID"
+// @Param If-Match header string false "ETag of the subscription the change is based on"
+// @Param request body InviteMemberRequest true "User to invite"
+// @Success 200 {object} model.SubscriptionMember
+// @Header 200 {string} ETag "Version of the subscription after the change"
+// @Failure 400 {object} ErrorResponse "Validation error"
+// @Failure 409 {object} ErrorResponse "Subscription was modified concurrently"
+// @Failure 412 {object} ErrorResponse "Subscription does not match If-Match"
+// @Failure 422 {object} ErrorResponse "All seats are taken"
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /api/v1/subscription/{subscription_id}/members [post]
+func (s *Server) inviteMember(c *gin.Context) {
+	ctx := context.Background()
+	subscriptionID := c.Param("subscription_id")
+
+	var request InviteMemberRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	version, err := parseIfMatch(c.GetHeader("If-Match"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid If-Match header",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	member, err := s.service.InviteMember(ctx, subscriptionID, version, request.UserID)
+	if err != nil {
+		log.Printf("Error inviting user %s to subscription %s: %v", request.UserID, subscriptionID, err)
+		status := manageErrorStatus(err, version)
+		if errors.Is(err, model.ErrNoSeatsLeft) {
+			status = http.StatusUnprocessableEntity
+		}
+		c.JSON(status, ErrorResponse{
+			Error:   "Failed to invite member",
+			Details: fmt.Sprintf("Error inviting member: %v", err),
+		})
+		return
+	}
+
+	s.setSubscriptionETag(ctx, c, subscriptionID)
+	c.JSON(http.StatusOK, member)
+}
+
+// @Summary Accept an invitation
+// @Description Makes the invited user an active member of the subscription. A user can be an active member of one subscription at a time.
+// @Tags Subscription
+// @Produce json
+// @Param subscription_id path string true "Subscription ID"
+// @Param member_id path string true "Member ID"
+// @Success 200 {object} model.SubscriptionMember
+// @Failure 409 {object} ErrorResponse "Subscription was modified concurrently"
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /api/v1/subscription/{subscription_id}/members/{member_id}/accept [post]
+func (s *Server) acceptInvitation(c *gin.Context) {
+	ctx := context.Background()
+	subscriptionID := c.Param("subscription_id")
+	memberID := c.Param("member_id")
+
+	member, err := s.service.AcceptInvitation(ctx, subscriptionID, memberID)
+	if err != nil {
+		log.Printf("Error accepting invitation %s to subscription %s: %v", memberID, subscriptionID, err)
+		c.JSON(manageErrorStatus(err, 0), ErrorResponse{
+			Error:   "Failed to accept invitation",
+			Details: fmt.Sprintf("Error accepting invitation: %v", err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// @Summary Remove a member
+// @Description Revokes an invitation or removes a member from the subscription, which frees the seat and ends the member's access right away.
+// @Tags Subscription
+// @Produce json
+// @Param subscription_id path string true "Subscription ID"
+// @Param member_id path string true "Member ID"
+// @Param If-Match header string false "ETag of the subscription the change is based on"
+// @Success 200 {object} RemoveMemberResponse
+// @Header 200 {string} ETag "Version of the subscription after the change"
+// @Failure 400 {object} ErrorResponse "Invalid If-Match header"
+// @Failure 409 {object} ErrorResponse "Subscription was modified concurrently"
+// @Failure 412 {object} ErrorResponse "Subscription does not match If-Match"
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /api/v1/subscription/{subscription_id}/members/{member_id} [delete]
+func (s *Server) removeMember(c *gin.Context) {
+	ctx := context.Background()
+	subscriptionID := c.Param("subscription_id")
+	memberID := c.Param("member_id")
+
+	version, err := parseIfMatch(c.GetHeader("If-Match"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid If-Match header",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if err := s.service.RemoveMember(ctx, subscriptionID, version, memberID); err != nil {
+		log.Printf("Error removing member %s from subscription %s: %v", memberID, subscriptionID, err)
+		c.JSON(manageErrorStatus(err, version), ErrorResponse{
+			Error:   "Failed to remove member",
+			Details: fmt.Sprintf("Error removing member: %v", err),
+		})
+		return
+	}
+
+	s.setSubscriptionETag(ctx, c, subscriptionID)
+	c.JSON(http.StatusOK, RemoveMemberResponse{
+		SubscriptionID: subscriptionID,
+		MemberID:       memberID,
+		Message:        "Member removed",
+	})
+}
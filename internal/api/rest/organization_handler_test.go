@@ -0,0 +1,184 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gymondo/internal/model"
+	"net/http"
+	"testing"
+)
+
+func Test_CreateOrganization(t *testing.T) {
+	t.Parallel()
+
+	adminID := uuid.New()
+
+	t.Run("successful", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().CreateOrganization(gomock.Any(), model.Organization{
+			Name:    "Acme GmbH",
+			VATID:   "DE123456789",
+			Address: "Hauptstraße 1",
+			Domain:  "acme.com",
+		}, adminID.String()).Return(model.Organization{
+			ID:       uuid.New(),
+			Name:     "Acme GmbH",
+			AdminIDs: []uuid.UUID{adminID},
+		}, nil)
+
+		r := gin.Default()
+		r.POST("/api/organizations", server.createOrganization)
+
+		requestBody := `{"name": "Acme GmbH", "vat_id": "DE123456789", "address": "Hauptstraße 1", "domain": "acme.com", "admin_id": "` + adminID.String() + `"}`
+		w := performPostRequest(r, "/api/organizations", requestBody)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"name":"Acme GmbH"`)
+	})
+
+	t.Run("missing address", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		r := gin.Default()
+		r.POST("/api/organizations", server.createOrganization)
+
+		requestBody := `{"name": "Acme GmbH", "admin_id": "` + adminID.String() + `"}`
+		w := performPostRequest(r, "/api/organizations", requestBody)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func Test_AddOrganizationAdmin(t *testing.T) {
+	t.Parallel()
+
+	organizationID := uuid.New().String()
+	userID := uuid.New().String()
+
+	t.Run("successful", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().AddOrganizationAdmin(gomock.Any(), organizationID, userID).Return(nil)
+
+		r := gin.Default()
+		r.POST("/api/admin/organizations/:organization_id/admins", server.addOrganizationAdmin)
+
+		w := performPostRequest(r, "/api/admin/organizations/"+organizationID+"/admins", `{"user_id": "`+userID+`"}`)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().AddOrganizationAdmin(gomock.Any(), organizationID, userID).
+			Return(fmt.Errorf("failed to fetch user: %w", errors.New("not found")))
+
+		r := gin.Default()
+		r.POST("/api/admin/organizations/:organization_id/admins", server.addOrganizationAdmin)
+
+		w := performPostRequest(r, "/api/admin/organizations/"+organizationID+"/admins", `{"user_id": "`+userID+`"}`)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func Test_ChangeSeats(t *testing.T) {
+	t.Parallel()
+
+	subscriptionID := uuid.New()
+
+	t.Run("successful", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().ChangeSeats(gomock.Any(), subscriptionID.String(), 2, 8).Return(model.SeatChange{
+			SubscriptionID: subscriptionID,
+			SeatsBefore:    5,
+			SeatsAfter:     8,
+			TotalPrice:     52.8,
+		}, nil)
+		mockService.EXPECT().FindSubscription(gomock.Any(), subscriptionID.String()).Return(model.Subscription{Version: 3}, nil)
+
+		r := gin.Default()
+		r.POST("/api/admin/subscriptions/:subscription_id/seats", server.changeSeats)
+
+		w := performPostRequestWithIfMatch(r, "/api/admin/subscriptions/"+subscriptionID.String()+"/seats", `{"seats": 8}`, `"2"`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"seats_after":8`)
+		assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	})
+
+	t.Run("no seats", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		r := gin.Default()
+		r.POST("/api/admin/subscriptions/:subscription_id/seats", server.changeSeats)
+
+		w := performPostRequest(r, "/api/admin/subscriptions/"+subscriptionID.String()+"/seats", `{"seats": 0}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func Test_JoinOrganization(t *testing.T) {
+	t.Parallel()
+
+	organizationID := uuid.New()
+	userID := uuid.New()
+
+	t.Run("all seats taken", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().JoinOrganization(gomock.Any(), organizationID.String(), userID.String()).
+			Return(model.SubscriptionMember{}, fmt.Errorf("10 of 10 seats are taken: %w", model.ErrNoSeatsLeft))
+
+		r := gin.Default()
+		r.POST("/api/organizations/:organization_id/join", server.joinOrganization)
+
+		requestBody := `{"user_id": "` + userID.String() + `"}`
+		w := performPostRequest(r, "/api/organizations/"+organizationID.String()+"/join", requestBody)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+}
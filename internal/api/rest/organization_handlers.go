@@ -0,0 +1,275 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gymondo/internal/model"
+)
+
+type CreateOrganizationRequest struct {
+	Name    string `json:"name" binding:"required" example:"Acme GmbH"`
+	VATID   string `json:"vat_id,omitempty" example:"DE123456789"`
+	Address string `json:"address" binding:"required" example:"Hauptstraße 1\n10115 Berlin"`
+	Domain  string `json:"domain,omitempty" example:"acme.com"`
+	AdminID string `json:"admin_id" binding:"required"`
+}
+
+type OrganizationUserRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+}
+
+type OrganizationSubscriptionRequest struct {
+	UserID    string `json:"user_id" binding:"required"`
+	ProductID string `json:"product_id" binding:"required" example:"e4a7c2b9-5d3f-4b8e-a1c6-7f9d0e2b3a58"`
+	Seats     int    `json:"seats" binding:"required,min=1" example:"10"`
+}
+
+type ChangeSeatsRequest struct {
+	Seats int `json:"seats" binding:"required,min=1" example:"12"`
+}
+
+// @Summary Create an organization
+// @Description Registers a company with its billing details, which are printed on the invoices of its subscriptions. The given user becomes its first admin. Employees whose email address is in the domain can join its subscription without an invitation.
+// @Tags Organization
+// @Accept json
+// @Produce json
+// @Param request body CreateOrganizationRequest true "Organization"
+// @Success 201 {object} model.Organization
+// @Failure 400 {object} ErrorResponse "Validation error"
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /api/v1/organizations [post]
+func (s *Server) createOrganization(c *gin.Context) {
+	ctx := context.Background()
+
+	var request CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	organization, err := s.service.CreateOrganization(ctx, model.Organization{
+		Name:    request.Name,
+		VATID:   request.VATID,
+		Address: request.Address,
+		Domain:  request.Domain,
+	}, request.AdminID)
+	if err != nil {
+		log.Printf("Error creating organization %s: %v", request.Name, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to create organization",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, organization)
+}
+
+// @Summary Get an organization
+// @Description Returns the billing details, domain and admins of the organization.
+// @Tags Organization
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Success 200 {object} model.Organization
+// @Failure 404 {object} ErrorResponse "Organization not found"
+// @Router /api/v1/organizations/{organization_id} [get]
+func (s *Server) getOrganization(c *gin.Context) {
+	ctx := context.Background()
+	organizationID := c.Param("organization_id")
+
+	organization, err := s.service.FindOrganization(ctx, organizationID)
+	if err != nil {
+		log.Printf("Error finding organization with ID %s: %v", organizationID, err)
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Organization not found",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, organization)
+}
+
+// @Summary Add an organization admin
+// @Description Lets the user manage the organization and subscribe for it.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param organization_id path string true "Organization ID"
+// @Param request body OrganizationUserRequest true "New admin"
+// @Success 204
+// @Failure 400 {object} ErrorResponse "Validation error"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /api/v1/admin/organizations/{organization_id}/admins [post]
+func (s *Server) addOrganizationAdmin(c *gin.Context) {
+	ctx := context.Background()
+	organizationID := c.Param("organization_id")
+
+	var request OrganizationUserRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if err := s.service.AddOrganizationAdmin(ctx, organizationID, request.UserID); err != nil {
+		log.Printf("Error adding admin %s to organization %s: %v", request.UserID, organizationID, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to add admin",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Subscribe an organization
+// @Description Subscribes the organization to a seat based product on behalf of one of its admins, who owns the subscription and takes one of its seats. The first period of all seats is charged right away. An organization has one subscription at a time.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param organization_id path string true "Organization ID"
+// @Param request body OrganizationSubscriptionRequest true "Subscription"
+// @Success 200 {object} SubscriptionResponse
+// @Failure 400 {object} ErrorResponse "Validation error"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /api/v1/admin/organizations/{organization_id}/subscribe [post]
+func (s *Server) subscribeOrganization(c *gin.Context) {
+	ctx := context.Background()
+	organizationID := c.Param("organization_id")
+
+	var request OrganizationSubscriptionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	subscriptionID, err := s.service.SubscribeOrganization(ctx, organizationID, request.UserID, request.ProductID, request.Seats)
+	if err != nil {
+		log.Printf("Error subscribing organization %s to product %s: %v", organizationID, request.ProductID, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal error",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SubscriptionResponse{
+		SubscriptionID: subscriptionID,
+		Message:        "Subscription created successfully",
+	})
+}
+
+// @Summary Join an organization
+// @Description Makes the user an active member of the organization's subscription without an invitation, if their email address is in the organization's domain and a seat is free.
+// @Tags Organization
+// @Accept json
+// @Produce json
+// @Param organization_id path string true "Organization ID"
+// @Param request body OrganizationUserRequest true "Employee"
+// @Success 200 {object} model.SubscriptionMember
+// @Failure 400 {object} ErrorResponse "Validation error"
+// @Failure 409 {object} ErrorResponse "Subscription was modified concurrently"
+// @Failure 422 {object} ErrorResponse "All seats are taken"
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /api/v1/organizations/{organization_id}/join [post]
+func (s *Server) joinOrganization(c *gin.Context) {
+	ctx := context.Background()
+	organizationID := c.Param("organization_id")
+
+	var request OrganizationUserRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	member, err := s.service.JoinOrganization(ctx, organizationID, request.UserID)
+	if err != nil {
+		log.Printf("Error joining user %s to organization %s: %v", request.UserID, organizationID, err)
+		status := manageErrorStatus(err, 0)
+		if errors.Is(err, model.ErrNoSeatsLeft) {
+			status = http.StatusUnprocessableEntity
+		}
+		c.JSON(status, ErrorResponse{
+			Error:   "Failed to join organization",
+			Details: fmt.Sprintf("Error joining organization: %v", err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// @Summary Change the seats of a subscription
+// @Description Sets the number of seats of an organization's subscription. Its price changes right away, the added or removed seats are prorated to the rest of the current period. Added seats are charged on the next renewal invoice, removed seats are credited to the owner's credit balance right away. Seats taken by the owner and members can't be removed.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param subscription_id path string true "Subscription ID"
+// @Param If-Match header string false "ETag of the subscription the change is based on"
+// @Param request body ChangeSeatsRequest true "Seats"
+// @Success 200 {object} model.SeatChange
+// @Header 200 {string} ETag "Version of the subscription after the change"
+// @Failure 400 {object} ErrorResponse "Validation error"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 409 {object} ErrorResponse "Subscription was modified concurrently"
+// @Failure 412 {object} ErrorResponse "Subscription does not match If-Match"
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /api/v1/admin/subscriptions/{subscription_id}/seats [put]
+func (s *Server) changeSeats(c *gin.Context) {
+	ctx := context.Background()
+	subscriptionID := c.Param("subscription_id")
+
+	var request ChangeSeatsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	version, err := parseIfMatch(c.GetHeader("If-Match"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid If-Match header",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	change, err := s.service.ChangeSeats(ctx, subscriptionID, version, request.Seats)
+	if err != nil {
+		log.Printf("Error changing seats of subscription %s: %v", subscriptionID, err)
+		c.JSON(manageErrorStatus(err, version), ErrorResponse{
+			Error:   "Failed to change seats",
+			Details: fmt.Sprintf("Error changing seats: %v", err),
+		})
+		return
+	}
+
+	s.setSubscriptionETag(ctx, c, subscriptionID)
+	c.JSON(http.StatusOK, change)
+}
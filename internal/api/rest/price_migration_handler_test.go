@@ -0,0 +1,156 @@
+package rest
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gymondo/internal/model"
+	"net/http"
+	"testing"
+)
+
+func Test_MigratePrice(t *testing.T) {
+	t.Parallel()
+
+	productID := uuid.New()
+	path := "/api/admin/products/" + productID.String() + "/price-migrations"
+
+	newRouter := func(server *Server) *gin.Engine {
+		r := gin.Default()
+		r.POST("/api/admin/products/:product_id/price-migrations", server.migratePrice)
+		return r
+	}
+
+	t.Run("successful migration", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().MigratePrice(gomock.Any(), productID.String(), 12.0, 2.0, false).
+			Return(model.PriceMigration{ID: uuid.New(), ProductID: productID, TotalPrice: 14}, nil)
+
+		w := performPostRequest(newRouter(server), path, `{"price": 12, "tax": 2}`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"total_price":14`)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().MigratePrice(gomock.Any(), productID.String(), 12.0, 2.0, true).
+			Return(model.PriceMigration{
+				ProductID: productID,
+				DryRun:    true,
+				PriceMigrationReport: model.PriceMigrationReport{
+					Subscriptions:       2,
+					AnnualRevenueImpact: 60.83,
+				},
+			}, nil)
+
+		w := performPostRequest(newRouter(server), path, `{"price": 12, "tax": 2, "dry_run": true}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"subscriptions":2`)
+		assert.Contains(t, w.Body.String(), `"annual_revenue_impact":60.83`)
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		w := performPostRequest(newRouter(server), path, `{"price": 0, "tax": 2}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("migration in progress", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().MigratePrice(gomock.Any(), productID.String(), 12.0, 2.0, false).
+			Return(model.PriceMigration{}, fmt.Errorf("3 price changes are pending: %w", model.ErrPriceMigrationInProgress))
+
+		w := performPostRequest(newRouter(server), path, `{"price": 12, "tax": 2}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().MigratePrice(gomock.Any(), productID.String(), 12.0, 2.0, false).
+			Return(model.PriceMigration{}, fmt.Errorf("failed to fetch product"))
+
+		w := performPostRequest(newRouter(server), path, `{"price": 12, "tax": 2}`)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func Test_GetPriceMigration(t *testing.T) {
+	t.Parallel()
+
+	migrationID := uuid.New()
+
+	t.Run("successful fetch", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().FindPriceMigration(gomock.Any(), migrationID.String()).
+			Return(model.PriceMigration{ID: migrationID, PriceMigrationReport: model.PriceMigrationReport{OptedOut: 1}}, nil)
+
+		r := gin.Default()
+		r.GET("/api/admin/price-migrations/:migration_id", server.getPriceMigration)
+
+		w := performRequest(r, "GET", "/api/admin/price-migrations/"+migrationID.String())
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"opted_out":1`)
+	})
+
+	t.Run("migration not found", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().FindPriceMigration(gomock.Any(), "999").Return(model.PriceMigration{}, fmt.Errorf("not found"))
+
+		r := gin.Default()
+		r.GET("/api/admin/price-migrations/:migration_id", server.getPriceMigration)
+
+		w := performRequest(r, "GET", "/api/admin/price-migrations/999")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
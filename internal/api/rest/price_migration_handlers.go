@@ -0,0 +1,94 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gymondo/internal/model"
+)
+
+type PriceMigrationRequest struct {
+	Price  float64 `json:"price" binding:"required,gt=0" example:"12.99"`
+	Tax    float64 `json:"tax" binding:"gte=0" example:"2.47"`
+	DryRun bool    `json:"dry_run"`
+}
+
+// @Summary Migrate the price of a product
+// @Description Sets a new price for the product. Subscriptions that have not ended get it from their first renewal after the notice period, their subscribers are notified and can decline it with the decline_price_change action, which ends the subscription at that renewal instead. The product keeps its price until the notice period has passed, so new, reactivated and gifted subscriptions pay the old price until then; those that started in the meantime get the new price after a notice period of their own. With dry_run nothing changes, the response only reports the affected subscriptions and the annual net revenue before and after. Only one migration per product can be pending at a time. Requires the admin token.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param product_id path string true "Product ID"
+// @Param request body PriceMigrationRequest true "Price Migration Request"
+// @Success 200 {object} model.PriceMigration "Dry run"
+// @Success 201 {object} model.PriceMigration
+// @Failure 400 {object} ErrorResponse "Validation error"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 422 {object} ErrorResponse "An earlier migration of the product is still pending"
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /api/v1/admin/products/{product_id}/price-migrations [post]
+func (s *Server) migratePrice(c *gin.Context) {
+	ctx := context.Background()
+	productID := c.Param("product_id")
+
+	var request PriceMigrationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Println("Validation error: ", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	migration, err := s.service.MigratePrice(ctx, productID, request.Price, request.Tax, request.DryRun)
+	if err != nil {
+		log.Printf("Error migrating price of product %s: %v", productID, err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, model.ErrPriceMigrationInProgress) {
+			status = http.StatusUnprocessableEntity
+		}
+		c.JSON(status, ErrorResponse{
+			Error:   "Failed to migrate price",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if migration.DryRun {
+		c.JSON(http.StatusOK, migration)
+		return
+	}
+	c.JSON(http.StatusCreated, migration)
+}
+
+// @Summary Get a price migration
+// @Description Returns the price migration with its subscriptions counted by whether the new price is scheduled, applied or was declined, and the annual net revenue before and after. Requires the admin token.
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param migration_id path string true "Price migration ID"
+// @Success 200 {object} model.PriceMigration
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Price migration not found"
+// @Router /api/v1/admin/price-migrations/{migration_id} [get]
+func (s *Server) getPriceMigration(c *gin.Context) {
+	ctx := context.Background()
+	migrationID := c.Param("migration_id")
+
+	migration, err := s.service.FindPriceMigration(ctx, migrationID)
+	if err != nil {
+		log.Printf("Error finding price migration %s: %v", migrationID, err)
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Price migration not found",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, migration)
+}
@@ -0,0 +1,82 @@
+package rest
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gymondo/internal/model"
+	"net/http"
+	"testing"
+)
+
+func Test_CreateReferralCode(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := NewMockservice(ctrl)
+	server := &Server{service: mockService}
+
+	userID := uuid.New()
+	mockService.EXPECT().CreateReferralCode(gomock.Any(), userID.String()).
+		Return(model.ReferralCode{UserID: userID, Code: "ANNA-7K3M"}, nil)
+
+	r := gin.Default()
+	r.POST("/api/users/:user_id/referral-code", server.createReferralCode)
+
+	w := performPostRequest(r, "/api/users/"+userID.String()+"/referral-code", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"ANNA-7K3M"`)
+}
+
+func Test_GetReferralStats(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := NewMockservice(ctrl)
+	server := &Server{service: mockService}
+
+	userID := uuid.New()
+	mockService.EXPECT().FindReferralStats(gomock.Any(), userID.String()).Return(model.ReferralStats{
+		UserID:     userID,
+		Code:       "ANNA-7K3M",
+		Referrals:  3,
+		Rewarded:   2,
+		DaysEarned: 60,
+	}, nil)
+
+	r := gin.Default()
+	r.GET("/api/users/:user_id/referrals", server.getReferralStats)
+
+	w := performRequest(r, "GET", "/api/users/"+userID.String()+"/referrals")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"rewarded":2`)
+	assert.Contains(t, w.Body.String(), `"days_earned":60`)
+}
+
+func Test_Subscribe_RejectedReferral(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := NewMockservice(ctrl)
+	server := &Server{service: mockService}
+
+	mockService.EXPECT().Subscribe(gomock.Any(), "123", "456", "", "ANNA-7K3M", gomock.Nil(), false).
+		Return("", fmt.Errorf("users can't refer themselves: %w", model.ErrReferralRejected))
+
+	r := gin.Default()
+	r.POST("/api/subscribe", server.subscribe)
+
+	requestBody := `{"user_id": "123", "product_id": "456", "referral_code": "ANNA-7K3M"}`
+	w := performPostRequest(r, "/api/subscribe", requestBody)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), `"error":"Validation error"`)
+	assert.Contains(t, w.Body.String(), "users can't refer themselves")
+}
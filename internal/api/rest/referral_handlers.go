@@ -0,0 +1,59 @@
+package rest
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Get or create a user's referral code
+// @Description Returns the code the user shares to refer friends, creating it on first use. Friends pass it as referral_code when subscribing.
+// @Tags Referral
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Success 200 {object} model.ReferralCode
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /api/v1/users/{user_id}/referral-code [post]
+func (s *Server) createReferralCode(c *gin.Context) {
+	ctx := context.Background()
+	userID := c.Param("user_id")
+
+	code, err := s.service.CreateReferralCode(ctx, userID)
+	if err != nil {
+		log.Printf("Error creating referral code of user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to create referral code",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, code)
+}
+
+// @Summary Get a user's referral stats
+// @Description Counts the referrals the user made by status and sums up the credit and extra days they got for referring and for being referred.
+// @Tags Referral
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Success 200 {object} model.ReferralStats
+// @Failure 404 {object} ErrorResponse "Referral stats not found"
+// @Router /api/v1/users/{user_id}/referrals [get]
+func (s *Server) getReferralStats(c *gin.Context) {
+	ctx := context.Background()
+	userID := c.Param("user_id")
+
+	stats, err := s.service.FindReferralStats(ctx, userID)
+	if err != nil {
+		log.Printf("Error finding referral stats of user %s: %v", userID, err)
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Referral stats not found",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
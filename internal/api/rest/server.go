@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"crypto/subtle"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/cors"
 	swaggerfiles "github.com/swaggo/files"
@@ -9,12 +10,16 @@ import (
 )
 
 type Server struct {
-	service service
+	service    service
+	adminToken string
+	// traveler is only set in non-production builds, see EnableTimeTravel.
+	traveler timeTraveler
 }
 
-func New(service service) *Server {
+func New(service service, adminToken string) *Server {
 	return &Server{
-		service: service,
+		service:    service,
+		adminToken: adminToken,
 	}
 }
 
@@ -26,8 +31,8 @@ func (s *Server) NewRoutes() http.Handler {
 		corsMiddleware := cors.New(cors.Options{
 			AllowedOrigins:   []string{"https://*", "http://*"},
 			AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-			AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-			ExposedHeaders:   []string{"Link"},
+			AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "If-Match", adminTokenHeader},
+			ExposedHeaders:   []string{"Link", "ETag"},
 			AllowCredentials: true,
 			MaxAge:           300,
 		})
@@ -43,6 +48,57 @@ func (s *Server) NewRoutes() http.Handler {
 	router.POST("/api/v1/product/subscribe/", s.subscribe)
 	router.GET("/api/v1/subscription/:subscription_id", s.getSubscription)
 	router.POST("/api/v1/subscription/:subscription_id/manage", s.manageSubscription)
+	router.GET("/api/v1/subscription/:subscription_id/payments", s.getPaymentAttempts)
+	router.GET("/api/v1/subscription/:subscription_id/reactivations", s.getReactivations)
+	router.GET("/api/v1/subscription/:subscription_id/retention-offers", s.getRetentionOffers)
+	router.POST("/api/v1/subscription/:subscription_id/add-ons", s.attachAddOn)
+	router.DELETE("/api/v1/subscription/:subscription_id/add-ons/:add_on_id", s.detachAddOn)
+	router.POST("/api/v1/subscription/:subscription_id/members", s.inviteMember)
+	router.POST("/api/v1/subscription/:subscription_id/members/:member_id/accept", s.acceptInvitation)
+	router.DELETE("/api/v1/subscription/:subscription_id/members/:member_id", s.removeMember)
+	router.GET("/api/v1/subscription/:subscription_id/invoices", s.getSubscriptionInvoices)
+	router.GET("/api/v1/invoices/:invoice_id", s.getInvoice)
+	router.GET("/api/v1/users/:user_id/credit", s.getCreditBalance)
+	router.GET("/api/v1/users/:user_id/entitlements", s.getEntitlements)
+	router.POST("/api/v1/users/:user_id/referral-code", s.createReferralCode)
+	router.GET("/api/v1/users/:user_id/referrals", s.getReferralStats)
+	router.POST("/api/v1/organizations", s.createOrganization)
+	router.GET("/api/v1/organizations/:organization_id", s.getOrganization)
+	router.POST("/api/v1/organizations/:organization_id/join", s.joinOrganization)
+	router.POST("/api/v1/gifts", s.purchaseGift)
+	router.GET("/api/v1/gifts/:code", s.getGift)
+	router.POST("/api/v1/gifts/:code/redeem", s.redeemGift)
+
+	admin := router.Group("/api/v1/admin", s.requireAdmin)
+	admin.POST("/users/:user_id/credit", s.grantCredit)
+	admin.POST("/products/:product_id/price-migrations", s.migratePrice)
+	admin.GET("/price-migrations/:migration_id", s.getPriceMigration)
+	admin.POST("/webhooks/endpoints", s.registerWebhookEndpoint)
+	admin.GET("/webhooks/endpoints", s.getWebhookEndpoints)
+	admin.DELETE("/webhooks/endpoints/:endpoint_id", s.deleteWebhookEndpoint)
+	admin.GET("/webhooks/deliveries", s.getWebhookDeliveries)
+	admin.POST("/webhooks/deliveries/:delivery_id/replay", s.replayWebhookDelivery)
+	admin.POST("/organizations/:organization_id/admins", s.addOrganizationAdmin)
+	admin.POST("/organizations/:organization_id/subscribe", s.subscribeOrganization)
+	admin.PUT("/subscriptions/:subscription_id/seats", s.changeSeats)
+	admin.GET("/jobs", s.getJobs)
+	s.registerTimeTravelRoutes(admin)
 
 	return router
 }
+
+const adminTokenHeader = "X-Admin-Token"
+
+// requireAdmin only lets requests through that carry the configured admin
+// token. Without a configured token the admin API stays closed.
+func (s *Server) requireAdmin(c *gin.Context) {
+	token := c.GetHeader(adminTokenHeader)
+	if s.adminToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) != 1 {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{
+			Error: "Unauthorized",
+		})
+		return
+	}
+
+	c.Next()
+}
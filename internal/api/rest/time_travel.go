@@ -0,0 +1,115 @@
+//go:build !production
+
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EnableTimeTravel exposes admin endpoints that move the traveler, which must
+// be the clock of the service, forward in time. It only exists in
+// non-production builds.
+func (s *Server) EnableTimeTravel(traveler timeTraveler) {
+	s.traveler = traveler
+}
+
+func (s *Server) registerTimeTravelRoutes(admin *gin.RouterGroup) {
+	if s.traveler == nil {
+		return
+	}
+
+	admin.GET("/time", s.getTime)
+	admin.POST("/time/travel", s.travelInTime)
+	admin.DELETE("/time/travel", s.resetTime)
+}
+
+type TimeResponse struct {
+	Now    time.Time `json:"now"`
+	Offset string    `json:"offset"`
+}
+
+type TimeTravelRequest struct {
+	Days     int    `json:"days"`
+	Duration string `json:"duration" example:"36h"`
+}
+
+func (s *Server) timeResponse() TimeResponse {
+	return TimeResponse{
+		Now:    s.traveler.Now(),
+		Offset: s.traveler.Offset().String(),
+	}
+}
+
+// @Summary Get the service time
+// @Description Returns the time the service currently works with for all users and how far it is ahead of the real time. Only available in non-production builds. Requires the admin token.
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Success 200 {object} TimeResponse
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Router /api/v1/admin/time [get]
+func (s *Server) getTime(c *gin.Context) {
+	c.JSON(http.StatusOK, s.timeResponse())
+}
+
+// @Summary Travel forward in time
+// @Description Moves the service time forward by the given days and duration, so trials expire and renewals become due without waiting. The next background job runs pick up the new time. The offset is global: it applies to every user and subscription of the instance and its database, not only to the caller's. Non-production builds therefore refuse to start against a database another instance uses. Only available in non-production builds. Requires the admin token.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param request body TimeTravelRequest true "Time Travel Request"
+// @Success 200 {object} TimeResponse
+// @Failure 400 {object} ErrorResponse "Validation error"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Router /api/v1/admin/time/travel [post]
+func (s *Server) travelInTime(c *gin.Context) {
+	var request TimeTravelRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	d := time.Duration(request.Days) * 24 * time.Hour
+	if request.Duration != "" {
+		parsed, err := time.ParseDuration(request.Duration)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Validation error",
+				Details: fmt.Sprintf("invalid duration %q", request.Duration),
+			})
+			return
+		}
+		d += parsed
+	}
+
+	if err := s.traveler.Travel(d); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, s.timeResponse())
+}
+
+// @Summary Return to the present
+// @Description Resets the service time to the real time. Background jobs are rescheduled from the real time, data written while traveling keeps its dates. Only available in non-production builds. Requires the admin token.
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Success 200 {object} TimeResponse
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Router /api/v1/admin/time/travel [delete]
+func (s *Server) resetTime(c *gin.Context) {
+	s.traveler.Reset()
+	c.JSON(http.StatusOK, s.timeResponse())
+}
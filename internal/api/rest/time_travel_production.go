@@ -0,0 +1,9 @@
+//go:build production
+
+package rest
+
+import "github.com/gin-gonic/gin"
+
+// registerTimeTravelRoutes does nothing, production builds can't travel in
+// time.
+func (s *Server) registerTimeTravelRoutes(admin *gin.RouterGroup) {}
@@ -0,0 +1,89 @@
+//go:build !production
+
+package rest
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gymondo/internal/clock"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newTimeTravelRouter(traveler timeTraveler) *gin.Engine {
+	server := &Server{}
+	server.EnableTimeTravel(traveler)
+
+	r := gin.Default()
+	server.registerTimeTravelRoutes(r.Group("/api/admin"))
+	return r
+}
+
+func Test_TimeTravel(t *testing.T) {
+	t.Parallel()
+
+	base := time.Date(2024, time.March, 10, 12, 0, 0, 0, time.UTC)
+
+	t.Run("routes are missing without a traveler", func(t *testing.T) {
+		t.Parallel()
+
+		server := &Server{}
+		r := gin.Default()
+		server.registerTimeTravelRoutes(r.Group("/api/admin"))
+
+		w := performRequest(r, "GET", "/api/admin/time")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("travel by days and duration", func(t *testing.T) {
+		t.Parallel()
+
+		traveler := clock.NewTraveler(clock.Fixed(base))
+		r := newTimeTravelRouter(traveler)
+
+		w := performPostRequest(r, "/api/admin/time/travel", `{"days": 14, "duration": "2h"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"now":"2024-03-24T14:00:00Z"`)
+		assert.Contains(t, w.Body.String(), `"offset":"338h0m0s"`)
+		assert.Equal(t, base.Add(338*time.Hour), traveler.Now())
+	})
+
+	t.Run("invalid duration", func(t *testing.T) {
+		t.Parallel()
+
+		traveler := clock.NewTraveler(clock.Fixed(base))
+		r := newTimeTravelRouter(traveler)
+
+		w := performPostRequest(r, "/api/admin/time/travel", `{"duration": "soon"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, time.Duration(0), traveler.Offset())
+	})
+
+	t.Run("travel into the past", func(t *testing.T) {
+		t.Parallel()
+
+		traveler := clock.NewTraveler(clock.Fixed(base))
+		r := newTimeTravelRouter(traveler)
+
+		w := performPostRequest(r, "/api/admin/time/travel", `{"days": -1}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, time.Duration(0), traveler.Offset())
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		t.Parallel()
+
+		traveler := clock.NewTraveler(clock.Fixed(base))
+		assert.NoError(t, traveler.Travel(48*time.Hour))
+		r := newTimeTravelRouter(traveler)
+
+		w := performRequest(r, "DELETE", "/api/admin/time/travel")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, base, traveler.Now())
+
+		w = performRequest(r, "GET", "/api/admin/time")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"offset":"0s"`)
+	})
+}
@@ -0,0 +1,141 @@
+package rest
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gymondo/internal/model"
+	"net/http"
+	"testing"
+)
+
+func Test_RegisterWebhookEndpoint(t *testing.T) {
+	t.Parallel()
+
+	t.Run("successful registration", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		eventTypes := []model.WebhookEventType{model.SubscriptionCreated}
+		mockService.EXPECT().RegisterWebhookEndpoint(gomock.Any(), "https://crm.example.com/hooks", eventTypes).
+			Return(model.WebhookEndpoint{ID: uuid.New(), Secret: "whsec_1", EventTypes: eventTypes, Active: true}, nil)
+
+		r := gin.Default()
+		r.POST("/api/admin/webhooks/endpoints", server.registerWebhookEndpoint)
+
+		w := performPostRequest(r, "/api/admin/webhooks/endpoints",
+			`{"url": "https://crm.example.com/hooks", "event_types": ["subscription.created"]}`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"secret":"whsec_1"`)
+	})
+
+	t.Run("invalid endpoint", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().RegisterWebhookEndpoint(gomock.Any(), "crm", gomock.Any()).
+			Return(model.WebhookEndpoint{}, fmt.Errorf("invalid webhook url"))
+
+		r := gin.Default()
+		r.POST("/api/admin/webhooks/endpoints", server.registerWebhookEndpoint)
+
+		w := performPostRequest(r, "/api/admin/webhooks/endpoints", `{"url": "crm"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func Test_GetWebhookDeliveries(t *testing.T) {
+	t.Parallel()
+
+	t.Run("filtered by status", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().FindWebhookDeliveries(gomock.Any(), model.WebhookDeadLettered).
+			Return([]model.WebhookDelivery{{ID: uuid.New(), Status: model.WebhookDeadLettered}}, nil)
+
+		r := gin.Default()
+		r.GET("/api/admin/webhooks/deliveries", server.getWebhookDeliveries)
+
+		w := performRequest(r, "GET", "/api/admin/webhooks/deliveries?status=dead")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"dead"`)
+	})
+
+	t.Run("unsupported status", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		r := gin.Default()
+		r.GET("/api/admin/webhooks/deliveries", server.getWebhookDeliveries)
+
+		w := performRequest(r, "GET", "/api/admin/webhooks/deliveries?status=lost")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func Test_ReplayWebhookDelivery(t *testing.T) {
+	t.Parallel()
+
+	t.Run("successful replay", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		deliveryID := uuid.New()
+		mockService.EXPECT().ReplayWebhookDelivery(gomock.Any(), deliveryID.String()).
+			Return(model.WebhookDelivery{ID: deliveryID, Status: model.WebhookPending}, nil)
+
+		r := gin.Default()
+		r.POST("/api/admin/webhooks/deliveries/:delivery_id/replay", server.replayWebhookDelivery)
+
+		w := performPostRequest(r, "/api/admin/webhooks/deliveries/"+deliveryID.String()+"/replay", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"pending"`)
+	})
+
+	t.Run("delivery not found", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockService := NewMockservice(ctrl)
+		server := &Server{service: mockService}
+
+		mockService.EXPECT().ReplayWebhookDelivery(gomock.Any(), "999").
+			Return(model.WebhookDelivery{}, fmt.Errorf("webhook delivery not found"))
+
+		r := gin.Default()
+		r.POST("/api/admin/webhooks/deliveries/:delivery_id/replay", server.replayWebhookDelivery)
+
+		w := performPostRequest(r, "/api/admin/webhooks/deliveries/999/replay", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
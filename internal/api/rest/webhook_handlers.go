@@ -0,0 +1,167 @@
+package rest
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gymondo/internal/model"
+)
+
+type RegisterWebhookEndpointRequest struct {
+	URL        string                   `json:"url" binding:"required"`
+	EventTypes []model.WebhookEventType `json:"event_types"`
+}
+
+// @Summary Register a webhook endpoint
+// @Description Registers an endpoint that receives subscription lifecycle events (subscription.created, subscription.paused, subscription.unpaused, subscription.pause_scheduled, subscription.canceled, subscription.reactivated, subscription.updated, subscription.activated). Without event types the endpoint receives every event. Payloads are signed with the returned secret, which is not shown again. Requires the admin token.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param request body RegisterWebhookEndpointRequest true "Register Webhook Endpoint Request"
+// @Success 201 {object} model.WebhookEndpoint
+// @Failure 400 {object} ErrorResponse "Validation error"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Router /api/v1/admin/webhooks/endpoints [post]
+func (s *Server) registerWebhookEndpoint(c *gin.Context) {
+	ctx := context.Background()
+
+	var request RegisterWebhookEndpointRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Println("Validation error: ", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	endpoint, err := s.service.RegisterWebhookEndpoint(ctx, request.URL, request.EventTypes)
+	if err != nil {
+		log.Printf("Error registering webhook endpoint %s: %v", request.URL, err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation error",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, endpoint)
+}
+
+// @Summary List webhook endpoints
+// @Description Returns all registered webhook endpoints without their secrets. Requires the admin token.
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Success 200 {array} model.WebhookEndpoint
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /api/v1/admin/webhooks/endpoints [get]
+func (s *Server) getWebhookEndpoints(c *gin.Context) {
+	ctx := context.Background()
+
+	endpoints, err := s.service.FindWebhookEndpoints(ctx)
+	if err != nil {
+		log.Printf("Error finding webhook endpoints: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal error",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, endpoints)
+}
+
+// @Summary Delete a webhook endpoint
+// @Description Deactivates the endpoint. Deliveries that are still pending are dead-lettered. Requires the admin token.
+// @Tags Admin
+// @Param X-Admin-Token header string true "Admin token"
+// @Param endpoint_id path string true "Endpoint ID"
+// @Success 204
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Webhook endpoint not found"
+// @Router /api/v1/admin/webhooks/endpoints/{endpoint_id} [delete]
+func (s *Server) deleteWebhookEndpoint(c *gin.Context) {
+	ctx := context.Background()
+	endpointID := c.Param("endpoint_id")
+
+	if err := s.service.DeleteWebhookEndpoint(ctx, endpointID); err != nil {
+		log.Printf("Error deleting webhook endpoint %s: %v", endpointID, err)
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Webhook endpoint not found",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary List webhook deliveries
+// @Description Returns webhook deliveries, newest first, optionally filtered by status (pending, delivered or dead). Requires the admin token.
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param status query string false "Delivery status"
+// @Success 200 {array} model.WebhookDelivery
+// @Failure 400 {object} ErrorResponse "Unsupported status"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /api/v1/admin/webhooks/deliveries [get]
+func (s *Server) getWebhookDeliveries(c *gin.Context) {
+	ctx := context.Background()
+	status := model.WebhookDeliveryStatus(c.Query("status"))
+
+	switch status {
+	case "", model.WebhookPending, model.WebhookDelivered, model.WebhookDeadLettered:
+	default:
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Unsupported status",
+			Details: string(status),
+		})
+		return
+	}
+
+	deliveries, err := s.service.FindWebhookDeliveries(ctx, status)
+	if err != nil {
+		log.Printf("Error finding webhook deliveries: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Internal error",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// @Summary Replay a webhook delivery
+// @Description Queues a delivered or dead-lettered webhook delivery again with a fresh set of attempts. Requires the admin token.
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Token header string true "Admin token"
+// @Param delivery_id path string true "Delivery ID"
+// @Success 200 {object} model.WebhookDelivery
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Webhook delivery not found"
+// @Router /api/v1/admin/webhooks/deliveries/{delivery_id}/replay [post]
+func (s *Server) replayWebhookDelivery(c *gin.Context) {
+	ctx := context.Background()
+	deliveryID := c.Param("delivery_id")
+
+	delivery, err := s.service.ReplayWebhookDelivery(ctx, deliveryID)
+	if err != nil {
+		log.Printf("Error replaying webhook delivery %s: %v", deliveryID, err)
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Webhook delivery not found",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
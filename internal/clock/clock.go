@@ -0,0 +1,31 @@
+package clock
+
+import "time"
+
+// Clock tells the current time. Date logic asks a Clock instead of calling
+// time.Now, so tests can pin the time and QA can move it forward.
+type Clock interface {
+	Now() time.Time
+}
+
+type system struct{}
+
+// System returns the clock of the operating system.
+func System() Clock {
+	return system{}
+}
+
+func (system) Now() time.Time {
+	return time.Now()
+}
+
+type fixed time.Time
+
+// Fixed returns a clock that always tells t.
+func Fixed(t time.Time) Clock {
+	return fixed(t)
+}
+
+func (f fixed) Now() time.Time {
+	return time.Time(f)
+}
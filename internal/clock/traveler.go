@@ -0,0 +1,56 @@
+//go:build !production
+
+package clock
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Traveler is a clock that runs ahead of another one by an adjustable
+// offset. It lets QA watch trials expire and subscriptions renew without
+// waiting, and is not part of production builds. There is a single offset
+// for everything the clock is used for, not one per user.
+type Traveler struct {
+	base Clock
+
+	mu     sync.RWMutex
+	offset time.Duration
+}
+
+func NewTraveler(base Clock) *Traveler {
+	return &Traveler{base: base}
+}
+
+func (t *Traveler) Now() time.Time {
+	return t.base.Now().Add(t.Offset())
+}
+
+func (t *Traveler) Offset() time.Duration {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.offset
+}
+
+// Travel moves the clock forward. Time never runs backwards, since dates
+// that were already written would then lie in the future.
+func (t *Traveler) Travel(d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf("can only travel forward in time, got %s", d)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.offset += d
+	return nil
+}
+
+// Reset returns to the base clock's time. Dates written while traveling stay
+// ahead of it; the job runner notices the clock went back and reschedules
+// its jobs from the present.
+func (t *Traveler) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.offset = 0
+}
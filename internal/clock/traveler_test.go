@@ -0,0 +1,29 @@
+//go:build !production
+
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Traveler(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.January, 10, 12, 0, 0, 0, time.UTC)
+	traveler := NewTraveler(Fixed(now))
+	assert.Equal(t, now, traveler.Now())
+
+	assert.NoError(t, traveler.Travel(72*time.Hour))
+	assert.NoError(t, traveler.Travel(time.Hour))
+	assert.Equal(t, 73*time.Hour, traveler.Offset())
+	assert.Equal(t, now.Add(73*time.Hour), traveler.Now())
+
+	assert.Error(t, traveler.Travel(-time.Hour))
+	assert.Equal(t, 73*time.Hour, traveler.Offset())
+
+	traveler.Reset()
+	assert.Equal(t, now, traveler.Now())
+}
@@ -0,0 +1,36 @@
+package invoice
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// formatAmount renders an amount the way German invoices print it, for
+// example "1.234,50 EUR".
+func formatAmount(amount float64, currency string) string {
+	formatted := fmt.Sprintf("%.2f", amount)
+	integer, fraction, _ := strings.Cut(formatted, ".")
+
+	negative := strings.HasPrefix(integer, "-")
+	integer = strings.TrimPrefix(integer, "-")
+
+	var grouped strings.Builder
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			grouped.WriteByte('.')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	sign := ""
+	if negative {
+		sign = "-"
+	}
+
+	return fmt.Sprintf("%s%s,%s %s", sign, grouped.String(), fraction, currency)
+}
+
+func formatDate(date time.Time) string {
+	return date.Format("02.01.2006")
+}
@@ -0,0 +1,82 @@
+package invoice
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"gymondo/internal/model"
+)
+
+const (
+	sellerName    = "Gymondo GmbH"
+	sellerAddress = "Ritterstraße 12-14, 10969 Berlin"
+	sellerVATID   = "DE281372484"
+)
+
+var htmlTemplate = template.Must(template.New("invoice").Funcs(template.FuncMap{
+	"amount": formatAmount,
+	"date":   formatDate,
+	"inc":    func(i int) int { return i + 1 },
+	"lines":  func(text string) []string { return strings.Split(text, "\n") },
+}).Parse(`<!DOCTYPE html>
+<html lang="de">
+<head>
+	<meta charset="utf-8">
+	<title>Rechnung {{.Invoice.Number}}</title>
+</head>
+<body>
+	<header>
+		<p>{{.SellerName}}<br>{{.SellerAddress}}<br>USt-IdNr. {{.SellerVATID}}</p>
+	</header>
+	<section>
+		{{- if .Invoice.CompanyName}}
+		<p>{{.Invoice.CompanyName}}{{range lines .Invoice.BillingAddress}}<br>{{.}}{{end}}{{if .Invoice.VATID}}<br>USt-IdNr. {{.Invoice.VATID}}{{end}}</p>
+		{{- end}}
+		<p>{{.Invoice.CustomerName}}<br>{{.Invoice.CustomerEmail}}</p>
+	</section>
+	<h1>Rechnung {{.Invoice.Number}}</h1>
+	<p>
+		Rechnungsdatum: {{date .Invoice.IssueDate}}<br>
+		Leistungszeitraum: {{date .Invoice.PeriodStart}} - {{date .Invoice.PeriodEnd}}
+	</p>
+	<table>
+		<thead>
+			<tr><th>Pos.</th><th>Beschreibung</th><th>Menge</th><th>Einzelpreis</th><th>USt.</th><th>Gesamt</th></tr>
+		</thead>
+		<tbody>
+			{{- range $i, $item := .Invoice.LineItems}}
+			<tr><td>{{inc $i}}</td><td>{{$item.Description}}</td><td>{{$item.Quantity}}</td><td>{{amount $item.UnitPrice $.Invoice.Currency}}</td><td>{{amount $item.Tax $.Invoice.Currency}}</td><td>{{amount $item.TotalPrice $.Invoice.Currency}}</td></tr>
+			{{- end}}
+		</tbody>
+	</table>
+	<p>
+		Nettobetrag: {{amount .Invoice.NetAmount .Invoice.Currency}}<br>
+		Umsatzsteuer: {{amount .Invoice.TaxAmount .Invoice.Currency}}<br>
+		<strong>Gesamtbetrag: {{amount .Invoice.TotalAmount .Invoice.Currency}}</strong>
+	</p>
+</body>
+</html>
+`))
+
+// RenderHTML writes the invoice as a standalone HTML document.
+func RenderHTML(w io.Writer, invoice model.Invoice) error {
+	data := struct {
+		SellerName    string
+		SellerAddress string
+		SellerVATID   string
+		Invoice       model.Invoice
+	}{
+		SellerName:    sellerName,
+		SellerAddress: sellerAddress,
+		SellerVATID:   sellerVATID,
+		Invoice:       invoice,
+	}
+
+	if err := htmlTemplate.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to render invoice %s as HTML: %w", invoice.Number, err)
+	}
+
+	return nil
+}
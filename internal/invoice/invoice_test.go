@@ -0,0 +1,86 @@
+package invoice
+
+import (
+	"bytes"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gymondo/internal/model"
+	"testing"
+	"time"
+)
+
+func testInvoice() model.Invoice {
+	issueDate := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	return model.Invoice{
+		ID:            uuid.New(),
+		Number:        "2026-000042",
+		CustomerName:  "jürgen (jay) müller",
+		CustomerEmail: "juergen@example.com",
+		IssueDate:     issueDate,
+		PeriodStart:   issueDate,
+		PeriodEnd:     issueDate.AddDate(0, 0, 365),
+		NetAmount:     1200,
+		TaxAmount:     228,
+		TotalAmount:   1428,
+		Currency:      "EUR",
+		LineItems: []model.InvoiceLineItem{
+			{Description: "enterprise plan", Quantity: 1, UnitPrice: 1200, Tax: 228, TotalPrice: 1428},
+		},
+	}
+}
+
+func Test_formatAmount(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "0,00 EUR", formatAmount(0, "EUR"))
+	assert.Equal(t, "11,00 EUR", formatAmount(11, "EUR"))
+	assert.Equal(t, "1.234,50 EUR", formatAmount(1234.5, "EUR"))
+	assert.Equal(t, "-1.234.567,89 EUR", formatAmount(-1234567.89, "EUR"))
+}
+
+func Test_RenderHTML(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	err := RenderHTML(&buf, testInvoice())
+	assert.NoError(t, err)
+
+	html := buf.String()
+	assert.Contains(t, html, "Rechnung 2026-000042")
+	assert.Contains(t, html, "jürgen (jay) müller")
+	assert.Contains(t, html, "Leistungszeitraum: 01.03.2026 - 01.03.2027")
+	assert.Contains(t, html, "Gesamtbetrag: 1.428,00 EUR")
+}
+
+func Test_RenderPDF(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	err := RenderPDF(&buf, testInvoice())
+	assert.NoError(t, err)
+
+	pdf := buf.String()
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-1.4")))
+	assert.True(t, bytes.HasSuffix(buf.Bytes(), []byte("%%EOF\n")))
+	assert.Contains(t, pdf, "(Rechnung 2026-000042) Tj")
+	assert.Contains(t, pdf, `(j\374rgen \(jay\) m\374ller) Tj`)
+}
+
+func Test_RenderCompanyDetails(t *testing.T) {
+	t.Parallel()
+
+	invoice := testInvoice()
+	invoice.CompanyName = "Acme GmbH"
+	invoice.VATID = "DE123456789"
+	invoice.BillingAddress = "Hauptstraße 1\n10115 Berlin"
+
+	var html bytes.Buffer
+	assert.NoError(t, RenderHTML(&html, invoice))
+	assert.Contains(t, html.String(), "Acme GmbH<br>Hauptstraße 1<br>10115 Berlin<br>USt-IdNr. DE123456789")
+
+	var pdf bytes.Buffer
+	assert.NoError(t, RenderPDF(&pdf, invoice))
+	assert.Contains(t, pdf.String(), "(Acme GmbH) Tj")
+	assert.Contains(t, pdf.String(), "(10115 Berlin) Tj")
+	assert.Contains(t, pdf.String(), "(USt-IdNr. DE123456789) Tj")
+}
@@ -0,0 +1,175 @@
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"gymondo/internal/model"
+)
+
+const (
+	pageWidth  = 595
+	pageHeight = 842
+	leftMargin = 50
+)
+
+type pdfFont string
+
+const (
+	regular pdfFont = "F1"
+	bold    pdfFont = "F2"
+)
+
+type pdfText struct {
+	font pdfFont
+	size int
+	x, y int
+	text string
+}
+
+// RenderPDF writes the invoice as a single page A4 PDF document. Only the
+// standard Helvetica fonts are used, so no font files need to be embedded.
+func RenderPDF(w io.Writer, invoice model.Invoice) error {
+	content := pdfContent(pdfLayout(invoice))
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] "+
+				"/Resources << /Font << /F1 5 0 R /F2 6 0 R >> >> /Contents 4 0 R >>",
+			pageWidth, pageHeight,
+		),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+	}
+
+	var document bytes.Buffer
+	document.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = document.Len()
+		fmt.Fprintf(&document, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xrefOffset := document.Len()
+	fmt.Fprintf(&document, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&document, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&document, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xrefOffset)
+
+	if _, err := w.Write(document.Bytes()); err != nil {
+		return fmt.Errorf("failed to render invoice %s as PDF: %w", invoice.Number, err)
+	}
+
+	return nil
+}
+
+func pdfLayout(invoice model.Invoice) []pdfText {
+	y := pageHeight - 60
+	var texts []pdfText
+	line := func(font pdfFont, size, x int, text string) {
+		texts = append(texts, pdfText{font: font, size: size, x: x, y: y, text: text})
+	}
+
+	line(bold, 12, leftMargin, sellerName)
+	y -= 15
+	line(regular, 10, leftMargin, sellerAddress)
+	y -= 13
+	line(regular, 10, leftMargin, "USt-IdNr. "+sellerVATID)
+
+	y -= 45
+	if invoice.CompanyName != "" {
+		line(bold, 10, leftMargin, invoice.CompanyName)
+		for _, addressLine := range strings.Split(invoice.BillingAddress, "\n") {
+			y -= 13
+			line(regular, 10, leftMargin, addressLine)
+		}
+		if invoice.VATID != "" {
+			y -= 13
+			line(regular, 10, leftMargin, "USt-IdNr. "+invoice.VATID)
+		}
+		y -= 13
+	}
+	line(regular, 10, leftMargin, invoice.CustomerName)
+	y -= 13
+	line(regular, 10, leftMargin, invoice.CustomerEmail)
+
+	y -= 45
+	line(bold, 16, leftMargin, "Rechnung "+invoice.Number)
+	y -= 22
+	line(regular, 10, leftMargin, "Rechnungsdatum: "+formatDate(invoice.IssueDate))
+	y -= 13
+	line(regular, 10, leftMargin, fmt.Sprintf(
+		"Leistungszeitraum: %s - %s", formatDate(invoice.PeriodStart), formatDate(invoice.PeriodEnd),
+	))
+
+	columns := []int{leftMargin, 80, 320, 360, 430, 500}
+	y -= 35
+	for i, header := range []string{"Pos.", "Beschreibung", "Menge", "Einzelpreis", "USt.", "Gesamt"} {
+		line(bold, 10, columns[i], header)
+	}
+	for i, item := range invoice.LineItems {
+		y -= 16
+		description := []rune(item.Description)
+		if len(description) > 45 {
+			description = append(description[:44], '…')
+		}
+		line(regular, 10, columns[0], strconv.Itoa(i+1))
+		line(regular, 10, columns[1], string(description))
+		line(regular, 10, columns[2], strconv.Itoa(item.Quantity))
+		line(regular, 10, columns[3], formatAmount(item.UnitPrice, invoice.Currency))
+		line(regular, 10, columns[4], formatAmount(item.Tax, invoice.Currency))
+		line(regular, 10, columns[5], formatAmount(item.TotalPrice, invoice.Currency))
+	}
+
+	y -= 35
+	line(regular, 10, columns[3], "Nettobetrag:")
+	line(regular, 10, columns[5], formatAmount(invoice.NetAmount, invoice.Currency))
+	y -= 13
+	line(regular, 10, columns[3], "Umsatzsteuer:")
+	line(regular, 10, columns[5], formatAmount(invoice.TaxAmount, invoice.Currency))
+	y -= 15
+	line(bold, 10, columns[3], "Gesamtbetrag:")
+	line(bold, 10, columns[5], formatAmount(invoice.TotalAmount, invoice.Currency))
+
+	return texts
+}
+
+func pdfContent(texts []pdfText) string {
+	var content bytes.Buffer
+	for _, text := range texts {
+		fmt.Fprintf(&content, "BT /%s %d Tf %d %d Td (%s) Tj ET\n", text.font, text.size, text.x, text.y, pdfString(text.text))
+	}
+
+	return content.String()
+}
+
+// pdfString escapes text for a PDF literal string in WinAnsiEncoding. Runes
+// outside Latin-1 are replaced, except for the ellipsis used when truncating.
+func pdfString(text string) string {
+	var escaped bytes.Buffer
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			escaped.WriteByte('\\')
+			escaped.WriteRune(r)
+		case r == '…':
+			escaped.WriteString(`\205`)
+		case r < 0x80:
+			escaped.WriteRune(r)
+		case r < 0x100:
+			fmt.Fprintf(&escaped, "\\%03o", r)
+		default:
+			escaped.WriteByte('?')
+		}
+	}
+
+	return escaped.String()
+}
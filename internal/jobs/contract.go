@@ -0,0 +1,25 @@
+//go:generate go run go.uber.org/mock/mockgen@v0.4.0 -source=contract.go -destination=contract_mock_test.go -package=$GOPACKAGE
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"gymondo/internal/model"
+)
+
+type Store interface {
+	// EnqueueJob adds the job to the queue unless a job of the same name is
+	// already queued or running.
+	EnqueueJob(ctx context.Context, job model.Job) error
+	// ClaimJob marks the next due job of one of the given names as running
+	// and returns it. Jobs still running since before staleBefore are
+	// considered abandoned and claimed again. It returns false when no job is
+	// due.
+	ClaimJob(ctx context.Context, names []string, now time.Time, staleBefore time.Time) (model.Job, bool, error)
+	UpdateJob(ctx context.Context, job model.Job) error
+	DeleteFinishedJobs(ctx context.Context, before time.Time) error
+	// RewindJobs moves pending runs and start times of running jobs that lie
+	// after now back to now, after the clock went backwards.
+	RewindJobs(ctx context.Context, now time.Time) error
+}
@@ -0,0 +1,114 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: contract.go
+//
+// Generated by this command:
+//
+//	mockgen -source=contract.go -destination=contract_mock_test.go -package=jobs
+//
+
+// Package jobs is a generated GoMock package.
+package jobs
+
+import (
+	context "context"
+	model "gymondo/internal/model"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockStore is a mock of Store interface.
+type MockStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockStoreMockRecorder
+}
+
+// MockStoreMockRecorder is the mock recorder for MockStore.
+type MockStoreMockRecorder struct {
+	mock *MockStore
+}
+
+// NewMockStore creates a new mock instance.
+func NewMockStore(ctrl *gomock.Controller) *MockStore {
+	mock := &MockStore{ctrl: ctrl}
+	mock.recorder = &MockStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStore) EXPECT() *MockStoreMockRecorder {
+	return m.recorder
+}
+
+// ClaimJob mocks base method.
+func (m *MockStore) ClaimJob(ctx context.Context, names []string, now, staleBefore time.Time) (model.Job, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimJob", ctx, names, now, staleBefore)
+	ret0, _ := ret[0].(model.Job)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ClaimJob indicates an expected call of ClaimJob.
+func (mr *MockStoreMockRecorder) ClaimJob(ctx, names, now, staleBefore any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimJob", reflect.TypeOf((*MockStore)(nil).ClaimJob), ctx, names, now, staleBefore)
+}
+
+// DeleteFinishedJobs mocks base method.
+func (m *MockStore) DeleteFinishedJobs(ctx context.Context, before time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFinishedJobs", ctx, before)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFinishedJobs indicates an expected call of DeleteFinishedJobs.
+func (mr *MockStoreMockRecorder) DeleteFinishedJobs(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFinishedJobs", reflect.TypeOf((*MockStore)(nil).DeleteFinishedJobs), ctx, before)
+}
+
+// EnqueueJob mocks base method.
+func (m *MockStore) EnqueueJob(ctx context.Context, job model.Job) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueJob", ctx, job)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnqueueJob indicates an expected call of EnqueueJob.
+func (mr *MockStoreMockRecorder) EnqueueJob(ctx, job any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueJob", reflect.TypeOf((*MockStore)(nil).EnqueueJob), ctx, job)
+}
+
+// RewindJobs mocks base method.
+func (m *MockStore) RewindJobs(ctx context.Context, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RewindJobs", ctx, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RewindJobs indicates an expected call of RewindJobs.
+func (mr *MockStoreMockRecorder) RewindJobs(ctx, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RewindJobs", reflect.TypeOf((*MockStore)(nil).RewindJobs), ctx, now)
+}
+
+// UpdateJob mocks base method.
+func (m *MockStore) UpdateJob(ctx context.Context, job model.Job) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateJob", ctx, job)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateJob indicates an expected call of UpdateJob.
+func (mr *MockStoreMockRecorder) UpdateJob(ctx, job any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateJob", reflect.TypeOf((*MockStore)(nil).UpdateJob), ctx, job)
+}
@@ -0,0 +1,254 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gymondo/internal/clock"
+	"gymondo/internal/model"
+)
+
+const (
+	defaultWorkers      = 4
+	defaultPollInterval = time.Second
+	defaultMaxAttempts  = 5
+	defaultRetryDelay   = 30 * time.Second
+	defaultStaleAfter   = time.Hour
+	defaultRetention    = 24 * time.Hour
+	pruneInterval       = time.Hour
+)
+
+// Handler does the work of a job. Returning an error fails the run, which is
+// retried with backoff.
+type Handler func(ctx context.Context) error
+
+type registration struct {
+	schedule Schedule
+	handler  Handler
+}
+
+// Runner runs periodic jobs through the job queue. Every replica enqueues the
+// runs of the registered schedules, the queue makes sure each run is
+// executed once and never overlaps with another run of the same job.
+type Runner struct {
+	store        Store
+	clock        clock.Clock
+	jobs         map[string]registration
+	workers      int
+	pollInterval time.Duration
+	maxAttempts  int
+	retryDelay   time.Duration
+	staleAfter   time.Duration
+	retention    time.Duration
+}
+
+func NewRunner(store Store, clock clock.Clock) *Runner {
+	return &Runner{
+		store:        store,
+		clock:        clock,
+		jobs:         make(map[string]registration),
+		workers:      defaultWorkers,
+		pollInterval: defaultPollInterval,
+		maxAttempts:  defaultMaxAttempts,
+		retryDelay:   defaultRetryDelay,
+		staleAfter:   defaultStaleAfter,
+		retention:    defaultRetention,
+	}
+}
+
+// Register adds a job that runs on the given schedule. It must be called
+// before Run.
+func (r *Runner) Register(name string, schedule Schedule, handler Handler) {
+	r.jobs[name] = registration{schedule: schedule, handler: handler}
+}
+
+// Run schedules and executes jobs until ctx is canceled. It then stops taking
+// new jobs and returns once the running ones have finished.
+func (r *Runner) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		r.scheduleLoop(ctx)
+	}()
+
+	for i := 0; i < r.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.workLoop(ctx)
+		}()
+	}
+
+	wg.Wait()
+}
+
+func (r *Runner) scheduleLoop(ctx context.Context) {
+	now := r.clock.Now()
+	next := make(map[string]time.Time, len(r.jobs))
+	for name, job := range r.jobs {
+		next[name] = job.schedule.Next(now)
+	}
+	nextPrune := now
+
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	last := now
+	for {
+		now := r.clock.Now()
+		if now.Before(last) {
+			r.rewind(ctx, next, now)
+			nextPrune = now
+		}
+		last = now
+
+		r.enqueueDue(ctx, next, now)
+
+		if !now.Before(nextPrune) {
+			if err := r.store.DeleteFinishedJobs(ctx, now.Add(-r.retention)); err != nil {
+				log.Printf("Error pruning finished jobs: %v", err)
+			}
+			nextPrune = now.Add(pruneInterval)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// enqueueDue enqueues every job whose next run is due and moves its next run
+// on. Runs that were missed, for example while no replica was up, are
+// collapsed into one.
+func (r *Runner) enqueueDue(ctx context.Context, next map[string]time.Time, now time.Time) {
+	for name, runAt := range next {
+		if runAt.IsZero() || runAt.After(now) {
+			continue
+		}
+
+		job := model.Job{
+			ID:          uuid.New(),
+			Name:        name,
+			Status:      model.JobPending,
+			MaxAttempts: r.maxAttempts,
+			RunAt:       runAt,
+			CreatedAt:   now,
+		}
+		if err := r.store.EnqueueJob(ctx, job); err != nil {
+			log.Printf("Error enqueueing job %s: %v", name, err)
+			continue
+		}
+
+		next[name] = r.jobs[name].schedule.Next(now)
+	}
+}
+
+// rewind reschedules the jobs after the clock went backwards, e.g. when time
+// travel was reset. Runs scheduled for the time the clock was at before would
+// otherwise only be due once it catches up again.
+func (r *Runner) rewind(ctx context.Context, next map[string]time.Time, now time.Time) {
+	log.Printf("Clock went back to %s, rescheduling jobs", now.Format(time.RFC3339))
+
+	for name, job := range r.jobs {
+		next[name] = job.schedule.Next(now)
+	}
+	if err := r.store.RewindJobs(ctx, now); err != nil {
+		log.Printf("Error rewinding jobs: %v", err)
+	}
+}
+
+func (r *Runner) workLoop(ctx context.Context) {
+	for {
+		ran, err := r.runNext(ctx)
+		if err != nil {
+			log.Printf("Error running job: %v", err)
+		}
+		if ran {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.pollInterval):
+		}
+	}
+}
+
+// runNext claims and runs the next due job. It reports whether a job was
+// run. A running job is not canceled with ctx, so shutting down waits for it.
+func (r *Runner) runNext(ctx context.Context) (bool, error) {
+	if ctx.Err() != nil {
+		return false, nil
+	}
+
+	now := r.clock.Now()
+	job, ok, err := r.store.ClaimJob(ctx, r.names(), now, now.Add(-r.staleAfter))
+	if err != nil {
+		return false, fmt.Errorf("failed to claim job: %w", err)
+	}
+	if !ok {
+		return false, nil
+	}
+
+	runCtx := context.WithoutCancel(ctx)
+	err = r.execute(runCtx, job)
+
+	finishedAt := r.clock.Now()
+	switch {
+	case err == nil:
+		job.Status = model.JobSucceeded
+		job.FinishedAt = &finishedAt
+		job.LastError = ""
+	case job.Attempts >= job.MaxAttempts:
+		log.Printf("Job %s %s failed for the last time: %v", job.Name, job.ID, err)
+		job.Status = model.JobFailed
+		job.FinishedAt = &finishedAt
+		job.LastError = err.Error()
+	default:
+		log.Printf("Job %s %s failed, retrying: %v", job.Name, job.ID, err)
+		job.Status = model.JobPending
+		job.RunAt = finishedAt.Add(r.retryDelay << (job.Attempts - 1))
+		job.LastError = err.Error()
+	}
+
+	if err := r.store.UpdateJob(runCtx, job); err != nil {
+		return true, fmt.Errorf("failed to update job %s %s: %w", job.Name, job.ID, err)
+	}
+
+	return true, nil
+}
+
+// execute runs the job's handler, turning a panic into a failed run.
+func (r *Runner) execute(ctx context.Context, job model.Job) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("job panicked: %v", recovered)
+		}
+	}()
+
+	registered, ok := r.jobs[job.Name]
+	if !ok {
+		return fmt.Errorf("no handler registered for job %s", job.Name)
+	}
+
+	return registered.handler(ctx)
+}
+
+func (r *Runner) names() []string {
+	names := make([]string, 0, len(r.jobs))
+	for name := range r.jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
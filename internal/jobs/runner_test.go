@@ -0,0 +1,237 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gymondo/internal/clock"
+	"gymondo/internal/model"
+)
+
+func Test_Runner_EnqueueDue(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := NewMockStore(ctrl)
+	runner := NewRunner(mockStore, clock.System())
+	runner.Register("renewals", MustParseSchedule("@hourly"), func(ctx context.Context) error { return nil })
+	runner.Register("reminders", MustParseSchedule("@daily"), func(ctx context.Context) error { return nil })
+
+	now := time.Date(2024, time.January, 10, 15, 0, 3, 0, time.UTC)
+	next := map[string]time.Time{
+		"renewals":  time.Date(2024, time.January, 10, 15, 0, 0, 0, time.UTC),
+		"reminders": time.Date(2024, time.January, 11, 0, 0, 0, 0, time.UTC),
+	}
+
+	mockStore.EXPECT().EnqueueJob(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, job model.Job) error {
+			assert.Equal(t, "renewals", job.Name)
+			assert.Equal(t, model.JobPending, job.Status)
+			assert.Equal(t, next["renewals"], job.RunAt)
+			assert.Equal(t, defaultMaxAttempts, job.MaxAttempts)
+			return nil
+		},
+	)
+
+	runner.enqueueDue(context.Background(), next, now)
+	assert.Equal(t, time.Date(2024, time.January, 10, 16, 0, 0, 0, time.UTC), next["renewals"])
+	assert.Equal(t, time.Date(2024, time.January, 11, 0, 0, 0, 0, time.UTC), next["reminders"])
+}
+
+func Test_Runner_Rewind(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := NewMockStore(ctrl)
+	runner := NewRunner(mockStore, clock.System())
+	runner.Register("renewals", MustParseSchedule("@hourly"), func(ctx context.Context) error { return nil })
+
+	// the schedule was computed while the clock was 30 days ahead
+	now := time.Date(2024, time.January, 10, 15, 20, 0, 0, time.UTC)
+	next := map[string]time.Time{
+		"renewals": time.Date(2024, time.February, 9, 16, 0, 0, 0, time.UTC),
+	}
+
+	mockStore.EXPECT().RewindJobs(gomock.Any(), now).Return(nil)
+
+	runner.rewind(context.Background(), next, now)
+	assert.Equal(t, time.Date(2024, time.January, 10, 16, 0, 0, 0, time.UTC), next["renewals"])
+}
+
+func Test_Runner_RunNext(t *testing.T) {
+	t.Parallel()
+
+	newJob := func(attempts int) model.Job {
+		return model.Job{
+			ID:          uuid.New(),
+			Name:        "renewals",
+			Status:      model.JobRunning,
+			Attempts:    attempts,
+			MaxAttempts: 3,
+		}
+	}
+
+	t.Run("no job due", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockStore := NewMockStore(ctrl)
+		runner := NewRunner(mockStore, clock.System())
+		runner.Register("renewals", Every(time.Hour), func(ctx context.Context) error { return nil })
+
+		mockStore.EXPECT().ClaimJob(gomock.Any(), []string{"renewals"}, gomock.Any(), gomock.Any()).Return(model.Job{}, false, nil)
+
+		ran, err := runner.runNext(context.Background())
+		assert.NoError(t, err)
+		assert.False(t, ran)
+	})
+
+	t.Run("successful run", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockStore := NewMockStore(ctrl)
+		runner := NewRunner(mockStore, clock.System())
+
+		calls := 0
+		runner.Register("renewals", Every(time.Hour), func(ctx context.Context) error {
+			calls++
+			return nil
+		})
+
+		mockStore.EXPECT().ClaimJob(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(newJob(1), true, nil)
+		mockStore.EXPECT().UpdateJob(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, job model.Job) error {
+				assert.Equal(t, model.JobSucceeded, job.Status)
+				assert.NotNil(t, job.FinishedAt)
+				return nil
+			},
+		)
+
+		ran, err := runner.runNext(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ran)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("failed run is retried with backoff", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockStore := NewMockStore(ctrl)
+		runner := NewRunner(mockStore, clock.System())
+		runner.Register("renewals", Every(time.Hour), func(ctx context.Context) error {
+			return errors.New("database error")
+		})
+
+		mockStore.EXPECT().ClaimJob(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(newJob(2), true, nil)
+		mockStore.EXPECT().UpdateJob(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, job model.Job) error {
+				assert.Equal(t, model.JobPending, job.Status)
+				assert.Equal(t, "database error", job.LastError)
+				assert.WithinDuration(t, time.Now().Add(2*defaultRetryDelay), job.RunAt, time.Second)
+				assert.Nil(t, job.FinishedAt)
+				return nil
+			},
+		)
+
+		_, err := runner.runNext(context.Background())
+		assert.NoError(t, err)
+	})
+
+	t.Run("last failed attempt fails the job", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockStore := NewMockStore(ctrl)
+		runner := NewRunner(mockStore, clock.System())
+		runner.Register("renewals", Every(time.Hour), func(ctx context.Context) error {
+			panic("nil map")
+		})
+
+		mockStore.EXPECT().ClaimJob(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(newJob(3), true, nil)
+		mockStore.EXPECT().UpdateJob(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, job model.Job) error {
+				assert.Equal(t, model.JobFailed, job.Status)
+				assert.Equal(t, "job panicked: nil map", job.LastError)
+				assert.NotNil(t, job.FinishedAt)
+				return nil
+			},
+		)
+
+		_, err := runner.runNext(context.Background())
+		assert.NoError(t, err)
+	})
+}
+
+func Test_Runner_Run_WaitsForRunningJobs(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := NewMockStore(ctrl)
+	runner := NewRunner(mockStore, clock.System())
+	runner.workers = 1
+	runner.pollInterval = 10 * time.Millisecond
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	runner.Register("renewals", Every(time.Hour), func(ctx context.Context) error {
+		close(started)
+		<-release
+		return ctx.Err()
+	})
+
+	mockStore.EXPECT().DeleteFinishedJobs(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockStore.EXPECT().EnqueueJob(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockStore.EXPECT().ClaimJob(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(
+		model.Job{ID: uuid.New(), Name: "renewals", Attempts: 1, MaxAttempts: 1}, true, nil,
+	)
+	mockStore.EXPECT().UpdateJob(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, job model.Job) error {
+			assert.Equal(t, model.JobSucceeded, job.Status)
+			return nil
+		},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		runner.Run(ctx)
+		close(done)
+	}()
+
+	<-started
+	cancel()
+
+	select {
+	case <-done:
+		t.Fatal("Run returned while a job was still running")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after the running job finished")
+	}
+}
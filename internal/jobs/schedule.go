@@ -0,0 +1,202 @@
+package jobs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule decides when a periodic job runs next.
+type Schedule interface {
+	// Next returns the first run strictly after t.
+	Next(t time.Time) time.Time
+}
+
+// ParseSchedule parses either a standard five field cron expression
+// ("minute hour day-of-month month day-of-week"), one of the shorthands
+// @hourly, @daily, @weekly and @monthly, or "@every <duration>" for
+// intervals below a minute.
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+
+	if interval, ok := strings.CutPrefix(spec, "@every "); ok {
+		duration, err := time.ParseDuration(strings.TrimSpace(interval))
+		if err != nil || duration <= 0 {
+			return nil, fmt.Errorf("invalid interval in schedule %q", spec)
+		}
+		return Every(duration), nil
+	}
+
+	switch spec {
+	case "@hourly":
+		spec = "0 * * * *"
+	case "@daily":
+		spec = "0 0 * * *"
+	case "@weekly":
+		spec = "0 0 * * 0"
+	case "@monthly":
+		spec = "0 0 1 * *"
+	}
+
+	return parseCron(spec)
+}
+
+// MustParseSchedule is like ParseSchedule but panics on an invalid spec. It
+// is meant for schedules that are fixed in code.
+func MustParseSchedule(spec string) Schedule {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		panic(err)
+	}
+	return schedule
+}
+
+type interval time.Duration
+
+// Every runs a job at a fixed interval. Runs are aligned to multiples of the
+// interval since the Unix epoch, so every replica computes the same runs.
+func Every(d time.Duration) Schedule {
+	return interval(d)
+}
+
+func (i interval) Next(t time.Time) time.Time {
+	d := time.Duration(i)
+	return t.Truncate(d).Add(d)
+}
+
+type cron struct {
+	minute, hour, dayOfMonth, month, dayOfWeek uint64
+	// anyDayOfMonth and anyDayOfWeek are set for "*". When both day fields
+	// are restricted, a day matches if either of them does.
+	anyDayOfMonth, anyDayOfWeek bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+func parseCron(spec string) (Schedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("schedule %q must have %d fields", spec, len(cronFields))
+	}
+
+	bits := make([]uint64, len(fields))
+	for i, field := range fields {
+		parsed, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %w", spec, err)
+		}
+		bits[i] = parsed
+	}
+
+	// Sunday may be written as 0 or 7
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+
+	return &cron{
+		minute:        bits[0],
+		hour:          bits[1],
+		dayOfMonth:    bits[2],
+		month:         bits[3],
+		dayOfWeek:     bits[4],
+		anyDayOfMonth: fields[2] == "*",
+		anyDayOfWeek:  fields[4] == "*",
+	}, nil
+}
+
+// parseCronField parses a comma separated list of "*", values and ranges,
+// each optionally followed by a "/step".
+func parseCronField(field string, bounds cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		valueRange, stepValue, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			parsed, err := strconv.Atoi(stepValue)
+			if err != nil || parsed < 1 {
+				return 0, fmt.Errorf("invalid step %q in %s", stepValue, bounds.name)
+			}
+			step = parsed
+		}
+
+		low, high := bounds.min, bounds.max
+		if valueRange != "*" {
+			lowValue, highValue, isRange := strings.Cut(valueRange, "-")
+
+			var err error
+			if low, err = strconv.Atoi(lowValue); err != nil {
+				return 0, fmt.Errorf("invalid value %q in %s", lowValue, bounds.name)
+			}
+			high = low
+			if isRange {
+				if high, err = strconv.Atoi(highValue); err != nil {
+					return 0, fmt.Errorf("invalid value %q in %s", highValue, bounds.name)
+				}
+			} else if hasStep {
+				high = bounds.max
+			}
+		}
+
+		if low < bounds.min || high > bounds.max || low > high {
+			return 0, fmt.Errorf("%q is out of range for %s", part, bounds.name)
+		}
+
+		for value := low; value <= high; value += step {
+			bits |= 1 << value
+		}
+	}
+
+	return bits, nil
+}
+
+func (c *cron) Next(t time.Time) time.Time {
+	next := t.Truncate(time.Minute).Add(time.Minute)
+
+	// every combination of fields repeats within a few years, so a schedule
+	// that matches nothing, such as February 30th, gives up eventually
+	limit := next.AddDate(5, 0, 0)
+	for next.Before(limit) {
+		if c.month&(1<<uint(next.Month())) == 0 {
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, next.Location())
+			continue
+		}
+		if !c.matchesDay(next) {
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, next.Location())
+			continue
+		}
+		if c.hour&(1<<uint(next.Hour())) == 0 {
+			next = time.Date(next.Year(), next.Month(), next.Day(), next.Hour()+1, 0, 0, 0, next.Location())
+			continue
+		}
+		if c.minute&(1<<uint(next.Minute())) == 0 {
+			next = next.Add(time.Minute)
+			continue
+		}
+		return next
+	}
+
+	return time.Time{}
+}
+
+func (c *cron) matchesDay(t time.Time) bool {
+	dayOfMonth := c.dayOfMonth&(1<<uint(t.Day())) != 0
+	dayOfWeek := c.dayOfWeek&(1<<uint(t.Weekday())) != 0
+
+	if c.anyDayOfMonth || c.anyDayOfWeek {
+		return dayOfMonth && dayOfWeek
+	}
+	return dayOfMonth || dayOfWeek
+}
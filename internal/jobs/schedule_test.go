@@ -0,0 +1,65 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseSchedule(t *testing.T) {
+	t.Parallel()
+
+	// a Wednesday
+	now := time.Date(2024, time.January, 10, 14, 37, 12, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		next time.Time
+	}{
+		{"@every 5s", time.Date(2024, time.January, 10, 14, 37, 15, 0, time.UTC)},
+		{"@hourly", time.Date(2024, time.January, 10, 15, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, time.January, 11, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2024, time.January, 14, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, time.January, 10, 14, 45, 0, 0, time.UTC)},
+		{"30 2 * * *", time.Date(2024, time.January, 11, 2, 30, 0, 0, time.UTC)},
+		{"0 9-17/4 * * 1-5", time.Date(2024, time.January, 10, 17, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, time.January, 14, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		// with both day fields restricted either of them matches
+		{"0 0 1 * 5", time.Date(2024, time.January, 12, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		schedule, err := ParseSchedule(test.spec)
+		assert.NoError(t, err, test.spec)
+		assert.Equal(t, test.next, schedule.Next(now), test.spec)
+	}
+}
+
+func Test_ParseSchedule_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"@every soon",
+		"@every -1s",
+	} {
+		_, err := ParseSchedule(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func Test_Cron_NeverMatching(t *testing.T) {
+	t.Parallel()
+
+	schedule := MustParseSchedule("0 0 30 2 *")
+	assert.True(t, schedule.Next(time.Now()).IsZero())
+}
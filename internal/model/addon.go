@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AddOnStatus string
+
+const (
+	AddOnActive   AddOnStatus = "active"
+	AddOnPaused   AddOnStatus = "paused"
+	AddOnCanceled AddOnStatus = "canceled"
+)
+
+// AddOnStatusOf returns the status the add-ons of a subscription with the
+// given status have. Add-ons are paused and canceled together with their
+// subscription.
+func AddOnStatusOf(status SubscriptionStatus) AddOnStatus {
+	switch status {
+	case Paused:
+		return AddOnPaused
+	case Canceled:
+		return AddOnCanceled
+	}
+	return AddOnActive
+}
+
+// SubscriptionAddOn is an add-on product attached to a subscription. Its
+// price is per period of the subscription and billed together with it.
+type SubscriptionAddOn struct {
+	ID             uuid.UUID   `json:"id"`
+	SubscriptionID uuid.UUID   `json:"subscription_id"`
+	ProductID      uuid.UUID   `json:"product_id"`
+	Name           string      `json:"name"`
+	Price          float64     `json:"price"`
+	Tax            float64     `json:"tax"`
+	TotalPrice     float64     `json:"total_price"`
+	Status         AddOnStatus `json:"status"`
+	StartDate      time.Time   `json:"start_date"`
+	CanceledDate   *time.Time  `json:"canceled_date,omitempty"`
+	// BilledUntil is the day up to which the add-on was charged, nil until
+	// it is charged for the first time.
+	BilledUntil *time.Time `json:"billed_until,omitempty"`
+}
@@ -0,0 +1,77 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CancellationReason string
+
+const (
+	TooExpensive     CancellationReason = "too_expensive"
+	NotUsing         CancellationReason = "not_using"
+	TemporaryBreak   CancellationReason = "temporary_break"
+	FoundAlternative CancellationReason = "found_alternative"
+	TechnicalIssues  CancellationReason = "technical_issues"
+	OtherReason      CancellationReason = "other"
+)
+
+// Valid reports whether the reason is one of the known reason codes.
+func (r CancellationReason) Valid() bool {
+	switch r {
+	case TooExpensive, NotUsing, TemporaryBreak, FoundAlternative, TechnicalIssues, OtherReason:
+		return true
+	}
+	return false
+}
+
+// CancellationSurvey is what a subscriber tells about why they cancel. Both
+// fields are optional.
+type CancellationSurvey struct {
+	Reason CancellationReason
+	Text   string
+}
+
+// Cancellation records a cancellation together with its survey answers.
+type Cancellation struct {
+	ID             uuid.UUID          `json:"id"`
+	SubscriptionID uuid.UUID          `json:"subscription_id"`
+	Reason         CancellationReason `json:"reason,omitempty"`
+	ReasonText     string             `json:"reason_text,omitempty"`
+	CanceledAt     time.Time          `json:"canceled_at"`
+}
+
+type RetentionOfferKind string
+
+const (
+	// PauseOffer pauses the subscription for PauseDays instead.
+	PauseOffer RetentionOfferKind = "pause"
+	// DowngradeOffer switches the subscription to the cheaper ProductID.
+	DowngradeOffer RetentionOfferKind = "downgrade"
+	// VoucherOffer discounts the subscription with VoucherCode.
+	VoucherOffer RetentionOfferKind = "voucher"
+)
+
+// RetentionOffer is an alternative to canceling that is proposed to
+// subscribers who cancel for Reason.
+type RetentionOffer struct {
+	ID          uuid.UUID          `json:"id"`
+	Reason      CancellationReason `json:"reason"`
+	Kind        RetentionOfferKind `json:"kind"`
+	Description string             `json:"description"`
+	PauseDays   int                `json:"pause_days,omitempty"`
+	ProductID   *uuid.UUID         `json:"product_id,omitempty"`
+	VoucherCode string             `json:"voucher_code,omitempty"`
+}
+
+// RetentionOfferAcceptance records that a subscriber took a retention offer
+// instead of canceling.
+type RetentionOfferAcceptance struct {
+	ID             uuid.UUID          `json:"id"`
+	SubscriptionID uuid.UUID          `json:"subscription_id"`
+	OfferID        uuid.UUID          `json:"offer_id"`
+	Reason         CancellationReason `json:"reason"`
+	Kind           RetentionOfferKind `json:"kind"`
+	AcceptedAt     time.Time          `json:"accepted_at"`
+}
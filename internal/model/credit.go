@@ -0,0 +1,67 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInsufficientCredit = errors.New("insufficient credit balance")
+
+type CreditTransactionKind string
+
+const (
+	CreditGrant       CreditTransactionKind = "grant"
+	CreditRefund      CreditTransactionKind = "refund"
+	CreditProration   CreditTransactionKind = "proration"
+	CreditConsumption CreditTransactionKind = "consumption"
+	CreditReferral    CreditTransactionKind = "referral"
+)
+
+// CreditAccount names a ledger account. Every credit transaction moves money
+// between the customer's credit account and one of the other accounts, so
+// the entries of a transaction always sum up to zero.
+type CreditAccount string
+
+const (
+	CustomerCreditAccount CreditAccount = "customer_credit"
+	GoodwillAccount       CreditAccount = "goodwill"
+	RefundsAccount        CreditAccount = "refunds"
+	ProrationsAccount     CreditAccount = "prorations"
+	RevenueAccount        CreditAccount = "revenue"
+	ReferralsAccount      CreditAccount = "referrals"
+)
+
+type CreditEntry struct {
+	Account CreditAccount `json:"account"`
+	Amount  float64       `json:"amount"`
+}
+
+type CreditTransaction struct {
+	ID             uuid.UUID             `json:"id"`
+	UserID         uuid.UUID             `json:"user_id"`
+	Kind           CreditTransactionKind `json:"kind"`
+	Reason         string                `json:"reason"`
+	SubscriptionID *uuid.UUID            `json:"subscription_id,omitempty"`
+	CreatedAt      time.Time             `json:"created_at"`
+	Entries        []CreditEntry         `json:"entries"`
+}
+
+// CustomerAmount returns how much the transaction changed the customer's
+// credit balance.
+func (t CreditTransaction) CustomerAmount() float64 {
+	var amount float64
+	for _, entry := range t.Entries {
+		if entry.Account == CustomerCreditAccount {
+			amount += entry.Amount
+		}
+	}
+	return amount
+}
+
+type CreditBalance struct {
+	UserID       uuid.UUID           `json:"user_id"`
+	Balance      float64             `json:"balance"`
+	Transactions []CreditTransaction `json:"transactions"`
+}
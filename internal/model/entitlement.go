@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EntitlementStatus string
+
+const (
+	// EntitledActive is a paid subscription in its current period.
+	EntitledActive EntitlementStatus = "active"
+	// EntitledTrialing is a subscription in its trial.
+	EntitledTrialing EntitlementStatus = "trialing"
+	// EntitledGracePeriod is a past due subscription whose payment is still
+	// being retried.
+	EntitledGracePeriod EntitlementStatus = "grace_period"
+)
+
+// Entitlements is the set of feature keys a user may use right now, resolved
+// from all their subscriptions that grant access.
+type Entitlements struct {
+	UserID       uuid.UUID          `json:"user_id"`
+	Entitlements []string           `json:"entitlements"`
+	Grants       []EntitlementGrant `json:"grants"`
+	ResolvedAt   time.Time          `json:"resolved_at"`
+}
+
+// EntitlementGrant is a subscription that grants entitlements, either owned
+// by the user or shared with them, and until when it does unless it is
+// renewed or paid.
+type EntitlementGrant struct {
+	SubscriptionID uuid.UUID         `json:"subscription_id"`
+	ProductID      uuid.UUID         `json:"product_id"`
+	Status         EntitlementStatus `json:"status"`
+	Entitlements   []string          `json:"entitlements"`
+	Until          time.Time         `json:"until"`
+	// OwnerID is set when the user is granted the entitlements as a member
+	// of a subscription owned by another user.
+	OwnerID *uuid.UUID `json:"owner_id,omitempty"`
+}
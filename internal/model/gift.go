@@ -0,0 +1,41 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrGiftNotRedeemable is returned when a gift code is redeemed that was
+// already redeemed or has expired.
+var ErrGiftNotRedeemable = errors.New("gift can't be redeemed")
+
+type GiftStatus string
+
+const (
+	GiftPurchased GiftStatus = "purchased"
+	GiftRedeemed  GiftStatus = "redeemed"
+	GiftExpired   GiftStatus = "expired"
+)
+
+// Gift is a prepaid period of a product bought for someone else. Whoever
+// redeems its code gets the period without being charged, either as a new
+// subscription or as an extension of their subscription to the product.
+type Gift struct {
+	ID             uuid.UUID  `json:"id"`
+	Code           string     `json:"code"`
+	BuyerID        uuid.UUID  `json:"buyer_id"`
+	ProductID      uuid.UUID  `json:"product_id"`
+	DurationDays   int        `json:"duration_days"`
+	Price          float64    `json:"price"`
+	Tax            float64    `json:"tax"`
+	TotalPrice     float64    `json:"total_price"`
+	TransactionID  string     `json:"transaction_id"`
+	Status         GiftStatus `json:"status"`
+	PurchasedAt    time.Time  `json:"purchased_at"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	RedeemedBy     *uuid.UUID `json:"redeemed_by,omitempty"`
+	RedeemedAt     *time.Time `json:"redeemed_at,omitempty"`
+	SubscriptionID *uuid.UUID `json:"subscription_id,omitempty"`
+}
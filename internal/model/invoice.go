@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Invoice struct {
+	ID             uuid.UUID `json:"id"`
+	Number         string    `json:"number"`
+	SubscriptionID uuid.UUID `json:"subscription_id"`
+	// GiftID is set instead of SubscriptionID on invoices of gift purchases.
+	GiftID        *uuid.UUID `json:"gift_id,omitempty"`
+	UserID        uuid.UUID  `json:"user_id"`
+	CustomerName  string     `json:"customer_name"`
+	CustomerEmail string     `json:"customer_email"`
+	// CompanyName, VATID and BillingAddress are set on invoices of
+	// organizations.
+	CompanyName    string            `json:"company_name,omitempty"`
+	VATID          string            `json:"vat_id,omitempty"`
+	BillingAddress string            `json:"billing_address,omitempty"`
+	IssueDate      time.Time         `json:"issue_date"`
+	PeriodStart    time.Time         `json:"period_start"`
+	PeriodEnd      time.Time         `json:"period_end"`
+	NetAmount      float64           `json:"net_amount"`
+	TaxAmount      float64           `json:"tax_amount"`
+	TotalAmount    float64           `json:"total_amount"`
+	Currency       string            `json:"currency"`
+	LineItems      []InvoiceLineItem `json:"line_items"`
+}
+
+type InvoiceLineItem struct {
+	Description string  `json:"description"`
+	Quantity    int     `json:"quantity"`
+	UnitPrice   float64 `json:"unit_price"`
+	Tax         float64 `json:"tax"`
+	TotalPrice  float64 `json:"total_price"`
+}
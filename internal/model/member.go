@@ -0,0 +1,33 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrNoSeatsLeft is returned when a member is invited to a subscription
+// whose seats are all taken.
+var ErrNoSeatsLeft = errors.New("all seats of the subscription are taken")
+
+type MemberStatus string
+
+const (
+	MemberInvited MemberStatus = "invited"
+	MemberActive  MemberStatus = "active"
+	MemberRemoved MemberStatus = "removed"
+)
+
+// SubscriptionMember is a user sharing the subscription of its owner. A
+// member takes one of the seats of the product once invited, and is granted
+// the product's entitlements while the owner's subscription grants them.
+type SubscriptionMember struct {
+	ID             uuid.UUID    `json:"id"`
+	SubscriptionID uuid.UUID    `json:"subscription_id"`
+	UserID         uuid.UUID    `json:"user_id"`
+	Status         MemberStatus `json:"status"`
+	InvitedAt      time.Time    `json:"invited_at"`
+	AcceptedAt     *time.Time   `json:"accepted_at,omitempty"`
+	RemovedAt      *time.Time   `json:"removed_at,omitempty"`
+}
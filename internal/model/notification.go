@@ -0,0 +1,28 @@
+package model
+
+type NotificationKind string
+
+const (
+	NotificationSubscriptionConfirmation NotificationKind = "subscription_confirmation"
+	NotificationTrialEnding              NotificationKind = "trial_ending"
+	NotificationRenewalUpcoming          NotificationKind = "renewal_upcoming"
+	NotificationPaymentFailed            NotificationKind = "payment_failed"
+	NotificationCancellationConfirmation NotificationKind = "cancellation_confirmation"
+	NotificationMemberInvitation         NotificationKind = "member_invitation"
+	NotificationGiftPurchase             NotificationKind = "gift_purchase"
+	NotificationGiftExpired              NotificationKind = "gift_expired"
+	NotificationPriceChange              NotificationKind = "price_change"
+)
+
+// Notification is a message about a subscription or a gift sent to a user.
+// It is rendered in the user's locale.
+type Notification struct {
+	Kind         NotificationKind
+	User         User
+	ProductName  string
+	Subscription Subscription
+	// Gift is set on notifications about gifts.
+	Gift Gift
+	// PriceChange is set on notifications about a new price.
+	PriceChange PriceChange
+}
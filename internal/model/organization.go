@@ -0,0 +1,55 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Organization is a company subscribing to seat based products for its
+// employees. Its billing details are printed on the invoices of its
+// subscriptions.
+type Organization struct {
+	ID      uuid.UUID `json:"id"`
+	Name    string    `json:"name"`
+	VATID   string    `json:"vat_id,omitempty"`
+	Address string    `json:"address"`
+	// Domain is the email domain of the employees, who can join the
+	// organization's subscription without an invitation.
+	Domain    string      `json:"domain,omitempty"`
+	AdminIDs  []uuid.UUID `json:"admin_ids"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// HasAdmin reports whether the user administrates the organization.
+func (o Organization) HasAdmin(userID uuid.UUID) bool {
+	for _, adminID := range o.AdminIDs {
+		if adminID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// EmployeeEmail reports whether the email address is in the organization's
+// domain.
+func (o Organization) EmployeeEmail(email string) bool {
+	at := strings.LastIndex(email, "@")
+	return o.Domain != "" && at >= 0 && strings.EqualFold(email[at+1:], o.Domain)
+}
+
+// SeatChange is a change of the seats of a subscription during its period.
+// Its price is prorated to the rest of the period. Added seats are billed
+// with the next renewal; removed seats have a negative price, which is
+// credited to the owner's credit balance when they are removed.
+type SeatChange struct {
+	ID             uuid.UUID `json:"id"`
+	SubscriptionID uuid.UUID `json:"subscription_id"`
+	SeatsBefore    int       `json:"seats_before"`
+	SeatsAfter     int       `json:"seats_after"`
+	ChangeDate     time.Time `json:"change_date"`
+	Price          float64   `json:"price"`
+	Tax            float64   `json:"tax"`
+	TotalPrice     float64   `json:"total_price"`
+}
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxMessage is an event written in the same transaction as the
+// subscription change it describes. Messages are published in the order of
+// their ID.
+type OutboxMessage struct {
+	ID             int64            `json:"id"`
+	EventID        uuid.UUID        `json:"event_id"`
+	SubscriptionID uuid.UUID        `json:"subscription_id"`
+	Type           WebhookEventType `json:"type"`
+	Payload        json.RawMessage  `json:"payload" swaggertype:"object"`
+	CreatedAt      time.Time        `json:"created_at"`
+	PublishedAt    *time.Time       `json:"published_at,omitempty"`
+}
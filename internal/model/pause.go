@@ -0,0 +1,72 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PausePolicy limits how a product's subscriptions may pause. A limit of 0
+// doesn't restrict anything.
+type PausePolicy struct {
+	// MaxPauseDays is the longest a single pause may last. Pauses without a
+	// resume date end after it.
+	MaxPauseDays int `json:"max_pause_days"`
+	// MaxPausesPerPeriod is how often a subscription may pause within one
+	// billing period.
+	MaxPausesPerPeriod int `json:"max_pauses_per_period"`
+	// MaxPausedDaysPerYear caps the paused days of a subscription within a
+	// calendar year.
+	MaxPausedDaysPerYear int `json:"max_paused_days_per_year"`
+	// MinActiveDaysBetweenPauses is how long a subscription has to be active
+	// after a pause before it may pause again.
+	MinActiveDaysBetweenPauses int `json:"min_active_days_between_pauses"`
+}
+
+// Limited reports whether the policy restricts pausing at all.
+func (p PausePolicy) Limited() bool {
+	return p != PausePolicy{}
+}
+
+// Pause is a period a subscription was paused for. EndDate is nil while the
+// subscription is still paused.
+type Pause struct {
+	ID             uuid.UUID  `json:"id"`
+	SubscriptionID uuid.UUID  `json:"subscription_id"`
+	StartDate      time.Time  `json:"start_date"`
+	EndDate        *time.Time `json:"end_date,omitempty"`
+}
+
+type PauseLimit string
+
+const (
+	MaxPauseDaysLimit               PauseLimit = "max_pause_days"
+	MaxPausesPerPeriodLimit         PauseLimit = "max_pauses_per_period"
+	MaxPausedDaysPerYearLimit       PauseLimit = "max_paused_days_per_year"
+	MinActiveDaysBetweenPausesLimit PauseLimit = "min_active_days_between_pauses"
+)
+
+// PauseLimitError is returned when a pause would break the pause policy of
+// the product. Allowed is the value of the limit that was hit and Actual the
+// value the pause would have led to.
+type PauseLimitError struct {
+	Limit   PauseLimit
+	Allowed int
+	Actual  int
+}
+
+func (e *PauseLimitError) Error() string {
+	switch e.Limit {
+	case MaxPauseDaysLimit:
+		return fmt.Sprintf("pause of %d days is longer than the allowed %d days", e.Actual, e.Allowed)
+	case MaxPausesPerPeriodLimit:
+		return fmt.Sprintf("subscription already paused %d times this period, %d pauses are allowed", e.Actual, e.Allowed)
+	case MaxPausedDaysPerYearLimit:
+		return fmt.Sprintf("pause would make %d paused days this year, %d are allowed", e.Actual, e.Allowed)
+	case MinActiveDaysBetweenPausesLimit:
+		return fmt.Sprintf("subscription was active for %d days since the last pause, %d are required", e.Actual, e.Allowed)
+	default:
+		return fmt.Sprintf("pause limit %s exceeded", e.Limit)
+	}
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PaymentAttemptStatus string
+
+const (
+	PaymentSucceeded PaymentAttemptStatus = "succeeded"
+	PaymentFailed    PaymentAttemptStatus = "failed"
+)
+
+type PaymentAttempt struct {
+	ID             uuid.UUID            `json:"id"`
+	SubscriptionID uuid.UUID            `json:"subscription_id"`
+	AttemptNumber  int                  `json:"attempt_number"`
+	Amount         float64              `json:"amount"`
+	CreditApplied  float64              `json:"credit_applied"`
+	Status         PaymentAttemptStatus `json:"status"`
+	TransactionID  string               `json:"transaction_id,omitempty"`
+	FailureReason  string               `json:"failure_reason,omitempty"`
+	AttemptedAt    time.Time            `json:"attempted_at"`
+	NextRetryDate  *time.Time           `json:"next_retry_date,omitempty"`
+}
@@ -0,0 +1,77 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrPriceMigrationInProgress is returned when the price of a product is
+// migrated while price changes of an earlier migration are still pending.
+var ErrPriceMigrationInProgress = errors.New("price migration of the product is still in progress")
+
+type PriceChangeStatus string
+
+const (
+	PriceChangeScheduled PriceChangeStatus = "scheduled"
+	PriceChangeApplied   PriceChangeStatus = "applied"
+	// PriceChangeOptedOut is a price change the subscriber declined. Their
+	// subscription ends instead of renewing at the new price.
+	PriceChangeOptedOut PriceChangeStatus = "opted_out"
+)
+
+// PriceMigration moves the existing subscriptions of a product to a new
+// price. Every subscription gets the new price from its first renewal after
+// the notice period, unless its subscriber opts out. The product itself moves
+// to the new price once the notice period has passed.
+type PriceMigration struct {
+	ID         uuid.UUID `json:"id"`
+	ProductID  uuid.UUID `json:"product_id"`
+	Price      float64   `json:"price"`
+	Tax        float64   `json:"tax"`
+	TotalPrice float64   `json:"total_price"`
+	NoticeDays int       `json:"notice_days"`
+	CreatedAt  time.Time `json:"created_at"`
+	// ProductPriceAppliedAt is when the product moved to the new price.
+	ProductPriceAppliedAt *time.Time `json:"product_price_applied_at,omitempty"`
+	// DryRun migrations only report what a migration would change, they
+	// are not stored.
+	DryRun bool `json:"dry_run"`
+	PriceMigrationReport
+}
+
+// PriceMigrationReport counts the subscriptions of a migration by the status
+// of their price change and sums up their net revenue per year before and
+// after the migration. Opted out subscriptions don't bring any revenue after
+// it.
+type PriceMigrationReport struct {
+	Subscriptions        int     `json:"subscriptions"`
+	Scheduled            int     `json:"scheduled"`
+	Applied              int     `json:"applied"`
+	OptedOut             int     `json:"opted_out"`
+	CurrentAnnualRevenue float64 `json:"current_annual_revenue"`
+	NewAnnualRevenue     float64 `json:"new_annual_revenue"`
+	AnnualRevenueImpact  float64 `json:"annual_revenue_impact"`
+}
+
+// PriceChange is the new price of one subscription of a price migration. It
+// takes effect with the renewal on EffectiveDate, or the first one after it
+// when the subscription was paused in between.
+type PriceChange struct {
+	ID             uuid.UUID         `json:"id"`
+	MigrationID    uuid.UUID         `json:"migration_id"`
+	SubscriptionID uuid.UUID         `json:"subscription_id"`
+	UserID         uuid.UUID         `json:"user_id"`
+	DurationDays   int               `json:"duration_days"`
+	OldPrice       float64           `json:"old_price"`
+	OldTax         float64           `json:"old_tax"`
+	OldTotalPrice  float64           `json:"old_total_price"`
+	Price          float64           `json:"price"`
+	Tax            float64           `json:"tax"`
+	TotalPrice     float64           `json:"total_price"`
+	EffectiveDate  time.Time         `json:"effective_date"`
+	Status         PriceChangeStatus `json:"status"`
+	AppliedAt      *time.Time        `json:"applied_at,omitempty"`
+	OptedOutAt     *time.Time        `json:"opted_out_at,omitempty"`
+}
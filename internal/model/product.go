@@ -2,11 +2,53 @@ package model
 
 import "github.com/google/uuid"
 
+type ProductKind string
+
+const (
+	// BaseProduct is subscribed to on its own.
+	BaseProduct ProductKind = "base"
+	// AddOnProduct can only be attached to a subscription to one of its
+	// BaseProductIDs.
+	AddOnProduct ProductKind = "add_on"
+)
+
 type Product struct {
-	ID           uuid.UUID `json:"id"`
-	Name         string    `json:"name"`
-	DurationDays int       `json:"duration_days"`
-	Price        float64   `json:"price"`
-	Tax          float64   `json:"tax"`
-	TotalPrice   float64   `json:"total_price"`
+	ID                   uuid.UUID           `json:"id"`
+	Name                 string              `json:"name"`
+	DurationDays         int                 `json:"duration_days"`
+	Price                float64             `json:"price"`
+	Tax                  float64             `json:"tax"`
+	TotalPrice           float64             `json:"total_price"`
+	RefundPolicy         RefundPolicy        `json:"refund_policy"`
+	WithdrawalPeriodDays int                 `json:"withdrawal_period_days"`
+	PausePolicy          PausePolicy         `json:"pause_policy"`
+	ReactivationPricing  ReactivationPricing `json:"reactivation_pricing"`
+	// Entitlements are the feature keys a subscription to the product grants,
+	// such as premium_classes.
+	Entitlements []string    `json:"entitlements"`
+	Kind         ProductKind `json:"kind"`
+	// BaseProductIDs are the products an add-on can be attached to.
+	BaseProductIDs []uuid.UUID `json:"base_product_ids,omitempty"`
+	// MaxMembers is the number of users the owner of a subscription to the
+	// product can share it with.
+	MaxMembers int `json:"max_members"`
+	// SeatBased products are subscribed to by organizations and priced per
+	// seat, every seat is one user.
+	SeatBased bool `json:"seat_based"`
+}
+
+// Shareable reports whether a subscription to the product can have members.
+func (p Product) Shareable() bool {
+	return p.MaxMembers > 0 || p.SeatBased
+}
+
+// CompatibleWith reports whether the add-on can be attached to a
+// subscription to the base product.
+func (p Product) CompatibleWith(baseProductID uuid.UUID) bool {
+	for _, id := range p.BaseProductIDs {
+		if id == baseProductID {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReactivationPricing string
+
+const (
+	// CurrentPriceReactivation restores a subscription at the current price
+	// of its product.
+	CurrentPriceReactivation ReactivationPricing = "current"
+	// LockedPriceReactivation restores a subscription at the price it had
+	// when it was canceled.
+	LockedPriceReactivation ReactivationPricing = "locked"
+)
+
+// Reactivation records that a canceled subscription was restored and at
+// which price.
+type Reactivation struct {
+	ID             uuid.UUID           `json:"id"`
+	SubscriptionID uuid.UUID           `json:"subscription_id"`
+	CanceledDate   time.Time           `json:"canceled_date"`
+	ReactivatedAt  time.Time           `json:"reactivated_at"`
+	Pricing        ReactivationPricing `json:"pricing"`
+	Price          float64             `json:"price"`
+	Tax            float64             `json:"tax"`
+	TotalPrice     float64             `json:"total_price"`
+}
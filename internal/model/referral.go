@@ -0,0 +1,82 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrReferralRejected is returned when a referral code is used by someone
+// who can't be referred, like the referrer themselves or an existing
+// customer.
+var ErrReferralRejected = errors.New("referral was rejected")
+
+type ReferralStatus string
+
+const (
+	ReferralPending  ReferralStatus = "pending"
+	ReferralRewarded ReferralStatus = "rewarded"
+	// ReferralVoid is a referral whose subscription was canceled before its
+	// first paid period was over.
+	ReferralVoid ReferralStatus = "void"
+)
+
+type ReferralRewardKind string
+
+const (
+	ReferralRewardCredit ReferralRewardKind = "credit"
+	ReferralRewardDays   ReferralRewardKind = "days"
+)
+
+// Valid reports whether k is one of the known reward kinds.
+func (k ReferralRewardKind) Valid() bool {
+	return k == ReferralRewardCredit || k == ReferralRewardDays
+}
+
+// ReferralCode is the code a user shares to refer friends.
+type ReferralCode struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Code      string    `json:"code"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Referral attributes the subscription of a new customer, the referee, to
+// the user whose code they subscribed with, the referrer. Both are rewarded
+// once the first paid period of the subscription is over.
+type Referral struct {
+	ID             uuid.UUID      `json:"id"`
+	Code           string         `json:"code"`
+	ReferrerID     uuid.UUID      `json:"referrer_id"`
+	RefereeID      uuid.UUID      `json:"referee_id"`
+	SubscriptionID uuid.UUID      `json:"subscription_id"`
+	Status         ReferralStatus `json:"status"`
+	CreatedAt      time.Time      `json:"created_at"`
+	SettledAt      *time.Time     `json:"settled_at,omitempty"`
+}
+
+// ReferralReward is what one side of a referral got: credit, or extra days
+// on the given subscription.
+type ReferralReward struct {
+	ID             uuid.UUID          `json:"id"`
+	ReferralID     uuid.UUID          `json:"referral_id"`
+	UserID         uuid.UUID          `json:"user_id"`
+	Kind           ReferralRewardKind `json:"kind"`
+	Credit         float64            `json:"credit,omitempty"`
+	Days           int                `json:"days,omitempty"`
+	SubscriptionID *uuid.UUID         `json:"subscription_id,omitempty"`
+	CreatedAt      time.Time          `json:"created_at"`
+}
+
+// ReferralStats sums up the referrals of a user and the rewards they got
+// for referring and for being referred.
+type ReferralStats struct {
+	UserID       uuid.UUID `json:"user_id"`
+	Code         string    `json:"code,omitempty"`
+	Referrals    int       `json:"referrals"`
+	Pending      int       `json:"pending"`
+	Rewarded     int       `json:"rewarded"`
+	Void         int       `json:"void"`
+	CreditEarned float64   `json:"credit_earned"`
+	DaysEarned   int       `json:"days_earned"`
+}
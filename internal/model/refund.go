@@ -0,0 +1,56 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RefundPolicy string
+
+const (
+	// NoRefund never returns money on cancellation.
+	NoRefund RefundPolicy = "none"
+	// WithdrawalPeriodRefund refunds the full first period when the
+	// subscription is canceled within the withdrawal period.
+	WithdrawalPeriodRefund RefundPolicy = "withdrawal_period"
+	// ProratedRefund refunds the full first period within the withdrawal
+	// period and the unused days of the current period afterwards.
+	ProratedRefund RefundPolicy = "prorated"
+)
+
+type CreditNote struct {
+	ID             uuid.UUID `json:"id"`
+	Number         string    `json:"number"`
+	InvoiceID      uuid.UUID `json:"invoice_id"`
+	SubscriptionID uuid.UUID `json:"subscription_id"`
+	UserID         uuid.UUID `json:"user_id"`
+	IssueDate      time.Time `json:"issue_date"`
+	Reason         string    `json:"reason"`
+	NetAmount      float64   `json:"net_amount"`
+	TaxAmount      float64   `json:"tax_amount"`
+	TotalAmount    float64   `json:"total_amount"`
+	Currency       string    `json:"currency"`
+}
+
+type RefundStatus string
+
+const (
+	// RefundPending is recorded with the cancellation until the payment
+	// gateway has paid the refund back.
+	RefundPending   RefundStatus = "pending"
+	RefundSucceeded RefundStatus = "succeeded"
+	RefundFailed    RefundStatus = "failed"
+)
+
+type Refund struct {
+	ID             uuid.UUID    `json:"id"`
+	SubscriptionID uuid.UUID    `json:"subscription_id"`
+	CreditNoteID   uuid.UUID    `json:"credit_note_id"`
+	Amount         float64      `json:"amount"`
+	CreditAmount   float64      `json:"credit_amount"`
+	Status         RefundStatus `json:"status"`
+	TransactionID  string       `json:"transaction_id,omitempty"`
+	FailureReason  string       `json:"failure_reason,omitempty"`
+	CreatedAt      time.Time    `json:"created_at"`
+}
@@ -0,0 +1,17 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reminder records that the user was reminded of an upcoming event of their
+// subscription, such as the end of the trial, so they are reminded only once.
+type Reminder struct {
+	ID             uuid.UUID        `json:"id"`
+	SubscriptionID uuid.UUID        `json:"subscription_id"`
+	Kind           NotificationKind `json:"kind"`
+	EventDate      time.Time        `json:"event_date"`
+	SentAt         time.Time        `json:"sent_at"`
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,22 +13,156 @@ const (
 	Active   SubscriptionStatus = "active"
 	Paused   SubscriptionStatus = "paused"
 	Canceled SubscriptionStatus = "canceled"
+	PastDue  SubscriptionStatus = "past_due"
+	// Scheduled subscriptions start on a later day. They grant nothing and
+	// are not charged until then.
+	Scheduled SubscriptionStatus = "scheduled"
 )
 
+// ErrSubscriptionConflict is returned when a subscription was changed since
+// it was read, so the update based on it was not applied.
+var ErrSubscriptionConflict = errors.New("subscription was modified concurrently")
+
 type Subscription struct {
-	ID             uuid.UUID          `json:"id"`
-	UserID         uuid.UUID          `json:"user_id"`
-	ProductID      uuid.UUID          `json:"product_id"`
-	StartDate      time.Time          `json:"start_date"`
-	EndDate        time.Time          `json:"end_date"`
-	DurationDays   int                `json:"duration_days"`
-	Price          float64            `json:"price"`
-	Tax            float64            `json:"tax"`
-	TotalPrice     float64            `json:"total_price"`
-	Status         SubscriptionStatus `json:"status"`
-	TrialStartDate *time.Time         `json:"trial_start_date,omitempty"`
-	TrialEndDate   *time.Time         `json:"trial_end_date,omitempty"`
-	CanceledDate   *time.Time         `json:"canceled_date,omitempty"`
-	PausedDate     *time.Time         `json:"paused_date,omitempty"`
-	UnpausedDate   *time.Time         `json:"unpaused_date,omitempty"`
+	ID                   uuid.UUID          `json:"id"`
+	UserID               uuid.UUID          `json:"user_id"`
+	ProductID            uuid.UUID          `json:"product_id"`
+	StartDate            time.Time          `json:"start_date"`
+	EndDate              time.Time          `json:"end_date"`
+	DurationDays         int                `json:"duration_days"`
+	Price                float64            `json:"price"`
+	Tax                  float64            `json:"tax"`
+	TotalPrice           float64            `json:"total_price"`
+	Status               SubscriptionStatus `json:"status"`
+	TrialStartDate       *time.Time         `json:"trial_start_date,omitempty"`
+	TrialEndDate         *time.Time         `json:"trial_end_date,omitempty"`
+	CanceledDate         *time.Time         `json:"canceled_date,omitempty"`
+	PausedDate           *time.Time         `json:"paused_date,omitempty"`
+	UnpausedDate         *time.Time         `json:"unpaused_date,omitempty"`
+	PastDueDate          *time.Time         `json:"past_due_date,omitempty"`
+	GraceEndDate         *time.Time         `json:"grace_end_date,omitempty"`
+	NextPaymentRetryDate *time.Time         `json:"next_payment_retry_date,omitempty"`
+	PauseFrom            *time.Time         `json:"pause_from,omitempty"`
+	ResumeOn             *time.Time         `json:"resume_on,omitempty"`
+	Version              int                `json:"version"`
+	TimeZone             string             `json:"time_zone"`
+	// AddOns are the add-ons attached to the subscription, including
+	// canceled ones.
+	AddOns []SubscriptionAddOn `json:"add_ons,omitempty"`
+	// Members are the invited and active members sharing the subscription.
+	Members []SubscriptionMember `json:"members,omitempty"`
+	// OrganizationID is set for subscriptions of an organization, which
+	// are priced per seat.
+	OrganizationID *uuid.UUID   `json:"organization_id,omitempty"`
+	Seats          int          `json:"seats"`
+	SeatChanges    []SeatChange `json:"seat_changes,omitempty"`
+}
+
+// LoadLocation returns the time zone with the given IANA name, or UTC when
+// the name is unknown.
+func LoadLocation(name string) *time.Location {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// PauseSchedule is when a pause starts and ends, as calendar days in the
+// time zone of the subscription. Without PauseFrom the pause starts right
+// away, without ResumeOn it lasts until the subscription is unpaused.
+type PauseSchedule struct {
+	PauseFrom *time.Time
+	ResumeOn  *time.Time
+}
+
+// StartOfDay returns midnight of the day t falls on in the given location.
+func StartOfDay(t time.Time, location *time.Location) time.Time {
+	year, month, day := t.In(location).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, location)
+}
+
+// DateIn returns midnight of the calendar day of t in the given location,
+// regardless of the location t is in.
+func DateIn(t time.Time, location *time.Location) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, location)
+}
+
+// Location returns the time zone that the dates of the subscription are
+// computed in.
+func (s Subscription) Location() *time.Location {
+	return LoadLocation(s.TimeZone)
+}
+
+// InLocation returns the subscription with its dates in its own time zone,
+// so they are rendered in it and days are added in it.
+func (s Subscription) InLocation() Subscription {
+	location := s.Location()
+	s.StartDate = s.StartDate.In(location)
+	s.EndDate = s.EndDate.In(location)
+	for _, date := range []**time.Time{
+		&s.TrialStartDate,
+		&s.TrialEndDate,
+		&s.CanceledDate,
+		&s.PausedDate,
+		&s.UnpausedDate,
+		&s.PastDueDate,
+		&s.GraceEndDate,
+		&s.NextPaymentRetryDate,
+		&s.PauseFrom,
+		&s.ResumeOn,
+	} {
+		if *date != nil {
+			local := (*date).In(location)
+			*date = &local
+		}
+	}
+
+	if s.AddOns != nil {
+		addOns := make([]SubscriptionAddOn, 0, len(s.AddOns))
+		for _, addOn := range s.AddOns {
+			addOn.StartDate = addOn.StartDate.In(location)
+			if addOn.CanceledDate != nil {
+				canceledDate := addOn.CanceledDate.In(location)
+				addOn.CanceledDate = &canceledDate
+			}
+			if addOn.BilledUntil != nil {
+				billedUntil := addOn.BilledUntil.In(location)
+				addOn.BilledUntil = &billedUntil
+			}
+			addOns = append(addOns, addOn)
+		}
+		s.AddOns = addOns
+	}
+
+	if s.SeatChanges != nil {
+		seatChanges := make([]SeatChange, 0, len(s.SeatChanges))
+		for _, change := range s.SeatChanges {
+			change.ChangeDate = change.ChangeDate.In(location)
+			seatChanges = append(seatChanges, change)
+		}
+		s.SeatChanges = seatChanges
+	}
+	return s
+}
+
+// AddOn returns the add-on with the given ID.
+func (s Subscription) AddOn(addOnID string) (SubscriptionAddOn, bool) {
+	for _, addOn := range s.AddOns {
+		if addOn.ID.String() == addOnID {
+			return addOn, true
+		}
+	}
+	return SubscriptionAddOn{}, false
+}
+
+// Member returns the invited or active member with the given ID.
+func (s Subscription) Member(memberID string) (SubscriptionMember, bool) {
+	for _, member := range s.Members {
+		if member.ID.String() == memberID {
+			return member, true
+		}
+	}
+	return SubscriptionMember{}, false
 }
@@ -2,9 +2,22 @@ package model
 
 import "github.com/google/uuid"
 
+// Locales that notifications are available in. Users without a supported
+// locale receive them in DefaultLocale.
+const (
+	LocaleGerman  = "de"
+	LocaleEnglish = "en"
+	DefaultLocale = LocaleGerman
+)
+
+// DefaultTimeZone is the IANA time zone of users that haven't set one.
+const DefaultTimeZone = "Europe/Berlin"
+
 type User struct {
 	ID         uuid.UUID `json:"id"`
 	FirstName  string    `json:"first_name"`
 	SecondName string    `json:"second_name"`
 	Email      string    `json:"email"`
+	Locale     string    `json:"locale"`
+	TimeZone   string    `json:"time_zone"`
 }
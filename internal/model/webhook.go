@@ -0,0 +1,85 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WebhookEventType string
+
+const (
+	SubscriptionCreated  WebhookEventType = "subscription.created"
+	SubscriptionPaused   WebhookEventType = "subscription.paused"
+	SubscriptionUnpaused WebhookEventType = "subscription.unpaused"
+	SubscriptionCanceled WebhookEventType = "subscription.canceled"
+	// SubscriptionReactivated announces that a canceled subscription was
+	// restored with a new period.
+	SubscriptionReactivated WebhookEventType = "subscription.reactivated"
+	// SubscriptionPauseScheduled announces that a scheduled pause or the
+	// resume date of a pause was set, changed or removed.
+	SubscriptionPauseScheduled WebhookEventType = "subscription.pause_scheduled"
+	// SubscriptionUpdated announces that the product or the price of a
+	// subscription changed.
+	SubscriptionUpdated WebhookEventType = "subscription.updated"
+	// SubscriptionActivated announces that a scheduled subscription has
+	// started.
+	SubscriptionActivated WebhookEventType = "subscription.activated"
+)
+
+type WebhookEndpoint struct {
+	ID  uuid.UUID `json:"id"`
+	URL string    `json:"url"`
+	// Secret signs every payload sent to the endpoint. It is only returned
+	// when the endpoint is registered.
+	Secret string `json:"secret,omitempty"`
+	// EventTypes limits the events delivered to the endpoint, an empty list
+	// subscribes it to every event.
+	EventTypes []WebhookEventType `json:"event_types"`
+	Active     bool               `json:"active"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+// Accepts reports whether the endpoint wants events of the given type.
+func (e WebhookEndpoint) Accepts(eventType WebhookEventType) bool {
+	if len(e.EventTypes) == 0 {
+		return true
+	}
+	for _, accepted := range e.EventTypes {
+		if accepted == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookEvent struct {
+	ID             uuid.UUID        `json:"id"`
+	Type           WebhookEventType `json:"type"`
+	SubscriptionID uuid.UUID        `json:"subscription_id"`
+	Payload        json.RawMessage  `json:"payload" swaggertype:"object"`
+	CreatedAt      time.Time        `json:"created_at"`
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookPending   WebhookDeliveryStatus = "pending"
+	WebhookDelivered WebhookDeliveryStatus = "delivered"
+	// WebhookDeadLettered deliveries ran out of attempts and are only sent
+	// again when replayed.
+	WebhookDeadLettered WebhookDeliveryStatus = "dead"
+)
+
+type WebhookDelivery struct {
+	ID            uuid.UUID             `json:"id"`
+	EventID       uuid.UUID             `json:"event_id"`
+	EndpointID    uuid.UUID             `json:"endpoint_id"`
+	Status        WebhookDeliveryStatus `json:"status"`
+	Attempts      int                   `json:"attempts"`
+	NextAttemptAt *time.Time            `json:"next_attempt_at,omitempty"`
+	LastError     string                `json:"last_error,omitempty"`
+	DeliveredAt   *time.Time            `json:"delivered_at,omitempty"`
+	CreatedAt     time.Time             `json:"created_at"`
+}
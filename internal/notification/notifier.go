@@ -0,0 +1,59 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gymondo/internal/model"
+)
+
+// LogNotifier only logs the rendered notifications. It is meant for local
+// development.
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, notification model.Notification) error {
+	message, err := Render(notification)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Notification %s to %s: %s\n%s", notification.Kind, message.To, message.Subject, message.Body)
+	return nil
+}
+
+// FileNotifier writes every notification as an .eml file into a directory,
+// where it can be opened with any mail client.
+type FileNotifier struct {
+	dir  string
+	from string
+}
+
+func NewFileNotifier(dir, from string) *FileNotifier {
+	return &FileNotifier{dir: dir, from: from}
+}
+
+func (n *FileNotifier) Notify(ctx context.Context, notification model.Notification) error {
+	message, err := Render(notification)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(n.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create notification directory: %w", err)
+	}
+
+	name := fmt.Sprintf("%s-%s-%s.eml", time.Now().Format("20060102T150405.000000000"), notification.Kind, notification.User.ID)
+	if err := os.WriteFile(filepath.Join(n.dir, name), composeMail(n.from, message, time.Now()), 0o644); err != nil {
+		return fmt.Errorf("failed to write notification: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package notification
+
+import (
+	"embed"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"gymondo/internal/model"
+)
+
+//go:embed templates
+var templateFiles embed.FS
+
+// Message is a rendered notification ready to be sent.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+var templates = mustParseTemplates()
+
+// Render renders the notification in the user's locale, falling back to
+// model.DefaultLocale for locales without templates.
+func Render(notification model.Notification) (Message, error) {
+	localized, ok := templates[notification.User.Locale]
+	if !ok {
+		localized = templates[model.DefaultLocale]
+	}
+
+	tmpl, ok := localized[notification.Kind]
+	if !ok {
+		return Message{}, fmt.Errorf("no template for notification %q", notification.Kind)
+	}
+
+	var subject, body strings.Builder
+	if err := tmpl.ExecuteTemplate(&subject, "subject", notification); err != nil {
+		return Message{}, fmt.Errorf("failed to render %s subject: %w", notification.Kind, err)
+	}
+	if err := tmpl.ExecuteTemplate(&body, "body", notification); err != nil {
+		return Message{}, fmt.Errorf("failed to render %s body: %w", notification.Kind, err)
+	}
+
+	return Message{
+		To:      notification.User.Email,
+		Subject: strings.TrimSpace(subject.String()),
+		Body:    strings.TrimLeft(body.String(), "\n"),
+	}, nil
+}
+
+var kinds = []model.NotificationKind{
+	model.NotificationSubscriptionConfirmation,
+	model.NotificationTrialEnding,
+	model.NotificationRenewalUpcoming,
+	model.NotificationPaymentFailed,
+	model.NotificationCancellationConfirmation,
+	model.NotificationMemberInvitation,
+	model.NotificationGiftPurchase,
+	model.NotificationGiftExpired,
+	model.NotificationPriceChange,
+}
+
+var localeFuncs = map[string]template.FuncMap{
+	model.LocaleGerman: {
+		"date":   dateFormatter("02.01.2006"),
+		"amount": func(amount float64) string { return strings.Replace(fmt.Sprintf("%.2f €", amount), ".", ",", 1) },
+	},
+	model.LocaleEnglish: {
+		"date":   dateFormatter("January 2, 2006"),
+		"amount": func(amount float64) string { return fmt.Sprintf("€%.2f", amount) },
+	},
+}
+
+// mustParseTemplates parses the template of every notification kind for every
+// locale, so a missing or broken template fails at startup rather than when
+// the notification is sent.
+func mustParseTemplates() map[string]map[model.NotificationKind]*template.Template {
+	parsed := make(map[string]map[model.NotificationKind]*template.Template)
+	for locale, funcs := range localeFuncs {
+		parsed[locale] = make(map[model.NotificationKind]*template.Template)
+		for _, kind := range kinds {
+			path := fmt.Sprintf("templates/%s/%s.tmpl", locale, kind)
+			parsed[locale][kind] = template.Must(template.New(string(kind)).Funcs(funcs).ParseFS(templateFiles, path))
+		}
+	}
+	return parsed
+}
+
+// dateFormatter formats both dates and optional dates of a subscription.
+func dateFormatter(layout string) func(date any) string {
+	return func(date any) string {
+		switch date := date.(type) {
+		case time.Time:
+			return date.Format(layout)
+		case *time.Time:
+			if date == nil {
+				return ""
+			}
+			return date.Format(layout)
+		default:
+			return fmt.Sprint(date)
+		}
+	}
+}
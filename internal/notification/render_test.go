@@ -0,0 +1,141 @@
+package notification
+
+import (
+	"context"
+	"io"
+	"mime/quotedprintable"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gymondo/internal/model"
+)
+
+func newNotification(kind model.NotificationKind, locale string) model.Notification {
+	endDate := time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC)
+	retryDate := endDate.AddDate(0, 0, 1)
+	graceEndDate := endDate.AddDate(0, 0, 7)
+
+	return model.Notification{
+		Kind:        kind,
+		User:        model.User{ID: uuid.New(), FirstName: "Anna", Email: "anna@example.com", Locale: locale},
+		ProductName: "Premium",
+		Subscription: model.Subscription{
+			EndDate:              endDate,
+			TrialEndDate:         &endDate,
+			CanceledDate:         &endDate,
+			NextPaymentRetryDate: &retryDate,
+			GraceEndDate:         &graceEndDate,
+			DurationDays:         30,
+			TotalPrice:           1234.5,
+		},
+		Gift: model.Gift{
+			Code:         "GIFT-7K3M-Q9XP",
+			DurationDays: 90,
+			ExpiresAt:    endDate,
+		},
+		PriceChange: model.PriceChange{
+			DurationDays:  30,
+			OldTotalPrice: 1234.5,
+			TotalPrice:    1299,
+			EffectiveDate: endDate,
+		},
+	}
+}
+
+func Test_Render(t *testing.T) {
+	t.Parallel()
+
+	t.Run("every kind renders in every locale", func(t *testing.T) {
+		t.Parallel()
+
+		for locale := range localeFuncs {
+			for _, kind := range kinds {
+				message, err := Render(newNotification(kind, locale))
+				assert.NoError(t, err, "%s/%s", locale, kind)
+				assert.Equal(t, "anna@example.com", message.To)
+				assert.NotEmpty(t, message.Subject, "%s/%s", locale, kind)
+				assert.NotContains(t, message.Subject, "\n")
+				assert.Contains(t, message.Body, "Anna", "%s/%s", locale, kind)
+				assert.NotContains(t, message.Body, "<no value>", "%s/%s", locale, kind)
+			}
+		}
+	})
+
+	t.Run("german formatting", func(t *testing.T) {
+		t.Parallel()
+
+		message, err := Render(newNotification(model.NotificationPaymentFailed, model.LocaleGerman))
+		assert.NoError(t, err)
+		assert.Equal(t, "Deine Zahlung ist fehlgeschlagen", message.Subject)
+		assert.Contains(t, message.Body, "1234,50 €")
+		assert.Contains(t, message.Body, "am 06.03.2024 erneut")
+		assert.Contains(t, message.Body, "bis zum 12.03.2024")
+	})
+
+	t.Run("english formatting", func(t *testing.T) {
+		t.Parallel()
+
+		message, err := Render(newNotification(model.NotificationRenewalUpcoming, model.LocaleEnglish))
+		assert.NoError(t, err)
+		assert.Equal(t, "Your Premium subscription renews on March 5, 2024", message.Subject)
+		assert.Contains(t, message.Body, "€1234.50")
+	})
+
+	t.Run("scheduled subscription tells when it starts", func(t *testing.T) {
+		t.Parallel()
+
+		notification := newNotification(model.NotificationSubscriptionConfirmation, model.LocaleEnglish)
+		notification.Subscription.Status = model.Scheduled
+		notification.Subscription.StartDate = time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+		message, err := Render(notification)
+		assert.NoError(t, err)
+		assert.Contains(t, message.Body, "Your subscription starts on January 1, 2025.")
+	})
+
+	t.Run("unknown locale falls back to the default", func(t *testing.T) {
+		t.Parallel()
+
+		message, err := Render(newNotification(model.NotificationCancellationConfirmation, "fr"))
+		assert.NoError(t, err)
+		assert.Equal(t, "Dein Premium-Abo ist gekündigt", message.Subject)
+	})
+
+	t.Run("unknown kind", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := Render(newNotification("subscription_renamed", model.LocaleEnglish))
+		assert.ErrorContains(t, err, "no template for notification")
+	})
+}
+
+func Test_FileNotifier_Notify(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	notifier := NewFileNotifier(dir, "Gymondo <no-reply@gymondo.de>")
+
+	err := notifier.Notify(context.Background(), newNotification(model.NotificationSubscriptionConfirmation, model.LocaleGerman))
+	assert.NoError(t, err)
+
+	files, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	content, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	assert.NoError(t, err)
+
+	header, body, _ := strings.Cut(string(content), "\r\n\r\n")
+	assert.Contains(t, header, "From: Gymondo <no-reply@gymondo.de>\r\n")
+	assert.Contains(t, header, "To: anna@example.com\r\n")
+	assert.Contains(t, header, "Subject: =?utf-8?q?Dein_Premium-Abo_ist_best=C3=A4tigt?=\r\n")
+
+	decoded, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(body)))
+	assert.NoError(t, err)
+	assert.Contains(t, string(decoded), "Testzeitraum läuft bis zum 05.03.2024")
+}
@@ -0,0 +1,77 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"time"
+
+	"gymondo/internal/model"
+)
+
+// SMTPNotifier sends notifications by mail. Without credentials it sends
+// unauthenticated, which is what local mail catchers such as Mailpit expect.
+type SMTPNotifier struct {
+	addr   string
+	auth   smtp.Auth
+	from   string
+	sender string
+}
+
+func NewSMTPNotifier(host, port, username, password, from string) *SMTPNotifier {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	// the envelope sender is the bare address of the From header
+	sender := from
+	if address, err := mail.ParseAddress(from); err == nil {
+		sender = address.Address
+	}
+
+	return &SMTPNotifier{
+		addr:   net.JoinHostPort(host, port),
+		auth:   auth,
+		from:   from,
+		sender: sender,
+	}
+}
+
+func (n *SMTPNotifier) Notify(ctx context.Context, notification model.Notification) error {
+	message, err := Render(notification)
+	if err != nil {
+		return err
+	}
+
+	if err := smtp.SendMail(n.addr, n.auth, n.sender, []string{message.To}, composeMail(n.from, message, time.Now())); err != nil {
+		return fmt.Errorf("failed to send %s mail to %s: %w", notification.Kind, message.To, err)
+	}
+
+	return nil
+}
+
+// composeMail builds a plain text UTF-8 mail. The body is quoted-printable
+// encoded and the subject encoded as needed, so umlauts survive any relay.
+func composeMail(from string, message Message, date time.Time) []byte {
+	var mail bytes.Buffer
+	fmt.Fprintf(&mail, "From: %s\r\n", from)
+	fmt.Fprintf(&mail, "To: %s\r\n", message.To)
+	fmt.Fprintf(&mail, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&mail, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	mail.WriteString("MIME-Version: 1.0\r\n")
+	mail.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	mail.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+	mail.WriteString("\r\n")
+
+	body := quotedprintable.NewWriter(&mail)
+	body.Write([]byte(message.Body))
+	body.Close()
+
+	return mail.Bytes()
+}
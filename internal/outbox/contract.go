@@ -0,0 +1,20 @@
+//go:generate go run go.uber.org/mock/mockgen@v0.4.0 -source=contract.go -destination=contract_mock_test.go -package=$GOPACKAGE
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"gymondo/internal/model"
+)
+
+type Store interface {
+	GetUnpublishedOutboxMessages(ctx context.Context, limit int) ([]model.OutboxMessage, error)
+	MarkOutboxMessagePublished(ctx context.Context, messageID int64, publishedAt time.Time) error
+}
+
+// Sink publishes outbox messages. Messages may be published more than once,
+// so sinks and their consumers have to be idempotent on the event ID.
+type Sink interface {
+	Publish(ctx context.Context, message model.OutboxMessage) error
+}
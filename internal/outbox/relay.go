@@ -0,0 +1,62 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+	"gymondo/internal/clock"
+)
+
+const defaultBatchSize = 100
+
+// Relay publishes unpublished outbox messages to a sink in the order they
+// were written. Only one relay should run against a database at a time,
+// otherwise messages of the same subscription may overtake each other.
+type Relay struct {
+	store     Store
+	sink      Sink
+	clock     clock.Clock
+	batchSize int
+}
+
+func NewRelay(store Store, sink Sink, clock clock.Clock) *Relay {
+	return &Relay{
+		store:     store,
+		sink:      sink,
+		clock:     clock,
+		batchSize: defaultBatchSize,
+	}
+}
+
+// RelayPending publishes the next batch of unpublished messages. When a
+// message fails, the later messages of the same subscription are held back
+// until the next run so subscribers never see them out of order; messages of
+// other subscriptions are still published.
+func (r *Relay) RelayPending(ctx context.Context) error {
+	messages, err := r.store.GetUnpublishedOutboxMessages(ctx, r.batchSize)
+	if err != nil {
+		return fmt.Errorf("failed to fetch outbox messages: %w", err)
+	}
+
+	blocked := make(map[uuid.UUID]bool)
+	for _, message := range messages {
+		if blocked[message.SubscriptionID] {
+			continue
+		}
+
+		if err := r.sink.Publish(ctx, message); err != nil {
+			log.Printf("Error publishing outbox message %d of subscription %s: %v", message.ID, message.SubscriptionID, err)
+			blocked[message.SubscriptionID] = true
+			continue
+		}
+
+		if err := r.store.MarkOutboxMessagePublished(ctx, message.ID, r.clock.Now()); err != nil {
+			log.Printf("Error marking outbox message %d as published: %v", message.ID, err)
+			blocked[message.SubscriptionID] = true
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gymondo/internal/clock"
+	"gymondo/internal/model"
+	"testing"
+	"time"
+)
+
+func Test_Relay_RelayPending(t *testing.T) {
+	t.Parallel()
+
+	t.Run("publishes messages in order", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockStore := NewMockStore(ctrl)
+		sink := NewMemorySink()
+		now := time.Date(2024, time.March, 5, 10, 0, 0, 0, time.UTC)
+		relay := NewRelay(mockStore, sink, clock.Fixed(now))
+
+		subscriptionID := uuid.New()
+		messages := []model.OutboxMessage{
+			{ID: 1, EventID: uuid.New(), SubscriptionID: subscriptionID, Type: model.SubscriptionCreated},
+			{ID: 2, EventID: uuid.New(), SubscriptionID: subscriptionID, Type: model.SubscriptionPaused},
+		}
+
+		mockStore.EXPECT().GetUnpublishedOutboxMessages(gomock.Any(), defaultBatchSize).Return(messages, nil)
+		gomock.InOrder(
+			mockStore.EXPECT().MarkOutboxMessagePublished(gomock.Any(), int64(1), now).Return(nil),
+			mockStore.EXPECT().MarkOutboxMessagePublished(gomock.Any(), int64(2), now).Return(nil),
+		)
+
+		err := relay.RelayPending(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, messages, sink.Messages())
+	})
+
+	t.Run("failed message holds back its subscription only", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockStore := NewMockStore(ctrl)
+		mockSink := NewMockSink(ctrl)
+		relay := NewRelay(mockStore, mockSink, clock.System())
+
+		failing := uuid.New()
+		other := uuid.New()
+		messages := []model.OutboxMessage{
+			{ID: 1, SubscriptionID: failing, Type: model.SubscriptionPaused},
+			{ID: 2, SubscriptionID: other, Type: model.SubscriptionCreated},
+			{ID: 3, SubscriptionID: failing, Type: model.SubscriptionUnpaused},
+		}
+
+		mockStore.EXPECT().GetUnpublishedOutboxMessages(gomock.Any(), defaultBatchSize).Return(messages, nil)
+		mockSink.EXPECT().Publish(gomock.Any(), messages[0]).Return(errors.New("sink unavailable"))
+		mockSink.EXPECT().Publish(gomock.Any(), messages[1]).Return(nil)
+		mockStore.EXPECT().MarkOutboxMessagePublished(gomock.Any(), int64(2), gomock.Any()).Return(nil)
+
+		err := relay.RelayPending(context.Background())
+		assert.NoError(t, err)
+	})
+
+	t.Run("failed fetch", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockStore := NewMockStore(ctrl)
+		relay := NewRelay(mockStore, NewLogSink(), clock.System())
+
+		mockStore.EXPECT().GetUnpublishedOutboxMessages(gomock.Any(), defaultBatchSize).Return(nil, errors.New("database error"))
+
+		err := relay.RelayPending(context.Background())
+		assert.ErrorContains(t, err, "failed to fetch outbox messages")
+	})
+}
+
+func Test_MultiSink_Publish(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	failing := NewMockSink(ctrl)
+	skipped := NewMemorySink()
+	message := model.OutboxMessage{ID: 1}
+
+	failing.EXPECT().Publish(gomock.Any(), message).Return(errors.New("sink unavailable"))
+
+	err := MultiSink{failing, skipped}.Publish(context.Background(), message)
+	assert.Error(t, err)
+	assert.Empty(t, skipped.Messages())
+}
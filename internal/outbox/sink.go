@@ -0,0 +1,110 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"gymondo/internal/model"
+)
+
+// SinkFunc adapts a function to the Sink interface.
+type SinkFunc func(ctx context.Context, message model.OutboxMessage) error
+
+func (f SinkFunc) Publish(ctx context.Context, message model.OutboxMessage) error {
+	return f(ctx, message)
+}
+
+// MultiSink publishes every message to each of its sinks in turn and stops at
+// the first failure. The whole message is published again on the next run,
+// including to the sinks that already succeeded.
+type MultiSink []Sink
+
+func (m MultiSink) Publish(ctx context.Context, message model.OutboxMessage) error {
+	for _, sink := range m {
+		if err := sink.Publish(ctx, message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LogSink only logs the messages.
+type LogSink struct{}
+
+func NewLogSink() *LogSink {
+	return &LogSink{}
+}
+
+func (s *LogSink) Publish(ctx context.Context, message model.OutboxMessage) error {
+	log.Printf("Published %s event %s for subscription %s", message.Type, message.EventID, message.SubscriptionID)
+	return nil
+}
+
+// MemorySink keeps the published messages in memory, for tests and local
+// development.
+type MemorySink struct {
+	mu       sync.Mutex
+	messages []model.OutboxMessage
+}
+
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+func (s *MemorySink) Publish(ctx context.Context, message model.OutboxMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messages = append(s.messages, message)
+	return nil
+}
+
+// Messages returns the published messages in the order they were published.
+func (s *MemorySink) Messages() []model.OutboxMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]model.OutboxMessage(nil), s.messages...)
+}
+
+// HTTPSink posts the payload of every message to a fixed URL.
+type HTTPSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPSink(url string, timeout time.Duration) *HTTPSink {
+	return &HTTPSink{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (s *HTTPSink) Publish(ctx context.Context, message model.OutboxMessage) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(message.Payload))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Event-Type", string(message.Type))
+	request.Header.Set("X-Event-ID", message.EventID.String())
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 1<<16))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("sink responded with status %d", response.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package payment
+
+import (
+	"context"
+	"log"
+
+	"github.com/google/uuid"
+)
+
+// LogGateway approves every charge and only logs it. It stands in for a real
+// payment provider when running the service locally.
+type LogGateway struct{}
+
+func NewLogGateway() *LogGateway {
+	return &LogGateway{}
+}
+
+func (g *LogGateway) Charge(
+	ctx context.Context,
+	userID uuid.UUID,
+	amount float64,
+	reference string,
+) (string, error) {
+	transactionID := uuid.New().String()
+	log.Printf("Charged user %s %.2f for %s (transaction %s)", userID, amount, reference, transactionID)
+
+	return transactionID, nil
+}
+
+func (g *LogGateway) Refund(
+	ctx context.Context,
+	userID uuid.UUID,
+	amount float64,
+	chargeTransactionID string,
+	reference string,
+) (string, error) {
+	transactionID := uuid.New().String()
+	log.Printf(
+		"Refunded user %s %.2f of transaction %s for %s (transaction %s)",
+		userID, amount, chargeTransactionID, reference, transactionID,
+	)
+
+	return transactionID, nil
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gymondo/internal/model"
+)
+
+func (r *Repository) SaveSubscriptionAddOn(ctx context.Context, addOn model.SubscriptionAddOn) error {
+	const query = `
+		insert into service.subscription_add_ons (
+			id,
+			subscription_id,
+			product_id,
+			price,
+			tax,
+			total_price,
+			status,
+			start_date,
+			canceled_date,
+			billed_until
+		) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		addOn.ID,
+		addOn.SubscriptionID,
+		addOn.ProductID,
+		addOn.Price,
+		addOn.Tax,
+		addOn.TotalPrice,
+		addOn.Status,
+		addOn.StartDate,
+		nullTime(addOn.CanceledDate),
+		nullTime(addOn.BilledUntil),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save add-on %s of subscription %s: %w", addOn.ID, addOn.SubscriptionID, err)
+	}
+
+	return nil
+}
+
+func (r *Repository) UpdateSubscriptionAddOn(ctx context.Context, addOn model.SubscriptionAddOn) error {
+	const query = `
+		update service.subscription_add_ons
+		set status = $2, canceled_date = $3, billed_until = $4
+		where id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query, addOn.ID, addOn.Status, nullTime(addOn.CanceledDate), nullTime(addOn.BilledUntil))
+	if err != nil {
+		return fmt.Errorf("failed to update add-on %s: %w", addOn.ID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gymondo/internal/model"
+)
+
+// SaveCreditTransaction stores a credit transaction with its ledger entries.
+// The customer's user row is locked for the duration of the write so
+// concurrent transactions cannot overdraw the credit balance.
+func (r *Repository) SaveCreditTransaction(ctx context.Context, transaction model.CreditTransaction) error {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin credit transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	const lockQuery = `select id from service.users where id = $1 for update`
+	if _, err := tx.ExecContext(ctx, lockQuery, transaction.UserID); err != nil {
+		return fmt.Errorf("failed to lock user %s: %w", transaction.UserID, err)
+	}
+
+	if amount := transaction.CustomerAmount(); amount < 0 {
+		var balance float64
+		if err := tx.QueryRowContext(ctx, creditBalanceQuery, transaction.UserID).Scan(&balance); err != nil {
+			return fmt.Errorf("failed to query credit balance of user %s: %w", transaction.UserID, err)
+		}
+		if balance+amount < 0 {
+			return model.ErrInsufficientCredit
+		}
+	}
+
+	const transactionQuery = `
+		insert into service.credit_transactions (
+			id,
+			user_id,
+			kind,
+			reason,
+			subscription_id,
+			created_at
+		) values ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err = tx.ExecContext(ctx, transactionQuery,
+		transaction.ID,
+		transaction.UserID,
+		transaction.Kind,
+		transaction.Reason,
+		transaction.SubscriptionID,
+		transaction.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save credit transaction %s: %w", transaction.ID, err)
+	}
+
+	const entryQuery = `
+		insert into service.credit_entries (transaction_id, account, user_id, amount)
+		values ($1, $2, $3, $4)
+	`
+
+	for _, entry := range transaction.Entries {
+		// only the customer's own account is kept per user, the others are
+		// shared ledger accounts
+		var userID any
+		if entry.Account == model.CustomerCreditAccount {
+			userID = transaction.UserID
+		}
+
+		if _, err := tx.ExecContext(ctx, entryQuery, transaction.ID, entry.Account, userID, entry.Amount); err != nil {
+			return fmt.Errorf("failed to save credit entry of transaction %s: %w", transaction.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit credit transaction %s: %w", transaction.ID, err)
+	}
+
+	return nil
+}
+
+const creditBalanceQuery = `
+	select coalesce(sum(amount), 0)
+	from service.credit_entries
+	where account = 'customer_credit' and user_id = $1
+`
+
+func (r *Repository) GetCreditBalance(ctx context.Context, userID string) (float64, error) {
+	var balance float64
+	if err := r.db.QueryRowContext(ctx, creditBalanceQuery, userID).Scan(&balance); err != nil {
+		return 0, fmt.Errorf("failed to query credit balance of user %s: %w", userID, err)
+	}
+
+	return balance, nil
+}
+
+// GetCreditTransactions returns the credit transactions of a user with their
+// entries, newest first.
+func (r *Repository) GetCreditTransactions(ctx context.Context, userID string) ([]model.CreditTransaction, error) {
+	const query = `
+		select t.id, t.user_id, t.kind, t.reason, t.subscription_id, t.created_at, e.account, e.amount
+		from service.credit_transactions t
+		join service.credit_entries e on e.transaction_id = t.id
+		where t.user_id = $1
+		order by t.created_at desc, t.id, e.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query credit transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var transactions []model.CreditTransaction
+	for rows.Next() {
+		var transaction model.CreditTransaction
+		var entry model.CreditEntry
+		if err := rows.Scan(
+			&transaction.ID,
+			&transaction.UserID,
+			&transaction.Kind,
+			&transaction.Reason,
+			&transaction.SubscriptionID,
+			&transaction.CreatedAt,
+			&entry.Account,
+			&entry.Amount,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan credit transaction row: %w", err)
+		}
+
+		if last := len(transactions) - 1; last >= 0 && transactions[last].ID == transaction.ID {
+			transactions[last].Entries = append(transactions[last].Entries, entry)
+			continue
+		}
+		transaction.Entries = []model.CreditEntry{entry}
+		transactions = append(transactions, transaction)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over credit transactions: %w", err)
+	}
+
+	return transactions, nil
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"gymondo/internal/model"
+	"time"
+)
+
+func (r *Repository) SaveGift(ctx context.Context, gift model.Gift) error {
+	const query = `
+		insert into service.gifts (
+			id,
+			code,
+			buyer_id,
+			product_id,
+			duration_days,
+			price,
+			tax,
+			total_price,
+			transaction_id,
+			status,
+			purchased_at,
+			expires_at
+		) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		gift.ID,
+		gift.Code,
+		gift.BuyerID,
+		gift.ProductID,
+		gift.DurationDays,
+		gift.Price,
+		gift.Tax,
+		gift.TotalPrice,
+		gift.TransactionID,
+		gift.Status,
+		gift.PurchasedAt,
+		gift.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save gift %s: %w", gift.ID, err)
+	}
+
+	return nil
+}
+
+const giftColumns = `
+	id,
+	code,
+	buyer_id,
+	product_id,
+	duration_days,
+	price,
+	tax,
+	total_price,
+	transaction_id,
+	status,
+	purchased_at,
+	expires_at,
+	redeemed_by,
+	redeemed_at,
+	subscription_id
+`
+
+func scanGift(row rowScanner) (model.Gift, error) {
+	var gift model.Gift
+	err := row.Scan(
+		&gift.ID,
+		&gift.Code,
+		&gift.BuyerID,
+		&gift.ProductID,
+		&gift.DurationDays,
+		&gift.Price,
+		&gift.Tax,
+		&gift.TotalPrice,
+		&gift.TransactionID,
+		&gift.Status,
+		&gift.PurchasedAt,
+		&gift.ExpiresAt,
+		&gift.RedeemedBy,
+		&gift.RedeemedAt,
+		&gift.SubscriptionID,
+	)
+	return gift, err
+}
+
+func (r *Repository) GetGiftByCode(ctx context.Context, code string) (model.Gift, error) {
+	query := `
+		select ` + giftColumns + `
+		from service.gifts
+		where code = $1
+	`
+
+	gift, err := scanGift(r.db.QueryRowContext(ctx, query, code))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return gift, fmt.Errorf("gift with code %s not found", code)
+		}
+		return gift, fmt.Errorf("failed to query gift with code %s: %w", code, err)
+	}
+
+	return gift, nil
+}
+
+// UpdateGift stores the redemption or expiry of a gift. Only gifts that are
+// still purchased change, so a gift is redeemed at most once; otherwise it
+// fails with model.ErrGiftNotRedeemable.
+func (r *Repository) UpdateGift(ctx context.Context, gift model.Gift) error {
+	const query = `
+		update service.gifts
+		set status = $2, redeemed_by = $3, redeemed_at = $4, subscription_id = $5
+		where id = $1 and status = 'purchased'
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		gift.ID,
+		gift.Status,
+		gift.RedeemedBy,
+		nullTime(gift.RedeemedAt),
+		gift.SubscriptionID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update gift %s: %w", gift.ID, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check update of gift %s: %w", gift.ID, err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("gift %s is no longer purchased: %w", gift.ID, model.ErrGiftNotRedeemable)
+	}
+
+	return nil
+}
+
+// GetGiftsDueForExpiry returns the unredeemed gifts that expired at or before
+// the given time.
+func (r *Repository) GetGiftsDueForExpiry(ctx context.Context, now time.Time) ([]model.Gift, error) {
+	query := `
+		select ` + giftColumns + `
+		from service.gifts
+		where status = 'purchased' and expires_at <= $1
+		order by expires_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query gifts due for expiry: %w", err)
+	}
+	defer rows.Close()
+
+	var gifts []model.Gift
+	for rows.Next() {
+		gift, err := scanGift(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan gift row: %w", err)
+		}
+		gifts = append(gifts, gift)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over gifts: %w", err)
+	}
+
+	return gifts, nil
+}
@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"gymondo/internal/model"
+)
+
+// SaveInvoice stores the invoice under the next number of its issue year. The
+// number is allocated in the same transaction as the insert, so a failed
+// insert never leaves a gap in the sequence.
+func (r *Repository) SaveInvoice(ctx context.Context, invoice model.Invoice) (model.Invoice, error) {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return invoice, fmt.Errorf("failed to begin invoice transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	year := invoice.IssueDate.Year()
+	sequenceNumber, err := nextSequenceNumber(ctx, tx, "service.invoice_sequences", year)
+	if err != nil {
+		return invoice, fmt.Errorf("failed to allocate invoice number for %d: %w", year, err)
+	}
+	invoice.Number = fmt.Sprintf("%d-%06d", year, sequenceNumber)
+
+	const invoiceQuery = `
+		insert into service.invoices (
+			id,
+			number,
+			year,
+			sequence_number,
+			subscription_id,
+			user_id,
+			customer_name,
+			customer_email,
+			issue_date,
+			period_start,
+			period_end,
+			net_amount,
+			tax_amount,
+			total_amount,
+			currency,
+			company_name,
+			vat_id,
+			billing_address,
+			gift_id
+		) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+	`
+
+	_, err = tx.ExecContext(ctx, invoiceQuery,
+		invoice.ID,
+		invoice.Number,
+		year,
+		sequenceNumber,
+		// invoices of gifts belong to no subscription
+		uuid.NullUUID{UUID: invoice.SubscriptionID, Valid: invoice.SubscriptionID != uuid.Nil},
+		invoice.UserID,
+		invoice.CustomerName,
+		invoice.CustomerEmail,
+		invoice.IssueDate,
+		invoice.PeriodStart,
+		invoice.PeriodEnd,
+		invoice.NetAmount,
+		invoice.TaxAmount,
+		invoice.TotalAmount,
+		invoice.Currency,
+		nullString(invoice.CompanyName),
+		nullString(invoice.VATID),
+		nullString(invoice.BillingAddress),
+		invoice.GiftID,
+	)
+	if err != nil {
+		return invoice, fmt.Errorf("failed to save invoice %s: %w", invoice.Number, err)
+	}
+
+	const lineItemQuery = `
+		insert into service.invoice_line_items (
+			invoice_id,
+			position,
+			description,
+			quantity,
+			unit_price,
+			tax,
+			total_price
+		) values ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	for i, item := range invoice.LineItems {
+		_, err := tx.ExecContext(ctx, lineItemQuery,
+			invoice.ID,
+			i+1,
+			item.Description,
+			item.Quantity,
+			item.UnitPrice,
+			item.Tax,
+			item.TotalPrice,
+		)
+		if err != nil {
+			return invoice, fmt.Errorf("failed to save line item %d of invoice %s: %w", i+1, invoice.Number, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return invoice, fmt.Errorf("failed to commit invoice %s: %w", invoice.Number, err)
+	}
+
+	return invoice, nil
+}
+
+// nextSequenceNumber increments and returns the counter of the given year in
+// a sequence table. The row stays locked until tx ends, which serializes
+// concurrent writers and keeps the numbering free of gaps.
+func nextSequenceNumber(ctx context.Context, tx dbtx, table string, year int) (int, error) {
+	query := `
+		insert into ` + table + ` (year, last_number)
+		values ($1, 1)
+		on conflict (year) do update
+		set last_number = ` + table + `.last_number + 1
+		returning last_number
+	`
+
+	var sequenceNumber int
+	if err := tx.QueryRowContext(ctx, query, year).Scan(&sequenceNumber); err != nil {
+		return 0, err
+	}
+
+	return sequenceNumber, nil
+}
+
+const invoiceColumns = `
+	id,
+	number,
+	subscription_id,
+	user_id,
+	customer_name,
+	customer_email,
+	issue_date,
+	period_start,
+	period_end,
+	net_amount,
+	tax_amount,
+	total_amount,
+	currency,
+	coalesce(company_name, ''),
+	coalesce(vat_id, ''),
+	coalesce(billing_address, ''),
+	gift_id
+`
+
+func scanInvoice(row rowScanner) (model.Invoice, error) {
+	var invoice model.Invoice
+	err := row.Scan(
+		&invoice.ID,
+		&invoice.Number,
+		&invoice.SubscriptionID,
+		&invoice.UserID,
+		&invoice.CustomerName,
+		&invoice.CustomerEmail,
+		&invoice.IssueDate,
+		&invoice.PeriodStart,
+		&invoice.PeriodEnd,
+		&invoice.NetAmount,
+		&invoice.TaxAmount,
+		&invoice.TotalAmount,
+		&invoice.Currency,
+		&invoice.CompanyName,
+		&invoice.VATID,
+		&invoice.BillingAddress,
+		&invoice.GiftID,
+	)
+	return invoice, err
+}
+
+func (r *Repository) GetInvoice(ctx context.Context, invoiceID string) (model.Invoice, error) {
+	query := `
+		select ` + invoiceColumns + `
+		from service.invoices
+		where id = $1
+	`
+
+	invoice, err := scanInvoice(r.db.QueryRowContext(ctx, query, invoiceID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return invoice, fmt.Errorf("invoice with ID %s not found: %w", invoiceID, err)
+		}
+		return invoice, fmt.Errorf("failed to retrieve invoice with ID %s: %w", invoiceID, err)
+	}
+
+	lineItems, err := r.getInvoiceLineItems(ctx, invoiceID)
+	if err != nil {
+		return invoice, err
+	}
+	invoice.LineItems = lineItems
+
+	return invoice, nil
+}
+
+func (r *Repository) getInvoiceLineItems(ctx context.Context, invoiceID string) ([]model.InvoiceLineItem, error) {
+	const query = `
+		select description, quantity, unit_price, tax, total_price
+		from service.invoice_line_items
+		where invoice_id = $1
+		order by position
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, invoiceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query line items of invoice %s: %w", invoiceID, err)
+	}
+	defer rows.Close()
+
+	var lineItems []model.InvoiceLineItem
+	for rows.Next() {
+		var item model.InvoiceLineItem
+		if err := rows.Scan(
+			&item.Description,
+			&item.Quantity,
+			&item.UnitPrice,
+			&item.Tax,
+			&item.TotalPrice,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan invoice line item row: %w", err)
+		}
+		lineItems = append(lineItems, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over invoice line items: %w", err)
+	}
+
+	return lineItems, nil
+}
+
+// GetSubscriptionInvoices returns the invoices of a subscription without their
+// line items, oldest first.
+func (r *Repository) GetSubscriptionInvoices(ctx context.Context, subscriptionID string) ([]model.Invoice, error) {
+	query := `
+		select ` + invoiceColumns + `
+		from service.invoices
+		where subscription_id = $1
+		order by year, sequence_number
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query invoices: %w", err)
+	}
+	defer rows.Close()
+
+	var invoices []model.Invoice
+	for rows.Next() {
+		invoice, err := scanInvoice(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan invoice row: %w", err)
+		}
+		invoices = append(invoices, invoice)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over invoices: %w", err)
+	}
+
+	return invoices, nil
+}
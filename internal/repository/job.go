@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"gymondo/internal/model"
+	"strings"
+	"time"
+)
+
+const jobColumns = `
+	id,
+	name,
+	status,
+	attempts,
+	max_attempts,
+	run_at,
+	started_at,
+	finished_at,
+	last_error,
+	created_at
+`
+
+func scanJob(row rowScanner) (model.Job, error) {
+	var job model.Job
+	err := row.Scan(
+		&job.ID,
+		&job.Name,
+		&job.Status,
+		&job.Attempts,
+		&job.MaxAttempts,
+		&job.RunAt,
+		&job.StartedAt,
+		&job.FinishedAt,
+		&job.LastError,
+		&job.CreatedAt,
+	)
+	return job, err
+}
+
+// EnqueueJob adds the job to the queue unless a job of the same name is
+// already queued or running.
+func (r *Repository) EnqueueJob(ctx context.Context, job model.Job) error {
+	query := `
+		insert into service.jobs (` + jobColumns + `)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		on conflict (name) where status in ('pending', 'running') do nothing
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		job.ID,
+		job.Name,
+		job.Status,
+		job.Attempts,
+		job.MaxAttempts,
+		job.RunAt,
+		nullTime(job.StartedAt),
+		nullTime(job.FinishedAt),
+		job.LastError,
+		job.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue job %s: %w", job.Name, err)
+	}
+
+	return nil
+}
+
+// ClaimJob marks the next due job of one of the given names as running and
+// returns it. Rows locked by other workers are skipped, so concurrent workers
+// never claim the same job.
+func (r *Repository) ClaimJob(
+	ctx context.Context,
+	names []string,
+	now time.Time,
+	staleBefore time.Time,
+) (model.Job, bool, error) {
+	query := `
+		update service.jobs
+		set status = 'running', attempts = attempts + 1, started_at = $2
+		where id = (
+			select id from service.jobs
+			where name = any(string_to_array($1, ','))
+				and (
+					(status = 'pending' and run_at <= $2)
+					or (status = 'running' and started_at < $3)
+				)
+			order by run_at
+			for update skip locked
+			limit 1
+		)
+		returning ` + jobColumns
+
+	job, err := scanJob(r.db.QueryRowContext(ctx, query, strings.Join(names, ","), now, staleBefore))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Job{}, false, nil
+		}
+		return model.Job{}, false, fmt.Errorf("failed to claim job: %w", err)
+	}
+
+	return job, true, nil
+}
+
+func (r *Repository) UpdateJob(ctx context.Context, job model.Job) error {
+	const query = `
+		update service.jobs
+		set status = $2, run_at = $3, finished_at = $4, last_error = $5
+		where id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		job.ID,
+		job.Status,
+		job.RunAt,
+		nullTime(job.FinishedAt),
+		job.LastError,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update job %s: %w", job.ID, err)
+	}
+
+	return nil
+}
+
+// DeleteFinishedJobs removes succeeded and failed jobs that finished before
+// the given time.
+func (r *Repository) DeleteFinishedJobs(ctx context.Context, before time.Time) error {
+	const query = `
+		delete from service.jobs
+		where status in ('succeeded', 'failed') and finished_at < $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, before); err != nil {
+		return fmt.Errorf("failed to delete finished jobs: %w", err)
+	}
+
+	return nil
+}
+
+// RewindJobs moves pending runs and start times of running jobs that lie
+// after now back to now, so they are claimed and considered stale as if the
+// clock had never been ahead.
+func (r *Repository) RewindJobs(ctx context.Context, now time.Time) error {
+	const query = `
+		update service.jobs
+		set run_at = least(run_at, $1), started_at = least(started_at, $1)
+		where (status = 'pending' and run_at > $1) or (status = 'running' and started_at > $1)
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, now); err != nil {
+		return fmt.Errorf("failed to rewind jobs: %w", err)
+	}
+
+	return nil
+}
+
+// GetJobs returns the most recent jobs, optionally filtered by status. An
+// empty status returns jobs of every status.
+func (r *Repository) GetJobs(ctx context.Context, status model.JobStatus, limit int) ([]model.Job, error) {
+	query := `
+		select ` + jobColumns + `
+		from service.jobs
+		where $1 = '' or status::text = $1
+		order by run_at desc
+		limit $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, string(status), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []model.Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job row: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over jobs: %w", err)
+	}
+
+	return jobs, nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gymondo/internal/model"
+)
+
+func (r *Repository) SaveSubscriptionMember(ctx context.Context, member model.SubscriptionMember) error {
+	const query = `
+		insert into service.subscription_members (
+			id,
+			subscription_id,
+			user_id,
+			status,
+			invited_at,
+			accepted_at,
+			removed_at
+		) values ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		member.ID,
+		member.SubscriptionID,
+		member.UserID,
+		member.Status,
+		member.InvitedAt,
+		nullTime(member.AcceptedAt),
+		nullTime(member.RemovedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save member %s of subscription %s: %w", member.UserID, member.SubscriptionID, err)
+	}
+
+	return nil
+}
+
+func (r *Repository) UpdateSubscriptionMember(ctx context.Context, member model.SubscriptionMember) error {
+	const query = `
+		update service.subscription_members
+		set status = $2, accepted_at = $3, removed_at = $4
+		where id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query, member.ID, member.Status, nullTime(member.AcceptedAt), nullTime(member.RemovedAt))
+	if err != nil {
+		return fmt.Errorf("failed to update member %s: %w", member.ID, err)
+	}
+
+	return nil
+}
+
+// GetMemberSubscriptions returns the subscriptions of other users that the
+// user is an active member of.
+func (r *Repository) GetMemberSubscriptions(ctx context.Context, userID string) ([]model.Subscription, error) {
+	query := `
+		select ` + subscriptionSelectColumns + `
+		from service.subscriptions s
+		where exists (
+			select 1
+			from service.subscription_members m
+			where m.subscription_id = s.id and m.user_id = $1 and m.status = 'active'
+		)
+		order by start_date
+	`
+
+	return r.querySubscriptions(ctx, query, userID)
+}
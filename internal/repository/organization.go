@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"gymondo/internal/model"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+func (r *Repository) SaveOrganization(ctx context.Context, organization model.Organization) error {
+	const query = `
+		insert into service.organizations (
+			id,
+			name,
+			vat_id,
+			address,
+			domain,
+			created_at
+		) values ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		organization.ID,
+		organization.Name,
+		nullString(organization.VATID),
+		organization.Address,
+		nullString(strings.ToLower(organization.Domain)),
+		organization.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save organization %s: %w", organization.Name, err)
+	}
+
+	return nil
+}
+
+func (r *Repository) SaveOrganizationAdmin(ctx context.Context, organizationID string, userID string) error {
+	const query = `
+		insert into service.organization_admins (organization_id, user_id)
+		values ($1, $2)
+		on conflict do nothing
+	`
+
+	_, err := r.db.ExecContext(ctx, query, organizationID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to save admin %s of organization %s: %w", userID, organizationID, err)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetOrganization(ctx context.Context, organizationID string) (model.Organization, error) {
+	const query = `
+		select
+			id,
+			name,
+			coalesce(vat_id, ''),
+			address,
+			coalesce(domain, ''),
+			created_at,
+			(
+				select coalesce(string_agg(user_id::text, ','), '')
+				from service.organization_admins
+				where organization_id = organizations.id
+			) as admin_ids
+		from service.organizations
+		where id = $1
+	`
+
+	var organization model.Organization
+	var adminIDs string
+	err := r.db.QueryRowContext(ctx, query, organizationID).Scan(
+		&organization.ID,
+		&organization.Name,
+		&organization.VATID,
+		&organization.Address,
+		&organization.Domain,
+		&organization.CreatedAt,
+		&adminIDs,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return organization, fmt.Errorf("organization with ID %s not found: %w", organizationID, err)
+		}
+		return organization, fmt.Errorf("failed to retrieve organization with ID %s: %w", organizationID, err)
+	}
+
+	organization.AdminIDs = make([]uuid.UUID, 0)
+	if adminIDs != "" {
+		for _, id := range strings.Split(adminIDs, ",") {
+			adminID, err := uuid.Parse(id)
+			if err != nil {
+				return organization, fmt.Errorf("invalid admin ID %s: %w", id, err)
+			}
+			organization.AdminIDs = append(organization.AdminIDs, adminID)
+		}
+	}
+
+	return organization, nil
+}
+
+// GetOrganizationSubscriptions returns the subscriptions of the organization
+// that are not canceled, oldest first.
+func (r *Repository) GetOrganizationSubscriptions(ctx context.Context, organizationID string) ([]model.Subscription, error) {
+	query := `
+		select ` + subscriptionSelectColumns + `
+		from service.subscriptions s
+		where organization_id = $1 and status <> 'canceled'
+		order by start_date
+	`
+
+	return r.querySubscriptions(ctx, query, organizationID)
+}
+
+func (r *Repository) SaveSeatChange(ctx context.Context, change model.SeatChange) error {
+	const query = `
+		insert into service.seat_changes (
+			id,
+			subscription_id,
+			seats_before,
+			seats_after,
+			change_date,
+			price,
+			tax,
+			total_price
+		) values ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		change.ID,
+		change.SubscriptionID,
+		change.SeatsBefore,
+		change.SeatsAfter,
+		change.ChangeDate,
+		change.Price,
+		change.Tax,
+		change.TotalPrice,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save seat change of subscription %s: %w", change.SubscriptionID, err)
+	}
+
+	return nil
+}
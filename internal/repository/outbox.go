@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gymondo/internal/model"
+	"time"
+)
+
+// SaveOutboxMessage stores a message for the outbox relay. Call it within the
+// unit of work that makes the change the message announces.
+func (r *Repository) SaveOutboxMessage(ctx context.Context, message model.OutboxMessage) error {
+	const query = `
+		insert into service.outbox_messages (
+			event_id,
+			subscription_id,
+			type,
+			payload,
+			created_at
+		) values ($1, $2, $3, $4, $5)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		message.EventID,
+		message.SubscriptionID,
+		message.Type,
+		string(message.Payload),
+		message.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save outbox message %s: %w", message.EventID, err)
+	}
+
+	return nil
+}
+
+// GetUnpublishedOutboxMessages returns up to limit messages that have not
+// been published yet, in the order they were written.
+func (r *Repository) GetUnpublishedOutboxMessages(ctx context.Context, limit int) ([]model.OutboxMessage, error) {
+	const query = `
+		select id, event_id, subscription_id, type, payload, created_at
+		from service.outbox_messages
+		where published_at is null
+		order by id
+		limit $1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query outbox messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []model.OutboxMessage
+	for rows.Next() {
+		var message model.OutboxMessage
+		var payload []byte
+		if err := rows.Scan(
+			&message.ID,
+			&message.EventID,
+			&message.SubscriptionID,
+			&message.Type,
+			&payload,
+			&message.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox message row: %w", err)
+		}
+		message.Payload = payload
+		messages = append(messages, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over outbox messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+func (r *Repository) MarkOutboxMessagePublished(ctx context.Context, messageID int64, publishedAt time.Time) error {
+	const query = `update service.outbox_messages set published_at = $2 where id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, messageID, publishedAt); err != nil {
+		return fmt.Errorf("failed to mark outbox message %d as published: %w", messageID, err)
+	}
+
+	return nil
+}
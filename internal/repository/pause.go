@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gymondo/internal/model"
+	"time"
+)
+
+func (r *Repository) SavePause(ctx context.Context, pause model.Pause) error {
+	const query = `
+		insert into service.subscription_pauses (id, subscription_id, start_date, end_date)
+		values ($1, $2, $3, $4)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, pause.ID, pause.SubscriptionID, pause.StartDate, nullTime(pause.EndDate))
+	if err != nil {
+		return fmt.Errorf("failed to save pause of subscription %s: %w", pause.SubscriptionID, err)
+	}
+
+	return nil
+}
+
+// EndPause ends the pause the subscription is in on the given date.
+func (r *Repository) EndPause(ctx context.Context, subscriptionID string, endDate time.Time) error {
+	const query = `
+		update service.subscription_pauses
+		set end_date = $2
+		where subscription_id = $1 and end_date is null
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, subscriptionID, endDate); err != nil {
+		return fmt.Errorf("failed to end pause of subscription %s: %w", subscriptionID, err)
+	}
+
+	return nil
+}
+
+// GetPauses returns the pauses of the subscription, oldest first.
+func (r *Repository) GetPauses(ctx context.Context, subscriptionID string) ([]model.Pause, error) {
+	const query = `
+		select id, subscription_id, start_date, end_date
+		from service.subscription_pauses
+		where subscription_id = $1
+		order by start_date
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pauses of subscription %s: %w", subscriptionID, err)
+	}
+	defer rows.Close()
+
+	var pauses []model.Pause
+	for rows.Next() {
+		var pause model.Pause
+		if err := rows.Scan(&pause.ID, &pause.SubscriptionID, &pause.StartDate, &pause.EndDate); err != nil {
+			return nil, fmt.Errorf("failed to scan pause row: %w", err)
+		}
+		pauses = append(pauses, pause)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over pauses: %w", err)
+	}
+
+	return pauses, nil
+}
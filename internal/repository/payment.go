@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gymondo/internal/model"
+)
+
+func (r *Repository) SavePaymentAttempt(ctx context.Context, attempt model.PaymentAttempt) error {
+	const query = `
+		insert into service.payment_attempts (
+			id,
+			subscription_id,
+			attempt_number,
+			amount,
+			credit_applied,
+			status,
+			transaction_id,
+			failure_reason,
+			attempted_at,
+			next_retry_date
+		) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		attempt.ID,
+		attempt.SubscriptionID,
+		attempt.AttemptNumber,
+		attempt.Amount,
+		attempt.CreditApplied,
+		attempt.Status,
+		nullString(attempt.TransactionID),
+		nullString(attempt.FailureReason),
+		attempt.AttemptedAt,
+		nullTime(attempt.NextRetryDate),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save payment attempt for subscription %s: %w", attempt.SubscriptionID, err)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetPaymentAttempts(ctx context.Context, subscriptionID string) ([]model.PaymentAttempt, error) {
+	const query = `
+		select
+			id,
+			subscription_id,
+			attempt_number,
+			amount,
+			credit_applied,
+			status,
+			coalesce(transaction_id, ''),
+			coalesce(failure_reason, ''),
+			attempted_at,
+			next_retry_date
+		from service.payment_attempts
+		where subscription_id = $1
+		order by attempted_at, attempt_number
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query payment attempts: %w", err)
+	}
+	defer rows.Close()
+
+	var attempts []model.PaymentAttempt
+	for rows.Next() {
+		var attempt model.PaymentAttempt
+		if err := rows.Scan(
+			&attempt.ID,
+			&attempt.SubscriptionID,
+			&attempt.AttemptNumber,
+			&attempt.Amount,
+			&attempt.CreditApplied,
+			&attempt.Status,
+			&attempt.TransactionID,
+			&attempt.FailureReason,
+			&attempt.AttemptedAt,
+			&attempt.NextRetryDate,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan payment attempt row: %w", err)
+		}
+		attempts = append(attempts, attempt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over payment attempts: %w", err)
+	}
+
+	return attempts, nil
+}
@@ -0,0 +1,323 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"gymondo/internal/model"
+	"time"
+)
+
+func (r *Repository) SavePriceMigration(ctx context.Context, migration model.PriceMigration) error {
+	const query = `
+		insert into service.price_migrations (
+			id,
+			product_id,
+			price,
+			tax,
+			total_price,
+			notice_days,
+			created_at
+		) values ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		migration.ID,
+		migration.ProductID,
+		migration.Price,
+		migration.Tax,
+		migration.TotalPrice,
+		migration.NoticeDays,
+		migration.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save price migration of product %s: %w", migration.ProductID, err)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetPriceMigration(ctx context.Context, migrationID string) (model.PriceMigration, error) {
+	query := `
+		select ` + priceMigrationColumns + `
+		from service.price_migrations
+		where id = $1
+	`
+
+	migration, err := scanPriceMigration(r.db.QueryRowContext(ctx, query, migrationID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return migration, fmt.Errorf("price migration with ID %s not found", migrationID)
+		}
+		return migration, fmt.Errorf("failed to query price migration %s: %w", migrationID, err)
+	}
+
+	return migration, nil
+}
+
+// GetPriceMigrationsDue returns the price migrations whose notice period has
+// passed at the given time without their product having moved to the new
+// price yet, oldest first.
+func (r *Repository) GetPriceMigrationsDue(ctx context.Context, date time.Time) ([]model.PriceMigration, error) {
+	query := `
+		select ` + priceMigrationColumns + `
+		from service.price_migrations
+		where product_price_applied_at is null
+			and created_at + notice_days * interval '1 day' <= $1
+		order by created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query price migrations due: %w", err)
+	}
+	defer rows.Close()
+
+	var migrations []model.PriceMigration
+	for rows.Next() {
+		migration, err := scanPriceMigration(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan price migration: %w", err)
+		}
+		migrations = append(migrations, migration)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate price migrations: %w", err)
+	}
+
+	return migrations, nil
+}
+
+// MarkPriceMigrationApplied stores when the product of the price migration
+// moved to the new price.
+func (r *Repository) MarkPriceMigrationApplied(ctx context.Context, migrationID string, appliedAt time.Time) error {
+	const query = `
+		update service.price_migrations
+		set product_price_applied_at = $2
+		where id = $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, migrationID, appliedAt); err != nil {
+		return fmt.Errorf("failed to mark price migration %s as applied: %w", migrationID, err)
+	}
+
+	return nil
+}
+
+const priceMigrationColumns = `
+	id,
+	product_id,
+	price,
+	tax,
+	total_price,
+	notice_days,
+	created_at,
+	product_price_applied_at
+`
+
+func scanPriceMigration(row rowScanner) (model.PriceMigration, error) {
+	var migration model.PriceMigration
+	err := row.Scan(
+		&migration.ID,
+		&migration.ProductID,
+		&migration.Price,
+		&migration.Tax,
+		&migration.TotalPrice,
+		&migration.NoticeDays,
+		&migration.CreatedAt,
+		&migration.ProductPriceAppliedAt,
+	)
+	return migration, err
+}
+
+// CountPendingPriceChanges counts the price changes of the product that are
+// scheduled or opted out and whose subscription has not ended yet.
+func (r *Repository) CountPendingPriceChanges(ctx context.Context, productID string) (int, error) {
+	const query = `
+		select count(*)
+		from service.price_changes c
+		join service.price_migrations m on m.id = c.migration_id
+		join service.subscriptions s on s.id = c.subscription_id
+		where m.product_id = $1
+			and c.status in ('scheduled', 'opted_out')
+			and s.status <> 'canceled'
+	`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, productID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count pending price changes of product %s: %w", productID, err)
+	}
+
+	return count, nil
+}
+
+func (r *Repository) SavePriceChange(ctx context.Context, change model.PriceChange) error {
+	const query = `
+		insert into service.price_changes (
+			id,
+			migration_id,
+			subscription_id,
+			user_id,
+			duration_days,
+			old_price,
+			old_tax,
+			old_total_price,
+			price,
+			tax,
+			total_price,
+			effective_date,
+			status
+		) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		change.ID,
+		change.MigrationID,
+		change.SubscriptionID,
+		change.UserID,
+		change.DurationDays,
+		change.OldPrice,
+		change.OldTax,
+		change.OldTotalPrice,
+		change.Price,
+		change.Tax,
+		change.TotalPrice,
+		change.EffectiveDate,
+		change.Status,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save price change of subscription %s: %w", change.SubscriptionID, err)
+	}
+
+	return nil
+}
+
+// UpdatePriceChange stores that a price change was applied or declined. Only
+// scheduled price changes change, so a price change declined while the
+// subscription renews is either applied or declined.
+func (r *Repository) UpdatePriceChange(ctx context.Context, change model.PriceChange) error {
+	const query = `
+		update service.price_changes
+		set status = $2, applied_at = $3, opted_out_at = $4
+		where id = $1 and status = 'scheduled'
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		change.ID,
+		change.Status,
+		nullTime(change.AppliedAt),
+		nullTime(change.OptedOutAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update price change %s: %w", change.ID, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check update of price change %s: %w", change.ID, err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("price change %s is no longer scheduled", change.ID)
+	}
+
+	return nil
+}
+
+const priceChangeColumns = `
+	c.id,
+	c.migration_id,
+	c.subscription_id,
+	c.user_id,
+	c.duration_days,
+	c.old_price,
+	c.old_tax,
+	c.old_total_price,
+	c.price,
+	c.tax,
+	c.total_price,
+	c.effective_date,
+	c.status,
+	c.applied_at,
+	c.opted_out_at
+`
+
+func scanPriceChange(row rowScanner) (model.PriceChange, error) {
+	var change model.PriceChange
+	err := row.Scan(
+		&change.ID,
+		&change.MigrationID,
+		&change.SubscriptionID,
+		&change.UserID,
+		&change.DurationDays,
+		&change.OldPrice,
+		&change.OldTax,
+		&change.OldTotalPrice,
+		&change.Price,
+		&change.Tax,
+		&change.TotalPrice,
+		&change.EffectiveDate,
+		&change.Status,
+		&change.AppliedAt,
+		&change.OptedOutAt,
+	)
+	return change, err
+}
+
+// GetPendingPriceChange returns the scheduled or opted out price change of
+// the subscription. It returns false when there is none, also when the
+// subscription moved to another product since the price change was made.
+func (r *Repository) GetPendingPriceChange(ctx context.Context, subscriptionID string) (model.PriceChange, bool, error) {
+	query := `
+		select ` + priceChangeColumns + `
+		from service.price_changes c
+		join service.price_migrations m on m.id = c.migration_id
+		join service.subscriptions s on s.id = c.subscription_id
+		where c.subscription_id = $1
+			and c.status in ('scheduled', 'opted_out')
+			and m.product_id = s.product_id
+		order by c.effective_date desc
+		limit 1
+	`
+
+	change, err := scanPriceChange(r.db.QueryRowContext(ctx, query, subscriptionID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.PriceChange{}, false, nil
+		}
+		return model.PriceChange{}, false, fmt.Errorf("failed to query price change of subscription %s: %w", subscriptionID, err)
+	}
+
+	return change, true, nil
+}
+
+// GetPriceChanges returns the price changes of the migration.
+func (r *Repository) GetPriceChanges(ctx context.Context, migrationID string) ([]model.PriceChange, error) {
+	query := `
+		select ` + priceChangeColumns + `
+		from service.price_changes c
+		where c.migration_id = $1
+		order by c.effective_date
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, migrationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query price changes of migration %s: %w", migrationID, err)
+	}
+	defer rows.Close()
+
+	var changes []model.PriceChange
+	for rows.Next() {
+		change, err := scanPriceChange(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan price change row: %w", err)
+		}
+		changes = append(changes, change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over price changes: %w", err)
+	}
+
+	return changes, nil
+}
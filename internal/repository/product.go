@@ -6,21 +6,87 @@ import (
 	"errors"
 	"fmt"
 	"gymondo/internal/model"
+	"strings"
+
+	"github.com/google/uuid"
 )
 
 type Repository struct {
-	db *sql.DB
+	db dbtx
+	// conn is nil for a repository bound to a unit of work, see WithinTx.
+	conn *sql.DB
 }
 
 func New(db *sql.DB) *Repository {
 	return &Repository{
-		db: db,
+		db:   db,
+		conn: db,
+	}
+}
+
+const productColumns = `
+	id, name, duration_days, price, tax, total_price, refund_policy, withdrawal_period_days,
+	max_pause_days, max_pauses_per_period, max_paused_days_per_year, min_active_days_between_pauses,
+	reactivation_pricing, entitlements, kind, max_members, seat_based,
+	(
+		select coalesce(string_agg(base_product_id::text, ','), '')
+		from service.product_add_ons
+		where add_on_product_id = products.id
+	) as base_product_ids
+`
+
+func scanProduct(row rowScanner) (model.Product, error) {
+	var product model.Product
+	var entitlements, baseProductIDs string
+	err := row.Scan(
+		&product.ID,
+		&product.Name,
+		&product.DurationDays,
+		&product.Price,
+		&product.Tax,
+		&product.TotalPrice,
+		&product.RefundPolicy,
+		&product.WithdrawalPeriodDays,
+		&product.PausePolicy.MaxPauseDays,
+		&product.PausePolicy.MaxPausesPerPeriod,
+		&product.PausePolicy.MaxPausedDaysPerYear,
+		&product.PausePolicy.MinActiveDaysBetweenPauses,
+		&product.ReactivationPricing,
+		&entitlements,
+		&product.Kind,
+		&product.MaxMembers,
+		&product.SeatBased,
+		&baseProductIDs,
+	)
+	if err != nil {
+		return product, err
+	}
+
+	product.Entitlements = splitEntitlements(entitlements)
+	if baseProductIDs != "" {
+		for _, id := range strings.Split(baseProductIDs, ",") {
+			baseProductID, err := uuid.Parse(id)
+			if err != nil {
+				return product, fmt.Errorf("invalid base product ID %s: %w", id, err)
+			}
+			product.BaseProductIDs = append(product.BaseProductIDs, baseProductID)
+		}
+	}
+
+	return product, nil
+}
+
+func splitEntitlements(value string) []string {
+	entitlements := make([]string, 0)
+	if value == "" {
+		return entitlements
 	}
+	return append(entitlements, strings.Split(value, ",")...)
 }
 
 func (r *Repository) GetProducts(ctx context.Context) ([]model.Product, error) {
-	const query = `
-		select id, name, duration_days, price, tax, total_price
+	query := `
+		select ` + productColumns + `
 		from service.products
 	`
 
@@ -32,15 +98,8 @@ func (r *Repository) GetProducts(ctx context.Context) ([]model.Product, error) {
 
 	var products []model.Product
 	for rows.Next() {
-		var product model.Product
-		if err := rows.Scan(
-			&product.ID,
-			&product.Name,
-			&product.DurationDays,
-			&product.Price,
-			&product.Tax,
-			&product.TotalPrice,
-		); err != nil {
+		product, err := scanProduct(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan product row: %w", err)
 		}
 		products = append(products, product)
@@ -57,21 +116,13 @@ func (r *Repository) GetProduct(
 	ctx context.Context,
 	productID string,
 ) (model.Product, error) {
-	const query = `
-		select id, name, duration_days, price, tax, total_price
+	query := `
+		select ` + productColumns + `
 		from service.products
 		where id = $1
 	`
 
-	var product model.Product
-	err := r.db.QueryRowContext(ctx, query, productID).Scan(
-		&product.ID,
-		&product.Name,
-		&product.DurationDays,
-		&product.Price,
-		&product.Tax,
-		&product.TotalPrice,
-	)
+	product, err := scanProduct(r.db.QueryRowContext(ctx, query, productID))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return product, fmt.Errorf("product with ID %s not found: %w", productID, err)
@@ -81,3 +132,18 @@ func (r *Repository) GetProduct(
 
 	return product, nil
 }
+
+// UpdateProductPrice sets the price new subscriptions to the product pay.
+func (r *Repository) UpdateProductPrice(ctx context.Context, productID string, price, tax, totalPrice float64) error {
+	const query = `
+		update service.products
+		set price = $2, tax = $3, total_price = $4
+		where id = $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, productID, price, tax, totalPrice); err != nil {
+		return fmt.Errorf("failed to update price of product %s: %w", productID, err)
+	}
+
+	return nil
+}
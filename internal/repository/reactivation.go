@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gymondo/internal/model"
+)
+
+func (r *Repository) SaveReactivation(ctx context.Context, reactivation model.Reactivation) error {
+	const query = `
+		insert into service.subscription_reactivations (
+			id,
+			subscription_id,
+			canceled_date,
+			reactivated_at,
+			pricing,
+			price,
+			tax,
+			total_price
+		) values ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		reactivation.ID,
+		reactivation.SubscriptionID,
+		reactivation.CanceledDate,
+		reactivation.ReactivatedAt,
+		reactivation.Pricing,
+		reactivation.Price,
+		reactivation.Tax,
+		reactivation.TotalPrice,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save reactivation of subscription %s: %w", reactivation.SubscriptionID, err)
+	}
+
+	return nil
+}
+
+// GetReactivations returns the reactivations of the subscription, oldest
+// first.
+func (r *Repository) GetReactivations(ctx context.Context, subscriptionID string) ([]model.Reactivation, error) {
+	const query = `
+		select id, subscription_id, canceled_date, reactivated_at, pricing, price, tax, total_price
+		from service.subscription_reactivations
+		where subscription_id = $1
+		order by reactivated_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reactivations of subscription %s: %w", subscriptionID, err)
+	}
+	defer rows.Close()
+
+	var reactivations []model.Reactivation
+	for rows.Next() {
+		var reactivation model.Reactivation
+		if err := rows.Scan(
+			&reactivation.ID,
+			&reactivation.SubscriptionID,
+			&reactivation.CanceledDate,
+			&reactivation.ReactivatedAt,
+			&reactivation.Pricing,
+			&reactivation.Price,
+			&reactivation.Tax,
+			&reactivation.TotalPrice,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan reactivation row: %w", err)
+		}
+		reactivations = append(reactivations, reactivation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over reactivations: %w", err)
+	}
+
+	return reactivations, nil
+}
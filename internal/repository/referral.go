@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"gymondo/internal/model"
+)
+
+func (r *Repository) SaveReferralCode(ctx context.Context, code model.ReferralCode) error {
+	const query = `
+		insert into service.referral_codes (user_id, code, created_at)
+		values ($1, $2, $3)
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, code.UserID, code.Code, code.CreatedAt); err != nil {
+		return fmt.Errorf("failed to save referral code of user %s: %w", code.UserID, err)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetReferralCode(ctx context.Context, code string) (model.ReferralCode, error) {
+	const query = `
+		select user_id, code, created_at
+		from service.referral_codes
+		where code = $1
+	`
+
+	var referralCode model.ReferralCode
+	err := r.db.QueryRowContext(ctx, query, code).Scan(
+		&referralCode.UserID,
+		&referralCode.Code,
+		&referralCode.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return referralCode, fmt.Errorf("referral code %s not found", code)
+		}
+		return referralCode, fmt.Errorf("failed to query referral code %s: %w", code, err)
+	}
+
+	return referralCode, nil
+}
+
+// GetUserReferralCode returns the referral code of the user. It returns
+// false when the user has none yet.
+func (r *Repository) GetUserReferralCode(ctx context.Context, userID string) (model.ReferralCode, bool, error) {
+	const query = `
+		select user_id, code, created_at
+		from service.referral_codes
+		where user_id = $1
+	`
+
+	var referralCode model.ReferralCode
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&referralCode.UserID,
+		&referralCode.Code,
+		&referralCode.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.ReferralCode{}, false, nil
+		}
+		return model.ReferralCode{}, false, fmt.Errorf("failed to query referral code of user %s: %w", userID, err)
+	}
+
+	return referralCode, true, nil
+}
+
+func (r *Repository) SaveReferral(ctx context.Context, referral model.Referral) error {
+	const query = `
+		insert into service.referrals (
+			id,
+			code,
+			referrer_id,
+			referee_id,
+			subscription_id,
+			status,
+			created_at
+		) values ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		referral.ID,
+		referral.Code,
+		referral.ReferrerID,
+		referral.RefereeID,
+		referral.SubscriptionID,
+		referral.Status,
+		referral.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save referral of user %s: %w", referral.RefereeID, err)
+	}
+
+	return nil
+}
+
+func (r *Repository) UpdateReferral(ctx context.Context, referral model.Referral) error {
+	const query = `
+		update service.referrals
+		set status = $2, settled_at = $3
+		where id = $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, referral.ID, referral.Status, nullTime(referral.SettledAt)); err != nil {
+		return fmt.Errorf("failed to update referral %s: %w", referral.ID, err)
+	}
+
+	return nil
+}
+
+const referralColumns = `
+	id,
+	code,
+	referrer_id,
+	referee_id,
+	subscription_id,
+	status,
+	created_at,
+	settled_at
+`
+
+// GetPendingReferrals returns the referrals whose rewards are not settled
+// yet, oldest first.
+func (r *Repository) GetPendingReferrals(ctx context.Context) ([]model.Referral, error) {
+	query := `
+		select ` + referralColumns + `
+		from service.referrals
+		where status = 'pending'
+		order by created_at
+	`
+
+	return r.queryReferrals(ctx, query)
+}
+
+// GetReferrals returns the referrals the user made, oldest first.
+func (r *Repository) GetReferrals(ctx context.Context, referrerID string) ([]model.Referral, error) {
+	query := `
+		select ` + referralColumns + `
+		from service.referrals
+		where referrer_id = $1
+		order by created_at
+	`
+
+	return r.queryReferrals(ctx, query, referrerID)
+}
+
+func (r *Repository) queryReferrals(ctx context.Context, query string, args ...any) ([]model.Referral, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query referrals: %w", err)
+	}
+	defer rows.Close()
+
+	var referrals []model.Referral
+	for rows.Next() {
+		var referral model.Referral
+		if err := rows.Scan(
+			&referral.ID,
+			&referral.Code,
+			&referral.ReferrerID,
+			&referral.RefereeID,
+			&referral.SubscriptionID,
+			&referral.Status,
+			&referral.CreatedAt,
+			&referral.SettledAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan referral row: %w", err)
+		}
+		referrals = append(referrals, referral)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over referrals: %w", err)
+	}
+
+	return referrals, nil
+}
+
+func (r *Repository) SaveReferralReward(ctx context.Context, reward model.ReferralReward) error {
+	const query = `
+		insert into service.referral_rewards (
+			id,
+			referral_id,
+			user_id,
+			kind,
+			credit,
+			days,
+			subscription_id,
+			created_at
+		) values ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		reward.ID,
+		reward.ReferralID,
+		reward.UserID,
+		reward.Kind,
+		reward.Credit,
+		reward.Days,
+		reward.SubscriptionID,
+		reward.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save referral reward of user %s: %w", reward.UserID, err)
+	}
+
+	return nil
+}
+
+// GetReferralRewards returns the rewards the user got, both as referrer and
+// as referee.
+func (r *Repository) GetReferralRewards(ctx context.Context, userID string) ([]model.ReferralReward, error) {
+	const query = `
+		select id, referral_id, user_id, kind, credit, days, subscription_id, created_at
+		from service.referral_rewards
+		where user_id = $1
+		order by created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query referral rewards of user %s: %w", userID, err)
+	}
+	defer rows.Close()
+
+	var rewards []model.ReferralReward
+	for rows.Next() {
+		var reward model.ReferralReward
+		if err := rows.Scan(
+			&reward.ID,
+			&reward.ReferralID,
+			&reward.UserID,
+			&reward.Kind,
+			&reward.Credit,
+			&reward.Days,
+			&reward.SubscriptionID,
+			&reward.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan referral reward row: %w", err)
+		}
+		rewards = append(rewards, reward)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over referral rewards: %w", err)
+	}
+
+	return rewards, nil
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gymondo/internal/model"
+)
+
+// SaveCreditNote stores the credit note under the next credit note number of
+// its issue year, using its own gap-free sequence.
+func (r *Repository) SaveCreditNote(ctx context.Context, creditNote model.CreditNote) (model.CreditNote, error) {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return creditNote, fmt.Errorf("failed to begin credit note transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	year := creditNote.IssueDate.Year()
+	sequenceNumber, err := nextSequenceNumber(ctx, tx, "service.credit_note_sequences", year)
+	if err != nil {
+		return creditNote, fmt.Errorf("failed to allocate credit note number for %d: %w", year, err)
+	}
+	creditNote.Number = fmt.Sprintf("CN-%d-%06d", year, sequenceNumber)
+
+	const query = `
+		insert into service.credit_notes (
+			id,
+			number,
+			year,
+			sequence_number,
+			invoice_id,
+			subscription_id,
+			user_id,
+			issue_date,
+			reason,
+			net_amount,
+			tax_amount,
+			total_amount,
+			currency
+		) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	_, err = tx.ExecContext(ctx, query,
+		creditNote.ID,
+		creditNote.Number,
+		year,
+		sequenceNumber,
+		creditNote.InvoiceID,
+		creditNote.SubscriptionID,
+		creditNote.UserID,
+		creditNote.IssueDate,
+		creditNote.Reason,
+		creditNote.NetAmount,
+		creditNote.TaxAmount,
+		creditNote.TotalAmount,
+		creditNote.Currency,
+	)
+	if err != nil {
+		return creditNote, fmt.Errorf("failed to save credit note %s: %w", creditNote.Number, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return creditNote, fmt.Errorf("failed to commit credit note %s: %w", creditNote.Number, err)
+	}
+
+	return creditNote, nil
+}
+
+func (r *Repository) SaveRefund(ctx context.Context, refund model.Refund) error {
+	const query = `
+		insert into service.refunds (
+			id,
+			subscription_id,
+			credit_note_id,
+			amount,
+			credit_amount,
+			status,
+			transaction_id,
+			failure_reason,
+			created_at
+		) values ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		refund.ID,
+		refund.SubscriptionID,
+		refund.CreditNoteID,
+		refund.Amount,
+		refund.CreditAmount,
+		refund.Status,
+		nullString(refund.TransactionID),
+		nullString(refund.FailureReason),
+		refund.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save refund for subscription %s: %w", refund.SubscriptionID, err)
+	}
+
+	return nil
+}
+
+// UpdateRefund stores the outcome of paying the refund back through the
+// payment gateway.
+func (r *Repository) UpdateRefund(ctx context.Context, refund model.Refund) error {
+	const query = `
+		update service.refunds
+		set status = $2, transaction_id = $3, failure_reason = $4
+		where id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		refund.ID,
+		refund.Status,
+		nullString(refund.TransactionID),
+		nullString(refund.FailureReason),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update refund %s: %w", refund.ID, err)
+	}
+
+	return nil
+}
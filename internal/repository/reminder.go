@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gymondo/internal/model"
+	"time"
+)
+
+// TryAdvisoryLock takes the transaction-level advisory lock with the given
+// key if no other session holds it. It must run within WithinTx; the lock is
+// released when the transaction ends.
+func (r *Repository) TryAdvisoryLock(ctx context.Context, key int64) (bool, error) {
+	var locked bool
+	if err := r.db.QueryRowContext(ctx, `select pg_try_advisory_xact_lock($1)`, key).Scan(&locked); err != nil {
+		return false, fmt.Errorf("failed to take advisory lock %d: %w", key, err)
+	}
+
+	return locked, nil
+}
+
+// GetSubscriptionsDueForTrialReminder returns active subscriptions whose
+// trial ends between from and until and whose users were not reminded of it
+// yet.
+func (r *Repository) GetSubscriptionsDueForTrialReminder(
+	ctx context.Context,
+	from time.Time,
+	until time.Time,
+) ([]model.Subscription, error) {
+	query := `
+		select ` + subscriptionSelectColumns + `
+		from service.subscriptions s
+		where status = 'active'
+			and trial_end_date >= $1 and trial_end_date <= $2
+			and not exists (
+				select 1 from service.reminders r
+				where r.subscription_id = s.id and r.kind = $3 and r.event_date = s.trial_end_date
+			)
+		order by trial_end_date
+	`
+
+	return r.querySubscriptions(ctx, query, from, until, model.NotificationTrialEnding)
+}
+
+// GetSubscriptionsDueForRenewalReminder returns active subscriptions of at
+// least minDurationDays that renew between from and until and whose users were
+// not reminded of it yet. Subscriptions still in their trial at that point
+// are left to the trial reminder.
+func (r *Repository) GetSubscriptionsDueForRenewalReminder(
+	ctx context.Context,
+	from time.Time,
+	until time.Time,
+	minDurationDays int,
+) ([]model.Subscription, error) {
+	query := `
+		select ` + subscriptionSelectColumns + `
+		from service.subscriptions s
+		where status = 'active'
+			and end_date >= $1 and end_date <= $2
+			and duration_days >= $3
+			and (trial_end_date is null or trial_end_date < end_date)
+			and not exists (
+				select 1 from service.reminders r
+				where r.subscription_id = s.id and r.kind = $4 and r.event_date = s.end_date
+			)
+		order by end_date
+	`
+
+	return r.querySubscriptions(ctx, query, from, until, minDurationDays, model.NotificationRenewalUpcoming)
+}
+
+// SaveReminder records a sent reminder. Recording the same reminder twice
+// keeps the first record.
+func (r *Repository) SaveReminder(ctx context.Context, reminder model.Reminder) error {
+	const query = `
+		insert into service.reminders (id, subscription_id, kind, event_date, sent_at)
+		values ($1, $2, $3, $4, $5)
+		on conflict (subscription_id, kind, event_date) do nothing
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		reminder.ID,
+		reminder.SubscriptionID,
+		reminder.Kind,
+		reminder.EventDate,
+		reminder.SentAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save %s reminder for subscription %s: %w", reminder.Kind, reminder.SubscriptionID, err)
+	}
+
+	return nil
+}
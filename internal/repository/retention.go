@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"gymondo/internal/model"
+
+	"github.com/google/uuid"
+)
+
+func (r *Repository) SaveCancellation(ctx context.Context, cancellation model.Cancellation) error {
+	const query = `
+		insert into service.cancellations (
+			id,
+			subscription_id,
+			reason,
+			reason_text,
+			canceled_at
+		) values ($1, $2, $3, $4, $5)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		cancellation.ID,
+		cancellation.SubscriptionID,
+		nullString(string(cancellation.Reason)),
+		nullString(cancellation.ReasonText),
+		cancellation.CanceledAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save cancellation of subscription %s: %w", cancellation.SubscriptionID, err)
+	}
+
+	return nil
+}
+
+const retentionOfferColumns = `id, reason, kind, description, pause_days, product_id, voucher_code`
+
+// GetRetentionOffers returns the active offers for the cancellation reason.
+func (r *Repository) GetRetentionOffers(
+	ctx context.Context,
+	reason model.CancellationReason,
+) ([]model.RetentionOffer, error) {
+	query := `
+		select ` + retentionOfferColumns + `
+		from service.retention_offers
+		where reason = $1 and active
+		order by kind, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, reason)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query retention offers for reason %s: %w", reason, err)
+	}
+	defer rows.Close()
+
+	var offers []model.RetentionOffer
+	for rows.Next() {
+		offer, err := scanRetentionOffer(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan retention offer row: %w", err)
+		}
+		offers = append(offers, offer)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over retention offers: %w", err)
+	}
+
+	return offers, nil
+}
+
+func (r *Repository) GetRetentionOffer(ctx context.Context, offerID string) (model.RetentionOffer, error) {
+	query := `
+		select ` + retentionOfferColumns + `
+		from service.retention_offers
+		where id = $1 and active
+	`
+
+	offer, err := scanRetentionOffer(r.db.QueryRowContext(ctx, query, offerID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return offer, fmt.Errorf("retention offer with ID %s not found", offerID)
+		}
+		return offer, fmt.Errorf("failed to query retention offer with ID %s: %w", offerID, err)
+	}
+
+	return offer, nil
+}
+
+func scanRetentionOffer(row rowScanner) (model.RetentionOffer, error) {
+	var (
+		offer       model.RetentionOffer
+		pauseDays   sql.NullInt64
+		productID   uuid.NullUUID
+		voucherCode sql.NullString
+	)
+
+	err := row.Scan(
+		&offer.ID,
+		&offer.Reason,
+		&offer.Kind,
+		&offer.Description,
+		&pauseDays,
+		&productID,
+		&voucherCode,
+	)
+	if err != nil {
+		return model.RetentionOffer{}, err
+	}
+
+	offer.PauseDays = int(pauseDays.Int64)
+	if productID.Valid {
+		offer.ProductID = &productID.UUID
+	}
+	offer.VoucherCode = voucherCode.String
+
+	return offer, nil
+}
+
+func (r *Repository) SaveRetentionOfferAcceptance(
+	ctx context.Context,
+	acceptance model.RetentionOfferAcceptance,
+) error {
+	const query = `
+		insert into service.retention_offer_acceptances (
+			id,
+			subscription_id,
+			offer_id,
+			reason,
+			kind,
+			accepted_at
+		) values ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		acceptance.ID,
+		acceptance.SubscriptionID,
+		acceptance.OfferID,
+		acceptance.Reason,
+		acceptance.Kind,
+		acceptance.AcceptedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save acceptance of retention offer %s: %w", acceptance.OfferID, err)
+	}
+
+	return nil
+}
@@ -3,34 +3,177 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"gymondo/internal/model"
 	"time"
 )
 
+const subscriptionColumns = `
+	id,
+	user_id,
+	product_id,
+	start_date,
+	end_date,
+	duration_days,
+	price,
+	tax,
+	total_price,
+	status,
+	trial_start_date,
+	trial_end_date,
+	canceled_date,
+	paused_date,
+	unpaused_date,
+	past_due_date,
+	grace_end_date,
+	next_payment_retry_date,
+	version,
+	time_zone,
+	pause_from,
+	resume_on,
+	organization_id,
+	seats
+`
+
+// subscriptionSelectColumns are the columns of a subscription together with
+// its add-ons, members and seat changes, for queries over
+// service.subscriptions aliased as s.
+const subscriptionSelectColumns = subscriptionColumns + `,
+	(
+		select coalesce(json_agg(json_build_object(
+			'id', a.id,
+			'subscription_id', a.subscription_id,
+			'product_id', a.product_id,
+			'name', p.name,
+			'price', a.price,
+			'tax', a.tax,
+			'total_price', a.total_price,
+			'status', a.status,
+			'start_date', a.start_date,
+			'canceled_date', a.canceled_date,
+			'billed_until', a.billed_until
+		) order by a.start_date), '[]')
+		from service.subscription_add_ons a
+		join service.products p on p.id = a.product_id
+		where a.subscription_id = s.id
+	) as add_ons,
+	(
+		select coalesce(json_agg(json_build_object(
+			'id', m.id,
+			'subscription_id', m.subscription_id,
+			'user_id', m.user_id,
+			'status', m.status,
+			'invited_at', m.invited_at,
+			'accepted_at', m.accepted_at
+		) order by m.invited_at), '[]')
+		from service.subscription_members m
+		where m.subscription_id = s.id and m.status <> 'removed'
+	) as members,
+	(
+		select coalesce(json_agg(json_build_object(
+			'id', c.id,
+			'subscription_id', c.subscription_id,
+			'seats_before', c.seats_before,
+			'seats_after', c.seats_after,
+			'change_date', c.change_date,
+			'price', c.price,
+			'tax', c.tax,
+			'total_price', c.total_price
+		) order by c.change_date), '[]')
+		from service.seat_changes c
+		where c.subscription_id = s.id
+	) as seat_changes
+`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSubscription(row rowScanner) (model.Subscription, error) {
+	var subscription model.Subscription
+	var addOns, members, seatChanges []byte
+	err := row.Scan(
+		&subscription.ID,
+		&subscription.UserID,
+		&subscription.ProductID,
+		&subscription.StartDate,
+		&subscription.EndDate,
+		&subscription.DurationDays,
+		&subscription.Price,
+		&subscription.Tax,
+		&subscription.TotalPrice,
+		&subscription.Status,
+		&subscription.TrialStartDate,
+		&subscription.TrialEndDate,
+		&subscription.CanceledDate,
+		&subscription.PausedDate,
+		&subscription.UnpausedDate,
+		&subscription.PastDueDate,
+		&subscription.GraceEndDate,
+		&subscription.NextPaymentRetryDate,
+		&subscription.Version,
+		&subscription.TimeZone,
+		&subscription.PauseFrom,
+		&subscription.ResumeOn,
+		&subscription.OrganizationID,
+		&subscription.Seats,
+		&addOns,
+		&members,
+		&seatChanges,
+	)
+	if err != nil {
+		return subscription, err
+	}
+
+	if err := json.Unmarshal(addOns, &subscription.AddOns); err != nil {
+		return subscription, fmt.Errorf("failed to decode add-ons of subscription %s: %w", subscription.ID, err)
+	}
+	if err := json.Unmarshal(members, &subscription.Members); err != nil {
+		return subscription, fmt.Errorf("failed to decode members of subscription %s: %w", subscription.ID, err)
+	}
+	if err := json.Unmarshal(seatChanges, &subscription.SeatChanges); err != nil {
+		return subscription, fmt.Errorf("failed to decode seat changes of subscription %s: %w", subscription.ID, err)
+	}
+
+	return subscription.InLocation(), nil
+}
+
+func (r *Repository) querySubscriptions(ctx context.Context, query string, args ...any) ([]model.Subscription, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subscriptions []model.Subscription
+	for rows.Next() {
+		subscription, err := scanSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan subscription row: %w", err)
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over subscriptions: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
 func (r *Repository) SaveSubscription(ctx context.Context, subscription model.Subscription) error {
+	return saveSubscription(ctx, r.db, subscription)
+}
+
+func saveSubscription(ctx context.Context, db dbtx, subscription model.Subscription) error {
 	query := `
-		INSERT INTO service.subscriptions (
-			id, 
-		    user_id, 
-		    product_id, 
-		    start_date,
-		    end_date,
-		    duration_days, 
-			price, 
-		    tax, 
-		    total_price, 
-		    status, 
-			trial_start_date, 
-		    trial_end_date, 
-		    canceled_date,
-		    paused_date,
-			unpaused_date
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		INSERT INTO service.subscriptions (` + subscriptionColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24)
 	`
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := db.ExecContext(ctx, query,
 		subscription.ID,
 		subscription.UserID,
 		subscription.ProductID,
@@ -46,6 +189,15 @@ func (r *Repository) SaveSubscription(ctx context.Context, subscription model.Su
 		nullTime(subscription.CanceledDate),
 		nullTime(subscription.PausedDate),
 		nullTime(subscription.UnpausedDate),
+		nullTime(subscription.PastDueDate),
+		nullTime(subscription.GraceEndDate),
+		nullTime(subscription.NextPaymentRetryDate),
+		subscription.Version,
+		subscription.TimeZone,
+		nullTime(subscription.PauseFrom),
+		nullTime(subscription.ResumeOn),
+		subscription.OrganizationID,
+		subscription.Seats,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to save subscription with ID %s: %w", subscription.ID, err)
@@ -61,46 +213,21 @@ func nullTime(t *time.Time) sql.NullTime {
 	return sql.NullTime{Valid: false}
 }
 
+func nullString(s string) sql.NullString {
+	if s != "" {
+		return sql.NullString{String: s, Valid: true}
+	}
+	return sql.NullString{Valid: false}
+}
+
 func (r *Repository) GetSubscription(ctx context.Context, subscriptionID string) (model.Subscription, error) {
 	query := `
-		select 
-			id, 
-			user_id, 
-			product_id, 
-			start_date,
-			end_date,
-			duration_days, 
-			price, 
-			tax, 
-			total_price,
-			status, 
-			trial_start_date, 
-			trial_end_date, 
-			canceled_date,
-			paused_date,
-			unpaused_date
-		from service.subscriptions 
+		select ` + subscriptionSelectColumns + `
+		from service.subscriptions s
 		where id = $1
 	`
 
-	var subscription model.Subscription
-	err := r.db.QueryRowContext(ctx, query, subscriptionID).Scan(
-		&subscription.ID,
-		&subscription.UserID,
-		&subscription.ProductID,
-		&subscription.StartDate,
-		&subscription.EndDate,
-		&subscription.DurationDays,
-		&subscription.Price,
-		&subscription.Tax,
-		&subscription.TotalPrice,
-		&subscription.Status,
-		&subscription.TrialStartDate,
-		&subscription.TrialEndDate,
-		&subscription.CanceledDate,
-		&subscription.PausedDate,
-		&subscription.UnpausedDate,
-	)
+	subscription, err := scanSubscription(r.db.QueryRowContext(ctx, query, subscriptionID))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return subscription, fmt.Errorf("subscription with ID %s not found: %w", subscriptionID, err)
@@ -111,30 +238,212 @@ func (r *Repository) GetSubscription(ctx context.Context, subscriptionID string)
 	return subscription, nil
 }
 
+// LockSubscription reads the subscription and locks its row until the
+// transaction the repository is bound to ends, so it can't be changed in the
+// meantime.
+func (r *Repository) LockSubscription(ctx context.Context, subscriptionID string) (model.Subscription, error) {
+	query := `
+		select ` + subscriptionSelectColumns + `
+		from service.subscriptions s
+		where id = $1
+		for update of s
+	`
+
+	subscription, err := scanSubscription(r.db.QueryRowContext(ctx, query, subscriptionID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return subscription, fmt.Errorf("subscription with ID %s not found: %w", subscriptionID, err)
+		}
+		return subscription, fmt.Errorf("failed to lock subscription with ID %s: %w", subscriptionID, err)
+	}
+
+	return subscription, nil
+}
+
+// GetUserSubscriptions returns every subscription of the user, oldest first.
+func (r *Repository) GetUserSubscriptions(ctx context.Context, userID string) ([]model.Subscription, error) {
+	query := `
+		select ` + subscriptionSelectColumns + `
+		from service.subscriptions s
+		where user_id = $1
+		order by start_date
+	`
+
+	return r.querySubscriptions(ctx, query, userID)
+}
+
+// GetProductSubscriptions returns the subscriptions to the product that have
+// not ended, oldest first.
+func (r *Repository) GetProductSubscriptions(ctx context.Context, productID string) ([]model.Subscription, error) {
+	query := `
+		select ` + subscriptionSelectColumns + `
+		from service.subscriptions s
+		where product_id = $1 and status <> 'canceled'
+		order by start_date
+	`
+
+	return r.querySubscriptions(ctx, query, productID)
+}
+
+// GetSubscriptionsDueForRenewal returns active subscriptions whose current
+// period has ended at or before the given time, and those whose trial has
+// ended by then without them being charged yet. Paused subscriptions are
+// never due; their end date moves back by the paused days when they resume.
+func (r *Repository) GetSubscriptionsDueForRenewal(ctx context.Context, date time.Time) ([]model.Subscription, error) {
+	query := `
+		select ` + subscriptionSelectColumns + `
+		from service.subscriptions s
+		where status = 'active'
+			and (end_date <= $1 or (trial_end_date <= $1 and start_date < trial_end_date))
+		order by end_date
+	`
+
+	return r.querySubscriptions(ctx, query, date)
+}
+
+// GetSubscriptionsDueForPaymentRetry returns past due subscriptions whose
+// next payment retry is scheduled at or before the given time.
+func (r *Repository) GetSubscriptionsDueForPaymentRetry(ctx context.Context, date time.Time) ([]model.Subscription, error) {
+	query := `
+		select ` + subscriptionSelectColumns + `
+		from service.subscriptions s
+		where status = 'past_due' and next_payment_retry_date <= $1
+		order by next_payment_retry_date
+	`
+
+	return r.querySubscriptions(ctx, query, date)
+}
+
+// GetSubscriptionsDueForPause returns active subscriptions with a scheduled
+// pause that starts at or before the given time.
+func (r *Repository) GetSubscriptionsDueForPause(ctx context.Context, date time.Time) ([]model.Subscription, error) {
+	query := `
+		select ` + subscriptionSelectColumns + `
+		from service.subscriptions s
+		where status = 'active' and pause_from <= $1
+		order by pause_from
+	`
+
+	return r.querySubscriptions(ctx, query, date)
+}
+
+// GetSubscriptionsDueForResume returns paused subscriptions whose pause ends
+// at or before the given time.
+func (r *Repository) GetSubscriptionsDueForResume(ctx context.Context, date time.Time) ([]model.Subscription, error) {
+	query := `
+		select ` + subscriptionSelectColumns + `
+		from service.subscriptions s
+		where status = 'paused' and resume_on <= $1
+		order by resume_on
+	`
+
+	return r.querySubscriptions(ctx, query, date)
+}
+
+// GetSubscriptionsDueForActivation returns scheduled subscriptions that start
+// at or before the given time.
+func (r *Repository) GetSubscriptionsDueForActivation(ctx context.Context, date time.Time) ([]model.Subscription, error) {
+	query := `
+		select ` + subscriptionSelectColumns + `
+		from service.subscriptions s
+		where status = 'scheduled' and start_date <= $1
+		order by start_date
+	`
+
+	return r.querySubscriptions(ctx, query, date)
+}
+
+// UpdateSubscription stores the subscription if it still has the version it
+// was read with and increments the stored version. Otherwise it returns
+// model.ErrSubscriptionConflict and leaves the row untouched.
 func (r *Repository) UpdateSubscription(
 	ctx context.Context,
 	subscription model.Subscription,
 ) error {
+	return updateSubscription(ctx, r.db, subscription)
+}
+
+func updateSubscription(ctx context.Context, db dbtx, subscription model.Subscription) error {
 	query := `
 		UPDATE service.subscriptions
-		SET 
+		SET
 		    status = $2,
 			canceled_date = $3,
 			paused_date = $4,
-			unpaused_date = $5
-		WHERE id = $1
+			unpaused_date = $5,
+			start_date = $6,
+			end_date = $7,
+			past_due_date = $8,
+			grace_end_date = $9,
+			next_payment_retry_date = $10,
+			pause_from = $12,
+			resume_on = $13,
+			product_id = $14,
+			duration_days = $15,
+			price = $16,
+			tax = $17,
+			total_price = $18,
+			seats = $19,
+			version = version + 1
+		WHERE id = $1 AND version = $11
 	`
 
-	_, err := r.db.ExecContext(ctx, query,
+	result, err := db.ExecContext(ctx, query,
 		subscription.ID,
 		subscription.Status,
 		subscription.CanceledDate,
 		subscription.PausedDate,
 		subscription.UnpausedDate,
+		subscription.StartDate,
+		subscription.EndDate,
+		subscription.PastDueDate,
+		subscription.GraceEndDate,
+		subscription.NextPaymentRetryDate,
+		subscription.Version,
+		subscription.PauseFrom,
+		subscription.ResumeOn,
+		subscription.ProductID,
+		subscription.DurationDays,
+		subscription.Price,
+		subscription.Tax,
+		subscription.TotalPrice,
+		subscription.Seats,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update subscription with ID %s: %w", subscription.ID, err)
 	}
 
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update subscription with ID %s: %w", subscription.ID, err)
+	}
+	if updated == 0 {
+		return fmt.Errorf("failed to update subscription with ID %s at version %d: %w",
+			subscription.ID, subscription.Version, model.ErrSubscriptionConflict)
+	}
+
+	return syncAddOnStatus(ctx, db, subscription)
+}
+
+// syncAddOnStatus pauses, resumes and cancels the add-ons of the
+// subscription together with it. Canceled add-ons stay canceled.
+func syncAddOnStatus(ctx context.Context, db dbtx, subscription model.Subscription) error {
+	const query = `
+		update service.subscription_add_ons
+		set status = $2, canceled_date = $3
+		where subscription_id = $1 and status <> 'canceled' and status <> $2
+	`
+
+	status := model.AddOnStatusOf(subscription.Status)
+	var canceledDate *time.Time
+	if status == model.AddOnCanceled {
+		canceledDate = subscription.CanceledDate
+	}
+
+	_, err := db.ExecContext(ctx, query, subscription.ID, status, canceledDate)
+	if err != nil {
+		return fmt.Errorf("failed to update add-ons of subscription %s: %w", subscription.ID, err)
+	}
+
 	return nil
 }
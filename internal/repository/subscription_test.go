@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gymondo/internal/model"
+	"testing"
+)
+
+// recordingDB records the first statement executed on it and reports the
+// given number of affected rows.
+type recordingDB struct {
+	rowsAffected int64
+	query        string
+	args         []any
+}
+
+func (d *recordingDB) ExecContext(_ context.Context, query string, args ...any) (sql.Result, error) {
+	if d.query == "" {
+		d.query = query
+		d.args = args
+	}
+	return driver.RowsAffected(d.rowsAffected), nil
+}
+
+func (d *recordingDB) QueryContext(context.Context, string, ...any) (*sql.Rows, error) {
+	return nil, nil
+}
+
+func (d *recordingDB) QueryRowContext(context.Context, string, ...any) *sql.Row {
+	return nil
+}
+
+func Test_updateSubscription(t *testing.T) {
+	t.Parallel()
+
+	subscription := model.Subscription{
+		ID:           uuid.New(),
+		ProductID:    uuid.New(),
+		Status:       model.Active,
+		DurationDays: 365,
+		Price:        99,
+		Tax:          18.81,
+		TotalPrice:   117.81,
+		Version:      4,
+	}
+
+	t.Run("product and price are written", func(t *testing.T) {
+		t.Parallel()
+
+		db := &recordingDB{rowsAffected: 1}
+
+		err := updateSubscription(context.Background(), db, subscription)
+		assert.NoError(t, err)
+		for _, column := range []string{"product_id", "duration_days", "price", "tax", "total_price"} {
+			assert.Contains(t, db.query, column+" = $")
+		}
+		assert.Contains(t, db.args, subscription.ProductID)
+		assert.Contains(t, db.args, subscription.DurationDays)
+		assert.Contains(t, db.args, subscription.Price)
+		assert.Contains(t, db.args, subscription.Tax)
+		assert.Contains(t, db.args, subscription.TotalPrice)
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		t.Parallel()
+
+		db := &recordingDB{rowsAffected: 0}
+
+		err := updateSubscription(context.Background(), db, subscription)
+		assert.ErrorIs(t, err, model.ErrSubscriptionConflict)
+	})
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"gymondo/internal/service"
+)
+
+// dbtx is implemented by both *sql.DB and *sql.Tx, so every query runs the
+// same way on its own or as part of a unit of work.
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type txn interface {
+	dbtx
+	Commit() error
+	Rollback() error
+}
+
+// joinedTx runs the statements of a multi-statement write on the transaction
+// of the surrounding unit of work, which alone decides about commit and
+// rollback.
+type joinedTx struct {
+	dbtx
+}
+
+func (joinedTx) Commit() error   { return nil }
+func (joinedTx) Rollback() error { return nil }
+
+// begin starts a transaction for a write that spans several statements, or
+// joins the unit of work the repository is bound to.
+func (r *Repository) begin(ctx context.Context) (txn, error) {
+	if r.conn == nil {
+		return joinedTx{r.db}, nil
+	}
+
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// WithinTx runs fn with a repository bound to a single transaction, which is
+// committed when fn returns nil and rolled back otherwise. Calls on a
+// repository that is already bound to a transaction join it.
+func (r *Repository) WithinTx(ctx context.Context, fn func(repo service.Repository) error) error {
+	if r.conn == nil {
+		return fn(r)
+	}
+
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(&Repository{db: tx}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -13,7 +13,7 @@ func (r *Repository) GetUser(
 	userID string,
 ) (model.User, error) {
 	const query = `
-		select id, first_name, second_name, email
+		select id, first_name, second_name, email, locale, time_zone
 		from service.users
 		where id = $1
 	`
@@ -24,6 +24,8 @@ func (r *Repository) GetUser(
 		&user.FirstName,
 		&user.SecondName,
 		&user.Email,
+		&user.Locale,
+		&user.TimeZone,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -0,0 +1,370 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"gymondo/internal/model"
+	"strings"
+	"time"
+)
+
+func (r *Repository) SaveWebhookEndpoint(ctx context.Context, endpoint model.WebhookEndpoint) error {
+	const query = `
+		insert into service.webhook_endpoints (
+			id,
+			url,
+			secret,
+			event_types,
+			active,
+			created_at
+		) values ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		endpoint.ID,
+		endpoint.URL,
+		endpoint.Secret,
+		joinEventTypes(endpoint.EventTypes),
+		endpoint.Active,
+		endpoint.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save webhook endpoint %s: %w", endpoint.ID, err)
+	}
+
+	return nil
+}
+
+// event types are stored as a comma separated list, an empty string
+// subscribes the endpoint to every event
+func joinEventTypes(eventTypes []model.WebhookEventType) string {
+	types := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		types = append(types, string(eventType))
+	}
+	return strings.Join(types, ",")
+}
+
+func splitEventTypes(value string) []model.WebhookEventType {
+	eventTypes := make([]model.WebhookEventType, 0)
+	if value == "" {
+		return eventTypes
+	}
+	for _, eventType := range strings.Split(value, ",") {
+		eventTypes = append(eventTypes, model.WebhookEventType(eventType))
+	}
+	return eventTypes
+}
+
+const webhookEndpointColumns = `
+	id,
+	url,
+	secret,
+	event_types,
+	active,
+	created_at
+`
+
+func scanWebhookEndpoint(row rowScanner) (model.WebhookEndpoint, error) {
+	var endpoint model.WebhookEndpoint
+	var eventTypes string
+	err := row.Scan(
+		&endpoint.ID,
+		&endpoint.URL,
+		&endpoint.Secret,
+		&eventTypes,
+		&endpoint.Active,
+		&endpoint.CreatedAt,
+	)
+	endpoint.EventTypes = splitEventTypes(eventTypes)
+	return endpoint, err
+}
+
+func (r *Repository) GetWebhookEndpoint(ctx context.Context, endpointID string) (model.WebhookEndpoint, error) {
+	query := `
+		select ` + webhookEndpointColumns + `
+		from service.webhook_endpoints
+		where id = $1
+	`
+
+	endpoint, err := scanWebhookEndpoint(r.db.QueryRowContext(ctx, query, endpointID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return endpoint, fmt.Errorf("webhook endpoint with ID %s not found: %w", endpointID, err)
+		}
+		return endpoint, fmt.Errorf("failed to retrieve webhook endpoint with ID %s: %w", endpointID, err)
+	}
+
+	return endpoint, nil
+}
+
+func (r *Repository) GetWebhookEndpoints(ctx context.Context) ([]model.WebhookEndpoint, error) {
+	query := `
+		select ` + webhookEndpointColumns + `
+		from service.webhook_endpoints
+		order by created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook endpoints: %w", err)
+	}
+	defer rows.Close()
+
+	var endpoints []model.WebhookEndpoint
+	for rows.Next() {
+		endpoint, err := scanWebhookEndpoint(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook endpoint row: %w", err)
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over webhook endpoints: %w", err)
+	}
+
+	return endpoints, nil
+}
+
+func (r *Repository) DeactivateWebhookEndpoint(ctx context.Context, endpointID string) error {
+	const query = `update service.webhook_endpoints set active = false where id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, endpointID)
+	if err != nil {
+		return fmt.Errorf("failed to deactivate webhook endpoint %s: %w", endpointID, err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return fmt.Errorf("webhook endpoint with ID %s not found: %w", endpointID, sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+// SaveWebhookEvent stores the event together with one pending delivery per
+// receiving endpoint, so an event is never stored without its deliveries.
+// Saving an event that already exists is a no-op, which keeps republished
+// outbox messages from being delivered twice.
+func (r *Repository) SaveWebhookEvent(
+	ctx context.Context,
+	event model.WebhookEvent,
+	deliveries []model.WebhookDelivery,
+) error {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin webhook event transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	const eventQuery = `
+		insert into service.webhook_events (
+			id,
+			type,
+			subscription_id,
+			payload,
+			created_at
+		) values ($1, $2, $3, $4, $5)
+		on conflict (id) do nothing
+	`
+
+	result, err := tx.ExecContext(ctx, eventQuery,
+		event.ID,
+		event.Type,
+		event.SubscriptionID,
+		string(event.Payload),
+		event.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save webhook event %s: %w", event.ID, err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return nil
+	}
+
+	const deliveryQuery = `
+		insert into service.webhook_deliveries (
+			id,
+			event_id,
+			endpoint_id,
+			status,
+			attempts,
+			next_attempt_at,
+			created_at
+		) values ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	for _, delivery := range deliveries {
+		_, err := tx.ExecContext(ctx, deliveryQuery,
+			delivery.ID,
+			delivery.EventID,
+			delivery.EndpointID,
+			delivery.Status,
+			delivery.Attempts,
+			nullTime(delivery.NextAttemptAt),
+			delivery.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save webhook delivery %s: %w", delivery.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit webhook event %s: %w", event.ID, err)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetWebhookEvent(ctx context.Context, eventID string) (model.WebhookEvent, error) {
+	const query = `
+		select id, type, subscription_id, payload, created_at
+		from service.webhook_events
+		where id = $1
+	`
+
+	var event model.WebhookEvent
+	var payload []byte
+	err := r.db.QueryRowContext(ctx, query, eventID).Scan(
+		&event.ID,
+		&event.Type,
+		&event.SubscriptionID,
+		&payload,
+		&event.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return event, fmt.Errorf("webhook event with ID %s not found: %w", eventID, err)
+		}
+		return event, fmt.Errorf("failed to retrieve webhook event with ID %s: %w", eventID, err)
+	}
+	event.Payload = payload
+
+	return event, nil
+}
+
+const webhookDeliveryColumns = `
+	id,
+	event_id,
+	endpoint_id,
+	status,
+	attempts,
+	next_attempt_at,
+	coalesce(last_error, ''),
+	delivered_at,
+	created_at
+`
+
+func scanWebhookDelivery(row rowScanner) (model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.EventID,
+		&delivery.EndpointID,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&delivery.LastError,
+		&delivery.DeliveredAt,
+		&delivery.CreatedAt,
+	)
+	return delivery, err
+}
+
+func (r *Repository) queryWebhookDeliveries(ctx context.Context, query string, args ...any) ([]model.WebhookDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []model.WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery row: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate over webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (r *Repository) GetWebhookDelivery(ctx context.Context, deliveryID string) (model.WebhookDelivery, error) {
+	query := `
+		select ` + webhookDeliveryColumns + `
+		from service.webhook_deliveries
+		where id = $1
+	`
+
+	delivery, err := scanWebhookDelivery(r.db.QueryRowContext(ctx, query, deliveryID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return delivery, fmt.Errorf("webhook delivery with ID %s not found: %w", deliveryID, err)
+		}
+		return delivery, fmt.Errorf("failed to retrieve webhook delivery with ID %s: %w", deliveryID, err)
+	}
+
+	return delivery, nil
+}
+
+// GetWebhookDeliveries returns the deliveries with the given status, newest
+// first. An empty status returns all deliveries.
+func (r *Repository) GetWebhookDeliveries(
+	ctx context.Context,
+	status model.WebhookDeliveryStatus,
+) ([]model.WebhookDelivery, error) {
+	query := `
+		select ` + webhookDeliveryColumns + `
+		from service.webhook_deliveries
+		where $1 = '' or status::text = $1
+		order by created_at desc
+	`
+
+	return r.queryWebhookDeliveries(ctx, query, string(status))
+}
+
+// GetWebhookDeliveriesDue returns pending deliveries whose next attempt is
+// scheduled at or before the given time, oldest event first.
+func (r *Repository) GetWebhookDeliveriesDue(ctx context.Context, now time.Time) ([]model.WebhookDelivery, error) {
+	query := `
+		select ` + webhookDeliveryColumns + `
+		from service.webhook_deliveries
+		where status = 'pending' and next_attempt_at <= $1
+		order by created_at
+	`
+
+	return r.queryWebhookDeliveries(ctx, query, now)
+}
+
+func (r *Repository) UpdateWebhookDelivery(ctx context.Context, delivery model.WebhookDelivery) error {
+	const query = `
+		update service.webhook_deliveries
+		set
+			status = $2,
+			attempts = $3,
+			next_attempt_at = $4,
+			last_error = $5,
+			delivered_at = $6
+		where id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		delivery.ID,
+		delivery.Status,
+		delivery.Attempts,
+		nullTime(delivery.NextAttemptAt),
+		nullString(delivery.LastError),
+		nullTime(delivery.DeliveredAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery %s: %w", delivery.ID, err)
+	}
+
+	return nil
+}
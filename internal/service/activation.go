@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"gymondo/internal/model"
+)
+
+// ActivateScheduledSubscriptions starts the scheduled subscriptions whose
+// start date has come. A subscription that can't be activated now, e.g.
+// because it was changed concurrently, is picked up again on the next run.
+func (s *Service) ActivateScheduledSubscriptions(ctx context.Context) error {
+	subscriptions, err := s.repository.GetSubscriptionsDueForActivation(ctx, s.now())
+	if err != nil {
+		return fmt.Errorf("failed to fetch subscriptions due for activation: %w", err)
+	}
+
+	for _, subscription := range subscriptions {
+		if err := s.activateSubscription(ctx, subscription); err != nil {
+			log.Printf("Error activating subscription %s: %v", subscription.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// activateSubscription charges the first period of the scheduled
+// subscription, unless it starts with a trial, and makes it active. A
+// declined charge cancels it instead: nothing was paid yet, so there is no
+// access to keep up while the payment is retried. The charge is made while
+// the subscription is locked, so a concurrent change can't discard it.
+func (s *Service) activateSubscription(ctx context.Context, subscription model.Subscription) error {
+	subscription.Status = model.Active
+
+	if subscription.TrialEndDate != nil {
+		if err := s.updateSubscriptionWithEvent(ctx, model.SubscriptionActivated, subscription); err != nil {
+			return fmt.Errorf("failed to activate subscription: %w", err)
+		}
+		return nil
+	}
+
+	var attempt model.PaymentAttempt
+	err := s.withinTx(ctx, func(tx *Service) error {
+		if err := tx.lockSubscription(ctx, subscription); err != nil {
+			return err
+		}
+
+		var err error
+		attempt, err = tx.attemptPayment(ctx, subscription, subscription.TotalPrice, 1)
+		if err != nil {
+			return err
+		}
+
+		eventType := model.SubscriptionActivated
+		if attempt.Status == model.PaymentFailed {
+			canceledDate := tx.today(subscription.Location())
+			subscription.Status = model.Canceled
+			subscription.CanceledDate = &canceledDate
+			eventType = model.SubscriptionCanceled
+		}
+
+		if err := tx.savePaymentAttempt(ctx, attempt); err != nil {
+			return err
+		}
+		if err := tx.updateSubscriptionWithEvent(ctx, eventType, subscription); err != nil {
+			return fmt.Errorf("failed to update subscription: %w", err)
+		}
+		if attempt.Status != model.PaymentSucceeded {
+			return nil
+		}
+		if err := tx.issueRenewalInvoice(ctx, subscription); err != nil {
+			return fmt.Errorf("failed to issue invoice: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.notifyDeclinedPayment(ctx, subscription, attempt)
+
+	return nil
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gymondo/internal/clock"
+	"gymondo/internal/model"
+	"testing"
+	"time"
+)
+
+func Test_Service_ActivateScheduledSubscriptions(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.January, 1, 6, 0, 0, 0, time.UTC)
+	startDate := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	newScheduled := func() model.Subscription {
+		return model.Subscription{
+			ID:           uuid.New(),
+			UserID:       uuid.New(),
+			ProductID:    uuid.New(),
+			StartDate:    startDate,
+			EndDate:      startDate.AddDate(0, 0, 30),
+			DurationDays: 30,
+			Price:        10,
+			Tax:          1,
+			TotalPrice:   11,
+			Status:       model.Scheduled,
+			Version:      1,
+			TimeZone:     "UTC",
+			Seats:        1,
+		}
+	}
+
+	t.Run("failed to fetch subscriptions", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now)}
+
+		mockRepo.EXPECT().GetSubscriptionsDueForActivation(gomock.Any(), now).Return(nil, fmt.Errorf("database error"))
+
+		err := service.ActivateScheduledSubscriptions(context.Background())
+		assert.ErrorContains(t, err, "failed to fetch subscriptions due for activation")
+	})
+
+	t.Run("first period is charged and invoiced", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockPayments := NewMockPaymentGateway(ctrl)
+		service := &Service{repository: mockRepo, payments: mockPayments, clock: clock.Fixed(now)}
+
+		subscription := newScheduled()
+
+		mockRepo.EXPECT().GetSubscriptionsDueForActivation(gomock.Any(), now).Return([]model.Subscription{subscription}, nil)
+		mockRepo.EXPECT().GetCreditBalance(gomock.Any(), subscription.UserID.String()).Return(0.0, nil)
+		mockPayments.EXPECT().Charge(gomock.Any(), subscription.UserID, 11.0, gomock.Any()).Return("tx-1", nil)
+		expectWithinTx(mockRepo)
+		expectLockSubscription(mockRepo, subscription)
+		mockRepo.EXPECT().SavePaymentAttempt(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, attempt model.PaymentAttempt) error {
+				assert.Equal(t, model.PaymentSucceeded, attempt.Status)
+				assert.Equal(t, "tx-1", attempt.TransactionID)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, activated model.Subscription) error {
+				assert.Equal(t, model.Active, activated.Status)
+				assert.Equal(t, startDate, activated.StartDate)
+				assert.Equal(t, startDate.AddDate(0, 0, 30), activated.EndDate)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, message model.OutboxMessage) error {
+				assert.Equal(t, model.SubscriptionActivated, message.Type)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().GetUser(gomock.Any(), subscription.UserID.String()).Return(model.User{ID: subscription.UserID}, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), subscription.ProductID.String()).Return(model.Product{ID: subscription.ProductID, Name: "basic plan"}, nil)
+		mockRepo.EXPECT().SaveInvoice(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, invoice model.Invoice) (model.Invoice, error) {
+				assert.Equal(t, startDate, invoice.PeriodStart)
+				assert.Equal(t, startDate.AddDate(0, 0, 30), invoice.PeriodEnd)
+				assert.Equal(t, 11.0, invoice.TotalAmount)
+				return invoice, nil
+			},
+		)
+
+		err := service.ActivateScheduledSubscriptions(context.Background())
+		assert.NoError(t, err)
+	})
+
+	t.Run("trial starts without a charge", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now)}
+
+		subscription := newScheduled()
+		trialEndDate := startDate.AddDate(0, 0, 30)
+		subscription.TrialStartDate = &startDate
+		subscription.TrialEndDate = &trialEndDate
+
+		mockRepo.EXPECT().GetSubscriptionsDueForActivation(gomock.Any(), now).Return([]model.Subscription{subscription}, nil)
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, activated model.Subscription) error {
+				assert.Equal(t, model.Active, activated.Status)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, message model.OutboxMessage) error {
+				assert.Equal(t, model.SubscriptionActivated, message.Type)
+
+				var payload webhookPayload
+				assert.NoError(t, json.Unmarshal(message.Payload, &payload))
+				assert.Equal(t, model.Active, payload.Data.Subscription.Status)
+				assert.Equal(t, 2, payload.Data.Subscription.Version)
+				return nil
+			},
+		)
+
+		err := service.ActivateScheduledSubscriptions(context.Background())
+		assert.NoError(t, err)
+	})
+
+	t.Run("declined charge cancels the subscription", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockPayments := NewMockPaymentGateway(ctrl)
+		mockNotifier := NewMockNotifier(ctrl)
+		service := &Service{
+			repository: mockRepo,
+			payments:   mockPayments,
+			notifier:   mockNotifier,
+			clock:      clock.Fixed(now),
+			config:     DefaultConfig(),
+		}
+
+		subscription := newScheduled()
+
+		mockRepo.EXPECT().GetSubscriptionsDueForActivation(gomock.Any(), now).Return([]model.Subscription{subscription}, nil)
+		mockRepo.EXPECT().GetCreditBalance(gomock.Any(), subscription.UserID.String()).Return(0.0, nil)
+		mockPayments.EXPECT().Charge(gomock.Any(), subscription.UserID, 11.0, gomock.Any()).Return("", errors.New("card declined"))
+		expectWithinTx(mockRepo)
+		expectLockSubscription(mockRepo, subscription)
+		mockRepo.EXPECT().SavePaymentAttempt(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, attempt model.PaymentAttempt) error {
+				assert.Equal(t, model.PaymentFailed, attempt.Status)
+				assert.Nil(t, attempt.NextRetryDate)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, canceled model.Subscription) error {
+				assert.Equal(t, model.Canceled, canceled.Status)
+				assert.Equal(t, startDate, *canceled.CanceledDate)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, message model.OutboxMessage) error {
+				assert.Equal(t, model.SubscriptionCanceled, message.Type)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().GetUser(gomock.Any(), subscription.UserID.String()).Return(model.User{ID: subscription.UserID}, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), subscription.ProductID.String()).Return(model.Product{ID: subscription.ProductID}, nil)
+		mockNotifier.EXPECT().Notify(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, notification model.Notification) error {
+				assert.Equal(t, model.NotificationCancellationConfirmation, notification.Kind)
+				return nil
+			},
+		)
+
+		err := service.ActivateScheduledSubscriptions(context.Background())
+		assert.NoError(t, err)
+	})
+}
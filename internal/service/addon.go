@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"gymondo/internal/model"
+)
+
+// AttachAddOn adds the add-on product to an active subscription to one of
+// its base products. The add-on is priced per period of the subscription and
+// billed with its next renewal, together with the prorated days until then.
+func (s *Service) AttachAddOn(
+	ctx context.Context,
+	subscriptionID string,
+	version int,
+	productID string,
+) (model.SubscriptionAddOn, error) {
+	subscription, err := s.repository.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		return model.SubscriptionAddOn{}, fmt.Errorf("failed to find subscription with ID %s: %w", subscriptionID, err)
+	}
+	if err := checkVersion(subscription, version); err != nil {
+		return model.SubscriptionAddOn{}, err
+	}
+	if subscription.Status != model.Active {
+		return model.SubscriptionAddOn{}, fmt.Errorf("add-ons can only be attached to active subscriptions")
+	}
+
+	product, err := s.repository.GetProduct(ctx, productID)
+	if err != nil {
+		return model.SubscriptionAddOn{}, fmt.Errorf("failed to fetch product: %w", err)
+	}
+	if product.Kind != model.AddOnProduct {
+		return model.SubscriptionAddOn{}, fmt.Errorf("product %s is not an add-on", productID)
+	}
+	if !product.CompatibleWith(subscription.ProductID) {
+		return model.SubscriptionAddOn{}, fmt.Errorf("add-on %s can't be attached to product %s", product.Name, subscription.ProductID)
+	}
+	for _, addOn := range subscription.AddOns {
+		if addOn.ProductID == product.ID && addOn.Status != model.AddOnCanceled {
+			return model.SubscriptionAddOn{}, fmt.Errorf("add-on %s is already attached", product.Name)
+		}
+	}
+
+	// the add-on's price is for its own duration, scaled to the period of the
+	// subscription it is billed with
+	periodShare := float64(subscription.DurationDays) / float64(product.DurationDays)
+	price := math.Round(product.Price*periodShare*100) / 100
+	tax := math.Round(product.Tax*periodShare*100) / 100
+
+	addOn := model.SubscriptionAddOn{
+		ID:             uuid.New(),
+		SubscriptionID: subscription.ID,
+		ProductID:      product.ID,
+		Name:           product.Name,
+		Price:          price,
+		Tax:            tax,
+		TotalPrice:     math.Round((price+tax)*100) / 100,
+		Status:         model.AddOnActive,
+		StartDate:      s.today(subscription.Location()),
+	}
+	subscription.AddOns = append(subscription.AddOns, addOn)
+
+	err = s.withinTx(ctx, func(tx *Service) error {
+		if err := tx.repository.SaveSubscriptionAddOn(ctx, addOn); err != nil {
+			return err
+		}
+		return tx.updateSubscriptionWithEvent(ctx, model.SubscriptionUpdated, subscription)
+	})
+	if err != nil {
+		return model.SubscriptionAddOn{}, fmt.Errorf("failed to attach add-on: %w", err)
+	}
+
+	return addOn, nil
+}
+
+// DetachAddOn cancels the add-on right away. When the add-on was paid for
+// the current period, its unused days are credited to the user and the
+// credited amount is returned. When it wasn't, the days it was used are
+// billed with the next renewal.
+func (s *Service) DetachAddOn(ctx context.Context, subscriptionID string, version int, addOnID string) (float64, error) {
+	subscription, err := s.repository.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find subscription with ID %s: %w", subscriptionID, err)
+	}
+	if err := checkVersion(subscription, version); err != nil {
+		return 0, err
+	}
+
+	addOn, ok := subscription.AddOn(addOnID)
+	if !ok || addOn.Status == model.AddOnCanceled {
+		return 0, fmt.Errorf("subscription has no add-on %s", addOnID)
+	}
+
+	today := s.today(subscription.Location())
+	credit := unusedAddOnCredit(subscription, addOn, today)
+
+	addOn.Status = model.AddOnCanceled
+	addOn.CanceledDate = &today
+	for i := range subscription.AddOns {
+		if subscription.AddOns[i].ID == addOn.ID {
+			subscription.AddOns[i] = addOn
+		}
+	}
+
+	err = s.withinTx(ctx, func(tx *Service) error {
+		if err := tx.repository.UpdateSubscriptionAddOn(ctx, addOn); err != nil {
+			return err
+		}
+
+		if credit > 0 {
+			transaction := s.newCreditTransaction(
+				subscription.UserID,
+				model.CreditProration,
+				model.ProrationsAccount,
+				credit,
+				fmt.Sprintf("Unused days of add-on %s", addOn.Name),
+			)
+			transaction.SubscriptionID = &subscription.ID
+			if err := tx.repository.SaveCreditTransaction(ctx, transaction); err != nil {
+				return fmt.Errorf("failed to credit unused days: %w", err)
+			}
+		}
+
+		return tx.updateSubscriptionWithEvent(ctx, model.SubscriptionUpdated, subscription)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to detach add-on: %w", err)
+	}
+
+	return credit, nil
+}
+
+// unusedAddOnCredit returns the share of the add-on's price for the days
+// left in the current period, if the add-on was charged for that period.
+// Add-ons attached during the period or the trial haven't been paid yet.
+func unusedAddOnCredit(subscription model.Subscription, addOn model.SubscriptionAddOn, today time.Time) float64 {
+	if subscription.Status != model.Active || addOn.BilledUntil == nil || addOn.BilledUntil.Before(subscription.EndDate) {
+		return 0
+	}
+
+	unusedDays := daysBetween(today, subscription.EndDate)
+	if unusedDays <= 0 || subscription.DurationDays <= 0 {
+		return 0
+	}
+
+	share := math.Min(float64(unusedDays)/float64(subscription.DurationDays), 1)
+	return math.Floor(addOn.TotalPrice*share*100) / 100
+}
+
+// addOnLineItems returns what the add-ons of the subscription add to the
+// invoice of the period starting at periodStart: each add-on for that
+// period, and the prorated days before it that each was used without being
+// charged for them, up to its detaching if it was detached.
+func addOnLineItems(subscription model.Subscription, periodStart time.Time) []model.InvoiceLineItem {
+	periodEnd := periodStart.AddDate(0, 0, subscription.DurationDays)
+
+	var lineItems []model.InvoiceLineItem
+	for _, addOn := range subscription.AddOns {
+		if addOn.StartDate.After(periodStart) {
+			continue
+		}
+
+		if from, until, ok := unbilledAddOnDays(subscription, addOn, periodStart); ok {
+			share := float64(daysBetween(from, until)) / float64(subscription.DurationDays)
+			price := math.Round(addOn.Price*share*100) / 100
+			tax := math.Round(addOn.Tax*share*100) / 100
+			lineItems = append(lineItems, model.InvoiceLineItem{
+				Description: fmt.Sprintf("%s (%s - %s, prorated)", addOn.Name,
+					from.Format("02.01.2006"), until.Format("02.01.2006")),
+				Quantity:   1,
+				UnitPrice:  price,
+				Tax:        tax,
+				TotalPrice: math.Round((price+tax)*100) / 100,
+			})
+		}
+
+		if addOn.Status == model.AddOnCanceled {
+			continue
+		}
+
+		lineItems = append(lineItems, model.InvoiceLineItem{
+			Description: fmt.Sprintf("%s (%s - %s)", addOn.Name,
+				periodStart.Format("02.01.2006"), periodEnd.Format("02.01.2006")),
+			Quantity:   1,
+			UnitPrice:  addOn.Price,
+			Tax:        addOn.Tax,
+			TotalPrice: addOn.TotalPrice,
+		})
+	}
+
+	return lineItems
+}
+
+// unbilledAddOnDays returns the days before periodStart the add-on was used
+// without being charged for them: from the day it was attached or last
+// charged up to, until periodStart or the day it was detached. Days used
+// during the trial are free.
+func unbilledAddOnDays(
+	subscription model.Subscription,
+	addOn model.SubscriptionAddOn,
+	periodStart time.Time,
+) (time.Time, time.Time, bool) {
+	from := addOn.StartDate
+	if addOn.BilledUntil != nil && addOn.BilledUntil.After(from) {
+		from = *addOn.BilledUntil
+	}
+	if subscription.TrialEndDate != nil && subscription.TrialEndDate.After(from) {
+		from = *subscription.TrialEndDate
+	}
+
+	until := periodStart
+	if addOn.Status == model.AddOnCanceled {
+		if addOn.CanceledDate == nil {
+			return from, until, false
+		}
+		if addOn.CanceledDate.Before(until) {
+			until = *addOn.CanceledDate
+		}
+	}
+
+	return from, until, from.Before(until)
+}
+
+// billAddOns records that the add-ons were charged with the invoice of the
+// period starting at periodStart: active ones up to the end of that period,
+// detached ones up to their detaching. It returns the add-ons it changed.
+func billAddOns(subscription *model.Subscription, periodStart time.Time) []model.SubscriptionAddOn {
+	periodEnd := periodStart.AddDate(0, 0, subscription.DurationDays)
+
+	var billed []model.SubscriptionAddOn
+	for i, addOn := range subscription.AddOns {
+		if addOn.StartDate.After(periodStart) {
+			continue
+		}
+
+		billedUntil := periodEnd
+		if addOn.Status == model.AddOnCanceled {
+			_, until, ok := unbilledAddOnDays(*subscription, addOn, periodStart)
+			if !ok {
+				continue
+			}
+			billedUntil = until
+		}
+
+		addOn.BilledUntil = &billedUntil
+		subscription.AddOns[i] = addOn
+		billed = append(billed, addOn)
+	}
+
+	return billed
+}
+
+// saveBilledAddOns stores which add-ons the invoice of the subscription's
+// current period charged.
+func (s *Service) saveBilledAddOns(ctx context.Context, subscription *model.Subscription) error {
+	for _, addOn := range billAddOns(subscription, subscription.StartDate) {
+		if err := s.repository.UpdateSubscriptionAddOn(ctx, addOn); err != nil {
+			return fmt.Errorf("failed to record billing of add-on %s: %w", addOn.ID, err)
+		}
+	}
+	return nil
+}
+
+// renewalTotal is what renewing the subscription charges: its own price and
+// that of its add-ons for the next period, and the seats added during the
+// current one.
+func renewalTotal(subscription model.Subscription) float64 {
+	return periodTotal(subscription, subscription.EndDate)
+}
+
+// periodTotal is what the invoice of the period starting at periodStart
+// charges: the subscription's own price, its add-ons and the seats added
+// before it. It is never negative.
+func periodTotal(subscription model.Subscription, periodStart time.Time) float64 {
+	total := subscription.TotalPrice
+	lineItems := append(
+		addOnLineItems(subscription, periodStart),
+		seatLineItems(subscription, periodStart)...,
+	)
+	for _, lineItem := range lineItems {
+		total += lineItem.TotalPrice
+	}
+	return math.Max(0, math.Round(total*100)/100)
+}
@@ -0,0 +1,374 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gymondo/internal/clock"
+	"gymondo/internal/model"
+	"testing"
+	"time"
+)
+
+func Test_Service_AttachAddOn(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.June, 20, 10, 0, 0, 0, time.UTC)
+	today := model.StartOfDay(now, time.UTC)
+	standardPlanID := uuid.New()
+
+	nutritionCoaching := model.Product{
+		ID:             uuid.New(),
+		Name:           "nutrition coaching",
+		DurationDays:   30,
+		Price:          5,
+		Tax:            0.5,
+		TotalPrice:     5.5,
+		Kind:           model.AddOnProduct,
+		BaseProductIDs: []uuid.UUID{standardPlanID},
+	}
+
+	activeSubscription := func() model.Subscription {
+		return model.Subscription{
+			ID:           uuid.New(),
+			UserID:       uuid.New(),
+			ProductID:    standardPlanID,
+			StartDate:    today.AddDate(0, 0, -20),
+			EndDate:      today.AddDate(0, 0, 40),
+			DurationDays: 60,
+			Status:       model.Active,
+		}
+	}
+
+	t.Run("priced per period of the subscription", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		subscription := activeSubscription()
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), nutritionCoaching.ID.String()).Return(nutritionCoaching, nil)
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().SaveSubscriptionAddOn(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, updated model.Subscription) error {
+				assert.Len(t, updated.AddOns, 1)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, message model.OutboxMessage) error {
+				assert.Equal(t, model.SubscriptionUpdated, message.Type)
+				return nil
+			},
+		)
+
+		addOn, err := service.AttachAddOn(context.Background(), subscription.ID.String(), 0, nutritionCoaching.ID.String())
+		assert.NoError(t, err)
+		assert.Equal(t, subscription.ID, addOn.SubscriptionID)
+		assert.Equal(t, "nutrition coaching", addOn.Name)
+		assert.Equal(t, 10.0, addOn.Price)
+		assert.Equal(t, 1.0, addOn.Tax)
+		assert.Equal(t, 11.0, addOn.TotalPrice)
+		assert.Equal(t, model.AddOnActive, addOn.Status)
+		assert.Equal(t, today, addOn.StartDate)
+	})
+
+	t.Run("incompatible base product", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		subscription := activeSubscription()
+		subscription.ProductID = uuid.New()
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), nutritionCoaching.ID.String()).Return(nutritionCoaching, nil)
+
+		_, err := service.AttachAddOn(context.Background(), subscription.ID.String(), 0, nutritionCoaching.ID.String())
+		assert.ErrorContains(t, err, "can't be attached to product")
+	})
+
+	t.Run("already attached", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		subscription := activeSubscription()
+		subscription.AddOns = []model.SubscriptionAddOn{
+			{ID: uuid.New(), ProductID: nutritionCoaching.ID, Status: model.AddOnActive},
+		}
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), nutritionCoaching.ID.String()).Return(nutritionCoaching, nil)
+
+		_, err := service.AttachAddOn(context.Background(), subscription.ID.String(), 0, nutritionCoaching.ID.String())
+		assert.EqualError(t, err, "add-on nutrition coaching is already attached")
+	})
+
+	t.Run("paused subscription", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		subscription := activeSubscription()
+		subscription.Status = model.Paused
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+
+		_, err := service.AttachAddOn(context.Background(), subscription.ID.String(), 0, nutritionCoaching.ID.String())
+		assert.EqualError(t, err, "add-ons can only be attached to active subscriptions")
+	})
+}
+
+func Test_Service_DetachAddOn(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.June, 20, 10, 0, 0, 0, time.UTC)
+	today := model.StartOfDay(now, time.UTC)
+
+	periodStart := today.AddDate(0, 0, -20)
+	periodEnd := today.AddDate(0, 0, 40)
+
+	tests := []struct {
+		name        string
+		addOnStart  time.Time
+		billedUntil *time.Time
+		credit      float64
+	}{
+		{name: "paid add-on credits its unused days", addOnStart: today.AddDate(0, 0, -50), billedUntil: &periodEnd, credit: 7.33},
+		{name: "add-on attached this period was not paid yet", addOnStart: today.AddDate(0, 0, -5), credit: 0},
+		{name: "add-on attached on the first day of the period was not paid yet", addOnStart: periodStart, credit: 0},
+		{name: "add-on paid for the previous period only", addOnStart: today.AddDate(0, 0, -50), billedUntil: &periodStart, credit: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := NewMockRepository(ctrl)
+			service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+			addOn := model.SubscriptionAddOn{
+				ID:          uuid.New(),
+				Name:        "nutrition coaching",
+				TotalPrice:  11,
+				Status:      model.AddOnActive,
+				StartDate:   test.addOnStart,
+				BilledUntil: test.billedUntil,
+			}
+			subscription := model.Subscription{
+				ID:           uuid.New(),
+				UserID:       uuid.New(),
+				StartDate:    periodStart,
+				EndDate:      periodEnd,
+				DurationDays: 60,
+				Status:       model.Active,
+				AddOns:       []model.SubscriptionAddOn{addOn},
+			}
+
+			mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+			expectWithinTx(mockRepo)
+			mockRepo.EXPECT().UpdateSubscriptionAddOn(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, detached model.SubscriptionAddOn) error {
+					assert.Equal(t, model.AddOnCanceled, detached.Status)
+					assert.Equal(t, today, *detached.CanceledDate)
+					return nil
+				},
+			)
+			if test.credit > 0 {
+				mockRepo.EXPECT().SaveCreditTransaction(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, transaction model.CreditTransaction) error {
+						assert.Equal(t, model.CreditProration, transaction.Kind)
+						assert.Equal(t, test.credit, transaction.CustomerAmount())
+						return nil
+					},
+				)
+			}
+			mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).Return(nil)
+			mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).Return(nil)
+
+			credit, err := service.DetachAddOn(context.Background(), subscription.ID.String(), 0, addOn.ID.String())
+			assert.NoError(t, err)
+			assert.Equal(t, test.credit, credit)
+		})
+	}
+
+	t.Run("unknown add-on", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		subscription := model.Subscription{ID: uuid.New(), Status: model.Active}
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+
+		addOnID := uuid.NewString()
+		_, err := service.DetachAddOn(context.Background(), subscription.ID.String(), 0, addOnID)
+		assert.EqualError(t, err, "subscription has no add-on "+addOnID)
+	})
+}
+
+func Test_addOnLineItems(t *testing.T) {
+	t.Parallel()
+
+	periodStart := time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC)
+	addOn := func(startDate time.Time, status model.AddOnStatus) model.SubscriptionAddOn {
+		return model.SubscriptionAddOn{
+			Name:       "live classes",
+			Price:      8,
+			Tax:        0.8,
+			TotalPrice: 8.8,
+			Status:     status,
+			StartDate:  startDate,
+		}
+	}
+
+	t.Run("prorates add-ons attached during the previous period", func(t *testing.T) {
+		t.Parallel()
+
+		subscription := model.Subscription{
+			DurationDays: 30,
+			AddOns:       []model.SubscriptionAddOn{addOn(periodStart.AddDate(0, 0, -15), model.AddOnActive)},
+		}
+
+		lineItems := addOnLineItems(subscription, periodStart)
+		assert.Len(t, lineItems, 2)
+		assert.Equal(t, "live classes (16.06.2024 - 01.07.2024, prorated)", lineItems[0].Description)
+		assert.Equal(t, 4.4, lineItems[0].TotalPrice)
+		assert.Equal(t, "live classes (01.07.2024 - 31.07.2024)", lineItems[1].Description)
+		assert.Equal(t, 8.8, lineItems[1].TotalPrice)
+	})
+
+	t.Run("skips canceled add-ons and the trial", func(t *testing.T) {
+		t.Parallel()
+
+		trialEndDate := periodStart
+		subscription := model.Subscription{
+			DurationDays: 30,
+			TrialEndDate: &trialEndDate,
+			AddOns: []model.SubscriptionAddOn{
+				addOn(periodStart.AddDate(0, 0, -15), model.AddOnActive),
+				addOn(periodStart.AddDate(0, 0, -40), model.AddOnCanceled),
+			},
+		}
+
+		lineItems := addOnLineItems(subscription, periodStart)
+		assert.Len(t, lineItems, 1)
+		assert.Equal(t, 8.8, lineItems[0].TotalPrice)
+	})
+
+	t.Run("bills the used days of add-ons detached during the previous period", func(t *testing.T) {
+		t.Parallel()
+
+		detached := addOn(periodStart.AddDate(0, 0, -20), model.AddOnCanceled)
+		canceledDate := periodStart.AddDate(0, 0, -5)
+		detached.CanceledDate = &canceledDate
+		subscription := model.Subscription{
+			DurationDays: 30,
+			AddOns:       []model.SubscriptionAddOn{detached},
+		}
+
+		lineItems := addOnLineItems(subscription, periodStart)
+		assert.Len(t, lineItems, 1)
+		assert.Equal(t, "live classes (11.06.2024 - 26.06.2024, prorated)", lineItems[0].Description)
+		assert.Equal(t, 4.0, lineItems[0].UnitPrice)
+		assert.Equal(t, 0.4, lineItems[0].Tax)
+		assert.Equal(t, 4.4, lineItems[0].TotalPrice)
+	})
+
+	t.Run("bills the whole period for add-ons attached on its first day", func(t *testing.T) {
+		t.Parallel()
+
+		previousStart := periodStart.AddDate(0, 0, -30)
+		subscription := model.Subscription{
+			DurationDays: 30,
+			AddOns:       []model.SubscriptionAddOn{addOn(previousStart, model.AddOnActive)},
+		}
+
+		lineItems := addOnLineItems(subscription, periodStart)
+		assert.Len(t, lineItems, 2)
+		assert.Equal(t, "live classes (01.06.2024 - 01.07.2024, prorated)", lineItems[0].Description)
+		assert.Equal(t, 8.8, lineItems[0].TotalPrice)
+	})
+
+	t.Run("skips the days already billed", func(t *testing.T) {
+		t.Parallel()
+
+		billed := addOn(periodStart.AddDate(0, 0, -45), model.AddOnActive)
+		billedUntil := periodStart
+		billed.BilledUntil = &billedUntil
+		subscription := model.Subscription{
+			DurationDays: 30,
+			AddOns:       []model.SubscriptionAddOn{billed},
+		}
+
+		lineItems := addOnLineItems(subscription, periodStart)
+		assert.Len(t, lineItems, 1)
+		assert.Equal(t, "live classes (01.07.2024 - 31.07.2024)", lineItems[0].Description)
+	})
+
+	t.Run("renewal charges the add-ons", func(t *testing.T) {
+		t.Parallel()
+
+		subscription := model.Subscription{
+			EndDate:      periodStart,
+			DurationDays: 30,
+			TotalPrice:   16.5,
+			AddOns:       []model.SubscriptionAddOn{addOn(periodStart.AddDate(0, 0, -15), model.AddOnActive)},
+		}
+
+		assert.Equal(t, 29.7, renewalTotal(subscription))
+	})
+}
+
+func Test_billAddOns(t *testing.T) {
+	t.Parallel()
+
+	periodStart := time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC)
+	canceledDate := periodStart.AddDate(0, 0, -5)
+	active := model.SubscriptionAddOn{ID: uuid.New(), Status: model.AddOnActive, StartDate: periodStart.AddDate(0, 0, -15)}
+	detached := model.SubscriptionAddOn{
+		ID:           uuid.New(),
+		Status:       model.AddOnCanceled,
+		StartDate:    periodStart.AddDate(0, 0, -20),
+		CanceledDate: &canceledDate,
+	}
+	later := model.SubscriptionAddOn{ID: uuid.New(), Status: model.AddOnActive, StartDate: periodStart.AddDate(0, 0, 3)}
+	subscription := model.Subscription{
+		DurationDays: 30,
+		AddOns:       []model.SubscriptionAddOn{active, detached, later},
+	}
+
+	billed := billAddOns(&subscription, periodStart)
+	assert.Len(t, billed, 2)
+	assert.Equal(t, periodStart.AddDate(0, 0, 30), *subscription.AddOns[0].BilledUntil)
+	assert.Equal(t, canceledDate, *subscription.AddOns[1].BilledUntil)
+	assert.Nil(t, subscription.AddOns[2].BilledUntil)
+
+	// the detached add-on is not billed again with the next period
+	subscription.AddOns = subscription.AddOns[:2]
+	lineItems := addOnLineItems(subscription, periodStart.AddDate(0, 0, 30))
+	assert.Len(t, lineItems, 1)
+	assert.Equal(t, " (31.07.2024 - 30.08.2024)", lineItems[0].Description)
+}
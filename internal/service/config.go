@@ -0,0 +1,67 @@
+package service
+
+import (
+	"time"
+
+	"gymondo/internal/model"
+)
+
+type Config struct {
+	// DunningRetryDays lists, in days after the first failed renewal charge,
+	// when the payment is retried. The subscription keeps access until the
+	// last retry and is canceled once every retry has failed.
+	DunningRetryDays []int
+	// WebhookMaxAttempts is how often a webhook delivery is attempted before
+	// it is dead-lettered.
+	WebhookMaxAttempts int
+	// WebhookRetryDelay is the wait before the first webhook retry, it
+	// doubles with every further attempt.
+	WebhookRetryDelay time.Duration
+	// TrialReminderLeadDays is how many days before a trial ends the user is
+	// reminded that it converts into a paid subscription.
+	TrialReminderLeadDays int
+	// RenewalReminderLeadDays is how many days before a renewal the user is
+	// reminded of it. Only subscriptions of at least
+	// RenewalReminderMinDurationDays are reminded, so monthly plans are not
+	// reminded every month.
+	RenewalReminderLeadDays        int
+	RenewalReminderMinDurationDays int
+	// ReactivationWindowDays is how many days after its cancellation a
+	// subscription can still be reactivated.
+	ReactivationWindowDays int
+	// EntitlementCacheTTL is how long resolved entitlements are served from
+	// memory. Changes made through this instance are seen right away, changes
+	// made by other replicas after at most the TTL.
+	EntitlementCacheTTL time.Duration
+	// GiftValidityDays is how many days after its purchase a gift code can
+	// be redeemed.
+	GiftValidityDays int
+	// ReferralReward is what both sides of a referral get once the first
+	// paid period of the referee is over: ReferralRewardDays extra days on
+	// their active subscription or ReferralRewardCredit credit. Users without
+	// an active subscription get the credit either way.
+	ReferralReward       model.ReferralRewardKind
+	ReferralRewardDays   int
+	ReferralRewardCredit float64
+	// PriceChangeNoticeDays is how many days in advance subscribers are told
+	// about a new price. It applies from their first renewal after that.
+	PriceChangeNoticeDays int
+}
+
+func DefaultConfig() Config {
+	return Config{
+		DunningRetryDays:               []int{1, 3, 5, 7},
+		WebhookMaxAttempts:             8,
+		WebhookRetryDelay:              time.Minute,
+		TrialReminderLeadDays:          3,
+		RenewalReminderLeadDays:        30,
+		RenewalReminderMinDurationDays: 365,
+		ReactivationWindowDays:         30,
+		EntitlementCacheTTL:            30 * time.Second,
+		GiftValidityDays:               365,
+		ReferralReward:                 model.ReferralRewardDays,
+		ReferralRewardDays:             30,
+		ReferralRewardCredit:           10,
+		PriceChangeNoticeDays:          30,
+	}
+}
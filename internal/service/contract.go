@@ -3,16 +3,122 @@ package service
 
 import (
 	"context"
+	"time"
 
+	"github.com/google/uuid"
 	"gymondo/internal/model"
 )
 
 type Repository interface {
+	// WithinTx runs fn with a repository bound to a single transaction, which
+	// is committed when fn returns nil and rolled back otherwise.
+	WithinTx(ctx context.Context, fn func(repo Repository) error) error
 	GetProduct(ctx context.Context, productID string) (model.Product, error)
 	GetProducts(ctx context.Context) ([]model.Product, error)
+	UpdateProductPrice(ctx context.Context, productID string, price, tax, totalPrice float64) error
 	GetUser(ctx context.Context, userID string) (model.User, error)
 	SaveSubscription(ctx context.Context, subscription model.Subscription) error
 	GetSubscription(ctx context.Context, subscriptionID string) (model.Subscription, error)
+	LockSubscription(ctx context.Context, subscriptionID string) (model.Subscription, error)
 	UpdateSubscription(ctx context.Context, subscription model.Subscription) error
+	GetUserSubscriptions(ctx context.Context, userID string) ([]model.Subscription, error)
+	GetProductSubscriptions(ctx context.Context, productID string) ([]model.Subscription, error)
+	SaveSubscriptionAddOn(ctx context.Context, addOn model.SubscriptionAddOn) error
+	UpdateSubscriptionAddOn(ctx context.Context, addOn model.SubscriptionAddOn) error
+	SaveSubscriptionMember(ctx context.Context, member model.SubscriptionMember) error
+	UpdateSubscriptionMember(ctx context.Context, member model.SubscriptionMember) error
+	GetMemberSubscriptions(ctx context.Context, userID string) ([]model.Subscription, error)
+	SaveOrganization(ctx context.Context, organization model.Organization) error
+	SaveOrganizationAdmin(ctx context.Context, organizationID string, userID string) error
+	GetOrganization(ctx context.Context, organizationID string) (model.Organization, error)
+	GetOrganizationSubscriptions(ctx context.Context, organizationID string) ([]model.Subscription, error)
+	SaveSeatChange(ctx context.Context, change model.SeatChange) error
+	SaveGift(ctx context.Context, gift model.Gift) error
+	GetGiftByCode(ctx context.Context, code string) (model.Gift, error)
+	UpdateGift(ctx context.Context, gift model.Gift) error
+	GetGiftsDueForExpiry(ctx context.Context, now time.Time) ([]model.Gift, error)
+	SaveReferralCode(ctx context.Context, code model.ReferralCode) error
+	GetReferralCode(ctx context.Context, code string) (model.ReferralCode, error)
+	GetUserReferralCode(ctx context.Context, userID string) (model.ReferralCode, bool, error)
+	SaveReferral(ctx context.Context, referral model.Referral) error
+	UpdateReferral(ctx context.Context, referral model.Referral) error
+	GetPendingReferrals(ctx context.Context) ([]model.Referral, error)
+	GetReferrals(ctx context.Context, referrerID string) ([]model.Referral, error)
+	SaveReferralReward(ctx context.Context, reward model.ReferralReward) error
+	GetReferralRewards(ctx context.Context, userID string) ([]model.ReferralReward, error)
+	GetSubscriptionsDueForRenewal(ctx context.Context, date time.Time) ([]model.Subscription, error)
+	GetSubscriptionsDueForPaymentRetry(ctx context.Context, date time.Time) ([]model.Subscription, error)
+	GetSubscriptionsDueForPause(ctx context.Context, date time.Time) ([]model.Subscription, error)
+	GetSubscriptionsDueForResume(ctx context.Context, date time.Time) ([]model.Subscription, error)
+	GetSubscriptionsDueForActivation(ctx context.Context, date time.Time) ([]model.Subscription, error)
+	SavePriceMigration(ctx context.Context, migration model.PriceMigration) error
+	GetPriceMigration(ctx context.Context, migrationID string) (model.PriceMigration, error)
+	GetPriceMigrationsDue(ctx context.Context, date time.Time) ([]model.PriceMigration, error)
+	MarkPriceMigrationApplied(ctx context.Context, migrationID string, appliedAt time.Time) error
+	CountPendingPriceChanges(ctx context.Context, productID string) (int, error)
+	SavePriceChange(ctx context.Context, change model.PriceChange) error
+	UpdatePriceChange(ctx context.Context, change model.PriceChange) error
+	GetPendingPriceChange(ctx context.Context, subscriptionID string) (model.PriceChange, bool, error)
+	GetPriceChanges(ctx context.Context, migrationID string) ([]model.PriceChange, error)
+	SavePause(ctx context.Context, pause model.Pause) error
+	EndPause(ctx context.Context, subscriptionID string, endDate time.Time) error
+	GetPauses(ctx context.Context, subscriptionID string) ([]model.Pause, error)
+	SaveReactivation(ctx context.Context, reactivation model.Reactivation) error
+	GetReactivations(ctx context.Context, subscriptionID string) ([]model.Reactivation, error)
+	SaveCancellation(ctx context.Context, cancellation model.Cancellation) error
+	GetRetentionOffers(ctx context.Context, reason model.CancellationReason) ([]model.RetentionOffer, error)
+	GetRetentionOffer(ctx context.Context, offerID string) (model.RetentionOffer, error)
+	SaveRetentionOfferAcceptance(ctx context.Context, acceptance model.RetentionOfferAcceptance) error
+	SavePaymentAttempt(ctx context.Context, attempt model.PaymentAttempt) error
+	GetPaymentAttempts(ctx context.Context, subscriptionID string) ([]model.PaymentAttempt, error)
 	GetVoucherByCode(ctx context.Context, voucherCode string) (model.Voucher, error)
+	SaveInvoice(ctx context.Context, invoice model.Invoice) (model.Invoice, error)
+	GetInvoice(ctx context.Context, invoiceID string) (model.Invoice, error)
+	GetSubscriptionInvoices(ctx context.Context, subscriptionID string) ([]model.Invoice, error)
+	SaveCreditNote(ctx context.Context, creditNote model.CreditNote) (model.CreditNote, error)
+	SaveRefund(ctx context.Context, refund model.Refund) error
+	UpdateRefund(ctx context.Context, refund model.Refund) error
+	SaveCreditTransaction(ctx context.Context, transaction model.CreditTransaction) error
+	GetCreditBalance(ctx context.Context, userID string) (float64, error)
+	GetCreditTransactions(ctx context.Context, userID string) ([]model.CreditTransaction, error)
+	SaveOutboxMessage(ctx context.Context, message model.OutboxMessage) error
+	SaveWebhookEndpoint(ctx context.Context, endpoint model.WebhookEndpoint) error
+	GetWebhookEndpoint(ctx context.Context, endpointID string) (model.WebhookEndpoint, error)
+	GetWebhookEndpoints(ctx context.Context) ([]model.WebhookEndpoint, error)
+	DeactivateWebhookEndpoint(ctx context.Context, endpointID string) error
+	SaveWebhookEvent(ctx context.Context, event model.WebhookEvent, deliveries []model.WebhookDelivery) error
+	GetWebhookEvent(ctx context.Context, eventID string) (model.WebhookEvent, error)
+	GetWebhookDelivery(ctx context.Context, deliveryID string) (model.WebhookDelivery, error)
+	GetWebhookDeliveries(ctx context.Context, status model.WebhookDeliveryStatus) ([]model.WebhookDelivery, error)
+	GetWebhookDeliveriesDue(ctx context.Context, now time.Time) ([]model.WebhookDelivery, error)
+	UpdateWebhookDelivery(ctx context.Context, delivery model.WebhookDelivery) error
+	TryAdvisoryLock(ctx context.Context, key int64) (bool, error)
+	GetSubscriptionsDueForTrialReminder(ctx context.Context, from time.Time, until time.Time) ([]model.Subscription, error)
+	GetSubscriptionsDueForRenewalReminder(
+		ctx context.Context,
+		from time.Time,
+		until time.Time,
+		minDurationDays int,
+	) ([]model.Subscription, error)
+	SaveReminder(ctx context.Context, reminder model.Reminder) error
+	GetJobs(ctx context.Context, status model.JobStatus, limit int) ([]model.Job, error)
+}
+
+type PaymentGateway interface {
+	Charge(ctx context.Context, userID uuid.UUID, amount float64, reference string) (transactionID string, err error)
+	Refund(
+		ctx context.Context,
+		userID uuid.UUID,
+		amount float64,
+		chargeTransactionID string,
+		reference string,
+	) (transactionID string, err error)
+}
+
+type WebhookSender interface {
+	Send(ctx context.Context, endpoint model.WebhookEndpoint, event model.WebhookEvent, deliveryID uuid.UUID) error
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, notification model.Notification) error
 }
@@ -13,7 +13,9 @@ import (
 	context "context"
 	model "gymondo/internal/model"
 	reflect "reflect"
+	time "time"
 
+	uuid "github.com/google/uuid"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -40,6 +42,290 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// CountPendingPriceChanges mocks base method.
+func (m *MockRepository) CountPendingPriceChanges(ctx context.Context, productID string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountPendingPriceChanges", ctx, productID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountPendingPriceChanges indicates an expected call of CountPendingPriceChanges.
+func (mr *MockRepositoryMockRecorder) CountPendingPriceChanges(ctx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountPendingPriceChanges", reflect.TypeOf((*MockRepository)(nil).CountPendingPriceChanges), ctx, productID)
+}
+
+// DeactivateWebhookEndpoint mocks base method.
+func (m *MockRepository) DeactivateWebhookEndpoint(ctx context.Context, endpointID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeactivateWebhookEndpoint", ctx, endpointID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeactivateWebhookEndpoint indicates an expected call of DeactivateWebhookEndpoint.
+func (mr *MockRepositoryMockRecorder) DeactivateWebhookEndpoint(ctx, endpointID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeactivateWebhookEndpoint", reflect.TypeOf((*MockRepository)(nil).DeactivateWebhookEndpoint), ctx, endpointID)
+}
+
+// EndPause mocks base method.
+func (m *MockRepository) EndPause(ctx context.Context, subscriptionID string, endDate time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EndPause", ctx, subscriptionID, endDate)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EndPause indicates an expected call of EndPause.
+func (mr *MockRepositoryMockRecorder) EndPause(ctx, subscriptionID, endDate any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EndPause", reflect.TypeOf((*MockRepository)(nil).EndPause), ctx, subscriptionID, endDate)
+}
+
+// GetCreditBalance mocks base method.
+func (m *MockRepository) GetCreditBalance(ctx context.Context, userID string) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCreditBalance", ctx, userID)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCreditBalance indicates an expected call of GetCreditBalance.
+func (mr *MockRepositoryMockRecorder) GetCreditBalance(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCreditBalance", reflect.TypeOf((*MockRepository)(nil).GetCreditBalance), ctx, userID)
+}
+
+// GetCreditTransactions mocks base method.
+func (m *MockRepository) GetCreditTransactions(ctx context.Context, userID string) ([]model.CreditTransaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCreditTransactions", ctx, userID)
+	ret0, _ := ret[0].([]model.CreditTransaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCreditTransactions indicates an expected call of GetCreditTransactions.
+func (mr *MockRepositoryMockRecorder) GetCreditTransactions(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCreditTransactions", reflect.TypeOf((*MockRepository)(nil).GetCreditTransactions), ctx, userID)
+}
+
+// GetGiftByCode mocks base method.
+func (m *MockRepository) GetGiftByCode(ctx context.Context, code string) (model.Gift, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGiftByCode", ctx, code)
+	ret0, _ := ret[0].(model.Gift)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGiftByCode indicates an expected call of GetGiftByCode.
+func (mr *MockRepositoryMockRecorder) GetGiftByCode(ctx, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGiftByCode", reflect.TypeOf((*MockRepository)(nil).GetGiftByCode), ctx, code)
+}
+
+// GetGiftsDueForExpiry mocks base method.
+func (m *MockRepository) GetGiftsDueForExpiry(ctx context.Context, now time.Time) ([]model.Gift, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGiftsDueForExpiry", ctx, now)
+	ret0, _ := ret[0].([]model.Gift)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGiftsDueForExpiry indicates an expected call of GetGiftsDueForExpiry.
+func (mr *MockRepositoryMockRecorder) GetGiftsDueForExpiry(ctx, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGiftsDueForExpiry", reflect.TypeOf((*MockRepository)(nil).GetGiftsDueForExpiry), ctx, now)
+}
+
+// GetInvoice mocks base method.
+func (m *MockRepository) GetInvoice(ctx context.Context, invoiceID string) (model.Invoice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInvoice", ctx, invoiceID)
+	ret0, _ := ret[0].(model.Invoice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInvoice indicates an expected call of GetInvoice.
+func (mr *MockRepositoryMockRecorder) GetInvoice(ctx, invoiceID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInvoice", reflect.TypeOf((*MockRepository)(nil).GetInvoice), ctx, invoiceID)
+}
+
+// GetJobs mocks base method.
+func (m *MockRepository) GetJobs(ctx context.Context, status model.JobStatus, limit int) ([]model.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJobs", ctx, status, limit)
+	ret0, _ := ret[0].([]model.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobs indicates an expected call of GetJobs.
+func (mr *MockRepositoryMockRecorder) GetJobs(ctx, status, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobs", reflect.TypeOf((*MockRepository)(nil).GetJobs), ctx, status, limit)
+}
+
+// GetMemberSubscriptions mocks base method.
+func (m *MockRepository) GetMemberSubscriptions(ctx context.Context, userID string) ([]model.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMemberSubscriptions", ctx, userID)
+	ret0, _ := ret[0].([]model.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMemberSubscriptions indicates an expected call of GetMemberSubscriptions.
+func (mr *MockRepositoryMockRecorder) GetMemberSubscriptions(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberSubscriptions", reflect.TypeOf((*MockRepository)(nil).GetMemberSubscriptions), ctx, userID)
+}
+
+// GetOrganization mocks base method.
+func (m *MockRepository) GetOrganization(ctx context.Context, organizationID string) (model.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganization", ctx, organizationID)
+	ret0, _ := ret[0].(model.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganization indicates an expected call of GetOrganization.
+func (mr *MockRepositoryMockRecorder) GetOrganization(ctx, organizationID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganization", reflect.TypeOf((*MockRepository)(nil).GetOrganization), ctx, organizationID)
+}
+
+// GetOrganizationSubscriptions mocks base method.
+func (m *MockRepository) GetOrganizationSubscriptions(ctx context.Context, organizationID string) ([]model.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganizationSubscriptions", ctx, organizationID)
+	ret0, _ := ret[0].([]model.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganizationSubscriptions indicates an expected call of GetOrganizationSubscriptions.
+func (mr *MockRepositoryMockRecorder) GetOrganizationSubscriptions(ctx, organizationID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganizationSubscriptions", reflect.TypeOf((*MockRepository)(nil).GetOrganizationSubscriptions), ctx, organizationID)
+}
+
+// GetPauses mocks base method.
+func (m *MockRepository) GetPauses(ctx context.Context, subscriptionID string) ([]model.Pause, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPauses", ctx, subscriptionID)
+	ret0, _ := ret[0].([]model.Pause)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPauses indicates an expected call of GetPauses.
+func (mr *MockRepositoryMockRecorder) GetPauses(ctx, subscriptionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPauses", reflect.TypeOf((*MockRepository)(nil).GetPauses), ctx, subscriptionID)
+}
+
+// GetPaymentAttempts mocks base method.
+func (m *MockRepository) GetPaymentAttempts(ctx context.Context, subscriptionID string) ([]model.PaymentAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentAttempts", ctx, subscriptionID)
+	ret0, _ := ret[0].([]model.PaymentAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentAttempts indicates an expected call of GetPaymentAttempts.
+func (mr *MockRepositoryMockRecorder) GetPaymentAttempts(ctx, subscriptionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentAttempts", reflect.TypeOf((*MockRepository)(nil).GetPaymentAttempts), ctx, subscriptionID)
+}
+
+// GetPendingPriceChange mocks base method.
+func (m *MockRepository) GetPendingPriceChange(ctx context.Context, subscriptionID string) (model.PriceChange, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingPriceChange", ctx, subscriptionID)
+	ret0, _ := ret[0].(model.PriceChange)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetPendingPriceChange indicates an expected call of GetPendingPriceChange.
+func (mr *MockRepositoryMockRecorder) GetPendingPriceChange(ctx, subscriptionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingPriceChange", reflect.TypeOf((*MockRepository)(nil).GetPendingPriceChange), ctx, subscriptionID)
+}
+
+// GetPendingReferrals mocks base method.
+func (m *MockRepository) GetPendingReferrals(ctx context.Context) ([]model.Referral, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingReferrals", ctx)
+	ret0, _ := ret[0].([]model.Referral)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingReferrals indicates an expected call of GetPendingReferrals.
+func (mr *MockRepositoryMockRecorder) GetPendingReferrals(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingReferrals", reflect.TypeOf((*MockRepository)(nil).GetPendingReferrals), ctx)
+}
+
+// GetPriceChanges mocks base method.
+func (m *MockRepository) GetPriceChanges(ctx context.Context, migrationID string) ([]model.PriceChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPriceChanges", ctx, migrationID)
+	ret0, _ := ret[0].([]model.PriceChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPriceChanges indicates an expected call of GetPriceChanges.
+func (mr *MockRepositoryMockRecorder) GetPriceChanges(ctx, migrationID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPriceChanges", reflect.TypeOf((*MockRepository)(nil).GetPriceChanges), ctx, migrationID)
+}
+
+// GetPriceMigration mocks base method.
+func (m *MockRepository) GetPriceMigration(ctx context.Context, migrationID string) (model.PriceMigration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPriceMigration", ctx, migrationID)
+	ret0, _ := ret[0].(model.PriceMigration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPriceMigration indicates an expected call of GetPriceMigration.
+func (mr *MockRepositoryMockRecorder) GetPriceMigration(ctx, migrationID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPriceMigration", reflect.TypeOf((*MockRepository)(nil).GetPriceMigration), ctx, migrationID)
+}
+
+// GetPriceMigrationsDue mocks base method.
+func (m *MockRepository) GetPriceMigrationsDue(ctx context.Context, date time.Time) ([]model.PriceMigration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPriceMigrationsDue", ctx, date)
+	ret0, _ := ret[0].([]model.PriceMigration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPriceMigrationsDue indicates an expected call of GetPriceMigrationsDue.
+func (mr *MockRepositoryMockRecorder) GetPriceMigrationsDue(ctx, date any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPriceMigrationsDue", reflect.TypeOf((*MockRepository)(nil).GetPriceMigrationsDue), ctx, date)
+}
+
 // GetProduct mocks base method.
 func (m *MockRepository) GetProduct(ctx context.Context, productID string) (model.Product, error) {
 	m.ctrl.T.Helper()
@@ -55,6 +341,21 @@ func (mr *MockRepositoryMockRecorder) GetProduct(ctx, productID any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProduct", reflect.TypeOf((*MockRepository)(nil).GetProduct), ctx, productID)
 }
 
+// GetProductSubscriptions mocks base method.
+func (m *MockRepository) GetProductSubscriptions(ctx context.Context, productID string) ([]model.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductSubscriptions", ctx, productID)
+	ret0, _ := ret[0].([]model.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductSubscriptions indicates an expected call of GetProductSubscriptions.
+func (mr *MockRepositoryMockRecorder) GetProductSubscriptions(ctx, productID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductSubscriptions", reflect.TypeOf((*MockRepository)(nil).GetProductSubscriptions), ctx, productID)
+}
+
 // GetProducts mocks base method.
 func (m *MockRepository) GetProducts(ctx context.Context) ([]model.Product, error) {
 	m.ctrl.T.Helper()
@@ -70,6 +371,96 @@ func (mr *MockRepositoryMockRecorder) GetProducts(ctx any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProducts", reflect.TypeOf((*MockRepository)(nil).GetProducts), ctx)
 }
 
+// GetReactivations mocks base method.
+func (m *MockRepository) GetReactivations(ctx context.Context, subscriptionID string) ([]model.Reactivation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReactivations", ctx, subscriptionID)
+	ret0, _ := ret[0].([]model.Reactivation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReactivations indicates an expected call of GetReactivations.
+func (mr *MockRepositoryMockRecorder) GetReactivations(ctx, subscriptionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReactivations", reflect.TypeOf((*MockRepository)(nil).GetReactivations), ctx, subscriptionID)
+}
+
+// GetReferralCode mocks base method.
+func (m *MockRepository) GetReferralCode(ctx context.Context, code string) (model.ReferralCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReferralCode", ctx, code)
+	ret0, _ := ret[0].(model.ReferralCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReferralCode indicates an expected call of GetReferralCode.
+func (mr *MockRepositoryMockRecorder) GetReferralCode(ctx, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReferralCode", reflect.TypeOf((*MockRepository)(nil).GetReferralCode), ctx, code)
+}
+
+// GetReferralRewards mocks base method.
+func (m *MockRepository) GetReferralRewards(ctx context.Context, userID string) ([]model.ReferralReward, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReferralRewards", ctx, userID)
+	ret0, _ := ret[0].([]model.ReferralReward)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReferralRewards indicates an expected call of GetReferralRewards.
+func (mr *MockRepositoryMockRecorder) GetReferralRewards(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReferralRewards", reflect.TypeOf((*MockRepository)(nil).GetReferralRewards), ctx, userID)
+}
+
+// GetReferrals mocks base method.
+func (m *MockRepository) GetReferrals(ctx context.Context, referrerID string) ([]model.Referral, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReferrals", ctx, referrerID)
+	ret0, _ := ret[0].([]model.Referral)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReferrals indicates an expected call of GetReferrals.
+func (mr *MockRepositoryMockRecorder) GetReferrals(ctx, referrerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReferrals", reflect.TypeOf((*MockRepository)(nil).GetReferrals), ctx, referrerID)
+}
+
+// GetRetentionOffer mocks base method.
+func (m *MockRepository) GetRetentionOffer(ctx context.Context, offerID string) (model.RetentionOffer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRetentionOffer", ctx, offerID)
+	ret0, _ := ret[0].(model.RetentionOffer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRetentionOffer indicates an expected call of GetRetentionOffer.
+func (mr *MockRepositoryMockRecorder) GetRetentionOffer(ctx, offerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRetentionOffer", reflect.TypeOf((*MockRepository)(nil).GetRetentionOffer), ctx, offerID)
+}
+
+// GetRetentionOffers mocks base method.
+func (m *MockRepository) GetRetentionOffers(ctx context.Context, reason model.CancellationReason) ([]model.RetentionOffer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRetentionOffers", ctx, reason)
+	ret0, _ := ret[0].([]model.RetentionOffer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRetentionOffers indicates an expected call of GetRetentionOffers.
+func (mr *MockRepositoryMockRecorder) GetRetentionOffers(ctx, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRetentionOffers", reflect.TypeOf((*MockRepository)(nil).GetRetentionOffers), ctx, reason)
+}
+
 // GetSubscription mocks base method.
 func (m *MockRepository) GetSubscription(ctx context.Context, subscriptionID string) (model.Subscription, error) {
 	m.ctrl.T.Helper()
@@ -85,60 +476,936 @@ func (mr *MockRepositoryMockRecorder) GetSubscription(ctx, subscriptionID any) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscription", reflect.TypeOf((*MockRepository)(nil).GetSubscription), ctx, subscriptionID)
 }
 
-// GetUser mocks base method.
-func (m *MockRepository) GetUser(ctx context.Context, userID string) (model.User, error) {
+// GetSubscriptionInvoices mocks base method.
+func (m *MockRepository) GetSubscriptionInvoices(ctx context.Context, subscriptionID string) ([]model.Invoice, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUser", ctx, userID)
-	ret0, _ := ret[0].(model.User)
+	ret := m.ctrl.Call(m, "GetSubscriptionInvoices", ctx, subscriptionID)
+	ret0, _ := ret[0].([]model.Invoice)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUser indicates an expected call of GetUser.
-func (mr *MockRepositoryMockRecorder) GetUser(ctx, userID any) *gomock.Call {
+// GetSubscriptionInvoices indicates an expected call of GetSubscriptionInvoices.
+func (mr *MockRepositoryMockRecorder) GetSubscriptionInvoices(ctx, subscriptionID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockRepository)(nil).GetUser), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriptionInvoices", reflect.TypeOf((*MockRepository)(nil).GetSubscriptionInvoices), ctx, subscriptionID)
 }
 
-// GetVoucherByCode mocks base method.
-func (m *MockRepository) GetVoucherByCode(ctx context.Context, voucherCode string) (model.Voucher, error) {
+// GetSubscriptionsDueForActivation mocks base method.
+func (m *MockRepository) GetSubscriptionsDueForActivation(ctx context.Context, date time.Time) ([]model.Subscription, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetVoucherByCode", ctx, voucherCode)
-	ret0, _ := ret[0].(model.Voucher)
+	ret := m.ctrl.Call(m, "GetSubscriptionsDueForActivation", ctx, date)
+	ret0, _ := ret[0].([]model.Subscription)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetVoucherByCode indicates an expected call of GetVoucherByCode.
-func (mr *MockRepositoryMockRecorder) GetVoucherByCode(ctx, voucherCode any) *gomock.Call {
+// GetSubscriptionsDueForActivation indicates an expected call of GetSubscriptionsDueForActivation.
+func (mr *MockRepositoryMockRecorder) GetSubscriptionsDueForActivation(ctx, date any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVoucherByCode", reflect.TypeOf((*MockRepository)(nil).GetVoucherByCode), ctx, voucherCode)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriptionsDueForActivation", reflect.TypeOf((*MockRepository)(nil).GetSubscriptionsDueForActivation), ctx, date)
 }
 
-// SaveSubscription mocks base method.
-func (m *MockRepository) SaveSubscription(ctx context.Context, subscription model.Subscription) error {
+// GetSubscriptionsDueForPause mocks base method.
+func (m *MockRepository) GetSubscriptionsDueForPause(ctx context.Context, date time.Time) ([]model.Subscription, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveSubscription", ctx, subscription)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "GetSubscriptionsDueForPause", ctx, date)
+	ret0, _ := ret[0].([]model.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// SaveSubscription indicates an expected call of SaveSubscription.
-func (mr *MockRepositoryMockRecorder) SaveSubscription(ctx, subscription any) *gomock.Call {
+// GetSubscriptionsDueForPause indicates an expected call of GetSubscriptionsDueForPause.
+func (mr *MockRepositoryMockRecorder) GetSubscriptionsDueForPause(ctx, date any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSubscription", reflect.TypeOf((*MockRepository)(nil).SaveSubscription), ctx, subscription)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriptionsDueForPause", reflect.TypeOf((*MockRepository)(nil).GetSubscriptionsDueForPause), ctx, date)
 }
 
-// UpdateSubscription mocks base method.
-func (m *MockRepository) UpdateSubscription(ctx context.Context, subscription model.Subscription) error {
+// GetSubscriptionsDueForPaymentRetry mocks base method.
+func (m *MockRepository) GetSubscriptionsDueForPaymentRetry(ctx context.Context, date time.Time) ([]model.Subscription, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateSubscription", ctx, subscription)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "GetSubscriptionsDueForPaymentRetry", ctx, date)
+	ret0, _ := ret[0].([]model.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// UpdateSubscription indicates an expected call of UpdateSubscription.
-func (mr *MockRepositoryMockRecorder) UpdateSubscription(ctx, subscription any) *gomock.Call {
+// GetSubscriptionsDueForPaymentRetry indicates an expected call of GetSubscriptionsDueForPaymentRetry.
+func (mr *MockRepositoryMockRecorder) GetSubscriptionsDueForPaymentRetry(ctx, date any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSubscription", reflect.TypeOf((*MockRepository)(nil).UpdateSubscription), ctx, subscription)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriptionsDueForPaymentRetry", reflect.TypeOf((*MockRepository)(nil).GetSubscriptionsDueForPaymentRetry), ctx, date)
+}
+
+// GetSubscriptionsDueForRenewal mocks base method.
+func (m *MockRepository) GetSubscriptionsDueForRenewal(ctx context.Context, date time.Time) ([]model.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscriptionsDueForRenewal", ctx, date)
+	ret0, _ := ret[0].([]model.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscriptionsDueForRenewal indicates an expected call of GetSubscriptionsDueForRenewal.
+func (mr *MockRepositoryMockRecorder) GetSubscriptionsDueForRenewal(ctx, date any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriptionsDueForRenewal", reflect.TypeOf((*MockRepository)(nil).GetSubscriptionsDueForRenewal), ctx, date)
+}
+
+// GetSubscriptionsDueForRenewalReminder mocks base method.
+func (m *MockRepository) GetSubscriptionsDueForRenewalReminder(ctx context.Context, from, until time.Time, minDurationDays int) ([]model.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscriptionsDueForRenewalReminder", ctx, from, until, minDurationDays)
+	ret0, _ := ret[0].([]model.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscriptionsDueForRenewalReminder indicates an expected call of GetSubscriptionsDueForRenewalReminder.
+func (mr *MockRepositoryMockRecorder) GetSubscriptionsDueForRenewalReminder(ctx, from, until, minDurationDays any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriptionsDueForRenewalReminder", reflect.TypeOf((*MockRepository)(nil).GetSubscriptionsDueForRenewalReminder), ctx, from, until, minDurationDays)
+}
+
+// GetSubscriptionsDueForResume mocks base method.
+func (m *MockRepository) GetSubscriptionsDueForResume(ctx context.Context, date time.Time) ([]model.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscriptionsDueForResume", ctx, date)
+	ret0, _ := ret[0].([]model.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscriptionsDueForResume indicates an expected call of GetSubscriptionsDueForResume.
+func (mr *MockRepositoryMockRecorder) GetSubscriptionsDueForResume(ctx, date any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriptionsDueForResume", reflect.TypeOf((*MockRepository)(nil).GetSubscriptionsDueForResume), ctx, date)
+}
+
+// GetSubscriptionsDueForTrialReminder mocks base method.
+func (m *MockRepository) GetSubscriptionsDueForTrialReminder(ctx context.Context, from, until time.Time) ([]model.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscriptionsDueForTrialReminder", ctx, from, until)
+	ret0, _ := ret[0].([]model.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscriptionsDueForTrialReminder indicates an expected call of GetSubscriptionsDueForTrialReminder.
+func (mr *MockRepositoryMockRecorder) GetSubscriptionsDueForTrialReminder(ctx, from, until any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriptionsDueForTrialReminder", reflect.TypeOf((*MockRepository)(nil).GetSubscriptionsDueForTrialReminder), ctx, from, until)
+}
+
+// GetUser mocks base method.
+func (m *MockRepository) GetUser(ctx context.Context, userID string) (model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", ctx, userID)
+	ret0, _ := ret[0].(model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockRepositoryMockRecorder) GetUser(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockRepository)(nil).GetUser), ctx, userID)
+}
+
+// GetUserReferralCode mocks base method.
+func (m *MockRepository) GetUserReferralCode(ctx context.Context, userID string) (model.ReferralCode, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserReferralCode", ctx, userID)
+	ret0, _ := ret[0].(model.ReferralCode)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetUserReferralCode indicates an expected call of GetUserReferralCode.
+func (mr *MockRepositoryMockRecorder) GetUserReferralCode(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserReferralCode", reflect.TypeOf((*MockRepository)(nil).GetUserReferralCode), ctx, userID)
+}
+
+// GetUserSubscriptions mocks base method.
+func (m *MockRepository) GetUserSubscriptions(ctx context.Context, userID string) ([]model.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserSubscriptions", ctx, userID)
+	ret0, _ := ret[0].([]model.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserSubscriptions indicates an expected call of GetUserSubscriptions.
+func (mr *MockRepositoryMockRecorder) GetUserSubscriptions(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserSubscriptions", reflect.TypeOf((*MockRepository)(nil).GetUserSubscriptions), ctx, userID)
+}
+
+// GetVoucherByCode mocks base method.
+func (m *MockRepository) GetVoucherByCode(ctx context.Context, voucherCode string) (model.Voucher, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVoucherByCode", ctx, voucherCode)
+	ret0, _ := ret[0].(model.Voucher)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVoucherByCode indicates an expected call of GetVoucherByCode.
+func (mr *MockRepositoryMockRecorder) GetVoucherByCode(ctx, voucherCode any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVoucherByCode", reflect.TypeOf((*MockRepository)(nil).GetVoucherByCode), ctx, voucherCode)
+}
+
+// GetWebhookDeliveries mocks base method.
+func (m *MockRepository) GetWebhookDeliveries(ctx context.Context, status model.WebhookDeliveryStatus) ([]model.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveries", ctx, status)
+	ret0, _ := ret[0].([]model.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDeliveries indicates an expected call of GetWebhookDeliveries.
+func (mr *MockRepositoryMockRecorder) GetWebhookDeliveries(ctx, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveries", reflect.TypeOf((*MockRepository)(nil).GetWebhookDeliveries), ctx, status)
+}
+
+// GetWebhookDeliveriesDue mocks base method.
+func (m *MockRepository) GetWebhookDeliveriesDue(ctx context.Context, now time.Time) ([]model.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveriesDue", ctx, now)
+	ret0, _ := ret[0].([]model.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDeliveriesDue indicates an expected call of GetWebhookDeliveriesDue.
+func (mr *MockRepositoryMockRecorder) GetWebhookDeliveriesDue(ctx, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveriesDue", reflect.TypeOf((*MockRepository)(nil).GetWebhookDeliveriesDue), ctx, now)
+}
+
+// GetWebhookDelivery mocks base method.
+func (m *MockRepository) GetWebhookDelivery(ctx context.Context, deliveryID string) (model.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDelivery", ctx, deliveryID)
+	ret0, _ := ret[0].(model.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDelivery indicates an expected call of GetWebhookDelivery.
+func (mr *MockRepositoryMockRecorder) GetWebhookDelivery(ctx, deliveryID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDelivery", reflect.TypeOf((*MockRepository)(nil).GetWebhookDelivery), ctx, deliveryID)
+}
+
+// GetWebhookEndpoint mocks base method.
+func (m *MockRepository) GetWebhookEndpoint(ctx context.Context, endpointID string) (model.WebhookEndpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookEndpoint", ctx, endpointID)
+	ret0, _ := ret[0].(model.WebhookEndpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookEndpoint indicates an expected call of GetWebhookEndpoint.
+func (mr *MockRepositoryMockRecorder) GetWebhookEndpoint(ctx, endpointID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookEndpoint", reflect.TypeOf((*MockRepository)(nil).GetWebhookEndpoint), ctx, endpointID)
+}
+
+// GetWebhookEndpoints mocks base method.
+func (m *MockRepository) GetWebhookEndpoints(ctx context.Context) ([]model.WebhookEndpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookEndpoints", ctx)
+	ret0, _ := ret[0].([]model.WebhookEndpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookEndpoints indicates an expected call of GetWebhookEndpoints.
+func (mr *MockRepositoryMockRecorder) GetWebhookEndpoints(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookEndpoints", reflect.TypeOf((*MockRepository)(nil).GetWebhookEndpoints), ctx)
+}
+
+// GetWebhookEvent mocks base method.
+func (m *MockRepository) GetWebhookEvent(ctx context.Context, eventID string) (model.WebhookEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookEvent", ctx, eventID)
+	ret0, _ := ret[0].(model.WebhookEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookEvent indicates an expected call of GetWebhookEvent.
+func (mr *MockRepositoryMockRecorder) GetWebhookEvent(ctx, eventID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookEvent", reflect.TypeOf((*MockRepository)(nil).GetWebhookEvent), ctx, eventID)
+}
+
+// LockSubscription mocks base method.
+func (m *MockRepository) LockSubscription(ctx context.Context, subscriptionID string) (model.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockSubscription", ctx, subscriptionID)
+	ret0, _ := ret[0].(model.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockSubscription indicates an expected call of LockSubscription.
+func (mr *MockRepositoryMockRecorder) LockSubscription(ctx, subscriptionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockSubscription", reflect.TypeOf((*MockRepository)(nil).LockSubscription), ctx, subscriptionID)
+}
+
+// MarkPriceMigrationApplied mocks base method.
+func (m *MockRepository) MarkPriceMigrationApplied(ctx context.Context, migrationID string, appliedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkPriceMigrationApplied", ctx, migrationID, appliedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkPriceMigrationApplied indicates an expected call of MarkPriceMigrationApplied.
+func (mr *MockRepositoryMockRecorder) MarkPriceMigrationApplied(ctx, migrationID, appliedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkPriceMigrationApplied", reflect.TypeOf((*MockRepository)(nil).MarkPriceMigrationApplied), ctx, migrationID, appliedAt)
+}
+
+// SaveCancellation mocks base method.
+func (m *MockRepository) SaveCancellation(ctx context.Context, cancellation model.Cancellation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveCancellation", ctx, cancellation)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveCancellation indicates an expected call of SaveCancellation.
+func (mr *MockRepositoryMockRecorder) SaveCancellation(ctx, cancellation any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveCancellation", reflect.TypeOf((*MockRepository)(nil).SaveCancellation), ctx, cancellation)
+}
+
+// SaveCreditNote mocks base method.
+func (m *MockRepository) SaveCreditNote(ctx context.Context, creditNote model.CreditNote) (model.CreditNote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveCreditNote", ctx, creditNote)
+	ret0, _ := ret[0].(model.CreditNote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveCreditNote indicates an expected call of SaveCreditNote.
+func (mr *MockRepositoryMockRecorder) SaveCreditNote(ctx, creditNote any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveCreditNote", reflect.TypeOf((*MockRepository)(nil).SaveCreditNote), ctx, creditNote)
+}
+
+// SaveCreditTransaction mocks base method.
+func (m *MockRepository) SaveCreditTransaction(ctx context.Context, transaction model.CreditTransaction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveCreditTransaction", ctx, transaction)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveCreditTransaction indicates an expected call of SaveCreditTransaction.
+func (mr *MockRepositoryMockRecorder) SaveCreditTransaction(ctx, transaction any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveCreditTransaction", reflect.TypeOf((*MockRepository)(nil).SaveCreditTransaction), ctx, transaction)
+}
+
+// SaveGift mocks base method.
+func (m *MockRepository) SaveGift(ctx context.Context, gift model.Gift) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveGift", ctx, gift)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveGift indicates an expected call of SaveGift.
+func (mr *MockRepositoryMockRecorder) SaveGift(ctx, gift any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveGift", reflect.TypeOf((*MockRepository)(nil).SaveGift), ctx, gift)
+}
+
+// SaveInvoice mocks base method.
+func (m *MockRepository) SaveInvoice(ctx context.Context, invoice model.Invoice) (model.Invoice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveInvoice", ctx, invoice)
+	ret0, _ := ret[0].(model.Invoice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveInvoice indicates an expected call of SaveInvoice.
+func (mr *MockRepositoryMockRecorder) SaveInvoice(ctx, invoice any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveInvoice", reflect.TypeOf((*MockRepository)(nil).SaveInvoice), ctx, invoice)
+}
+
+// SaveOrganization mocks base method.
+func (m *MockRepository) SaveOrganization(ctx context.Context, organization model.Organization) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveOrganization", ctx, organization)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveOrganization indicates an expected call of SaveOrganization.
+func (mr *MockRepositoryMockRecorder) SaveOrganization(ctx, organization any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOrganization", reflect.TypeOf((*MockRepository)(nil).SaveOrganization), ctx, organization)
+}
+
+// SaveOrganizationAdmin mocks base method.
+func (m *MockRepository) SaveOrganizationAdmin(ctx context.Context, organizationID, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveOrganizationAdmin", ctx, organizationID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveOrganizationAdmin indicates an expected call of SaveOrganizationAdmin.
+func (mr *MockRepositoryMockRecorder) SaveOrganizationAdmin(ctx, organizationID, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOrganizationAdmin", reflect.TypeOf((*MockRepository)(nil).SaveOrganizationAdmin), ctx, organizationID, userID)
+}
+
+// SaveOutboxMessage mocks base method.
+func (m *MockRepository) SaveOutboxMessage(ctx context.Context, message model.OutboxMessage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveOutboxMessage", ctx, message)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveOutboxMessage indicates an expected call of SaveOutboxMessage.
+func (mr *MockRepositoryMockRecorder) SaveOutboxMessage(ctx, message any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOutboxMessage", reflect.TypeOf((*MockRepository)(nil).SaveOutboxMessage), ctx, message)
+}
+
+// SavePause mocks base method.
+func (m *MockRepository) SavePause(ctx context.Context, pause model.Pause) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePause", ctx, pause)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePause indicates an expected call of SavePause.
+func (mr *MockRepositoryMockRecorder) SavePause(ctx, pause any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePause", reflect.TypeOf((*MockRepository)(nil).SavePause), ctx, pause)
+}
+
+// SavePaymentAttempt mocks base method.
+func (m *MockRepository) SavePaymentAttempt(ctx context.Context, attempt model.PaymentAttempt) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePaymentAttempt", ctx, attempt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePaymentAttempt indicates an expected call of SavePaymentAttempt.
+func (mr *MockRepositoryMockRecorder) SavePaymentAttempt(ctx, attempt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePaymentAttempt", reflect.TypeOf((*MockRepository)(nil).SavePaymentAttempt), ctx, attempt)
+}
+
+// SavePriceChange mocks base method.
+func (m *MockRepository) SavePriceChange(ctx context.Context, change model.PriceChange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePriceChange", ctx, change)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePriceChange indicates an expected call of SavePriceChange.
+func (mr *MockRepositoryMockRecorder) SavePriceChange(ctx, change any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePriceChange", reflect.TypeOf((*MockRepository)(nil).SavePriceChange), ctx, change)
+}
+
+// SavePriceMigration mocks base method.
+func (m *MockRepository) SavePriceMigration(ctx context.Context, migration model.PriceMigration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePriceMigration", ctx, migration)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SavePriceMigration indicates an expected call of SavePriceMigration.
+func (mr *MockRepositoryMockRecorder) SavePriceMigration(ctx, migration any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePriceMigration", reflect.TypeOf((*MockRepository)(nil).SavePriceMigration), ctx, migration)
+}
+
+// SaveReactivation mocks base method.
+func (m *MockRepository) SaveReactivation(ctx context.Context, reactivation model.Reactivation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveReactivation", ctx, reactivation)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveReactivation indicates an expected call of SaveReactivation.
+func (mr *MockRepositoryMockRecorder) SaveReactivation(ctx, reactivation any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveReactivation", reflect.TypeOf((*MockRepository)(nil).SaveReactivation), ctx, reactivation)
+}
+
+// SaveReferral mocks base method.
+func (m *MockRepository) SaveReferral(ctx context.Context, referral model.Referral) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveReferral", ctx, referral)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveReferral indicates an expected call of SaveReferral.
+func (mr *MockRepositoryMockRecorder) SaveReferral(ctx, referral any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveReferral", reflect.TypeOf((*MockRepository)(nil).SaveReferral), ctx, referral)
+}
+
+// SaveReferralCode mocks base method.
+func (m *MockRepository) SaveReferralCode(ctx context.Context, code model.ReferralCode) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveReferralCode", ctx, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveReferralCode indicates an expected call of SaveReferralCode.
+func (mr *MockRepositoryMockRecorder) SaveReferralCode(ctx, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveReferralCode", reflect.TypeOf((*MockRepository)(nil).SaveReferralCode), ctx, code)
+}
+
+// SaveReferralReward mocks base method.
+func (m *MockRepository) SaveReferralReward(ctx context.Context, reward model.ReferralReward) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveReferralReward", ctx, reward)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveReferralReward indicates an expected call of SaveReferralReward.
+func (mr *MockRepositoryMockRecorder) SaveReferralReward(ctx, reward any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveReferralReward", reflect.TypeOf((*MockRepository)(nil).SaveReferralReward), ctx, reward)
+}
+
+// SaveRefund mocks base method.
+func (m *MockRepository) SaveRefund(ctx context.Context, refund model.Refund) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveRefund", ctx, refund)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveRefund indicates an expected call of SaveRefund.
+func (mr *MockRepositoryMockRecorder) SaveRefund(ctx, refund any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRefund", reflect.TypeOf((*MockRepository)(nil).SaveRefund), ctx, refund)
+}
+
+// SaveReminder mocks base method.
+func (m *MockRepository) SaveReminder(ctx context.Context, reminder model.Reminder) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveReminder", ctx, reminder)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveReminder indicates an expected call of SaveReminder.
+func (mr *MockRepositoryMockRecorder) SaveReminder(ctx, reminder any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveReminder", reflect.TypeOf((*MockRepository)(nil).SaveReminder), ctx, reminder)
+}
+
+// SaveRetentionOfferAcceptance mocks base method.
+func (m *MockRepository) SaveRetentionOfferAcceptance(ctx context.Context, acceptance model.RetentionOfferAcceptance) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveRetentionOfferAcceptance", ctx, acceptance)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveRetentionOfferAcceptance indicates an expected call of SaveRetentionOfferAcceptance.
+func (mr *MockRepositoryMockRecorder) SaveRetentionOfferAcceptance(ctx, acceptance any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRetentionOfferAcceptance", reflect.TypeOf((*MockRepository)(nil).SaveRetentionOfferAcceptance), ctx, acceptance)
+}
+
+// SaveSeatChange mocks base method.
+func (m *MockRepository) SaveSeatChange(ctx context.Context, change model.SeatChange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSeatChange", ctx, change)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSeatChange indicates an expected call of SaveSeatChange.
+func (mr *MockRepositoryMockRecorder) SaveSeatChange(ctx, change any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSeatChange", reflect.TypeOf((*MockRepository)(nil).SaveSeatChange), ctx, change)
+}
+
+// SaveSubscription mocks base method.
+func (m *MockRepository) SaveSubscription(ctx context.Context, subscription model.Subscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSubscription", ctx, subscription)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSubscription indicates an expected call of SaveSubscription.
+func (mr *MockRepositoryMockRecorder) SaveSubscription(ctx, subscription any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSubscription", reflect.TypeOf((*MockRepository)(nil).SaveSubscription), ctx, subscription)
+}
+
+// SaveSubscriptionAddOn mocks base method.
+func (m *MockRepository) SaveSubscriptionAddOn(ctx context.Context, addOn model.SubscriptionAddOn) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSubscriptionAddOn", ctx, addOn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSubscriptionAddOn indicates an expected call of SaveSubscriptionAddOn.
+func (mr *MockRepositoryMockRecorder) SaveSubscriptionAddOn(ctx, addOn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSubscriptionAddOn", reflect.TypeOf((*MockRepository)(nil).SaveSubscriptionAddOn), ctx, addOn)
+}
+
+// SaveSubscriptionMember mocks base method.
+func (m *MockRepository) SaveSubscriptionMember(ctx context.Context, member model.SubscriptionMember) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSubscriptionMember", ctx, member)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSubscriptionMember indicates an expected call of SaveSubscriptionMember.
+func (mr *MockRepositoryMockRecorder) SaveSubscriptionMember(ctx, member any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSubscriptionMember", reflect.TypeOf((*MockRepository)(nil).SaveSubscriptionMember), ctx, member)
+}
+
+// SaveWebhookEndpoint mocks base method.
+func (m *MockRepository) SaveWebhookEndpoint(ctx context.Context, endpoint model.WebhookEndpoint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveWebhookEndpoint", ctx, endpoint)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveWebhookEndpoint indicates an expected call of SaveWebhookEndpoint.
+func (mr *MockRepositoryMockRecorder) SaveWebhookEndpoint(ctx, endpoint any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWebhookEndpoint", reflect.TypeOf((*MockRepository)(nil).SaveWebhookEndpoint), ctx, endpoint)
+}
+
+// SaveWebhookEvent mocks base method.
+func (m *MockRepository) SaveWebhookEvent(ctx context.Context, event model.WebhookEvent, deliveries []model.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveWebhookEvent", ctx, event, deliveries)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveWebhookEvent indicates an expected call of SaveWebhookEvent.
+func (mr *MockRepositoryMockRecorder) SaveWebhookEvent(ctx, event, deliveries any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWebhookEvent", reflect.TypeOf((*MockRepository)(nil).SaveWebhookEvent), ctx, event, deliveries)
+}
+
+// TryAdvisoryLock mocks base method.
+func (m *MockRepository) TryAdvisoryLock(ctx context.Context, key int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TryAdvisoryLock", ctx, key)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TryAdvisoryLock indicates an expected call of TryAdvisoryLock.
+func (mr *MockRepositoryMockRecorder) TryAdvisoryLock(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TryAdvisoryLock", reflect.TypeOf((*MockRepository)(nil).TryAdvisoryLock), ctx, key)
+}
+
+// UpdateGift mocks base method.
+func (m *MockRepository) UpdateGift(ctx context.Context, gift model.Gift) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateGift", ctx, gift)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateGift indicates an expected call of UpdateGift.
+func (mr *MockRepositoryMockRecorder) UpdateGift(ctx, gift any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateGift", reflect.TypeOf((*MockRepository)(nil).UpdateGift), ctx, gift)
+}
+
+// UpdatePriceChange mocks base method.
+func (m *MockRepository) UpdatePriceChange(ctx context.Context, change model.PriceChange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePriceChange", ctx, change)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePriceChange indicates an expected call of UpdatePriceChange.
+func (mr *MockRepositoryMockRecorder) UpdatePriceChange(ctx, change any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePriceChange", reflect.TypeOf((*MockRepository)(nil).UpdatePriceChange), ctx, change)
+}
+
+// UpdateProductPrice mocks base method.
+func (m *MockRepository) UpdateProductPrice(ctx context.Context, productID string, price, tax, totalPrice float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProductPrice", ctx, productID, price, tax, totalPrice)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProductPrice indicates an expected call of UpdateProductPrice.
+func (mr *MockRepositoryMockRecorder) UpdateProductPrice(ctx, productID, price, tax, totalPrice any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProductPrice", reflect.TypeOf((*MockRepository)(nil).UpdateProductPrice), ctx, productID, price, tax, totalPrice)
+}
+
+// UpdateReferral mocks base method.
+func (m *MockRepository) UpdateReferral(ctx context.Context, referral model.Referral) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateReferral", ctx, referral)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateReferral indicates an expected call of UpdateReferral.
+func (mr *MockRepositoryMockRecorder) UpdateReferral(ctx, referral any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReferral", reflect.TypeOf((*MockRepository)(nil).UpdateReferral), ctx, referral)
+}
+
+// UpdateRefund mocks base method.
+func (m *MockRepository) UpdateRefund(ctx context.Context, refund model.Refund) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRefund", ctx, refund)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRefund indicates an expected call of UpdateRefund.
+func (mr *MockRepositoryMockRecorder) UpdateRefund(ctx, refund any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRefund", reflect.TypeOf((*MockRepository)(nil).UpdateRefund), ctx, refund)
+}
+
+// UpdateSubscription mocks base method.
+func (m *MockRepository) UpdateSubscription(ctx context.Context, subscription model.Subscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSubscription", ctx, subscription)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSubscription indicates an expected call of UpdateSubscription.
+func (mr *MockRepositoryMockRecorder) UpdateSubscription(ctx, subscription any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSubscription", reflect.TypeOf((*MockRepository)(nil).UpdateSubscription), ctx, subscription)
+}
+
+// UpdateSubscriptionAddOn mocks base method.
+func (m *MockRepository) UpdateSubscriptionAddOn(ctx context.Context, addOn model.SubscriptionAddOn) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSubscriptionAddOn", ctx, addOn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSubscriptionAddOn indicates an expected call of UpdateSubscriptionAddOn.
+func (mr *MockRepositoryMockRecorder) UpdateSubscriptionAddOn(ctx, addOn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSubscriptionAddOn", reflect.TypeOf((*MockRepository)(nil).UpdateSubscriptionAddOn), ctx, addOn)
+}
+
+// UpdateSubscriptionMember mocks base method.
+func (m *MockRepository) UpdateSubscriptionMember(ctx context.Context, member model.SubscriptionMember) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSubscriptionMember", ctx, member)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSubscriptionMember indicates an expected call of UpdateSubscriptionMember.
+func (mr *MockRepositoryMockRecorder) UpdateSubscriptionMember(ctx, member any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSubscriptionMember", reflect.TypeOf((*MockRepository)(nil).UpdateSubscriptionMember), ctx, member)
+}
+
+// UpdateWebhookDelivery mocks base method.
+func (m *MockRepository) UpdateWebhookDelivery(ctx context.Context, delivery model.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhookDelivery", ctx, delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebhookDelivery indicates an expected call of UpdateWebhookDelivery.
+func (mr *MockRepositoryMockRecorder) UpdateWebhookDelivery(ctx, delivery any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhookDelivery", reflect.TypeOf((*MockRepository)(nil).UpdateWebhookDelivery), ctx, delivery)
+}
+
+// WithinTx mocks base method.
+func (m *MockRepository) WithinTx(ctx context.Context, fn func(Repository) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithinTx", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithinTx indicates an expected call of WithinTx.
+func (mr *MockRepositoryMockRecorder) WithinTx(ctx, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithinTx", reflect.TypeOf((*MockRepository)(nil).WithinTx), ctx, fn)
+}
+
+// MockPaymentGateway is a mock of PaymentGateway interface.
+type MockPaymentGateway struct {
+	ctrl     *gomock.Controller
+	recorder *MockPaymentGatewayMockRecorder
+}
+
+// MockPaymentGatewayMockRecorder is the mock recorder for MockPaymentGateway.
+type MockPaymentGatewayMockRecorder struct {
+	mock *MockPaymentGateway
+}
+
+// NewMockPaymentGateway creates a new mock instance.
+func NewMockPaymentGateway(ctrl *gomock.Controller) *MockPaymentGateway {
+	mock := &MockPaymentGateway{ctrl: ctrl}
+	mock.recorder = &MockPaymentGatewayMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPaymentGateway) EXPECT() *MockPaymentGatewayMockRecorder {
+	return m.recorder
+}
+
+// Charge mocks base method.
+func (m *MockPaymentGateway) Charge(ctx context.Context, userID uuid.UUID, amount float64, reference string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Charge", ctx, userID, amount, reference)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Charge indicates an expected call of Charge.
+func (mr *MockPaymentGatewayMockRecorder) Charge(ctx, userID, amount, reference any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Charge", reflect.TypeOf((*MockPaymentGateway)(nil).Charge), ctx, userID, amount, reference)
+}
+
+// Refund mocks base method.
+func (m *MockPaymentGateway) Refund(ctx context.Context, userID uuid.UUID, amount float64, chargeTransactionID, reference string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refund", ctx, userID, amount, chargeTransactionID, reference)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refund indicates an expected call of Refund.
+func (mr *MockPaymentGatewayMockRecorder) Refund(ctx, userID, amount, chargeTransactionID, reference any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refund", reflect.TypeOf((*MockPaymentGateway)(nil).Refund), ctx, userID, amount, chargeTransactionID, reference)
+}
+
+// MockWebhookSender is a mock of WebhookSender interface.
+type MockWebhookSender struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookSenderMockRecorder
+}
+
+// MockWebhookSenderMockRecorder is the mock recorder for MockWebhookSender.
+type MockWebhookSenderMockRecorder struct {
+	mock *MockWebhookSender
+}
+
+// NewMockWebhookSender creates a new mock instance.
+func NewMockWebhookSender(ctrl *gomock.Controller) *MockWebhookSender {
+	mock := &MockWebhookSender{ctrl: ctrl}
+	mock.recorder = &MockWebhookSenderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookSender) EXPECT() *MockWebhookSenderMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method.
+func (m *MockWebhookSender) Send(ctx context.Context, endpoint model.WebhookEndpoint, event model.WebhookEvent, deliveryID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", ctx, endpoint, event, deliveryID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockWebhookSenderMockRecorder) Send(ctx, endpoint, event, deliveryID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockWebhookSender)(nil).Send), ctx, endpoint, event, deliveryID)
+}
+
+// MockNotifier is a mock of Notifier interface.
+type MockNotifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotifierMockRecorder
+}
+
+// MockNotifierMockRecorder is the mock recorder for MockNotifier.
+type MockNotifierMockRecorder struct {
+	mock *MockNotifier
+}
+
+// NewMockNotifier creates a new mock instance.
+func NewMockNotifier(ctrl *gomock.Controller) *MockNotifier {
+	mock := &MockNotifier{ctrl: ctrl}
+	mock.recorder = &MockNotifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotifier) EXPECT() *MockNotifierMockRecorder {
+	return m.recorder
+}
+
+// Notify mocks base method.
+func (m *MockNotifier) Notify(ctx context.Context, notification model.Notification) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Notify", ctx, notification)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Notify indicates an expected call of Notify.
+func (mr *MockNotifierMockRecorder) Notify(ctx, notification any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Notify", reflect.TypeOf((*MockNotifier)(nil).Notify), ctx, notification)
 }
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/google/uuid"
+	"gymondo/internal/model"
+)
+
+// GrantCredit adds goodwill credit to the user's balance. The credit is used
+// up before the payment method is charged on the next subscribe or renewal.
+func (s *Service) GrantCredit(
+	ctx context.Context,
+	userID string,
+	amount float64,
+	reason string,
+) (model.CreditTransaction, error) {
+	if amount <= 0 {
+		return model.CreditTransaction{}, fmt.Errorf("credit amount must be greater than 0")
+	}
+	if reason == "" {
+		return model.CreditTransaction{}, fmt.Errorf("credit reason is required")
+	}
+
+	user, err := s.repository.GetUser(ctx, userID)
+	if err != nil {
+		return model.CreditTransaction{}, fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	transaction := s.newCreditTransaction(user.ID, model.CreditGrant, model.GoodwillAccount, amount, reason)
+	if err := s.repository.SaveCreditTransaction(ctx, transaction); err != nil {
+		return model.CreditTransaction{}, fmt.Errorf("failed to save credit transaction: %w", err)
+	}
+
+	return transaction, nil
+}
+
+func (s *Service) FindCreditBalance(ctx context.Context, userID string) (model.CreditBalance, error) {
+	user, err := s.repository.GetUser(ctx, userID)
+	if err != nil {
+		return model.CreditBalance{}, fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	balance, err := s.repository.GetCreditBalance(ctx, userID)
+	if err != nil {
+		return model.CreditBalance{}, fmt.Errorf("failed to fetch credit balance: %w", err)
+	}
+
+	transactions, err := s.repository.GetCreditTransactions(ctx, userID)
+	if err != nil {
+		return model.CreditBalance{}, fmt.Errorf("failed to fetch credit transactions: %w", err)
+	}
+	if transactions == nil {
+		transactions = []model.CreditTransaction{}
+	}
+
+	return model.CreditBalance{
+		UserID:       user.ID,
+		Balance:      balance,
+		Transactions: transactions,
+	}, nil
+}
+
+// newCreditTransaction builds a transaction that moves amount between the
+// customer's credit account and the given counter account. A positive amount
+// credits the customer, a negative one debits them.
+func (s *Service) newCreditTransaction(
+	userID uuid.UUID,
+	kind model.CreditTransactionKind,
+	counterAccount model.CreditAccount,
+	amount float64,
+	reason string,
+) model.CreditTransaction {
+	return model.CreditTransaction{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Kind:      kind,
+		Reason:    reason,
+		CreatedAt: s.now(),
+		Entries: []model.CreditEntry{
+			{Account: model.CustomerCreditAccount, Amount: amount},
+			{Account: counterAccount, Amount: -amount},
+		},
+	}
+}
+
+// applicableCredit returns how much of the user's credit balance can be put
+// towards a charge of the given amount.
+func (s *Service) applicableCredit(ctx context.Context, userID uuid.UUID, amount float64) (float64, error) {
+	balance, err := s.repository.GetCreditBalance(ctx, userID.String())
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch credit balance: %w", err)
+	}
+	if balance <= 0 {
+		return 0, nil
+	}
+
+	return math.Min(balance, amount), nil
+}
@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gymondo/internal/model"
+	"testing"
+	"time"
+)
+
+func Test_Service_GrantCredit(t *testing.T) {
+	t.Parallel()
+
+	t.Run("invalid amount", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo}
+
+		_, err := service.GrantCredit(context.Background(), uuid.New().String(), 0, "goodwill")
+		assert.ErrorContains(t, err, "credit amount must be greater than 0")
+	})
+
+	t.Run("missing reason", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo}
+
+		_, err := service.GrantCredit(context.Background(), uuid.New().String(), 10, "")
+		assert.ErrorContains(t, err, "credit reason is required")
+	})
+
+	t.Run("successful grant", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo}
+
+		userID := uuid.New()
+
+		mockRepo.EXPECT().GetUser(gomock.Any(), userID.String()).Return(model.User{ID: userID}, nil)
+		mockRepo.EXPECT().SaveCreditTransaction(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, transaction model.CreditTransaction) error {
+				assert.Equal(t, model.CreditGrant, transaction.Kind)
+				assert.Equal(t, 15.0, transaction.CustomerAmount())
+				assert.Equal(t, []model.CreditEntry{
+					{Account: model.CustomerCreditAccount, Amount: 15},
+					{Account: model.GoodwillAccount, Amount: -15},
+				}, transaction.Entries)
+				return nil
+			},
+		)
+
+		transaction, err := service.GrantCredit(context.Background(), userID.String(), 15, "delayed class")
+		assert.NoError(t, err)
+		assert.Equal(t, "delayed class", transaction.Reason)
+	})
+}
+
+func Test_Service_FindCreditBalance(t *testing.T) {
+	t.Parallel()
+
+	t.Run("successful balance fetch", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo}
+
+		userID := uuid.New()
+
+		mockRepo.EXPECT().GetUser(gomock.Any(), userID.String()).Return(model.User{ID: userID}, nil)
+		mockRepo.EXPECT().GetCreditBalance(gomock.Any(), userID.String()).Return(12.5, nil)
+		mockRepo.EXPECT().GetCreditTransactions(gomock.Any(), userID.String()).Return(nil, nil)
+
+		balance, err := service.FindCreditBalance(context.Background(), userID.String())
+		assert.NoError(t, err)
+		assert.Equal(t, 12.5, balance.Balance)
+		assert.Empty(t, balance.Transactions)
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo}
+
+		mockRepo.EXPECT().GetUser(gomock.Any(), "999").Return(model.User{}, fmt.Errorf("user not found"))
+
+		_, err := service.FindCreditBalance(context.Background(), "999")
+		assert.ErrorContains(t, err, "failed to fetch user")
+	})
+}
+
+func Test_Service_RenewSubscriptions_WithCredit(t *testing.T) {
+	t.Parallel()
+
+	t.Run("credit covers part of the price", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockPayments := NewMockPaymentGateway(ctrl)
+		service := &Service{repository: mockRepo, payments: mockPayments, config: DefaultConfig()}
+
+		endDate := time.Now().Truncate(24 * time.Hour)
+		subscription := model.Subscription{
+			ID:           uuid.New(),
+			UserID:       uuid.New(),
+			StartDate:    endDate.AddDate(0, 0, -30),
+			EndDate:      endDate,
+			DurationDays: 30,
+			TotalPrice:   11,
+			Status:       model.Active,
+		}
+
+		mockRepo.EXPECT().GetSubscriptionsDueForRenewal(gomock.Any(), gomock.Any()).Return([]model.Subscription{subscription}, nil)
+		mockRepo.EXPECT().GetPendingPriceChange(gomock.Any(), subscription.ID.String()).Return(model.PriceChange{}, false, nil)
+		mockRepo.EXPECT().GetCreditBalance(gomock.Any(), subscription.UserID.String()).Return(4.0, nil)
+		mockPayments.EXPECT().Charge(gomock.Any(), subscription.UserID, 7.0, gomock.Any()).Return("tx-1", nil)
+		expectWithinTx(mockRepo)
+		expectLockSubscription(mockRepo, subscription)
+		mockRepo.EXPECT().SavePaymentAttempt(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, attempt model.PaymentAttempt) error {
+				assert.Equal(t, 7.0, attempt.Amount)
+				assert.Equal(t, 4.0, attempt.CreditApplied)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SaveCreditTransaction(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, transaction model.CreditTransaction) error {
+				assert.Equal(t, model.CreditConsumption, transaction.Kind)
+				assert.Equal(t, -4.0, transaction.CustomerAmount())
+				assert.Equal(t, &subscription.ID, transaction.SubscriptionID)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().GetUser(gomock.Any(), subscription.UserID.String()).Return(model.User{ID: subscription.UserID}, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), subscription.ProductID.String()).Return(model.Product{ID: subscription.ProductID}, nil)
+		mockRepo.EXPECT().SaveInvoice(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, invoice model.Invoice) (model.Invoice, error) {
+				return invoice, nil
+			},
+		)
+
+		err := service.RenewSubscriptions(context.Background())
+		assert.NoError(t, err)
+	})
+
+	t.Run("credit covers the whole price", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockPayments := NewMockPaymentGateway(ctrl)
+		service := &Service{repository: mockRepo, payments: mockPayments, config: DefaultConfig()}
+
+		endDate := time.Now().Truncate(24 * time.Hour)
+		subscription := model.Subscription{
+			ID:           uuid.New(),
+			UserID:       uuid.New(),
+			StartDate:    endDate.AddDate(0, 0, -30),
+			EndDate:      endDate,
+			DurationDays: 30,
+			TotalPrice:   11,
+			Status:       model.Active,
+		}
+
+		mockRepo.EXPECT().GetSubscriptionsDueForRenewal(gomock.Any(), gomock.Any()).Return([]model.Subscription{subscription}, nil)
+		mockRepo.EXPECT().GetPendingPriceChange(gomock.Any(), subscription.ID.String()).Return(model.PriceChange{}, false, nil)
+		mockRepo.EXPECT().GetCreditBalance(gomock.Any(), subscription.UserID.String()).Return(50.0, nil)
+		expectWithinTx(mockRepo)
+		expectLockSubscription(mockRepo, subscription)
+		mockRepo.EXPECT().SavePaymentAttempt(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, attempt model.PaymentAttempt) error {
+				assert.Equal(t, model.PaymentSucceeded, attempt.Status)
+				assert.Equal(t, 0.0, attempt.Amount)
+				assert.Equal(t, 11.0, attempt.CreditApplied)
+				assert.Empty(t, attempt.TransactionID)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SaveCreditTransaction(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().GetUser(gomock.Any(), subscription.UserID.String()).Return(model.User{ID: subscription.UserID}, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), subscription.ProductID.String()).Return(model.Product{ID: subscription.ProductID}, nil)
+		mockRepo.EXPECT().SaveInvoice(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, invoice model.Invoice) (model.Invoice, error) {
+				return invoice, nil
+			},
+		)
+
+		err := service.RenewSubscriptions(context.Background())
+		assert.NoError(t, err)
+	})
+}
+
+func Test_Service_attemptPayment(t *testing.T) {
+	t.Parallel()
+
+	t.Run("amount below zero charges nothing", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockPayments := NewMockPaymentGateway(ctrl)
+		service := &Service{repository: mockRepo, payments: mockPayments, config: DefaultConfig()}
+
+		subscription := model.Subscription{ID: uuid.New(), UserID: uuid.New()}
+		mockRepo.EXPECT().GetCreditBalance(gomock.Any(), subscription.UserID.String()).Return(5.0, nil)
+
+		attempt, err := service.attemptPayment(context.Background(), subscription, -12.5, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, model.PaymentSucceeded, attempt.Status)
+		assert.Equal(t, 0.0, attempt.Amount)
+		assert.Equal(t, 0.0, attempt.CreditApplied)
+	})
+	t.Run("credit spent in the meantime charges the full amount", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockPayments := NewMockPaymentGateway(ctrl)
+		service := &Service{repository: mockRepo, payments: mockPayments, config: DefaultConfig()}
+
+		subscription := model.Subscription{ID: uuid.New(), UserID: uuid.New()}
+		mockRepo.EXPECT().GetCreditBalance(gomock.Any(), subscription.UserID.String()).Return(4.0, nil)
+		mockRepo.EXPECT().SaveCreditTransaction(gomock.Any(), gomock.Any()).Return(model.ErrInsufficientCredit)
+		mockPayments.EXPECT().Charge(gomock.Any(), subscription.UserID, 11.0, gomock.Any()).Return("tx-1", nil)
+
+		attempt, err := service.attemptPayment(context.Background(), subscription, 11, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, model.PaymentSucceeded, attempt.Status)
+		assert.Equal(t, 11.0, attempt.Amount)
+		assert.Equal(t, 0.0, attempt.CreditApplied)
+	})
+
+	t.Run("declined charge gives the credit back", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockPayments := NewMockPaymentGateway(ctrl)
+		service := &Service{repository: mockRepo, payments: mockPayments, config: DefaultConfig()}
+
+		subscription := model.Subscription{ID: uuid.New(), UserID: uuid.New()}
+		mockRepo.EXPECT().GetCreditBalance(gomock.Any(), subscription.UserID.String()).Return(4.0, nil)
+		gomock.InOrder(
+			mockRepo.EXPECT().SaveCreditTransaction(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, transaction model.CreditTransaction) error {
+					assert.Equal(t, -4.0, transaction.CustomerAmount())
+					return nil
+				},
+			),
+			mockPayments.EXPECT().Charge(gomock.Any(), subscription.UserID, 7.0, gomock.Any()).Return("", errors.New("card declined")),
+			mockRepo.EXPECT().SaveCreditTransaction(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, transaction model.CreditTransaction) error {
+					assert.Equal(t, 4.0, transaction.CustomerAmount())
+					return nil
+				},
+			),
+		)
+
+		attempt, err := service.attemptPayment(context.Background(), subscription, 11, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, model.PaymentFailed, attempt.Status)
+		assert.Equal(t, 0.0, attempt.CreditApplied)
+	})
+}
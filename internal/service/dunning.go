@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"gymondo/internal/model"
+)
+
+// RetryFailedPayments retries the renewal charge of every past due
+// subscription whose next retry is due. Subscriptions are reactivated on a
+// successful charge and canceled once the retry schedule is exhausted.
+func (s *Service) RetryFailedPayments(ctx context.Context) error {
+	subscriptions, err := s.repository.GetSubscriptionsDueForPaymentRetry(ctx, s.now())
+	if err != nil {
+		return fmt.Errorf("failed to fetch subscriptions due for payment retry: %w", err)
+	}
+
+	for _, subscription := range subscriptions {
+		if err := s.retryPayment(ctx, subscription); err != nil {
+			log.Printf("Error retrying payment for subscription %s: %v", subscription.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) retryPayment(ctx context.Context, subscription model.Subscription) error {
+	attempts, err := s.repository.GetPaymentAttempts(ctx, subscription.ID.String())
+	if err != nil {
+		return fmt.Errorf("failed to fetch payment attempts: %w", err)
+	}
+
+	attemptNumber := 1
+	for _, attempt := range attempts {
+		if subscription.PastDueDate != nil && !attempt.AttemptedAt.Before(*subscription.PastDueDate) {
+			attemptNumber++
+		}
+	}
+
+	var attempt model.PaymentAttempt
+	err = s.withinTx(ctx, func(tx *Service) error {
+		if err := tx.lockSubscription(ctx, subscription); err != nil {
+			return err
+		}
+
+		attempt, err = tx.attemptPayment(ctx, subscription, renewalTotal(subscription), attemptNumber)
+		if err != nil {
+			return err
+		}
+
+		if attempt.Status == model.PaymentSucceeded {
+			startNextPeriod(&subscription)
+		} else {
+			subscription.NextPaymentRetryDate = tx.nextPaymentRetryDate(*subscription.PastDueDate, attemptNumber)
+			attempt.NextRetryDate = subscription.NextPaymentRetryDate
+			if subscription.NextPaymentRetryDate == nil {
+				log.Printf("Payment retries exhausted for subscription %s, canceling", subscription.ID)
+				today := tx.today(subscription.Location())
+				subscription.Status = model.Canceled
+				subscription.CanceledDate = &today
+			}
+		}
+
+		return tx.recordPayment(ctx, subscription, attempt)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.notifyDeclinedPayment(ctx, subscription, attempt)
+
+	return nil
+}
+
+// startDunning marks the subscription as past due after its renewal charge
+// was declined. Access continues until the grace period, which lasts until
+// the last scheduled retry, ends.
+func (s *Service) startDunning(subscription *model.Subscription, today time.Time) {
+	retryDays := s.config.DunningRetryDays
+	if len(retryDays) == 0 {
+		subscription.Status = model.Canceled
+		subscription.CanceledDate = &today
+		return
+	}
+
+	graceEndDate := today.AddDate(0, 0, retryDays[len(retryDays)-1])
+
+	subscription.Status = model.PastDue
+	subscription.PastDueDate = &today
+	subscription.GraceEndDate = &graceEndDate
+	subscription.NextPaymentRetryDate = s.nextPaymentRetryDate(today, 1)
+}
+
+// nextPaymentRetryDate returns when to retry after the given attempt failed,
+// or nil when no retries are left.
+func (s *Service) nextPaymentRetryDate(pastDueDate time.Time, failedAttempt int) *time.Time {
+	if failedAttempt > len(s.config.DunningRetryDays) {
+		return nil
+	}
+
+	retryDate := pastDueDate.AddDate(0, 0, s.config.DunningRetryDays[failedAttempt-1])
+	return &retryDate
+}
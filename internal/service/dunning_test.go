@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gymondo/internal/model"
+	"testing"
+	"time"
+)
+
+func Test_Service_RetryFailedPayments(t *testing.T) {
+	t.Parallel()
+
+	pastDueSubscription := func(pastDueDate time.Time) model.Subscription {
+		graceEndDate := pastDueDate.AddDate(0, 0, 7)
+		return model.Subscription{
+			ID:           uuid.New(),
+			UserID:       uuid.New(),
+			StartDate:    pastDueDate.AddDate(0, 0, -30),
+			EndDate:      pastDueDate,
+			DurationDays: 30,
+			TotalPrice:   11,
+			Status:       model.PastDue,
+			PastDueDate:  &pastDueDate,
+			GraceEndDate: &graceEndDate,
+		}
+	}
+
+	failedAttempts := func(subscription model.Subscription, count int) []model.PaymentAttempt {
+		attempts := make([]model.PaymentAttempt, 0, count)
+		for i := 1; i <= count; i++ {
+			attempts = append(attempts, model.PaymentAttempt{
+				SubscriptionID: subscription.ID,
+				AttemptNumber:  i,
+				Status:         model.PaymentFailed,
+				AttemptedAt:    subscription.PastDueDate.Add(time.Duration(i) * time.Hour),
+			})
+		}
+		return attempts
+	}
+
+	t.Run("successful retry reactivates subscription", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockPayments := NewMockPaymentGateway(ctrl)
+		service := &Service{repository: mockRepo, payments: mockPayments, config: DefaultConfig()}
+
+		pastDueDate := time.Now().Truncate(24*time.Hour).AddDate(0, 0, -1)
+		subscription := pastDueSubscription(pastDueDate)
+
+		mockRepo.EXPECT().GetSubscriptionsDueForPaymentRetry(gomock.Any(), gomock.Any()).Return([]model.Subscription{subscription}, nil)
+		mockRepo.EXPECT().GetPaymentAttempts(gomock.Any(), subscription.ID.String()).Return(failedAttempts(subscription, 1), nil)
+		mockRepo.EXPECT().GetCreditBalance(gomock.Any(), subscription.UserID.String()).Return(0.0, nil)
+		mockPayments.EXPECT().Charge(gomock.Any(), subscription.UserID, 11.0, gomock.Any()).Return("tx-2", nil)
+		expectWithinTx(mockRepo)
+		expectLockSubscription(mockRepo, subscription)
+		mockRepo.EXPECT().SavePaymentAttempt(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, attempt model.PaymentAttempt) error {
+				assert.Equal(t, 2, attempt.AttemptNumber)
+				assert.Equal(t, model.PaymentSucceeded, attempt.Status)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, renewed model.Subscription) error {
+				assert.Equal(t, model.Active, renewed.Status)
+				assert.Equal(t, pastDueDate, renewed.StartDate)
+				assert.Nil(t, renewed.PastDueDate)
+				assert.Nil(t, renewed.GraceEndDate)
+				assert.Nil(t, renewed.NextPaymentRetryDate)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().GetUser(gomock.Any(), subscription.UserID.String()).Return(model.User{ID: subscription.UserID}, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), subscription.ProductID.String()).Return(model.Product{ID: subscription.ProductID}, nil)
+		mockRepo.EXPECT().SaveInvoice(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, invoice model.Invoice) (model.Invoice, error) {
+				return invoice, nil
+			},
+		)
+
+		err := service.RetryFailedPayments(context.Background())
+		assert.NoError(t, err)
+	})
+
+	t.Run("failed retry schedules the next one", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockPayments := NewMockPaymentGateway(ctrl)
+		mockNotifier := NewMockNotifier(ctrl)
+		service := &Service{repository: mockRepo, payments: mockPayments, notifier: mockNotifier, config: DefaultConfig()}
+
+		pastDueDate := time.Now().Truncate(24*time.Hour).AddDate(0, 0, -1)
+		subscription := pastDueSubscription(pastDueDate)
+
+		mockRepo.EXPECT().GetSubscriptionsDueForPaymentRetry(gomock.Any(), gomock.Any()).Return([]model.Subscription{subscription}, nil)
+		mockRepo.EXPECT().GetPaymentAttempts(gomock.Any(), subscription.ID.String()).Return(failedAttempts(subscription, 1), nil)
+		mockRepo.EXPECT().GetCreditBalance(gomock.Any(), subscription.UserID.String()).Return(0.0, nil)
+		mockPayments.EXPECT().Charge(gomock.Any(), subscription.UserID, 11.0, gomock.Any()).Return("", errors.New("insufficient funds"))
+		expectWithinTx(mockRepo)
+		expectLockSubscription(mockRepo, subscription)
+		mockRepo.EXPECT().SavePaymentAttempt(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, pastDue model.Subscription) error {
+				assert.Equal(t, model.PastDue, pastDue.Status)
+				assert.Equal(t, pastDueDate.AddDate(0, 0, 3), *pastDue.NextPaymentRetryDate)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().GetUser(gomock.Any(), subscription.UserID.String()).Return(model.User{ID: subscription.UserID}, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), subscription.ProductID.String()).Return(model.Product{ID: subscription.ProductID}, nil)
+		mockNotifier.EXPECT().Notify(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, notification model.Notification) error {
+				assert.Equal(t, model.NotificationPaymentFailed, notification.Kind)
+				return nil
+			},
+		)
+
+		err := service.RetryFailedPayments(context.Background())
+		assert.NoError(t, err)
+	})
+
+	t.Run("exhausted retries cancel subscription", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockPayments := NewMockPaymentGateway(ctrl)
+		mockNotifier := NewMockNotifier(ctrl)
+		service := &Service{repository: mockRepo, payments: mockPayments, notifier: mockNotifier, config: DefaultConfig()}
+
+		pastDueDate := time.Now().Truncate(24*time.Hour).AddDate(0, 0, -7)
+		subscription := pastDueSubscription(pastDueDate)
+
+		mockRepo.EXPECT().GetSubscriptionsDueForPaymentRetry(gomock.Any(), gomock.Any()).Return([]model.Subscription{subscription}, nil)
+		mockRepo.EXPECT().GetPaymentAttempts(gomock.Any(), subscription.ID.String()).Return(failedAttempts(subscription, 4), nil)
+		mockRepo.EXPECT().GetCreditBalance(gomock.Any(), subscription.UserID.String()).Return(0.0, nil)
+		mockPayments.EXPECT().Charge(gomock.Any(), subscription.UserID, 11.0, gomock.Any()).Return("", errors.New("insufficient funds"))
+		expectWithinTx(mockRepo)
+		expectLockSubscription(mockRepo, subscription)
+		mockRepo.EXPECT().SavePaymentAttempt(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, attempt model.PaymentAttempt) error {
+				assert.Equal(t, 5, attempt.AttemptNumber)
+				assert.Nil(t, attempt.NextRetryDate)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, message model.OutboxMessage) error {
+				assert.Equal(t, model.SubscriptionCanceled, message.Type)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, canceled model.Subscription) error {
+				assert.Equal(t, model.Canceled, canceled.Status)
+				assert.NotNil(t, canceled.CanceledDate)
+				assert.Nil(t, canceled.NextPaymentRetryDate)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().GetUser(gomock.Any(), subscription.UserID.String()).Return(model.User{ID: subscription.UserID}, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), subscription.ProductID.String()).Return(model.Product{ID: subscription.ProductID}, nil)
+		mockNotifier.EXPECT().Notify(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, notification model.Notification) error {
+				assert.Equal(t, model.NotificationCancellationConfirmation, notification.Kind)
+				return nil
+			},
+		)
+
+		err := service.RetryFailedPayments(context.Background())
+		assert.NoError(t, err)
+	})
+}
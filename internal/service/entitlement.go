@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gymondo/internal/model"
+)
+
+// FindEntitlements resolves the active, trialing and grace period
+// subscriptions of the user, and those the user is a member of, into the
+// entitlements they grant. Results are cached for Config.EntitlementCacheTTL.
+func (s *Service) FindEntitlements(ctx context.Context, userID string) (model.Entitlements, error) {
+	now := s.now()
+	if entitlements, ok := s.entitlements.get(userID, now); ok {
+		return entitlements, nil
+	}
+
+	parsedUserID, err := uuid.Parse(userID)
+	if err != nil {
+		return model.Entitlements{}, fmt.Errorf("invalid user ID %s: %w", userID, err)
+	}
+
+	subscriptions, err := s.repository.GetUserSubscriptions(ctx, userID)
+	if err != nil {
+		return model.Entitlements{}, fmt.Errorf("failed to fetch subscriptions of user %s: %w", userID, err)
+	}
+
+	memberships, err := s.repository.GetMemberSubscriptions(ctx, userID)
+	if err != nil {
+		return model.Entitlements{}, fmt.Errorf("failed to fetch memberships of user %s: %w", userID, err)
+	}
+
+	products, err := s.repository.GetProducts(ctx)
+	if err != nil {
+		return model.Entitlements{}, fmt.Errorf("failed to fetch products: %w", err)
+	}
+
+	entitlements := resolveEntitlements(parsedUserID, subscriptions, memberships, products, now)
+	s.entitlements.put(userID, entitlements, now)
+
+	return entitlements, nil
+}
+
+// resolveEntitlements unites the entitlements of every subscription that
+// grants access at the given time and of its active add-ons. Subscriptions
+// the user is a member of grant their product's entitlements while they
+// grant access to their owner, as long as the product can be shared.
+func resolveEntitlements(
+	userID uuid.UUID,
+	subscriptions []model.Subscription,
+	memberships []model.Subscription,
+	products []model.Product,
+	now time.Time,
+) model.Entitlements {
+	productsByID := make(map[uuid.UUID]model.Product, len(products))
+	for _, product := range products {
+		productsByID[product.ID] = product
+	}
+
+	entitlements := model.Entitlements{
+		UserID:       userID,
+		Entitlements: []string{},
+		Grants:       []model.EntitlementGrant{},
+		ResolvedAt:   now,
+	}
+
+	granted := make(map[string]bool)
+	grant := func(subscriptionGrant model.EntitlementGrant) {
+		entitlements.Grants = append(entitlements.Grants, subscriptionGrant)
+		for _, entitlement := range subscriptionGrant.Entitlements {
+			if !granted[entitlement] {
+				granted[entitlement] = true
+				entitlements.Entitlements = append(entitlements.Entitlements, entitlement)
+			}
+		}
+	}
+
+	for _, subscription := range subscriptions {
+		status, until, ok := entitlementStatus(subscription, now)
+		if !ok {
+			continue
+		}
+
+		product := productsByID[subscription.ProductID]
+		subscriptionEntitlements := append([]string{}, product.Entitlements...)
+		for _, addOn := range subscription.AddOns {
+			if addOn.Status == model.AddOnActive {
+				subscriptionEntitlements = append(subscriptionEntitlements, productsByID[addOn.ProductID].Entitlements...)
+			}
+		}
+
+		grant(model.EntitlementGrant{
+			SubscriptionID: subscription.ID,
+			ProductID:      subscription.ProductID,
+			Status:         status,
+			Entitlements:   subscriptionEntitlements,
+			Until:          until,
+		})
+	}
+
+	for _, membership := range memberships {
+		status, until, ok := entitlementStatus(membership, now)
+		product := productsByID[membership.ProductID]
+		if !ok || !product.Shareable() {
+			continue
+		}
+
+		ownerID := membership.UserID
+		grant(model.EntitlementGrant{
+			SubscriptionID: membership.ID,
+			ProductID:      membership.ProductID,
+			Status:         status,
+			Entitlements:   append([]string{}, product.Entitlements...),
+			Until:          until,
+			OwnerID:        &ownerID,
+		})
+	}
+	sort.Strings(entitlements.Entitlements)
+
+	return entitlements
+}
+
+// entitlementStatus tells whether the subscription grants access at the
+// given time, and until when. Scheduled, paused and canceled subscriptions
+// don't, past due ones only until their grace period ends.
+func entitlementStatus(subscription model.Subscription, now time.Time) (model.EntitlementStatus, time.Time, bool) {
+	switch subscription.Status {
+	case model.Active:
+		if subscription.TrialEndDate != nil && subscription.TrialEndDate.After(now) {
+			return model.EntitledTrialing, *subscription.TrialEndDate, true
+		}
+		return model.EntitledActive, subscription.EndDate, true
+	case model.PastDue:
+		if subscription.GraceEndDate == nil || !subscription.GraceEndDate.After(now) {
+			return "", time.Time{}, false
+		}
+		return model.EntitledGracePeriod, *subscription.GraceEndDate, true
+	}
+
+	return "", time.Time{}, false
+}
+
+// entitlementCache keeps resolved entitlements per user for a short time. A
+// nil cache or a zero TTL caches nothing.
+type entitlementCache struct {
+	ttl       time.Duration
+	mu        sync.Mutex
+	entries   map[string]cachedEntitlements
+	lastSweep time.Time
+}
+
+type cachedEntitlements struct {
+	entitlements model.Entitlements
+	expiresAt    time.Time
+}
+
+func newEntitlementCache(ttl time.Duration) *entitlementCache {
+	return &entitlementCache{
+		ttl:     ttl,
+		entries: make(map[string]cachedEntitlements),
+	}
+}
+
+func (c *entitlementCache) get(userID string, now time.Time) (model.Entitlements, bool) {
+	if c == nil || c.ttl <= 0 {
+		return model.Entitlements{}, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[userID]
+	if !ok || !now.Before(entry.expiresAt) {
+		return model.Entitlements{}, false
+	}
+	return entry.entitlements, true
+}
+
+func (c *entitlementCache) put(userID string, entitlements model.Entitlements, now time.Time) {
+	if c == nil || c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// drop expired entries once per TTL, so users that are looked up once
+	// don't pile up
+	if now.Sub(c.lastSweep) >= c.ttl {
+		for cachedUserID, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, cachedUserID)
+			}
+		}
+		c.lastSweep = now
+	}
+	c.entries[userID] = cachedEntitlements{entitlements: entitlements, expiresAt: now.Add(c.ttl)}
+}
+
+// invalidate forgets the entitlements of the user, so the next lookup sees a
+// change to one of their subscriptions right away.
+func (c *entitlementCache) invalidate(userID string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, userID)
+}
@@ -0,0 +1,307 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gymondo/internal/clock"
+	"gymondo/internal/model"
+	"testing"
+	"time"
+)
+
+func Test_Service_FindEntitlements(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.June, 20, 10, 0, 0, 0, time.UTC)
+	later := now.AddDate(0, 0, 5)
+	earlier := now.AddDate(0, 0, -5)
+
+	basicPlan := model.Product{ID: uuid.New(), Entitlements: []string{"workouts"}}
+	premiumPlan := model.Product{ID: uuid.New(), Entitlements: []string{"workouts", "nutrition_plans", "premium_classes"}}
+	products := []model.Product{basicPlan, premiumPlan}
+
+	t.Run("resolves subscriptions that grant access", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name         string
+			subscription model.Subscription
+			status       model.EntitlementStatus
+			entitlements []string
+		}{
+			{
+				name:         "active",
+				subscription: model.Subscription{ProductID: basicPlan.ID, Status: model.Active, EndDate: later},
+				status:       model.EntitledActive,
+				entitlements: []string{"workouts"},
+			},
+			{
+				name:         "trialing",
+				subscription: model.Subscription{ProductID: premiumPlan.ID, Status: model.Active, TrialEndDate: &later},
+				status:       model.EntitledTrialing,
+				entitlements: []string{"nutrition_plans", "premium_classes", "workouts"},
+			},
+			{
+				name:         "in grace period",
+				subscription: model.Subscription{ProductID: premiumPlan.ID, Status: model.PastDue, GraceEndDate: &later},
+				status:       model.EntitledGracePeriod,
+				entitlements: []string{"nutrition_plans", "premium_classes", "workouts"},
+			},
+			{
+				name:         "grace period over",
+				subscription: model.Subscription{ProductID: premiumPlan.ID, Status: model.PastDue, GraceEndDate: &earlier},
+				entitlements: []string{},
+			},
+			{
+				name:         "paused",
+				subscription: model.Subscription{ProductID: premiumPlan.ID, Status: model.Paused},
+				entitlements: []string{},
+			},
+			{
+				name:         "canceled",
+				subscription: model.Subscription{ProductID: premiumPlan.ID, Status: model.Canceled},
+				entitlements: []string{},
+			},
+			{
+				name:         "scheduled",
+				subscription: model.Subscription{ProductID: premiumPlan.ID, Status: model.Scheduled, StartDate: later},
+				entitlements: []string{},
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				t.Parallel()
+
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				mockRepo := NewMockRepository(ctrl)
+				service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+				userID := uuid.New()
+				subscription := test.subscription
+				subscription.ID = uuid.New()
+				subscription.UserID = userID
+
+				mockRepo.EXPECT().GetUserSubscriptions(gomock.Any(), userID.String()).Return([]model.Subscription{subscription}, nil)
+				mockRepo.EXPECT().GetMemberSubscriptions(gomock.Any(), userID.String()).Return(nil, nil)
+				mockRepo.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+
+				entitlements, err := service.FindEntitlements(context.Background(), userID.String())
+				assert.NoError(t, err)
+				assert.Equal(t, test.entitlements, entitlements.Entitlements)
+				if test.status == "" {
+					assert.Empty(t, entitlements.Grants)
+					return
+				}
+				assert.Len(t, entitlements.Grants, 1)
+				assert.Equal(t, test.status, entitlements.Grants[0].Status)
+				assert.Equal(t, subscription.ID, entitlements.Grants[0].SubscriptionID)
+			})
+		}
+	})
+
+	t.Run("unites the entitlements of all subscriptions", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		userID := uuid.New()
+		mockRepo.EXPECT().GetUserSubscriptions(gomock.Any(), userID.String()).Return([]model.Subscription{
+			{ID: uuid.New(), ProductID: basicPlan.ID, Status: model.Active, EndDate: later},
+			{ID: uuid.New(), ProductID: premiumPlan.ID, Status: model.Active, EndDate: later},
+		}, nil)
+		mockRepo.EXPECT().GetMemberSubscriptions(gomock.Any(), userID.String()).Return(nil, nil)
+		mockRepo.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+
+		entitlements, err := service.FindEntitlements(context.Background(), userID.String())
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"nutrition_plans", "premium_classes", "workouts"}, entitlements.Entitlements)
+		assert.Len(t, entitlements.Grants, 2)
+	})
+
+	t.Run("cached until the TTL passes", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{
+			repository:   mockRepo,
+			clock:        clock.Fixed(now),
+			config:       DefaultConfig(),
+			entitlements: newEntitlementCache(30 * time.Second),
+		}
+
+		userID := uuid.New()
+		mockRepo.EXPECT().GetUserSubscriptions(gomock.Any(), userID.String()).Return(nil, nil).Times(2)
+		mockRepo.EXPECT().GetMemberSubscriptions(gomock.Any(), userID.String()).Return(nil, nil).Times(2)
+		mockRepo.EXPECT().GetProducts(gomock.Any()).Return(products, nil).Times(2)
+
+		_, err := service.FindEntitlements(context.Background(), userID.String())
+		assert.NoError(t, err)
+
+		service.clock = clock.Fixed(now.Add(29 * time.Second))
+		_, err = service.FindEntitlements(context.Background(), userID.String())
+		assert.NoError(t, err)
+
+		service.clock = clock.Fixed(now.Add(30 * time.Second))
+		_, err = service.FindEntitlements(context.Background(), userID.String())
+		assert.NoError(t, err)
+	})
+
+	t.Run("subscription changes invalidate the cache", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{
+			repository:   mockRepo,
+			clock:        clock.Fixed(now),
+			config:       DefaultConfig(),
+			entitlements: newEntitlementCache(30 * time.Second),
+		}
+
+		userID := uuid.New()
+		subscription := model.Subscription{ID: uuid.New(), UserID: userID, ProductID: basicPlan.ID, Status: model.Active, EndDate: later}
+		paused := subscription
+		paused.Status = model.Paused
+
+		gomock.InOrder(
+			mockRepo.EXPECT().GetUserSubscriptions(gomock.Any(), userID.String()).Return([]model.Subscription{subscription}, nil),
+			mockRepo.EXPECT().GetUserSubscriptions(gomock.Any(), userID.String()).Return([]model.Subscription{paused}, nil),
+		)
+		mockRepo.EXPECT().GetMemberSubscriptions(gomock.Any(), userID.String()).Return(nil, nil).Times(2)
+		mockRepo.EXPECT().GetProducts(gomock.Any()).Return(products, nil).Times(2)
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).Return(nil)
+
+		entitlements, err := service.FindEntitlements(context.Background(), userID.String())
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"workouts"}, entitlements.Entitlements)
+
+		err = service.updateSubscriptionWithEvent(context.Background(), model.SubscriptionPaused, paused)
+		assert.NoError(t, err)
+
+		entitlements, err = service.FindEntitlements(context.Background(), userID.String())
+		assert.NoError(t, err)
+		assert.Empty(t, entitlements.Entitlements)
+	})
+	t.Run("members share the entitlements of the owner's subscription", func(t *testing.T) {
+		t.Parallel()
+
+		familyPlan := model.Product{ID: uuid.New(), Entitlements: []string{"workouts", "nutrition_plans"}, MaxMembers: 4}
+		products := append([]model.Product{familyPlan}, products...)
+		ownerID := uuid.New()
+
+		tests := []struct {
+			name         string
+			membership   model.Subscription
+			entitlements []string
+		}{
+			{
+				name:         "owner active",
+				membership:   model.Subscription{ProductID: familyPlan.ID, Status: model.Active, EndDate: later},
+				entitlements: []string{"nutrition_plans", "workouts"},
+			},
+			{
+				name:         "owner paused",
+				membership:   model.Subscription{ProductID: familyPlan.ID, Status: model.Paused},
+				entitlements: []string{},
+			},
+			{
+				name:         "owner canceled",
+				membership:   model.Subscription{ProductID: familyPlan.ID, Status: model.Canceled},
+				entitlements: []string{},
+			},
+			{
+				name:         "product can't be shared",
+				membership:   model.Subscription{ProductID: premiumPlan.ID, Status: model.Active, EndDate: later},
+				entitlements: []string{},
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				t.Parallel()
+
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				mockRepo := NewMockRepository(ctrl)
+				service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+				userID := uuid.New()
+				membership := test.membership
+				membership.ID = uuid.New()
+				membership.UserID = ownerID
+
+				mockRepo.EXPECT().GetUserSubscriptions(gomock.Any(), userID.String()).Return(nil, nil)
+				mockRepo.EXPECT().GetMemberSubscriptions(gomock.Any(), userID.String()).Return([]model.Subscription{membership}, nil)
+				mockRepo.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+
+				entitlements, err := service.FindEntitlements(context.Background(), userID.String())
+				assert.NoError(t, err)
+				assert.Equal(t, test.entitlements, entitlements.Entitlements)
+				if len(test.entitlements) == 0 {
+					assert.Empty(t, entitlements.Grants)
+					return
+				}
+				assert.Len(t, entitlements.Grants, 1)
+				assert.Equal(t, &ownerID, entitlements.Grants[0].OwnerID)
+			})
+		}
+	})
+
+	t.Run("changes to the owner's subscription invalidate the members' cache", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{
+			repository:   mockRepo,
+			clock:        clock.Fixed(now),
+			config:       DefaultConfig(),
+			entitlements: newEntitlementCache(30 * time.Second),
+		}
+
+		memberID := uuid.New()
+		subscription := model.Subscription{
+			ID:        uuid.New(),
+			UserID:    uuid.New(),
+			ProductID: basicPlan.ID,
+			Status:    model.Active,
+			EndDate:   later,
+			Members:   []model.SubscriptionMember{{ID: uuid.New(), UserID: memberID, Status: model.MemberActive}},
+		}
+
+		mockRepo.EXPECT().GetUserSubscriptions(gomock.Any(), memberID.String()).Return(nil, nil).Times(2)
+		mockRepo.EXPECT().GetMemberSubscriptions(gomock.Any(), memberID.String()).Return(nil, nil).Times(2)
+		mockRepo.EXPECT().GetProducts(gomock.Any()).Return(products, nil).Times(2)
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).Return(nil)
+
+		_, err := service.FindEntitlements(context.Background(), memberID.String())
+		assert.NoError(t, err)
+
+		err = service.updateSubscriptionWithEvent(context.Background(), model.SubscriptionCanceled, subscription)
+		assert.NoError(t, err)
+
+		_, err = service.FindEntitlements(context.Background(), memberID.String())
+		assert.NoError(t, err)
+	})
+}
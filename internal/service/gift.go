@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log"
+	"math"
+
+	"github.com/google/uuid"
+	"gymondo/internal/model"
+)
+
+// PurchaseGift charges the buyer for the given number of periods of the
+// product and returns a gift code for them, which anyone can redeem until it
+// expires. The purchase is invoiced to the buyer and the code is sent to
+// them.
+func (s *Service) PurchaseGift(ctx context.Context, buyerID string, productID string, periods int) (model.Gift, error) {
+	if periods < 1 {
+		return model.Gift{}, fmt.Errorf("a gift covers at least one period")
+	}
+
+	buyer, err := s.repository.GetUser(ctx, buyerID)
+	if err != nil {
+		return model.Gift{}, fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	product, err := s.repository.GetProduct(ctx, productID)
+	if err != nil {
+		return model.Gift{}, fmt.Errorf("failed to fetch product: %w", err)
+	}
+	if product.Kind == model.AddOnProduct || product.SeatBased {
+		return model.Gift{}, fmt.Errorf("product %s can't be given as a gift", product.Name)
+	}
+
+	code, err := newGiftCode()
+	if err != nil {
+		return model.Gift{}, fmt.Errorf("failed to generate gift code: %w", err)
+	}
+
+	location := model.LoadLocation(buyer.TimeZone)
+	gift := model.Gift{
+		ID:           uuid.New(),
+		Code:         code,
+		BuyerID:      buyer.ID,
+		ProductID:    product.ID,
+		DurationDays: product.DurationDays * periods,
+		Price:        math.Round(product.Price*float64(periods)*100) / 100,
+		Tax:          math.Round(product.Tax*float64(periods)*100) / 100,
+		TotalPrice:   math.Round(product.TotalPrice*float64(periods)*100) / 100,
+		Status:       model.GiftPurchased,
+		PurchasedAt:  s.now(),
+		ExpiresAt:    s.today(location).AddDate(0, 0, s.config.GiftValidityDays),
+	}
+
+	reference := fmt.Sprintf("gift %s", gift.ID)
+	gift.TransactionID, err = s.payments.Charge(ctx, buyer.ID, gift.TotalPrice, reference)
+	if err != nil {
+		return model.Gift{}, fmt.Errorf("failed to charge gift: %w", err)
+	}
+
+	err = s.withinTx(ctx, func(tx *Service) error {
+		if err := tx.repository.SaveGift(ctx, gift); err != nil {
+			return fmt.Errorf("failed to save gift: %w", err)
+		}
+		if _, err := tx.issueGiftInvoice(ctx, buyer, product, gift); err != nil {
+			return fmt.Errorf("failed to issue invoice: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return model.Gift{}, err
+	}
+
+	s.notifyGift(ctx, model.NotificationGiftPurchase, buyer, product.Name, gift)
+
+	return gift, nil
+}
+
+func (s *Service) FindGift(ctx context.Context, code string) (model.Gift, error) {
+	gift, err := s.repository.GetGiftByCode(ctx, code)
+	if err != nil {
+		return model.Gift{}, fmt.Errorf("failed to find gift: %w", err)
+	}
+
+	return gift, nil
+}
+
+// RedeemGift gives the user the prepaid period of the gift without charging
+// them. An active subscription of the user to the gifted product is extended,
+// which postpones its next charge; otherwise a new subscription starts today
+// and renews at the regular price once the gifted period is over.
+func (s *Service) RedeemGift(ctx context.Context, code string, userID string) (model.Subscription, error) {
+	gift, err := s.repository.GetGiftByCode(ctx, code)
+	if err != nil {
+		return model.Subscription{}, fmt.Errorf("failed to find gift: %w", err)
+	}
+	if gift.Status != model.GiftPurchased {
+		return model.Subscription{}, fmt.Errorf("gift is already %s: %w", gift.Status, model.ErrGiftNotRedeemable)
+	}
+
+	now := s.now()
+	if !now.Before(gift.ExpiresAt) {
+		return model.Subscription{}, fmt.Errorf("gift expired on %s: %w",
+			gift.ExpiresAt.Format("02.01.2006"), model.ErrGiftNotRedeemable)
+	}
+
+	user, err := s.repository.GetUser(ctx, userID)
+	if err != nil {
+		return model.Subscription{}, fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	product, err := s.repository.GetProduct(ctx, gift.ProductID.String())
+	if err != nil {
+		return model.Subscription{}, fmt.Errorf("failed to fetch product: %w", err)
+	}
+
+	subscriptions, err := s.repository.GetUserSubscriptions(ctx, userID)
+	if err != nil {
+		return model.Subscription{}, fmt.Errorf("failed to fetch subscriptions of user %s: %w", userID, err)
+	}
+
+	gift.Status = model.GiftRedeemed
+	gift.RedeemedBy = &user.ID
+	gift.RedeemedAt = &now
+
+	if subscription, ok := extendableSubscription(subscriptions, gift.ProductID); ok {
+		subscription.EndDate = subscription.EndDate.AddDate(0, 0, gift.DurationDays)
+		gift.SubscriptionID = &subscription.ID
+
+		// the gift is updated first, so a concurrent redemption fails before
+		// the subscription is touched
+		err = s.withinTx(ctx, func(tx *Service) error {
+			if err := tx.repository.UpdateGift(ctx, gift); err != nil {
+				return err
+			}
+			return tx.updateSubscriptionWithEvent(ctx, model.SubscriptionUpdated, subscription)
+		})
+		if err != nil {
+			return model.Subscription{}, fmt.Errorf("failed to redeem gift: %w", err)
+		}
+		subscription.Version++
+
+		return subscription, nil
+	}
+
+	location := model.LoadLocation(user.TimeZone)
+	startDate := s.today(location)
+	subscription := model.Subscription{
+		ID:           uuid.New(),
+		UserID:       user.ID,
+		ProductID:    product.ID,
+		StartDate:    startDate,
+		EndDate:      startDate.AddDate(0, 0, gift.DurationDays),
+		DurationDays: product.DurationDays,
+		Price:        product.Price,
+		Tax:          product.Tax,
+		TotalPrice:   product.TotalPrice,
+		Status:       model.Active,
+		Version:      1,
+		TimeZone:     location.String(),
+		Seats:        1,
+	}
+	gift.SubscriptionID = &subscription.ID
+
+	message, err := s.newOutboxMessage(model.SubscriptionCreated, subscription)
+	if err != nil {
+		return model.Subscription{}, err
+	}
+
+	err = s.withinTx(ctx, func(tx *Service) error {
+		if err := tx.repository.UpdateGift(ctx, gift); err != nil {
+			return err
+		}
+		if err := tx.repository.SaveSubscription(ctx, subscription); err != nil {
+			return fmt.Errorf("failed to save subscription: %w", err)
+		}
+		if err := tx.repository.SaveOutboxMessage(ctx, message); err != nil {
+			return fmt.Errorf("failed to save subscription event: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return model.Subscription{}, fmt.Errorf("failed to redeem gift: %w", err)
+	}
+	s.entitlements.invalidate(user.ID.String())
+
+	s.notify(ctx, model.NotificationSubscriptionConfirmation, user, product.Name, subscription)
+
+	return subscription, nil
+}
+
+// extendableSubscription returns the active personal subscription to the
+// product, if the user has one.
+func extendableSubscription(subscriptions []model.Subscription, productID uuid.UUID) (model.Subscription, bool) {
+	for _, subscription := range subscriptions {
+		if subscription.ProductID == productID &&
+			subscription.Status == model.Active &&
+			subscription.OrganizationID == nil {
+			return subscription, true
+		}
+	}
+	return model.Subscription{}, false
+}
+
+// ExpireGifts expires the gifts that were not redeemed in time and tells
+// their buyers. Expired gifts are not refunded. A gift redeemed concurrently
+// stays redeemed.
+func (s *Service) ExpireGifts(ctx context.Context) error {
+	gifts, err := s.repository.GetGiftsDueForExpiry(ctx, s.now())
+	if err != nil {
+		return fmt.Errorf("failed to fetch gifts due for expiry: %w", err)
+	}
+
+	for _, gift := range gifts {
+		if err := s.expireGift(ctx, gift); err != nil {
+			log.Printf("Error expiring gift %s: %v", gift.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) expireGift(ctx context.Context, gift model.Gift) error {
+	gift.Status = model.GiftExpired
+	if err := s.repository.UpdateGift(ctx, gift); err != nil {
+		return err
+	}
+
+	buyer, err := s.repository.GetUser(ctx, gift.BuyerID.String())
+	if err != nil {
+		return fmt.Errorf("failed to fetch buyer: %w", err)
+	}
+
+	product, err := s.repository.GetProduct(ctx, gift.ProductID.String())
+	if err != nil {
+		return fmt.Errorf("failed to fetch product: %w", err)
+	}
+
+	s.notifyGift(ctx, model.NotificationGiftExpired, buyer, product.Name, gift)
+
+	return nil
+}
+
+// notifyGift sends a notification about the gift to its buyer. A
+// notification that can't be sent is logged.
+func (s *Service) notifyGift(
+	ctx context.Context,
+	kind model.NotificationKind,
+	buyer model.User,
+	productName string,
+	gift model.Gift,
+) {
+	notification := model.Notification{
+		Kind:        kind,
+		User:        buyer,
+		ProductName: productName,
+		Gift:        gift,
+	}
+	if err := s.notifier.Notify(ctx, notification); err != nil {
+		log.Printf("Error sending %s notification for gift %s: %v", kind, gift.ID, err)
+	}
+}
+
+// codeAlphabet leaves out characters that are easily mixed up, like 0 and O
+// or 1 and I. Its 32 characters divide 256, so every character is equally
+// likely.
+const codeAlphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+
+// randomCode returns length random characters of codeAlphabet.
+func randomCode(length int) (string, error) {
+	random := make([]byte, length)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	code := make([]byte, length)
+	for i, b := range random {
+		code[i] = codeAlphabet[int(b)%len(codeAlphabet)]
+	}
+	return string(code), nil
+}
+
+// newGiftCode returns a random code like GIFT-7K3M-Q9XP.
+func newGiftCode() (string, error) {
+	code, err := randomCode(8)
+	if err != nil {
+		return "", err
+	}
+	return "GIFT-" + code[:4] + "-" + code[4:], nil
+}
@@ -0,0 +1,356 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gymondo/internal/clock"
+	"gymondo/internal/model"
+	"testing"
+	"time"
+)
+
+func Test_Service_PurchaseGift(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.June, 20, 10, 0, 0, 0, time.UTC)
+	premiumPlan := model.Product{
+		ID:           uuid.New(),
+		Name:         "premium plan",
+		Kind:         model.BaseProduct,
+		DurationDays: 30,
+		Price:        10,
+		Tax:          1,
+		TotalPrice:   11,
+	}
+
+	t.Run("charges and invoices the buyer", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockPayments := NewMockPaymentGateway(ctrl)
+		mockNotifier := NewMockNotifier(ctrl)
+		service := &Service{
+			repository: mockRepo,
+			payments:   mockPayments,
+			notifier:   mockNotifier,
+			clock:      clock.Fixed(now),
+			config:     DefaultConfig(),
+		}
+
+		buyer := model.User{ID: uuid.New(), FirstName: "Anna", SecondName: "Schmidt", TimeZone: "UTC"}
+
+		mockRepo.EXPECT().GetUser(gomock.Any(), buyer.ID.String()).Return(buyer, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), premiumPlan.ID.String()).Return(premiumPlan, nil)
+		mockPayments.EXPECT().Charge(gomock.Any(), buyer.ID, 33.0, gomock.Any()).Return("tx-1", nil)
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().SaveGift(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().SaveInvoice(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, invoice model.Invoice) (model.Invoice, error) {
+				assert.NotNil(t, invoice.GiftID)
+				assert.Equal(t, uuid.Nil, invoice.SubscriptionID)
+				assert.Equal(t, buyer.ID, invoice.UserID)
+				assert.Equal(t, "Anna Schmidt", invoice.CustomerName)
+				assert.Equal(t, 30.0, invoice.NetAmount)
+				assert.Equal(t, 33.0, invoice.TotalAmount)
+				assert.Equal(t, "Gift: premium plan, 90 days", invoice.LineItems[0].Description)
+				assert.Equal(t, 1, invoice.LineItems[0].Quantity)
+				assert.Equal(t, 30.0, invoice.LineItems[0].UnitPrice)
+				assert.Equal(t, 3.0, invoice.LineItems[0].Tax)
+				assert.Equal(t, 33.0, invoice.LineItems[0].TotalPrice)
+				return invoice, nil
+			},
+		)
+		mockNotifier.EXPECT().Notify(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, notification model.Notification) error {
+				assert.Equal(t, model.NotificationGiftPurchase, notification.Kind)
+				assert.Equal(t, buyer.ID, notification.User.ID)
+				assert.NotEmpty(t, notification.Gift.Code)
+				return nil
+			},
+		)
+
+		gift, err := service.PurchaseGift(context.Background(), buyer.ID.String(), premiumPlan.ID.String(), 3)
+		assert.NoError(t, err)
+		assert.Equal(t, model.GiftPurchased, gift.Status)
+		assert.Equal(t, 90, gift.DurationDays)
+		assert.Equal(t, 33.0, gift.TotalPrice)
+		assert.Equal(t, "tx-1", gift.TransactionID)
+		assert.Equal(t, time.Date(2025, time.June, 20, 0, 0, 0, 0, time.UTC), gift.ExpiresAt)
+		assert.Regexp(t, `^GIFT-[2-9A-HJ-NP-Z]{4}-[2-9A-HJ-NP-Z]{4}$`, gift.Code)
+	})
+
+	t.Run("declined charge", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockPayments := NewMockPaymentGateway(ctrl)
+		service := &Service{repository: mockRepo, payments: mockPayments, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		buyer := model.User{ID: uuid.New()}
+
+		mockRepo.EXPECT().GetUser(gomock.Any(), buyer.ID.String()).Return(buyer, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), premiumPlan.ID.String()).Return(premiumPlan, nil)
+		mockPayments.EXPECT().Charge(gomock.Any(), buyer.ID, 11.0, gomock.Any()).Return("", errors.New("card declined"))
+
+		_, err := service.PurchaseGift(context.Background(), buyer.ID.String(), premiumPlan.ID.String(), 1)
+		assert.EqualError(t, err, "failed to charge gift: card declined")
+	})
+
+	t.Run("add-ons can't be given", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		buyer := model.User{ID: uuid.New()}
+		addOn := model.Product{ID: uuid.New(), Name: "nutrition plans", Kind: model.AddOnProduct}
+
+		mockRepo.EXPECT().GetUser(gomock.Any(), buyer.ID.String()).Return(buyer, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), addOn.ID.String()).Return(addOn, nil)
+
+		_, err := service.PurchaseGift(context.Background(), buyer.ID.String(), addOn.ID.String(), 1)
+		assert.EqualError(t, err, "product nutrition plans can't be given as a gift")
+	})
+}
+
+func Test_Service_RedeemGift(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.June, 20, 10, 0, 0, 0, time.UTC)
+	today := model.StartOfDay(now, time.UTC)
+	premiumPlan := model.Product{
+		ID:           uuid.New(),
+		Name:         "premium plan",
+		DurationDays: 30,
+		Price:        10,
+		Tax:          1,
+		TotalPrice:   11,
+	}
+
+	newGift := func() model.Gift {
+		return model.Gift{
+			ID:           uuid.New(),
+			Code:         "GIFT-7K3M-Q9XP",
+			BuyerID:      uuid.New(),
+			ProductID:    premiumPlan.ID,
+			DurationDays: 90,
+			TotalPrice:   33,
+			Status:       model.GiftPurchased,
+			ExpiresAt:    today.AddDate(0, 0, 10),
+		}
+	}
+
+	t.Run("starts a subscription without a charge", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockNotifier := NewMockNotifier(ctrl)
+		service := &Service{repository: mockRepo, notifier: mockNotifier, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		gift := newGift()
+		user := model.User{ID: uuid.New(), TimeZone: "UTC"}
+
+		mockRepo.EXPECT().GetGiftByCode(gomock.Any(), gift.Code).Return(gift, nil)
+		mockRepo.EXPECT().GetUser(gomock.Any(), user.ID.String()).Return(user, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), premiumPlan.ID.String()).Return(premiumPlan, nil)
+		mockRepo.EXPECT().GetUserSubscriptions(gomock.Any(), user.ID.String()).Return(nil, nil)
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().UpdateGift(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, redeemed model.Gift) error {
+				assert.Equal(t, model.GiftRedeemed, redeemed.Status)
+				assert.Equal(t, &user.ID, redeemed.RedeemedBy)
+				assert.Equal(t, now, *redeemed.RedeemedAt)
+				assert.NotNil(t, redeemed.SubscriptionID)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SaveSubscription(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).Return(nil)
+		mockNotifier.EXPECT().Notify(gomock.Any(), gomock.Any()).Return(nil)
+
+		subscription, err := service.RedeemGift(context.Background(), gift.Code, user.ID.String())
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, subscription.UserID)
+		assert.Equal(t, model.Active, subscription.Status)
+		assert.Equal(t, today, subscription.StartDate)
+		assert.Equal(t, today.AddDate(0, 0, 90), subscription.EndDate)
+		assert.Equal(t, 30, subscription.DurationDays)
+		assert.Equal(t, 11.0, subscription.TotalPrice)
+	})
+
+	t.Run("extends an active subscription to the product", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		gift := newGift()
+		user := model.User{ID: uuid.New(), TimeZone: "UTC"}
+		existing := model.Subscription{
+			ID:           uuid.New(),
+			UserID:       user.ID,
+			ProductID:    premiumPlan.ID,
+			StartDate:    today.AddDate(0, 0, -10),
+			EndDate:      today.AddDate(0, 0, 20),
+			DurationDays: 30,
+			Status:       model.Active,
+			Version:      3,
+		}
+		canceled := existing
+		canceled.ID = uuid.New()
+		canceled.Status = model.Canceled
+
+		mockRepo.EXPECT().GetGiftByCode(gomock.Any(), gift.Code).Return(gift, nil)
+		mockRepo.EXPECT().GetUser(gomock.Any(), user.ID.String()).Return(user, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), premiumPlan.ID.String()).Return(premiumPlan, nil)
+		mockRepo.EXPECT().GetUserSubscriptions(gomock.Any(), user.ID.String()).Return([]model.Subscription{canceled, existing}, nil)
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().UpdateGift(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, redeemed model.Gift) error {
+				assert.Equal(t, &existing.ID, redeemed.SubscriptionID)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, updated model.Subscription) error {
+				assert.Equal(t, existing.ID, updated.ID)
+				assert.Equal(t, today.AddDate(0, 0, 110), updated.EndDate)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).Return(nil)
+
+		subscription, err := service.RedeemGift(context.Background(), gift.Code, user.ID.String())
+		assert.NoError(t, err)
+		assert.Equal(t, existing.ID, subscription.ID)
+		assert.Equal(t, existing.StartDate, subscription.StartDate)
+		assert.Equal(t, 4, subscription.Version)
+	})
+
+	t.Run("redeemed concurrently", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		gift := newGift()
+		user := model.User{ID: uuid.New(), TimeZone: "UTC"}
+
+		mockRepo.EXPECT().GetGiftByCode(gomock.Any(), gift.Code).Return(gift, nil)
+		mockRepo.EXPECT().GetUser(gomock.Any(), user.ID.String()).Return(user, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), premiumPlan.ID.String()).Return(premiumPlan, nil)
+		mockRepo.EXPECT().GetUserSubscriptions(gomock.Any(), user.ID.String()).Return(nil, nil)
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().UpdateGift(gomock.Any(), gomock.Any()).
+			Return(fmt.Errorf("gift %s is no longer purchased: %w", gift.ID, model.ErrGiftNotRedeemable))
+
+		_, err := service.RedeemGift(context.Background(), gift.Code, user.ID.String())
+		assert.True(t, errors.Is(err, model.ErrGiftNotRedeemable))
+	})
+
+	tests := []struct {
+		name  string
+		gift  func() model.Gift
+		error string
+	}{
+		{
+			name: "already redeemed",
+			gift: func() model.Gift {
+				gift := newGift()
+				gift.Status = model.GiftRedeemed
+				return gift
+			},
+			error: "gift is already redeemed: gift can't be redeemed",
+		},
+		{
+			name: "expired",
+			gift: func() model.Gift {
+				gift := newGift()
+				gift.ExpiresAt = today
+				return gift
+			},
+			error: "gift expired on 20.06.2024: gift can't be redeemed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := NewMockRepository(ctrl)
+			service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+			gift := test.gift()
+			mockRepo.EXPECT().GetGiftByCode(gomock.Any(), gift.Code).Return(gift, nil)
+
+			_, err := service.RedeemGift(context.Background(), gift.Code, uuid.NewString())
+			assert.EqualError(t, err, test.error)
+			assert.True(t, errors.Is(err, model.ErrGiftNotRedeemable))
+		})
+	}
+}
+
+func Test_Service_ExpireGifts(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2024, time.June, 20, 10, 0, 0, 0, time.UTC)
+	mockRepo := NewMockRepository(ctrl)
+	mockNotifier := NewMockNotifier(ctrl)
+	service := &Service{repository: mockRepo, notifier: mockNotifier, clock: clock.Fixed(now), config: DefaultConfig()}
+
+	product := model.Product{ID: uuid.New(), Name: "premium plan"}
+	buyer := model.User{ID: uuid.New()}
+	redeemed := model.Gift{ID: uuid.New(), BuyerID: buyer.ID, ProductID: product.ID, Status: model.GiftPurchased}
+	expired := model.Gift{ID: uuid.New(), BuyerID: buyer.ID, ProductID: product.ID, Status: model.GiftPurchased}
+
+	mockRepo.EXPECT().GetGiftsDueForExpiry(gomock.Any(), now).Return([]model.Gift{redeemed, expired}, nil)
+	// the first gift was redeemed since it was fetched
+	mockRepo.EXPECT().UpdateGift(gomock.Any(), gomock.Any()).
+		Return(fmt.Errorf("gift %s is no longer purchased: %w", redeemed.ID, model.ErrGiftNotRedeemable))
+	mockRepo.EXPECT().UpdateGift(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, gift model.Gift) error {
+			assert.Equal(t, expired.ID, gift.ID)
+			assert.Equal(t, model.GiftExpired, gift.Status)
+			return nil
+		},
+	)
+	mockRepo.EXPECT().GetUser(gomock.Any(), buyer.ID.String()).Return(buyer, nil)
+	mockRepo.EXPECT().GetProduct(gomock.Any(), product.ID.String()).Return(product, nil)
+	mockNotifier.EXPECT().Notify(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, notification model.Notification) error {
+			assert.Equal(t, model.NotificationGiftExpired, notification.Kind)
+			assert.Equal(t, expired.ID, notification.Gift.ID)
+			return nil
+		},
+	)
+
+	assert.NoError(t, service.ExpireGifts(context.Background()))
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/google/uuid"
+	"gymondo/internal/model"
+)
+
+const invoiceCurrency = "EUR"
+
+func (s *Service) FindInvoice(ctx context.Context, invoiceID string) (model.Invoice, error) {
+	invoice, err := s.repository.GetInvoice(ctx, invoiceID)
+	if err != nil {
+		return model.Invoice{}, fmt.Errorf("failed to fetch invoice with ID %s: %w", invoiceID, err)
+	}
+
+	return invoice, nil
+}
+
+func (s *Service) FindSubscriptionInvoices(ctx context.Context, subscriptionID string) ([]model.Invoice, error) {
+	invoices, err := s.repository.GetSubscriptionInvoices(ctx, subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch invoices for subscription %s: %w", subscriptionID, err)
+	}
+	if invoices == nil {
+		return []model.Invoice{}, nil
+	}
+
+	return invoices, nil
+}
+
+// issueInvoice bills the current period of the subscription to the user. The
+// invoice number is assigned by the repository.
+func (s *Service) issueInvoice(
+	ctx context.Context,
+	user model.User,
+	productName string,
+	subscription model.Subscription,
+) (model.Invoice, error) {
+	description := fmt.Sprintf(
+		"%s (%s - %s)",
+		productName,
+		subscription.StartDate.Format("02.01.2006"),
+		subscription.EndDate.Format("02.01.2006"),
+	)
+
+	// subscriptions of organizations list the price per seat
+	quantity, unitPrice := 1, subscription.Price
+	if subscription.Seats > 1 {
+		quantity = subscription.Seats
+		unitPrice = math.Round(subscription.Price/float64(subscription.Seats)*100) / 100
+	}
+
+	lineItems := []model.InvoiceLineItem{
+		{
+			Description: description,
+			Quantity:    quantity,
+			UnitPrice:   unitPrice,
+			Tax:         subscription.Tax,
+			TotalPrice:  subscription.TotalPrice,
+		},
+	}
+	lineItems = append(lineItems, addOnLineItems(subscription, subscription.StartDate)...)
+	lineItems = append(lineItems, seatLineItems(subscription, subscription.StartDate)...)
+
+	// the rounded seat price times the seats may be off by a cent, so the
+	// subscription's own price is counted
+	netAmount := subscription.Price
+	taxAmount := subscription.Tax
+	totalAmount := subscription.TotalPrice
+	for _, lineItem := range lineItems[1:] {
+		netAmount += lineItem.UnitPrice * float64(lineItem.Quantity)
+		taxAmount += lineItem.Tax
+		totalAmount += lineItem.TotalPrice
+	}
+
+	invoice := model.Invoice{
+		ID:             uuid.New(),
+		SubscriptionID: subscription.ID,
+		UserID:         user.ID,
+		CustomerName:   fmt.Sprintf("%s %s", user.FirstName, user.SecondName),
+		CustomerEmail:  user.Email,
+		IssueDate:      s.today(subscription.Location()),
+		PeriodStart:    subscription.StartDate,
+		PeriodEnd:      subscription.EndDate,
+		NetAmount:      math.Round(netAmount*100) / 100,
+		TaxAmount:      math.Round(taxAmount*100) / 100,
+		TotalAmount:    math.Round(totalAmount*100) / 100,
+		Currency:       invoiceCurrency,
+		LineItems:      lineItems,
+	}
+
+	if subscription.OrganizationID != nil {
+		organization, err := s.repository.GetOrganization(ctx, subscription.OrganizationID.String())
+		if err != nil {
+			return model.Invoice{}, fmt.Errorf("failed to fetch organization: %w", err)
+		}
+		invoice.CompanyName = organization.Name
+		invoice.VATID = organization.VATID
+		invoice.BillingAddress = organization.Address
+	}
+
+	invoice, err := s.repository.SaveInvoice(ctx, invoice)
+	if err != nil {
+		return model.Invoice{}, fmt.Errorf("failed to save invoice: %w", err)
+	}
+
+	return invoice, nil
+}
+
+// issueRenewalInvoice loads the customer and product of the subscription and
+// invoices its current period.
+func (s *Service) issueRenewalInvoice(ctx context.Context, subscription model.Subscription) error {
+	user, err := s.repository.GetUser(ctx, subscription.UserID.String())
+	if err != nil {
+		return fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	product, err := s.repository.GetProduct(ctx, subscription.ProductID.String())
+	if err != nil {
+		return fmt.Errorf("failed to fetch product: %w", err)
+	}
+
+	if _, err := s.issueInvoice(ctx, user, product.Name, subscription); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// issueGiftInvoice bills the purchase of the gift to its buyer. The gift is
+// delivered with its code, so the invoice covers the day of the purchase.
+func (s *Service) issueGiftInvoice(
+	ctx context.Context,
+	buyer model.User,
+	product model.Product,
+	gift model.Gift,
+) (model.Invoice, error) {
+	issueDate := s.today(model.LoadLocation(buyer.TimeZone))
+	invoice := model.Invoice{
+		ID:            uuid.New(),
+		GiftID:        &gift.ID,
+		UserID:        buyer.ID,
+		CustomerName:  fmt.Sprintf("%s %s", buyer.FirstName, buyer.SecondName),
+		CustomerEmail: buyer.Email,
+		IssueDate:     issueDate,
+		PeriodStart:   issueDate,
+		PeriodEnd:     issueDate,
+		NetAmount:     gift.Price,
+		TaxAmount:     gift.Tax,
+		TotalAmount:   gift.TotalPrice,
+		Currency:      invoiceCurrency,
+		LineItems: []model.InvoiceLineItem{
+			{
+				Description: fmt.Sprintf("Gift: %s, %d days", product.Name, gift.DurationDays),
+				Quantity:    1,
+				UnitPrice:   gift.Price,
+				Tax:         gift.Tax,
+				TotalPrice:  gift.TotalPrice,
+			},
+		},
+	}
+
+	invoice, err := s.repository.SaveInvoice(ctx, invoice)
+	if err != nil {
+		return model.Invoice{}, fmt.Errorf("failed to save invoice: %w", err)
+	}
+
+	return invoice, nil
+}
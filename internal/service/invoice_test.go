@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gymondo/internal/model"
+	"testing"
+	"time"
+)
+
+func Test_Service_FindInvoice(t *testing.T) {
+	t.Parallel()
+
+	t.Run("successful invoice fetch", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo}
+
+		invoiceID := uuid.New()
+		expectedInvoice := model.Invoice{ID: invoiceID, Number: "2026-000001"}
+		mockRepo.EXPECT().GetInvoice(gomock.Any(), invoiceID.String()).Return(expectedInvoice, nil)
+
+		invoice, err := service.FindInvoice(context.Background(), invoiceID.String())
+		assert.NoError(t, err)
+		assert.Equal(t, expectedInvoice, invoice)
+	})
+
+	t.Run("invoice not found", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo}
+
+		invoiceID := uuid.New().String()
+		mockRepo.EXPECT().GetInvoice(gomock.Any(), invoiceID).Return(model.Invoice{}, fmt.Errorf("invoice not found"))
+
+		_, err := service.FindInvoice(context.Background(), invoiceID)
+		assert.ErrorContains(t, err, "invoice not found")
+	})
+}
+
+func Test_Service_issueInvoice(t *testing.T) {
+	t.Parallel()
+
+	t.Run("invoice takes prices from subscription", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo}
+
+		user := model.User{ID: uuid.New(), FirstName: "jane", SecondName: "smith", Email: "jane.smith@example.com"}
+		startDate := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		subscription := model.Subscription{
+			ID:         uuid.New(),
+			UserID:     user.ID,
+			StartDate:  startDate,
+			EndDate:    startDate.AddDate(0, 0, 30),
+			Price:      9,
+			Tax:        0.9,
+			TotalPrice: 9.9,
+		}
+
+		mockRepo.EXPECT().SaveInvoice(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, invoice model.Invoice) (model.Invoice, error) {
+				invoice.Number = "2026-000007"
+				return invoice, nil
+			},
+		)
+
+		invoice, err := service.issueInvoice(context.Background(), user, "basic plan", subscription)
+		assert.NoError(t, err)
+		assert.Equal(t, "2026-000007", invoice.Number)
+		assert.Equal(t, "jane smith", invoice.CustomerName)
+		assert.Equal(t, "jane.smith@example.com", invoice.CustomerEmail)
+		assert.Equal(t, 9.0, invoice.NetAmount)
+		assert.Equal(t, 0.9, invoice.TaxAmount)
+		assert.Equal(t, 9.9, invoice.TotalAmount)
+		assert.Equal(t, "EUR", invoice.Currency)
+		assert.Equal(t, []model.InvoiceLineItem{
+			{Description: "basic plan (01.01.2026 - 31.01.2026)", Quantity: 1, UnitPrice: 9, Tax: 0.9, TotalPrice: 9.9},
+		}, invoice.LineItems)
+	})
+
+	t.Run("failed to save invoice", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo}
+
+		mockRepo.EXPECT().SaveInvoice(gomock.Any(), gomock.Any()).Return(model.Invoice{}, fmt.Errorf("database error"))
+
+		_, err := service.issueInvoice(context.Background(), model.User{}, "basic plan", model.Subscription{})
+		assert.ErrorContains(t, err, "failed to save invoice")
+	})
+}
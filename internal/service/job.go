@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"gymondo/internal/model"
+)
+
+// jobsLimit caps how many jobs FindJobs returns.
+const jobsLimit = 100
+
+// FindJobs returns the most recent background jobs, optionally filtered by
+// status.
+func (s *Service) FindJobs(ctx context.Context, status model.JobStatus) ([]model.Job, error) {
+	jobs, err := s.repository.GetJobs(ctx, status, jobsLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch jobs: %w", err)
+	}
+	if jobs == nil {
+		return []model.Job{}, nil
+	}
+
+	return jobs, nil
+}
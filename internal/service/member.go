@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gymondo/internal/model"
+)
+
+// InviteMember invites the user to share the subscription, taking one of the
+// seats of its product until the invitation is declined or the member is
+// removed. The user is notified about the invitation.
+func (s *Service) InviteMember(
+	ctx context.Context,
+	subscriptionID string,
+	version int,
+	userID string,
+) (model.SubscriptionMember, error) {
+	subscription, err := s.repository.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		return model.SubscriptionMember{}, fmt.Errorf("failed to find subscription with ID %s: %w", subscriptionID, err)
+	}
+	if err := checkVersion(subscription, version); err != nil {
+		return model.SubscriptionMember{}, err
+	}
+	if subscription.Status == model.Canceled {
+		return model.SubscriptionMember{}, fmt.Errorf("members can't be invited to a canceled subscription")
+	}
+
+	product, err := s.repository.GetProduct(ctx, subscription.ProductID.String())
+	if err != nil {
+		return model.SubscriptionMember{}, fmt.Errorf("failed to fetch product: %w", err)
+	}
+	if !product.Shareable() {
+		return model.SubscriptionMember{}, fmt.Errorf("product %s can't be shared with members", product.Name)
+	}
+
+	user, err := s.repository.GetUser(ctx, userID)
+	if err != nil {
+		return model.SubscriptionMember{}, fmt.Errorf("failed to fetch user: %w", err)
+	}
+	if user.ID == subscription.UserID {
+		return model.SubscriptionMember{}, fmt.Errorf("the owner can't be a member of their own subscription")
+	}
+	for _, member := range subscription.Members {
+		if member.UserID == user.ID {
+			return model.SubscriptionMember{}, fmt.Errorf("user %s is already %s", user.ID, memberStatusText(member.Status))
+		}
+	}
+	if err := checkFreeSeat(subscription, product); err != nil {
+		return model.SubscriptionMember{}, err
+	}
+
+	member := model.SubscriptionMember{
+		ID:             uuid.New(),
+		SubscriptionID: subscription.ID,
+		UserID:         user.ID,
+		Status:         model.MemberInvited,
+		InvitedAt:      s.now(),
+	}
+	subscription.Members = append(subscription.Members, member)
+
+	err = s.withinTx(ctx, func(tx *Service) error {
+		if err := tx.repository.SaveSubscriptionMember(ctx, member); err != nil {
+			return err
+		}
+		return tx.updateSubscriptionWithEvent(ctx, model.SubscriptionUpdated, subscription)
+	})
+	if err != nil {
+		return model.SubscriptionMember{}, fmt.Errorf("failed to invite member: %w", err)
+	}
+
+	s.notify(ctx, model.NotificationMemberInvitation, user, product.Name, subscription)
+
+	return member, nil
+}
+
+// AcceptInvitation makes the invited user an active member of the
+// subscription. A user can be an active member of one subscription at a
+// time, not counting subscriptions that no longer grant access.
+func (s *Service) AcceptInvitation(
+	ctx context.Context,
+	subscriptionID string,
+	memberID string,
+) (model.SubscriptionMember, error) {
+	subscription, err := s.repository.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		return model.SubscriptionMember{}, fmt.Errorf("failed to find subscription with ID %s: %w", subscriptionID, err)
+	}
+	if subscription.Status == model.Canceled {
+		return model.SubscriptionMember{}, fmt.Errorf("subscription %s is canceled", subscriptionID)
+	}
+
+	member, ok := subscription.Member(memberID)
+	if !ok || member.Status != model.MemberInvited {
+		return model.SubscriptionMember{}, fmt.Errorf("subscription has no pending invitation %s", memberID)
+	}
+
+	if err := s.checkNoMembership(ctx, member.UserID); err != nil {
+		return model.SubscriptionMember{}, err
+	}
+
+	acceptedAt := s.now()
+	member.Status = model.MemberActive
+	member.AcceptedAt = &acceptedAt
+	for i := range subscription.Members {
+		if subscription.Members[i].ID == member.ID {
+			subscription.Members[i] = member
+		}
+	}
+
+	err = s.withinTx(ctx, func(tx *Service) error {
+		if err := tx.repository.UpdateSubscriptionMember(ctx, member); err != nil {
+			return err
+		}
+		return tx.updateSubscriptionWithEvent(ctx, model.SubscriptionUpdated, subscription)
+	})
+	if err != nil {
+		return model.SubscriptionMember{}, fmt.Errorf("failed to accept invitation: %w", err)
+	}
+
+	return member, nil
+}
+
+// RemoveMember revokes an invitation or removes an active member, which
+// frees the seat and ends the member's access right away.
+func (s *Service) RemoveMember(ctx context.Context, subscriptionID string, version int, memberID string) error {
+	subscription, err := s.repository.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		return fmt.Errorf("failed to find subscription with ID %s: %w", subscriptionID, err)
+	}
+	if err := checkVersion(subscription, version); err != nil {
+		return err
+	}
+
+	member, ok := subscription.Member(memberID)
+	if !ok {
+		return fmt.Errorf("subscription has no member %s", memberID)
+	}
+
+	removedAt := s.now()
+	member.Status = model.MemberRemoved
+	member.RemovedAt = &removedAt
+	members := make([]model.SubscriptionMember, 0, len(subscription.Members))
+	for _, other := range subscription.Members {
+		if other.ID != member.ID {
+			members = append(members, other)
+		}
+	}
+	subscription.Members = members
+
+	err = s.withinTx(ctx, func(tx *Service) error {
+		if err := tx.repository.UpdateSubscriptionMember(ctx, member); err != nil {
+			return err
+		}
+		return tx.updateSubscriptionWithEvent(ctx, model.SubscriptionUpdated, subscription)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove member: %w", err)
+	}
+
+	s.entitlements.invalidate(member.UserID.String())
+	return nil
+}
+
+// memberSeats is the number of members the subscription can have. Every
+// seat of a seat based product is one user, including the owner.
+func memberSeats(subscription model.Subscription, product model.Product) int {
+	if product.SeatBased {
+		return subscription.Seats - 1
+	}
+	return product.MaxMembers
+}
+
+func checkFreeSeat(subscription model.Subscription, product model.Product) error {
+	seats := memberSeats(subscription, product)
+	if len(subscription.Members) >= seats {
+		return fmt.Errorf("%d of %d seats are taken: %w", len(subscription.Members), seats, model.ErrNoSeatsLeft)
+	}
+	return nil
+}
+
+// checkNoMembership fails when the user is a member of another subscription
+// that still grants access. Memberships of canceled or expired subscriptions
+// don't keep the user from joining a new one.
+func (s *Service) checkNoMembership(ctx context.Context, userID uuid.UUID) error {
+	memberships, err := s.repository.GetMemberSubscriptions(ctx, userID.String())
+	if err != nil {
+		return fmt.Errorf("failed to fetch memberships of user %s: %w", userID, err)
+	}
+
+	now := s.now()
+	for _, membership := range memberships {
+		if _, _, ok := entitlementStatus(membership, now); ok {
+			return fmt.Errorf("user %s is already a member of subscription %s", userID, membership.ID)
+		}
+	}
+
+	return nil
+}
+
+func memberStatusText(status model.MemberStatus) string {
+	if status == model.MemberInvited {
+		return "invited"
+	}
+	return "a member"
+}
@@ -0,0 +1,307 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gymondo/internal/clock"
+	"gymondo/internal/model"
+	"testing"
+	"time"
+)
+
+func Test_Service_InviteMember(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.June, 20, 10, 0, 0, 0, time.UTC)
+	familyPlan := model.Product{ID: uuid.New(), Name: "family", MaxMembers: 2}
+
+	newSubscription := func(members ...model.SubscriptionMember) model.Subscription {
+		return model.Subscription{
+			ID:        uuid.New(),
+			UserID:    uuid.New(),
+			ProductID: familyPlan.ID,
+			Status:    model.Active,
+			Members:   members,
+		}
+	}
+
+	t.Run("invites and notifies the user", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockNotifier := NewMockNotifier(ctrl)
+		service := &Service{repository: mockRepo, notifier: mockNotifier, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		subscription := newSubscription()
+		user := model.User{ID: uuid.New(), FirstName: "Anna"}
+
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), familyPlan.ID.String()).Return(familyPlan, nil)
+		mockRepo.EXPECT().GetUser(gomock.Any(), user.ID.String()).Return(user, nil)
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().SaveSubscriptionMember(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, updated model.Subscription) error {
+				assert.Len(t, updated.Members, 1)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).Return(nil)
+		mockNotifier.EXPECT().Notify(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, notification model.Notification) error {
+				assert.Equal(t, model.NotificationMemberInvitation, notification.Kind)
+				assert.Equal(t, user, notification.User)
+				assert.Equal(t, "family", notification.ProductName)
+				return nil
+			},
+		)
+
+		member, err := service.InviteMember(context.Background(), subscription.ID.String(), 0, user.ID.String())
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, member.UserID)
+		assert.Equal(t, model.MemberInvited, member.Status)
+		assert.Equal(t, now, member.InvitedAt)
+	})
+
+	t.Run("all seats taken", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		subscription := newSubscription(
+			model.SubscriptionMember{ID: uuid.New(), UserID: uuid.New(), Status: model.MemberActive},
+			model.SubscriptionMember{ID: uuid.New(), UserID: uuid.New(), Status: model.MemberInvited},
+		)
+		userID := uuid.New()
+
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), familyPlan.ID.String()).Return(familyPlan, nil)
+		mockRepo.EXPECT().GetUser(gomock.Any(), userID.String()).Return(model.User{ID: userID}, nil)
+
+		_, err := service.InviteMember(context.Background(), subscription.ID.String(), 0, userID.String())
+		assert.True(t, errors.Is(err, model.ErrNoSeatsLeft))
+	})
+
+	t.Run("product without seats", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		subscription := newSubscription()
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), familyPlan.ID.String()).Return(model.Product{ID: familyPlan.ID, Name: "basic"}, nil)
+
+		_, err := service.InviteMember(context.Background(), subscription.ID.String(), 0, uuid.NewString())
+		assert.EqualError(t, err, "product basic can't be shared with members")
+	})
+
+	t.Run("owner", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		subscription := newSubscription()
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), familyPlan.ID.String()).Return(familyPlan, nil)
+		mockRepo.EXPECT().GetUser(gomock.Any(), subscription.UserID.String()).Return(model.User{ID: subscription.UserID}, nil)
+
+		_, err := service.InviteMember(context.Background(), subscription.ID.String(), 0, subscription.UserID.String())
+		assert.EqualError(t, err, "the owner can't be a member of their own subscription")
+	})
+
+	t.Run("canceled subscription", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		subscription := newSubscription()
+		subscription.Status = model.Canceled
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+
+		_, err := service.InviteMember(context.Background(), subscription.ID.String(), 0, uuid.NewString())
+		assert.EqualError(t, err, "members can't be invited to a canceled subscription")
+	})
+}
+
+func Test_Service_AcceptInvitation(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.June, 20, 10, 0, 0, 0, time.UTC)
+
+	newSubscription := func() (model.Subscription, model.SubscriptionMember) {
+		member := model.SubscriptionMember{ID: uuid.New(), UserID: uuid.New(), Status: model.MemberInvited}
+		return model.Subscription{
+			ID:      uuid.New(),
+			UserID:  uuid.New(),
+			Status:  model.Active,
+			Members: []model.SubscriptionMember{member},
+		}, member
+	}
+
+	t.Run("activates the member", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		subscription, invited := newSubscription()
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+		mockRepo.EXPECT().GetMemberSubscriptions(gomock.Any(), invited.UserID.String()).Return(nil, nil)
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().UpdateSubscriptionMember(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, updated model.Subscription) error {
+				assert.Equal(t, model.MemberActive, updated.Members[0].Status)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).Return(nil)
+
+		member, err := service.AcceptInvitation(context.Background(), subscription.ID.String(), invited.ID.String())
+		assert.NoError(t, err)
+		assert.Equal(t, model.MemberActive, member.Status)
+		assert.Equal(t, now, *member.AcceptedAt)
+	})
+
+	t.Run("already a member elsewhere", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		subscription, invited := newSubscription()
+		canceled := model.Subscription{ID: uuid.New(), Status: model.Canceled}
+		other := model.Subscription{ID: uuid.New(), Status: model.Active}
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+		mockRepo.EXPECT().GetMemberSubscriptions(gomock.Any(), invited.UserID.String()).Return([]model.Subscription{canceled, other}, nil)
+
+		_, err := service.AcceptInvitation(context.Background(), subscription.ID.String(), invited.ID.String())
+		assert.EqualError(t, err, "user "+invited.UserID.String()+" is already a member of subscription "+other.ID.String())
+	})
+
+	t.Run("memberships of ended subscriptions don't count", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		subscription, invited := newSubscription()
+		graceEndDate := now.AddDate(0, 0, -1)
+		ended := []model.Subscription{
+			{ID: uuid.New(), Status: model.Canceled},
+			{ID: uuid.New(), Status: model.PastDue, GraceEndDate: &graceEndDate},
+		}
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+		mockRepo.EXPECT().GetMemberSubscriptions(gomock.Any(), invited.UserID.String()).Return(ended, nil)
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().UpdateSubscriptionMember(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).Return(nil)
+
+		member, err := service.AcceptInvitation(context.Background(), subscription.ID.String(), invited.ID.String())
+		assert.NoError(t, err)
+		assert.Equal(t, model.MemberActive, member.Status)
+	})
+
+	t.Run("no pending invitation", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		subscription, _ := newSubscription()
+		subscription.Members[0].Status = model.MemberActive
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+
+		memberID := subscription.Members[0].ID.String()
+		_, err := service.AcceptInvitation(context.Background(), subscription.ID.String(), memberID)
+		assert.EqualError(t, err, "subscription has no pending invitation "+memberID)
+	})
+}
+
+func Test_Service_RemoveMember(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.June, 20, 10, 0, 0, 0, time.UTC)
+
+	t.Run("frees the seat and clears the member's entitlements", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{
+			repository:   mockRepo,
+			clock:        clock.Fixed(now),
+			config:       DefaultConfig(),
+			entitlements: newEntitlementCache(30 * time.Second),
+		}
+
+		member := model.SubscriptionMember{ID: uuid.New(), UserID: uuid.New(), Status: model.MemberActive}
+		subscription := model.Subscription{
+			ID:      uuid.New(),
+			UserID:  uuid.New(),
+			Status:  model.Active,
+			Members: []model.SubscriptionMember{member},
+		}
+		service.entitlements.put(member.UserID.String(), model.Entitlements{Entitlements: []string{"workouts"}}, now)
+
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().UpdateSubscriptionMember(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, removed model.SubscriptionMember) error {
+				assert.Equal(t, model.MemberRemoved, removed.Status)
+				assert.Equal(t, now, *removed.RemovedAt)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, updated model.Subscription) error {
+				assert.Empty(t, updated.Members)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).Return(nil)
+
+		err := service.RemoveMember(context.Background(), subscription.ID.String(), 0, member.ID.String())
+		assert.NoError(t, err)
+
+		_, cached := service.entitlements.get(member.UserID.String(), now)
+		assert.False(t, cached)
+	})
+}
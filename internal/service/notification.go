@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"gymondo/internal/model"
+)
+
+// notify sends a notification about a change that is already stored. A
+// notification that can't be sent is logged and does not undo the change.
+func (s *Service) notify(
+	ctx context.Context,
+	kind model.NotificationKind,
+	user model.User,
+	productName string,
+	subscription model.Subscription,
+) {
+	notification := model.Notification{
+		Kind:         kind,
+		User:         user,
+		ProductName:  productName,
+		Subscription: subscription,
+	}
+	if err := s.notifier.Notify(ctx, notification); err != nil {
+		log.Printf("Error sending %s notification for subscription %s: %v", kind, subscription.ID, err)
+	}
+}
+
+// notifySubscription looks up the user and product of the subscription and
+// sends them the notification.
+func (s *Service) notifySubscription(ctx context.Context, kind model.NotificationKind, subscription model.Subscription) {
+	if err := s.sendSubscriptionNotification(ctx, kind, subscription); err != nil {
+		log.Printf("Error sending %s notification for subscription %s: %v", kind, subscription.ID, err)
+	}
+}
+
+func (s *Service) sendSubscriptionNotification(
+	ctx context.Context,
+	kind model.NotificationKind,
+	subscription model.Subscription,
+) error {
+	user, err := s.repository.GetUser(ctx, subscription.UserID.String())
+	if err != nil {
+		return fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	product, err := s.repository.GetProduct(ctx, subscription.ProductID.String())
+	if err != nil {
+		return fmt.Errorf("failed to fetch product: %w", err)
+	}
+
+	return s.notifier.Notify(ctx, model.Notification{
+		Kind:         kind,
+		User:         user,
+		ProductName:  product.Name,
+		Subscription: subscription,
+	})
+}
@@ -0,0 +1,338 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gymondo/internal/model"
+)
+
+// CreateOrganization registers the organization with the user as its first
+// admin.
+func (s *Service) CreateOrganization(
+	ctx context.Context,
+	organization model.Organization,
+	adminID string,
+) (model.Organization, error) {
+	if strings.TrimSpace(organization.Name) == "" || strings.TrimSpace(organization.Address) == "" {
+		return model.Organization{}, fmt.Errorf("organizations need a name and a billing address")
+	}
+
+	admin, err := s.repository.GetUser(ctx, adminID)
+	if err != nil {
+		return model.Organization{}, fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	organization.ID = uuid.New()
+	organization.Domain = strings.ToLower(strings.TrimPrefix(organization.Domain, "@"))
+	organization.AdminIDs = []uuid.UUID{admin.ID}
+	organization.CreatedAt = s.now()
+
+	err = s.repository.WithinTx(ctx, func(repo Repository) error {
+		if err := repo.SaveOrganization(ctx, organization); err != nil {
+			return err
+		}
+		return repo.SaveOrganizationAdmin(ctx, organization.ID.String(), admin.ID.String())
+	})
+	if err != nil {
+		return model.Organization{}, fmt.Errorf("failed to create organization: %w", err)
+	}
+
+	return organization, nil
+}
+
+func (s *Service) FindOrganization(ctx context.Context, organizationID string) (model.Organization, error) {
+	organization, err := s.repository.GetOrganization(ctx, organizationID)
+	if err != nil {
+		return model.Organization{}, fmt.Errorf("failed to fetch organization: %w", err)
+	}
+
+	return organization, nil
+}
+
+// AddOrganizationAdmin lets the user manage the organization and subscribe
+// for it.
+func (s *Service) AddOrganizationAdmin(ctx context.Context, organizationID string, userID string) error {
+	if _, err := s.repository.GetOrganization(ctx, organizationID); err != nil {
+		return fmt.Errorf("failed to fetch organization: %w", err)
+	}
+
+	user, err := s.repository.GetUser(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	if err := s.repository.SaveOrganizationAdmin(ctx, organizationID, user.ID.String()); err != nil {
+		return fmt.Errorf("failed to add admin: %w", err)
+	}
+
+	return nil
+}
+
+// SubscribeOrganization subscribes the organization to a seat based product
+// on behalf of one of its admins, who owns the subscription and takes one of
+// its seats. The price is the product's price per seat, the first period is
+// charged right away. An organization has one subscription at a time.
+func (s *Service) SubscribeOrganization(
+	ctx context.Context,
+	organizationID string,
+	adminID string,
+	productID string,
+	seats int,
+) (string, error) {
+	organization, err := s.repository.GetOrganization(ctx, organizationID)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch organization: %w", err)
+	}
+
+	admin, err := s.repository.GetUser(ctx, adminID)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch user: %w", err)
+	}
+	if !organization.HasAdmin(admin.ID) {
+		return "", fmt.Errorf("user %s is not an admin of organization %s", admin.ID, organization.Name)
+	}
+
+	product, err := s.repository.GetProduct(ctx, productID)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch product: %w", err)
+	}
+	if !product.SeatBased {
+		return "", fmt.Errorf("product %s is not priced per seat", product.Name)
+	}
+	if seats < 1 {
+		return "", fmt.Errorf("a subscription needs at least one seat")
+	}
+
+	subscriptions, err := s.repository.GetOrganizationSubscriptions(ctx, organizationID)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch subscriptions of organization %s: %w", organizationID, err)
+	}
+	if len(subscriptions) > 0 {
+		return "", fmt.Errorf("organization %s already has subscription %s", organization.Name, subscriptions[0].ID)
+	}
+
+	location := model.LoadLocation(admin.TimeZone)
+	startDate := s.today(location)
+	price := math.Round(product.Price*float64(seats)*100) / 100
+	tax := math.Round(product.Tax*float64(seats)*100) / 100
+	subscription := model.Subscription{
+		ID:             uuid.New(),
+		UserID:         admin.ID,
+		ProductID:      product.ID,
+		StartDate:      startDate,
+		EndDate:        startDate.AddDate(0, 0, product.DurationDays),
+		DurationDays:   product.DurationDays,
+		Price:          price,
+		Tax:            tax,
+		TotalPrice:     math.Round((price+tax)*100) / 100,
+		Status:         model.Active,
+		Version:        1,
+		TimeZone:       location.String(),
+		OrganizationID: &organization.ID,
+		Seats:          seats,
+	}
+
+	return s.startSubscription(ctx, admin, product, subscription, nil)
+}
+
+// ChangeSeats sets the number of seats of an organization's subscription.
+// The price of the subscription changes right away; the added or removed
+// seats are prorated to the rest of the current period. Added seats are
+// charged with the next renewal, removed ones are credited to the owner's
+// credit balance right away. Seats taken by the owner and members can't be
+// removed.
+func (s *Service) ChangeSeats(
+	ctx context.Context,
+	subscriptionID string,
+	version int,
+	seats int,
+) (model.SeatChange, error) {
+	subscription, err := s.repository.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		return model.SeatChange{}, fmt.Errorf("failed to find subscription with ID %s: %w", subscriptionID, err)
+	}
+	if err := checkVersion(subscription, version); err != nil {
+		return model.SeatChange{}, err
+	}
+	if subscription.OrganizationID == nil {
+		return model.SeatChange{}, fmt.Errorf("only subscriptions of organizations have seats")
+	}
+	if subscription.Status != model.Active {
+		return model.SeatChange{}, fmt.Errorf("seats can only be changed on active subscriptions")
+	}
+	if taken := len(subscription.Members) + 1; seats < taken {
+		return model.SeatChange{}, fmt.Errorf("%d seats are taken, remove members first", taken)
+	}
+	if seats == subscription.Seats {
+		return model.SeatChange{}, fmt.Errorf("subscription already has %d seats", seats)
+	}
+
+	today := s.today(subscription.Location())
+	seatPrice := subscription.Price / float64(subscription.Seats)
+	seatTax := subscription.Tax / float64(subscription.Seats)
+
+	// the change applies to the days left of the current period
+	share := float64(daysBetween(today, subscription.EndDate)) / float64(subscription.DurationDays)
+	share = math.Max(0, math.Min(share, 1))
+	added := float64(seats - subscription.Seats)
+	changePrice := math.Round(seatPrice*added*share*100) / 100
+	changeTax := math.Round(seatTax*added*share*100) / 100
+
+	change := model.SeatChange{
+		ID:             uuid.New(),
+		SubscriptionID: subscription.ID,
+		SeatsBefore:    subscription.Seats,
+		SeatsAfter:     seats,
+		ChangeDate:     today,
+		Price:          changePrice,
+		Tax:            changeTax,
+		TotalPrice:     math.Round((changePrice+changeTax)*100) / 100,
+	}
+
+	subscription.Seats = seats
+	subscription.Price = math.Round(seatPrice*float64(seats)*100) / 100
+	subscription.Tax = math.Round(seatTax*float64(seats)*100) / 100
+	subscription.TotalPrice = math.Round((subscription.Price+subscription.Tax)*100) / 100
+	subscription.SeatChanges = append(subscription.SeatChanges, change)
+
+	var credit *model.CreditTransaction
+	if change.TotalPrice < 0 {
+		transaction := s.newCreditTransaction(
+			subscription.UserID,
+			model.CreditProration,
+			model.ProrationsAccount,
+			-change.TotalPrice,
+			fmt.Sprintf("Unused days of %d removed seats", change.SeatsBefore-change.SeatsAfter),
+		)
+		transaction.SubscriptionID = &subscription.ID
+		credit = &transaction
+	}
+
+	err = s.withinTx(ctx, func(tx *Service) error {
+		if err := tx.repository.SaveSeatChange(ctx, change); err != nil {
+			return err
+		}
+		if credit != nil {
+			if err := tx.repository.SaveCreditTransaction(ctx, *credit); err != nil {
+				return fmt.Errorf("failed to credit removed seats: %w", err)
+			}
+		}
+		return tx.updateSubscriptionWithEvent(ctx, model.SubscriptionUpdated, subscription)
+	})
+	if err != nil {
+		return model.SeatChange{}, fmt.Errorf("failed to change seats: %w", err)
+	}
+
+	return change, nil
+}
+
+// JoinOrganization makes the user an active member of the organization's
+// subscription without an invitation, if their email address is in the
+// organization's domain and a seat is free.
+func (s *Service) JoinOrganization(
+	ctx context.Context,
+	organizationID string,
+	userID string,
+) (model.SubscriptionMember, error) {
+	organization, err := s.repository.GetOrganization(ctx, organizationID)
+	if err != nil {
+		return model.SubscriptionMember{}, fmt.Errorf("failed to fetch organization: %w", err)
+	}
+
+	user, err := s.repository.GetUser(ctx, userID)
+	if err != nil {
+		return model.SubscriptionMember{}, fmt.Errorf("failed to fetch user: %w", err)
+	}
+	if !organization.EmployeeEmail(user.Email) {
+		return model.SubscriptionMember{}, fmt.Errorf("email %s is not in the domain of organization %s",
+			user.Email, organization.Name)
+	}
+
+	subscriptions, err := s.repository.GetOrganizationSubscriptions(ctx, organizationID)
+	if err != nil {
+		return model.SubscriptionMember{}, fmt.Errorf("failed to fetch subscriptions of organization %s: %w",
+			organizationID, err)
+	}
+	if len(subscriptions) == 0 {
+		return model.SubscriptionMember{}, fmt.Errorf("organization %s has no subscription", organization.Name)
+	}
+	subscription := subscriptions[0]
+
+	if user.ID == subscription.UserID {
+		return model.SubscriptionMember{}, fmt.Errorf("the owner can't be a member of their own subscription")
+	}
+	for _, member := range subscription.Members {
+		if member.UserID == user.ID {
+			return model.SubscriptionMember{}, fmt.Errorf("user %s is already %s", user.ID, memberStatusText(member.Status))
+		}
+	}
+
+	product, err := s.repository.GetProduct(ctx, subscription.ProductID.String())
+	if err != nil {
+		return model.SubscriptionMember{}, fmt.Errorf("failed to fetch product: %w", err)
+	}
+	if err := checkFreeSeat(subscription, product); err != nil {
+		return model.SubscriptionMember{}, err
+	}
+
+	if err := s.checkNoMembership(ctx, user.ID); err != nil {
+		return model.SubscriptionMember{}, err
+	}
+
+	now := s.now()
+	member := model.SubscriptionMember{
+		ID:             uuid.New(),
+		SubscriptionID: subscription.ID,
+		UserID:         user.ID,
+		Status:         model.MemberActive,
+		InvitedAt:      now,
+		AcceptedAt:     &now,
+	}
+	subscription.Members = append(subscription.Members, member)
+
+	err = s.withinTx(ctx, func(tx *Service) error {
+		if err := tx.repository.SaveSubscriptionMember(ctx, member); err != nil {
+			return err
+		}
+		return tx.updateSubscriptionWithEvent(ctx, model.SubscriptionUpdated, subscription)
+	})
+	if err != nil {
+		return model.SubscriptionMember{}, fmt.Errorf("failed to join organization: %w", err)
+	}
+
+	return member, nil
+}
+
+// seatLineItems returns the seats added during the period before the one
+// starting at periodStart, which are billed on its invoice. Removed seats
+// were credited when they were removed.
+func seatLineItems(subscription model.Subscription, periodStart time.Time) []model.InvoiceLineItem {
+	previousStart := periodStart.AddDate(0, 0, -subscription.DurationDays)
+
+	var lineItems []model.InvoiceLineItem
+	for _, change := range subscription.SeatChanges {
+		if change.ChangeDate.Before(previousStart) || !change.ChangeDate.Before(periodStart) {
+			continue
+		}
+		if change.SeatsAfter < change.SeatsBefore {
+			continue
+		}
+
+		description := fmt.Sprintf("%d additional seats", change.SeatsAfter-change.SeatsBefore)
+		lineItems = append(lineItems, model.InvoiceLineItem{
+			Description: fmt.Sprintf("%s (%s - %s, prorated)", description,
+				change.ChangeDate.Format("02.01.2006"), periodStart.Format("02.01.2006")),
+			Quantity:   1,
+			UnitPrice:  change.Price,
+			Tax:        change.Tax,
+			TotalPrice: change.TotalPrice,
+		})
+	}
+
+	return lineItems
+}
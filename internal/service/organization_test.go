@@ -0,0 +1,372 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gymondo/internal/clock"
+	"gymondo/internal/model"
+	"testing"
+	"time"
+)
+
+func Test_Service_SubscribeOrganization(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.June, 20, 10, 0, 0, 0, time.UTC)
+	teamPlan := model.Product{
+		ID:           uuid.New(),
+		Name:         "team plan",
+		DurationDays: 365,
+		Price:        80,
+		Tax:          8,
+		TotalPrice:   88,
+		SeatBased:    true,
+	}
+
+	t.Run("charges every seat and bills the organization", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockPayments := NewMockPaymentGateway(ctrl)
+		mockNotifier := NewMockNotifier(ctrl)
+		service := &Service{
+			repository: mockRepo,
+			payments:   mockPayments,
+			notifier:   mockNotifier,
+			clock:      clock.Fixed(now),
+			config:     DefaultConfig(),
+		}
+
+		admin := model.User{ID: uuid.New(), FirstName: "Anna", TimeZone: "UTC"}
+		organization := model.Organization{
+			ID:       uuid.New(),
+			Name:     "Acme GmbH",
+			VATID:    "DE123456789",
+			Address:  "Hauptstraße 1\n10115 Berlin",
+			AdminIDs: []uuid.UUID{admin.ID},
+		}
+
+		mockRepo.EXPECT().GetOrganization(gomock.Any(), organization.ID.String()).Return(organization, nil).Times(2)
+		mockRepo.EXPECT().GetUser(gomock.Any(), admin.ID.String()).Return(admin, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), teamPlan.ID.String()).Return(teamPlan, nil)
+		mockRepo.EXPECT().GetOrganizationSubscriptions(gomock.Any(), organization.ID.String()).Return(nil, nil)
+		mockRepo.EXPECT().GetCreditBalance(gomock.Any(), admin.ID.String()).Return(0.0, nil)
+		mockPayments.EXPECT().Charge(gomock.Any(), admin.ID, 440.0, gomock.Any()).Return("tx-1", nil)
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().SaveSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, subscription model.Subscription) error {
+				assert.Equal(t, 5, subscription.Seats)
+				assert.Equal(t, &organization.ID, subscription.OrganizationID)
+				assert.Equal(t, 400.0, subscription.Price)
+				assert.Equal(t, 40.0, subscription.Tax)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().SavePaymentAttempt(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().SaveInvoice(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, invoice model.Invoice) (model.Invoice, error) {
+				assert.Equal(t, "Acme GmbH", invoice.CompanyName)
+				assert.Equal(t, "DE123456789", invoice.VATID)
+				assert.Equal(t, "Hauptstraße 1\n10115 Berlin", invoice.BillingAddress)
+				assert.Equal(t, 5, invoice.LineItems[0].Quantity)
+				assert.Equal(t, 80.0, invoice.LineItems[0].UnitPrice)
+				assert.Equal(t, 400.0, invoice.NetAmount)
+				assert.Equal(t, 440.0, invoice.TotalAmount)
+				return invoice, nil
+			},
+		)
+		mockNotifier.EXPECT().Notify(gomock.Any(), gomock.Any()).Return(nil)
+
+		subscriptionID, err := service.SubscribeOrganization(
+			context.Background(), organization.ID.String(), admin.ID.String(), teamPlan.ID.String(), 5)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, subscriptionID)
+	})
+
+	t.Run("only admins subscribe", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		user := model.User{ID: uuid.New()}
+		organization := model.Organization{ID: uuid.New(), Name: "Acme GmbH", AdminIDs: []uuid.UUID{uuid.New()}}
+
+		mockRepo.EXPECT().GetOrganization(gomock.Any(), organization.ID.String()).Return(organization, nil)
+		mockRepo.EXPECT().GetUser(gomock.Any(), user.ID.String()).Return(user, nil)
+
+		_, err := service.SubscribeOrganization(
+			context.Background(), organization.ID.String(), user.ID.String(), teamPlan.ID.String(), 5)
+		assert.EqualError(t, err, "user "+user.ID.String()+" is not an admin of organization Acme GmbH")
+	})
+
+	t.Run("users can't subscribe to seat based products", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		userID := uuid.New()
+		mockRepo.EXPECT().GetUser(gomock.Any(), userID.String()).Return(model.User{ID: userID}, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), teamPlan.ID.String()).Return(teamPlan, nil)
+
+		_, err := service.Subscribe(context.Background(), userID.String(), teamPlan.ID.String(), "", "", nil, false)
+		assert.EqualError(t, err, "product team plan can only be subscribed to by an organization")
+	})
+}
+
+func Test_Service_AddOrganizationAdmin(t *testing.T) {
+	t.Parallel()
+
+	user := model.User{ID: uuid.New()}
+	organization := model.Organization{ID: uuid.New(), Name: "Acme GmbH"}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	service := &Service{repository: mockRepo}
+
+	mockRepo.EXPECT().GetOrganization(gomock.Any(), organization.ID.String()).Return(organization, nil)
+	mockRepo.EXPECT().GetUser(gomock.Any(), user.ID.String()).Return(user, nil)
+	mockRepo.EXPECT().SaveOrganizationAdmin(gomock.Any(), organization.ID.String(), user.ID.String()).Return(nil)
+
+	err := service.AddOrganizationAdmin(context.Background(), organization.ID.String(), user.ID.String())
+	assert.NoError(t, err)
+}
+
+func Test_Service_ChangeSeats(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.June, 20, 10, 0, 0, 0, time.UTC)
+	today := model.StartOfDay(now, time.UTC)
+	organizationID := uuid.New()
+
+	newSubscription := func() model.Subscription {
+		return model.Subscription{
+			ID:             uuid.New(),
+			UserID:         uuid.New(),
+			StartDate:      today.AddDate(0, 0, -292),
+			EndDate:        today.AddDate(0, 0, 73),
+			DurationDays:   365,
+			Price:          400,
+			Tax:            40,
+			TotalPrice:     440,
+			Status:         model.Active,
+			OrganizationID: &organizationID,
+			Seats:          5,
+			Members:        []model.SubscriptionMember{{ID: uuid.New(), UserID: uuid.New(), Status: model.MemberActive}},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		seats        int
+		changeTotal  float64
+		subscription float64
+		credit       float64
+	}{
+		{name: "added seats are charged for the rest of the period", seats: 8, changeTotal: 52.8, subscription: 704},
+		{name: "removed seats are credited for the rest of the period", seats: 3, changeTotal: -35.2, subscription: 264, credit: 35.2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := NewMockRepository(ctrl)
+			service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+			subscription := newSubscription()
+			mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+			expectWithinTx(mockRepo)
+			mockRepo.EXPECT().SaveSeatChange(gomock.Any(), gomock.Any()).Return(nil)
+			if test.credit > 0 {
+				mockRepo.EXPECT().SaveCreditTransaction(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, transaction model.CreditTransaction) error {
+						assert.Equal(t, subscription.UserID, transaction.UserID)
+						assert.Equal(t, model.CreditProration, transaction.Kind)
+						assert.Equal(t, test.credit, transaction.CustomerAmount())
+						return nil
+					},
+				)
+			}
+			mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, updated model.Subscription) error {
+					assert.Equal(t, test.seats, updated.Seats)
+					assert.Equal(t, test.subscription, updated.TotalPrice)
+					assert.Len(t, updated.SeatChanges, 1)
+					return nil
+				},
+			)
+			mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).Return(nil)
+
+			change, err := service.ChangeSeats(context.Background(), subscription.ID.String(), 0, test.seats)
+			assert.NoError(t, err)
+			assert.Equal(t, 5, change.SeatsBefore)
+			assert.Equal(t, test.seats, change.SeatsAfter)
+			assert.Equal(t, today, change.ChangeDate)
+			assert.Equal(t, test.changeTotal, change.TotalPrice)
+		})
+	}
+
+	t.Run("seats in use can't be removed", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		subscription := newSubscription()
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+
+		_, err := service.ChangeSeats(context.Background(), subscription.ID.String(), 0, 1)
+		assert.EqualError(t, err, "2 seats are taken, remove members first")
+	})
+
+	t.Run("personal subscription", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		subscription := newSubscription()
+		subscription.OrganizationID = nil
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+
+		_, err := service.ChangeSeats(context.Background(), subscription.ID.String(), 0, 8)
+		assert.EqualError(t, err, "only subscriptions of organizations have seats")
+	})
+}
+
+func Test_Service_JoinOrganization(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.June, 20, 10, 0, 0, 0, time.UTC)
+	teamPlan := model.Product{ID: uuid.New(), SeatBased: true}
+	organization := model.Organization{ID: uuid.New(), Name: "Acme GmbH", Domain: "acme.com"}
+
+	newSubscription := func(seats int) model.Subscription {
+		return model.Subscription{
+			ID:             uuid.New(),
+			UserID:         uuid.New(),
+			ProductID:      teamPlan.ID,
+			Status:         model.Active,
+			OrganizationID: &organization.ID,
+			Seats:          seats,
+			Members:        []model.SubscriptionMember{{ID: uuid.New(), UserID: uuid.New(), Status: model.MemberActive}},
+		}
+	}
+
+	t.Run("employees join without an invitation", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		user := model.User{ID: uuid.New(), Email: "Anna.Schmidt@ACME.com"}
+		subscription := newSubscription(3)
+
+		mockRepo.EXPECT().GetOrganization(gomock.Any(), organization.ID.String()).Return(organization, nil)
+		mockRepo.EXPECT().GetUser(gomock.Any(), user.ID.String()).Return(user, nil)
+		mockRepo.EXPECT().GetOrganizationSubscriptions(gomock.Any(), organization.ID.String()).Return([]model.Subscription{subscription}, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), teamPlan.ID.String()).Return(teamPlan, nil)
+		mockRepo.EXPECT().GetMemberSubscriptions(gomock.Any(), user.ID.String()).Return(nil, nil)
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().SaveSubscriptionMember(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).Return(nil)
+
+		member, err := service.JoinOrganization(context.Background(), organization.ID.String(), user.ID.String())
+		assert.NoError(t, err)
+		assert.Equal(t, subscription.ID, member.SubscriptionID)
+		assert.Equal(t, model.MemberActive, member.Status)
+		assert.Equal(t, now, *member.AcceptedAt)
+	})
+
+	t.Run("all seats taken", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		user := model.User{ID: uuid.New(), Email: "anna@acme.com"}
+
+		mockRepo.EXPECT().GetOrganization(gomock.Any(), organization.ID.String()).Return(organization, nil)
+		mockRepo.EXPECT().GetUser(gomock.Any(), user.ID.String()).Return(user, nil)
+		mockRepo.EXPECT().GetOrganizationSubscriptions(gomock.Any(), organization.ID.String()).Return([]model.Subscription{newSubscription(2)}, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), teamPlan.ID.String()).Return(teamPlan, nil)
+
+		_, err := service.JoinOrganization(context.Background(), organization.ID.String(), user.ID.String())
+		assert.True(t, errors.Is(err, model.ErrNoSeatsLeft))
+	})
+
+	t.Run("email outside the domain", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		user := model.User{ID: uuid.New(), Email: "anna@notacme.com"}
+
+		mockRepo.EXPECT().GetOrganization(gomock.Any(), organization.ID.String()).Return(organization, nil)
+		mockRepo.EXPECT().GetUser(gomock.Any(), user.ID.String()).Return(user, nil)
+
+		_, err := service.JoinOrganization(context.Background(), organization.ID.String(), user.ID.String())
+		assert.EqualError(t, err, "email anna@notacme.com is not in the domain of organization Acme GmbH")
+	})
+}
+
+func Test_seatLineItems(t *testing.T) {
+	t.Parallel()
+
+	periodStart := time.Date(2025, time.June, 20, 0, 0, 0, 0, time.UTC)
+	subscription := model.Subscription{
+		EndDate:      periodStart,
+		DurationDays: 365,
+		TotalPrice:   704,
+		Seats:        8,
+		SeatChanges: []model.SeatChange{
+			{SeatsBefore: 4, SeatsAfter: 5, ChangeDate: periodStart.AddDate(0, 0, -400), TotalPrice: 80},
+			{SeatsBefore: 5, SeatsAfter: 8, ChangeDate: periodStart.AddDate(0, 0, -73), Price: 48, Tax: 4.8, TotalPrice: 52.8},
+			{SeatsBefore: 8, SeatsAfter: 7, ChangeDate: periodStart.AddDate(0, 0, -10), Price: -2.19, Tax: -0.22, TotalPrice: -2.41},
+		},
+	}
+
+	lineItems := seatLineItems(subscription, periodStart)
+	assert.Len(t, lineItems, 1)
+	assert.Equal(t, "3 additional seats (08.04.2025 - 20.06.2025, prorated)", lineItems[0].Description)
+	assert.Equal(t, 52.8, lineItems[0].TotalPrice)
+
+	assert.Equal(t, 756.8, renewalTotal(subscription))
+}
@@ -0,0 +1,349 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"gymondo/internal/model"
+)
+
+// pauseDates returns the days the pause starts and ends on in the time zone
+// of the subscription. Without a resume date the returned one is nil.
+func pauseDates(
+	subscription model.Subscription,
+	schedule model.PauseSchedule,
+	today time.Time,
+) (time.Time, *time.Time, error) {
+	location := subscription.Location()
+
+	pauseFrom := today
+	if schedule.PauseFrom != nil {
+		pauseFrom = model.DateIn(*schedule.PauseFrom, location)
+		if pauseFrom.Before(today) {
+			return time.Time{}, nil, fmt.Errorf("pause can't start in the past")
+		}
+	}
+
+	if schedule.ResumeOn == nil {
+		return pauseFrom, nil, nil
+	}
+
+	resumeOn := model.DateIn(*schedule.ResumeOn, location)
+	if !resumeOn.After(pauseFrom) {
+		return time.Time{}, nil, fmt.Errorf("pause must end after it starts")
+	}
+
+	return pauseFrom, &resumeOn, nil
+}
+
+// rescheduleResume sets when a paused subscription resumes.
+func (s *Service) rescheduleResume(
+	ctx context.Context,
+	subscription model.Subscription,
+	schedule model.PauseSchedule,
+) error {
+	if schedule.PauseFrom != nil || schedule.ResumeOn == nil {
+		return fmt.Errorf("subscription is already paused")
+	}
+
+	today := s.today(subscription.Location())
+	_, resumeOn, err := pauseDates(subscription, schedule, today)
+	if err != nil {
+		return err
+	}
+
+	pausedDate := today
+	if subscription.PausedDate != nil {
+		pausedDate = *subscription.PausedDate
+	}
+	resumeOn, err = s.checkPausePolicy(ctx, subscription, pausedDate, resumeOn)
+	if err != nil {
+		return err
+	}
+	subscription.ResumeOn = resumeOn
+
+	err = s.updateSubscriptionWithEvent(ctx, model.SubscriptionPauseScheduled, subscription)
+	if err != nil {
+		return fmt.Errorf("failed to reschedule resume: %w", err)
+	}
+
+	return nil
+}
+
+// CancelScheduledPause removes a pause that is scheduled but has not started
+// yet.
+func (s *Service) CancelScheduledPause(ctx context.Context, subscriptionID string, version int) error {
+	subscription, err := s.repository.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		return fmt.Errorf("failed to find subscription with ID %s: %w", subscriptionID, err)
+	}
+	if err := checkVersion(subscription, version); err != nil {
+		return err
+	}
+
+	if subscription.Status != model.Active || subscription.PauseFrom == nil {
+		return fmt.Errorf("subscription has no scheduled pause")
+	}
+
+	subscription.PauseFrom = nil
+	subscription.ResumeOn = nil
+
+	err = s.updateSubscriptionWithEvent(ctx, model.SubscriptionPauseScheduled, subscription)
+	if err != nil {
+		return fmt.Errorf("failed to cancel scheduled pause: %w", err)
+	}
+
+	return nil
+}
+
+// ApplyScheduledPauses pauses the subscriptions whose scheduled pause has
+// started and resumes the ones whose pause has ended. A subscription that
+// can't be changed now, e.g. because it was changed concurrently, is picked
+// up again on the next run.
+func (s *Service) ApplyScheduledPauses(ctx context.Context) error {
+	now := s.now()
+
+	pausing, err := s.repository.GetSubscriptionsDueForPause(ctx, now)
+	if err != nil {
+		return fmt.Errorf("failed to fetch subscriptions due for pause: %w", err)
+	}
+
+	for _, subscription := range pausing {
+		pausedDate := *subscription.PauseFrom
+		subscription.Status = model.Paused
+		subscription.PausedDate = &pausedDate
+		subscription.PauseFrom = nil
+
+		if err := s.startPause(ctx, subscription); err != nil {
+			log.Printf("Error pausing subscription %s: %v", subscription.ID, err)
+		}
+	}
+
+	resuming, err := s.repository.GetSubscriptionsDueForResume(ctx, now)
+	if err != nil {
+		return fmt.Errorf("failed to fetch subscriptions due for resume: %w", err)
+	}
+
+	for _, subscription := range resuming {
+		unpausedDate := *subscription.ResumeOn
+		subscription.Status = model.Active
+		subscription.UnpausedDate = &unpausedDate
+		subscription.ResumeOn = nil
+
+		if err := s.endPause(ctx, subscription); err != nil {
+			log.Printf("Error resuming subscription %s: %v", subscription.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// checkPausePolicy fails with a model.PauseLimitError when a pause from
+// pauseFrom until resumeOn breaks the pause policy of the product. A pause
+// without a resume date is limited to the days the policy allows, the
+// returned resume date says when it ends.
+func (s *Service) checkPausePolicy(
+	ctx context.Context,
+	subscription model.Subscription,
+	pauseFrom time.Time,
+	resumeOn *time.Time,
+) (*time.Time, error) {
+	product, err := s.repository.GetProduct(ctx, subscription.ProductID.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch product: %w", err)
+	}
+	if !product.PausePolicy.Limited() {
+		return resumeOn, nil
+	}
+
+	pauses, err := s.repository.GetPauses(ctx, subscription.ID.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pauses: %w", err)
+	}
+
+	// the pause the subscription is in is the one being checked
+	var ended []model.Pause
+	for _, pause := range pauses {
+		if pause.EndDate != nil {
+			ended = append(ended, pause)
+		}
+	}
+
+	return applyPausePolicy(product.PausePolicy, subscription, ended, pauseFrom, resumeOn)
+}
+
+// applyPausePolicy checks a pause against the policy, given the pauses the
+// subscription has already ended. All days of the pause count towards the
+// year it starts in.
+func applyPausePolicy(
+	policy model.PausePolicy,
+	subscription model.Subscription,
+	ended []model.Pause,
+	pauseFrom time.Time,
+	resumeOn *time.Time,
+) (*time.Time, error) {
+	if policy.MinActiveDaysBetweenPauses > 0 && len(ended) > 0 {
+		activeDays := daysBetween(*ended[len(ended)-1].EndDate, pauseFrom)
+		if activeDays < policy.MinActiveDaysBetweenPauses {
+			return nil, &model.PauseLimitError{
+				Limit:   model.MinActiveDaysBetweenPausesLimit,
+				Allowed: policy.MinActiveDaysBetweenPauses,
+				Actual:  activeDays,
+			}
+		}
+	}
+
+	if policy.MaxPausesPerPeriod > 0 {
+		periodStart := subscription.StartDate
+		if !pauseFrom.Before(subscription.EndDate) {
+			periodStart = subscription.EndDate
+		}
+
+		pausesInPeriod := 0
+		for _, pause := range ended {
+			if !pause.StartDate.Before(periodStart) {
+				pausesInPeriod++
+			}
+		}
+		if pausesInPeriod >= policy.MaxPausesPerPeriod {
+			return nil, &model.PauseLimitError{
+				Limit:   model.MaxPausesPerPeriodLimit,
+				Allowed: policy.MaxPausesPerPeriod,
+				Actual:  pausesInPeriod,
+			}
+		}
+	}
+
+	allowedDays := policy.MaxPauseDays
+	usedDays := 0
+	if policy.MaxPausedDaysPerYear > 0 {
+		yearStart := time.Date(pauseFrom.Year(), time.January, 1, 0, 0, 0, 0, pauseFrom.Location())
+		yearEnd := yearStart.AddDate(1, 0, 0)
+		for _, pause := range ended {
+			usedDays += overlappingDays(pause.StartDate, *pause.EndDate, yearStart, yearEnd)
+		}
+
+		remainingDays := policy.MaxPausedDaysPerYear - usedDays
+		if remainingDays <= 0 {
+			return nil, &model.PauseLimitError{
+				Limit:   model.MaxPausedDaysPerYearLimit,
+				Allowed: policy.MaxPausedDaysPerYear,
+				Actual:  usedDays,
+			}
+		}
+		if allowedDays == 0 || remainingDays < allowedDays {
+			allowedDays = remainingDays
+		}
+	}
+
+	if resumeOn == nil {
+		if allowedDays == 0 {
+			return nil, nil
+		}
+		limitedResumeOn := pauseFrom.AddDate(0, 0, allowedDays)
+		return &limitedResumeOn, nil
+	}
+
+	pauseDays := daysBetween(pauseFrom, *resumeOn)
+	if policy.MaxPauseDays > 0 && pauseDays > policy.MaxPauseDays {
+		return nil, &model.PauseLimitError{
+			Limit:   model.MaxPauseDaysLimit,
+			Allowed: policy.MaxPauseDays,
+			Actual:  pauseDays,
+		}
+	}
+	if policy.MaxPausedDaysPerYear > 0 && usedDays+pauseDays > policy.MaxPausedDaysPerYear {
+		return nil, &model.PauseLimitError{
+			Limit:   model.MaxPausedDaysPerYearLimit,
+			Allowed: policy.MaxPausedDaysPerYear,
+			Actual:  usedDays + pauseDays,
+		}
+	}
+
+	return resumeOn, nil
+}
+
+// daysBetween counts the calendar days from one midnight to another, also
+// across daylight saving time changes.
+func daysBetween(from, to time.Time) int {
+	return int(math.Round(to.Sub(from).Hours() / 24))
+}
+
+func overlappingDays(start, end, rangeStart, rangeEnd time.Time) int {
+	if start.Before(rangeStart) {
+		start = rangeStart
+	}
+	if end.After(rangeEnd) {
+		end = rangeEnd
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return daysBetween(start, end)
+}
+
+// startPause stores the subscription as paused and records the pause.
+func (s *Service) startPause(ctx context.Context, subscription model.Subscription) error {
+	return s.withinTx(ctx, func(tx *Service) error {
+		if err := tx.updateSubscriptionWithEvent(ctx, model.SubscriptionPaused, subscription); err != nil {
+			return err
+		}
+		return tx.repository.SavePause(ctx, model.Pause{
+			ID:             uuid.New(),
+			SubscriptionID: subscription.ID,
+			StartDate:      *subscription.PausedDate,
+		})
+	})
+}
+
+// endPause stores the subscription as active again and ends its pause. The
+// paused days were not used, so the current period and the add-ons paid for
+// it are extended by them and the next renewal moves back accordingly.
+func (s *Service) endPause(ctx context.Context, subscription model.Subscription) error {
+	extended := extendByPausedDays(&subscription)
+
+	return s.withinTx(ctx, func(tx *Service) error {
+		if err := tx.updateSubscriptionWithEvent(ctx, model.SubscriptionUnpaused, subscription); err != nil {
+			return err
+		}
+		for _, addOn := range extended {
+			if err := tx.repository.UpdateSubscriptionAddOn(ctx, addOn); err != nil {
+				return fmt.Errorf("failed to extend add-on %s: %w", addOn.ID, err)
+			}
+		}
+		return tx.repository.EndPause(ctx, subscription.ID.String(), *subscription.UnpausedDate)
+	})
+}
+
+// extendByPausedDays pushes the end of the subscription's current period
+// back by the days it was paused, and with it the billing of the add-ons
+// paid until then. It returns the add-ons it extended.
+func extendByPausedDays(subscription *model.Subscription) []model.SubscriptionAddOn {
+	if subscription.PausedDate == nil || subscription.UnpausedDate == nil {
+		return nil
+	}
+	pausedDays := daysBetween(*subscription.PausedDate, *subscription.UnpausedDate)
+	if pausedDays <= 0 {
+		return nil
+	}
+
+	periodEnd := subscription.EndDate
+	subscription.EndDate = periodEnd.In(subscription.Location()).AddDate(0, 0, pausedDays)
+
+	var extended []model.SubscriptionAddOn
+	for i, addOn := range subscription.AddOns {
+		if addOn.Status != model.AddOnActive || addOn.BilledUntil == nil || addOn.BilledUntil.Before(periodEnd) {
+			continue
+		}
+		billedUntil := subscription.EndDate
+		addOn.BilledUntil = &billedUntil
+		subscription.AddOns[i] = addOn
+		extended = append(extended, addOn)
+	}
+
+	return extended
+}
@@ -0,0 +1,491 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gymondo/internal/clock"
+	"gymondo/internal/model"
+	"testing"
+	"time"
+)
+
+func expectPausePolicy(mockRepo *MockRepository, policy model.PausePolicy) {
+	mockRepo.EXPECT().GetProduct(gomock.Any(), gomock.Any()).Return(model.Product{PausePolicy: policy}, nil)
+}
+
+func Test_Service_PauseSubscription_Scheduled(t *testing.T) {
+	t.Parallel()
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+
+	now := time.Date(2024, time.June, 20, 10, 0, 0, 0, berlin)
+	day := func(month time.Month, day int) *time.Time {
+		date := time.Date(2024, month, day, 0, 0, 0, 0, time.UTC)
+		return &date
+	}
+
+	t.Run("pause starting later is scheduled", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now)}
+
+		subscription := model.Subscription{ID: uuid.New(), Status: model.Active, TimeZone: "Europe/Berlin"}
+
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+		expectPausePolicy(mockRepo, model.PausePolicy{})
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, scheduled model.Subscription) error {
+				assert.Equal(t, model.Active, scheduled.Status)
+				assert.Equal(t, time.Date(2024, time.July, 1, 0, 0, 0, 0, berlin), *scheduled.PauseFrom)
+				assert.Equal(t, time.Date(2024, time.July, 29, 0, 0, 0, 0, berlin), *scheduled.ResumeOn)
+				assert.Nil(t, scheduled.PausedDate)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, message model.OutboxMessage) error {
+				assert.Equal(t, model.SubscriptionPauseScheduled, message.Type)
+				return nil
+			},
+		)
+
+		schedule := model.PauseSchedule{PauseFrom: day(time.July, 1), ResumeOn: day(time.July, 29)}
+		err := service.PauseSubscription(context.Background(), subscription.ID.String(), 0, schedule)
+		assert.NoError(t, err)
+	})
+
+	t.Run("pause with resume date starts today", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now)}
+
+		pauseFrom := time.Date(2024, time.July, 1, 0, 0, 0, 0, berlin)
+		subscription := model.Subscription{ID: uuid.New(), Status: model.Active, TimeZone: "Europe/Berlin", PauseFrom: &pauseFrom}
+
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+		expectPausePolicy(mockRepo, model.PausePolicy{})
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, paused model.Subscription) error {
+				assert.Equal(t, model.Paused, paused.Status)
+				assert.Equal(t, time.Date(2024, time.June, 20, 0, 0, 0, 0, berlin), *paused.PausedDate)
+				assert.Equal(t, time.Date(2024, time.July, 18, 0, 0, 0, 0, berlin), *paused.ResumeOn)
+				assert.Nil(t, paused.PauseFrom)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).Return(nil)
+
+		schedule := model.PauseSchedule{ResumeOn: day(time.July, 18)}
+		mockRepo.EXPECT().SavePause(gomock.Any(), gomock.Any()).Return(nil)
+
+		err := service.PauseSubscription(context.Background(), subscription.ID.String(), 0, schedule)
+		assert.NoError(t, err)
+	})
+
+	t.Run("pause starting in the past", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now)}
+
+		subscription := model.Subscription{ID: uuid.New(), Status: model.Active, TimeZone: "Europe/Berlin"}
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+
+		schedule := model.PauseSchedule{PauseFrom: day(time.June, 19)}
+		err := service.PauseSubscription(context.Background(), subscription.ID.String(), 0, schedule)
+		assert.EqualError(t, err, "pause can't start in the past")
+	})
+
+	t.Run("pause ending before it starts", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now)}
+
+		subscription := model.Subscription{ID: uuid.New(), Status: model.Active, TimeZone: "Europe/Berlin"}
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+
+		schedule := model.PauseSchedule{PauseFrom: day(time.July, 1), ResumeOn: day(time.July, 1)}
+		err := service.PauseSubscription(context.Background(), subscription.ID.String(), 0, schedule)
+		assert.EqualError(t, err, "pause must end after it starts")
+	})
+
+	t.Run("scheduled pause overlapping the trial", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now)}
+
+		trialEndDate := time.Date(2024, time.July, 5, 0, 0, 0, 0, berlin)
+		subscription := model.Subscription{
+			ID:           uuid.New(),
+			Status:       model.Active,
+			TimeZone:     "Europe/Berlin",
+			TrialEndDate: &trialEndDate,
+		}
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+
+		schedule := model.PauseSchedule{PauseFrom: day(time.July, 1)}
+		err := service.PauseSubscription(context.Background(), subscription.ID.String(), 0, schedule)
+		assert.EqualError(t, err, "can't pause subscription during trial period")
+	})
+
+	t.Run("change resume date of a paused subscription", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now)}
+
+		pausedDate := time.Date(2024, time.June, 1, 0, 0, 0, 0, berlin)
+		subscription := model.Subscription{
+			ID:         uuid.New(),
+			Status:     model.Paused,
+			TimeZone:   "Europe/Berlin",
+			PausedDate: &pausedDate,
+		}
+
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+		expectPausePolicy(mockRepo, model.PausePolicy{})
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, paused model.Subscription) error {
+				assert.Equal(t, model.Paused, paused.Status)
+				assert.Equal(t, pausedDate, *paused.PausedDate)
+				assert.Equal(t, time.Date(2024, time.July, 1, 0, 0, 0, 0, berlin), *paused.ResumeOn)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).Return(nil)
+
+		schedule := model.PauseSchedule{ResumeOn: day(time.July, 1)}
+		err := service.PauseSubscription(context.Background(), subscription.ID.String(), 0, schedule)
+		assert.NoError(t, err)
+	})
+
+	t.Run("pause a paused subscription again", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now)}
+
+		subscription := model.Subscription{ID: uuid.New(), Status: model.Paused}
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+
+		schedule := model.PauseSchedule{PauseFrom: day(time.July, 1)}
+		err := service.PauseSubscription(context.Background(), subscription.ID.String(), 0, schedule)
+		assert.EqualError(t, err, "subscription is already paused")
+	})
+}
+
+func Test_Service_CancelScheduledPause(t *testing.T) {
+	t.Parallel()
+
+	t.Run("removes the scheduled pause", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo}
+
+		pauseFrom := time.Now().AddDate(0, 0, 7)
+		resumeOn := pauseFrom.AddDate(0, 0, 14)
+		subscription := model.Subscription{ID: uuid.New(), Status: model.Active, PauseFrom: &pauseFrom, ResumeOn: &resumeOn}
+
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, updated model.Subscription) error {
+				assert.Nil(t, updated.PauseFrom)
+				assert.Nil(t, updated.ResumeOn)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, message model.OutboxMessage) error {
+				assert.Equal(t, model.SubscriptionPauseScheduled, message.Type)
+				return nil
+			},
+		)
+
+		err := service.CancelScheduledPause(context.Background(), subscription.ID.String(), 0)
+		assert.NoError(t, err)
+	})
+
+	t.Run("no pause scheduled", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo}
+
+		subscription := model.Subscription{ID: uuid.New(), Status: model.Active}
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+
+		err := service.CancelScheduledPause(context.Background(), subscription.ID.String(), 0)
+		assert.EqualError(t, err, "subscription has no scheduled pause")
+	})
+}
+
+func Test_Service_ApplyScheduledPauses(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2024, time.July, 1, 6, 0, 0, 0, time.UTC)
+	mockRepo := NewMockRepository(ctrl)
+	service := &Service{repository: mockRepo, clock: clock.Fixed(now)}
+
+	today := model.StartOfDay(now, time.UTC)
+	resumeOn := today.AddDate(0, 0, 28)
+	pausing := model.Subscription{ID: uuid.New(), Status: model.Active, PauseFrom: &today, ResumeOn: &resumeOn}
+	pausedDate := today.AddDate(0, 0, -28)
+	resuming := model.Subscription{
+		ID:         uuid.New(),
+		Status:     model.Paused,
+		EndDate:    today.AddDate(0, 0, -10),
+		PausedDate: &pausedDate,
+		ResumeOn:   &today,
+	}
+
+	mockRepo.EXPECT().GetSubscriptionsDueForPause(gomock.Any(), now).Return([]model.Subscription{pausing}, nil)
+	mockRepo.EXPECT().GetSubscriptionsDueForResume(gomock.Any(), now).Return([]model.Subscription{resuming}, nil)
+	expectWithinTx(mockRepo)
+	gomock.InOrder(
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, paused model.Subscription) error {
+				assert.Equal(t, model.Paused, paused.Status)
+				assert.Equal(t, today, *paused.PausedDate)
+				assert.Equal(t, resumeOn, *paused.ResumeOn)
+				assert.Nil(t, paused.PauseFrom)
+				return nil
+			},
+		),
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, resumed model.Subscription) error {
+				assert.Equal(t, model.Active, resumed.Status)
+				assert.Equal(t, today, *resumed.UnpausedDate)
+				assert.Nil(t, resumed.ResumeOn)
+				assert.Equal(t, today.AddDate(0, 0, 18), resumed.EndDate)
+				return nil
+			},
+		),
+	)
+	mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	mockRepo.EXPECT().SavePause(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, pause model.Pause) error {
+			assert.Equal(t, pausing.ID, pause.SubscriptionID)
+			assert.Equal(t, today, pause.StartDate)
+			return nil
+		},
+	)
+	mockRepo.EXPECT().EndPause(gomock.Any(), resuming.ID.String(), today).Return(nil)
+
+	err := service.ApplyScheduledPauses(context.Background())
+	assert.NoError(t, err)
+}
+
+func Test_extendByPausedDays(t *testing.T) {
+	t.Parallel()
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+
+	periodEnd := time.Date(2024, time.April, 10, 0, 0, 0, 0, berlin)
+	pausedDate := time.Date(2024, time.March, 20, 0, 0, 0, 0, berlin)
+	unpausedDate := time.Date(2024, time.April, 19, 0, 0, 0, 0, berlin)
+	earlier := periodEnd.AddDate(0, 0, -30)
+
+	subscription := model.Subscription{
+		EndDate:      periodEnd,
+		TimeZone:     "Europe/Berlin",
+		PausedDate:   &pausedDate,
+		UnpausedDate: &unpausedDate,
+		AddOns: []model.SubscriptionAddOn{
+			{ID: uuid.New(), Status: model.AddOnActive, BilledUntil: &periodEnd},
+			{ID: uuid.New(), Status: model.AddOnActive, BilledUntil: &earlier},
+			{ID: uuid.New(), Status: model.AddOnActive},
+			{ID: uuid.New(), Status: model.AddOnCanceled, BilledUntil: &periodEnd},
+		},
+	}
+
+	extended := extendByPausedDays(&subscription)
+
+	// 30 paused days across the change to summer time
+	expectedEnd := time.Date(2024, time.May, 10, 0, 0, 0, 0, berlin)
+	assert.True(t, expectedEnd.Equal(subscription.EndDate))
+	assert.Len(t, extended, 1)
+	assert.Equal(t, subscription.AddOns[0].ID, extended[0].ID)
+	assert.True(t, expectedEnd.Equal(*extended[0].BilledUntil))
+	assert.Equal(t, &earlier, subscription.AddOns[1].BilledUntil)
+}
+
+func Test_Service_PauseSubscription_Limits(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.June, 20, 10, 0, 0, 0, time.UTC)
+	today := model.StartOfDay(now, time.UTC)
+	date := func(month time.Month, day int) *time.Time {
+		date := time.Date(2024, month, day, 0, 0, 0, 0, time.UTC)
+		return &date
+	}
+	ended := func(from, until *time.Time) model.Pause {
+		return model.Pause{ID: uuid.New(), StartDate: *from, EndDate: until}
+	}
+	subscription := model.Subscription{
+		Status:    model.Active,
+		StartDate: *date(time.June, 1),
+		EndDate:   *date(time.July, 1),
+	}
+
+	tests := []struct {
+		name     string
+		policy   model.PausePolicy
+		pauses   []model.Pause
+		schedule model.PauseSchedule
+		limit    model.PauseLimit
+		allowed  int
+		actual   int
+	}{
+		{
+			name:     "pause too long",
+			policy:   model.PausePolicy{MaxPauseDays: 30},
+			schedule: model.PauseSchedule{ResumeOn: date(time.July, 31)},
+			limit:    model.MaxPauseDaysLimit,
+			allowed:  30,
+			actual:   41,
+		},
+		{
+			name:     "too many pauses this period",
+			policy:   model.PausePolicy{MaxPausesPerPeriod: 1},
+			pauses:   []model.Pause{ended(date(time.June, 2), date(time.June, 5))},
+			schedule: model.PauseSchedule{ResumeOn: date(time.June, 25)},
+			limit:    model.MaxPausesPerPeriodLimit,
+			allowed:  1,
+			actual:   1,
+		},
+		{
+			name:     "too many paused days this year",
+			policy:   model.PausePolicy{MaxPausedDaysPerYear: 30},
+			pauses:   []model.Pause{ended(date(time.February, 1), date(time.February, 21))},
+			schedule: model.PauseSchedule{ResumeOn: date(time.July, 5)},
+			limit:    model.MaxPausedDaysPerYearLimit,
+			allowed:  30,
+			actual:   35,
+		},
+		{
+			name:     "paused days of the year used up",
+			policy:   model.PausePolicy{MaxPausedDaysPerYear: 20},
+			pauses:   []model.Pause{ended(date(time.February, 1), date(time.February, 21))},
+			schedule: model.PauseSchedule{},
+			limit:    model.MaxPausedDaysPerYearLimit,
+			allowed:  20,
+			actual:   20,
+		},
+		{
+			name:     "too soon after the last pause",
+			policy:   model.PausePolicy{MinActiveDaysBetweenPauses: 30},
+			pauses:   []model.Pause{ended(date(time.May, 1), date(time.June, 10))},
+			schedule: model.PauseSchedule{},
+			limit:    model.MinActiveDaysBetweenPausesLimit,
+			allowed:  30,
+			actual:   10,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := NewMockRepository(ctrl)
+			service := &Service{repository: mockRepo, clock: clock.Fixed(now)}
+
+			subscription := subscription
+			subscription.ID = uuid.New()
+
+			mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+			expectPausePolicy(mockRepo, test.policy)
+			mockRepo.EXPECT().GetPauses(gomock.Any(), subscription.ID.String()).Return(test.pauses, nil)
+
+			err := service.PauseSubscription(context.Background(), subscription.ID.String(), 0, test.schedule)
+
+			var limitErr *model.PauseLimitError
+			if assert.ErrorAs(t, err, &limitErr) {
+				assert.Equal(t, test.limit, limitErr.Limit)
+				assert.Equal(t, test.allowed, limitErr.Allowed)
+				assert.Equal(t, test.actual, limitErr.Actual)
+			}
+		})
+	}
+
+	t.Run("pause without resume date ends with the allowed days", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now)}
+
+		subscription := subscription
+		subscription.ID = uuid.New()
+		policy := model.PausePolicy{MaxPauseDays: 30, MaxPausedDaysPerYear: 40}
+		pauses := []model.Pause{
+			ended(date(time.January, 2), date(time.January, 22)),
+			// the pause the subscription is in doesn't count
+			{ID: uuid.New(), StartDate: today},
+		}
+
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+		expectPausePolicy(mockRepo, policy)
+		mockRepo.EXPECT().GetPauses(gomock.Any(), subscription.ID.String()).Return(pauses, nil)
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, paused model.Subscription) error {
+				assert.Equal(t, model.Paused, paused.Status)
+				assert.Equal(t, today.AddDate(0, 0, 20), *paused.ResumeOn)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().SavePause(gomock.Any(), gomock.Any()).Return(nil)
+
+		err := service.PauseSubscription(context.Background(), subscription.ID.String(), 0, model.PauseSchedule{})
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,342 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"gymondo/internal/model"
+)
+
+// MigratePrice moves the subscriptions to the product that have not ended to
+// a new price. Their subscribers are told about it and pay it from their
+// first renewal after the notice period, unless they decline it. The product
+// keeps its price until the notice period has passed, so no subscription,
+// whether new, reactivated or redeemed from a gift, pays the new price
+// before then; ApplyPriceMigrations moves it afterwards. A dry run only
+// reports which subscriptions would change and how that changes revenue.
+func (s *Service) MigratePrice(
+	ctx context.Context,
+	productID string,
+	price float64,
+	tax float64,
+	dryRun bool,
+) (model.PriceMigration, error) {
+	if price <= 0 || tax < 0 {
+		return model.PriceMigration{}, fmt.Errorf("price must be positive and tax can't be negative")
+	}
+
+	product, err := s.repository.GetProduct(ctx, productID)
+	if err != nil {
+		return model.PriceMigration{}, fmt.Errorf("failed to fetch product: %w", err)
+	}
+	if product.Kind == model.AddOnProduct || product.SeatBased {
+		return model.PriceMigration{}, fmt.Errorf("price of product %s can't be migrated", product.Name)
+	}
+
+	// two migrations of one product would compete for the same renewals
+	pending, err := s.repository.CountPendingPriceChanges(ctx, productID)
+	if err != nil {
+		return model.PriceMigration{}, fmt.Errorf("failed to count pending price changes: %w", err)
+	}
+	if pending > 0 {
+		return model.PriceMigration{}, fmt.Errorf("%d price changes of product %s are pending: %w",
+			pending, product.Name, model.ErrPriceMigrationInProgress)
+	}
+
+	subscriptions, err := s.repository.GetProductSubscriptions(ctx, productID)
+	if err != nil {
+		return model.PriceMigration{}, fmt.Errorf("failed to fetch subscriptions of product %s: %w", productID, err)
+	}
+
+	migration := model.PriceMigration{
+		ID:         uuid.New(),
+		ProductID:  product.ID,
+		Price:      math.Round(price*100) / 100,
+		Tax:        math.Round(tax*100) / 100,
+		TotalPrice: math.Round((price+tax)*100) / 100,
+		NoticeDays: s.config.PriceChangeNoticeDays,
+		CreatedAt:  s.now(),
+		DryRun:     dryRun,
+	}
+
+	changes := s.priceChanges(migration, subscriptions)
+	migration.PriceMigrationReport = priceMigrationReport(changes)
+
+	if dryRun {
+		return migration, nil
+	}
+
+	err = s.withinTx(ctx, func(tx *Service) error {
+		if err := tx.repository.SavePriceMigration(ctx, migration); err != nil {
+			return fmt.Errorf("failed to save price migration: %w", err)
+		}
+		return tx.savePriceChanges(ctx, changes)
+	})
+	if err != nil {
+		return model.PriceMigration{}, err
+	}
+
+	for _, change := range changes {
+		s.notifyPriceChange(ctx, product.Name, change)
+	}
+
+	return migration, nil
+}
+
+// ApplyPriceMigrations moves the products of the price migrations whose
+// notice period has passed to their new price. Subscriptions that started at
+// the old price during the notice period get the new price from their first
+// renewal after a notice period of their own. A migration that can't be
+// applied now is picked up again on the next run.
+func (s *Service) ApplyPriceMigrations(ctx context.Context) error {
+	migrations, err := s.repository.GetPriceMigrationsDue(ctx, s.now())
+	if err != nil {
+		return fmt.Errorf("failed to fetch price migrations due: %w", err)
+	}
+
+	for _, migration := range migrations {
+		if err := s.applyPriceMigration(ctx, migration); err != nil {
+			log.Printf("Error applying price migration %s: %v", migration.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) applyPriceMigration(ctx context.Context, migration model.PriceMigration) error {
+	product, err := s.repository.GetProduct(ctx, migration.ProductID.String())
+	if err != nil {
+		return fmt.Errorf("failed to fetch product: %w", err)
+	}
+
+	subscriptions, err := s.repository.GetProductSubscriptions(ctx, migration.ProductID.String())
+	if err != nil {
+		return fmt.Errorf("failed to fetch subscriptions of product %s: %w", migration.ProductID, err)
+	}
+
+	scheduled, err := s.repository.GetPriceChanges(ctx, migration.ID.String())
+	if err != nil {
+		return fmt.Errorf("failed to fetch price changes: %w", err)
+	}
+	noticed := make(map[uuid.UUID]bool, len(scheduled))
+	for _, change := range scheduled {
+		noticed[change.SubscriptionID] = true
+	}
+
+	var unnoticed []model.Subscription
+	for _, subscription := range subscriptions {
+		if !noticed[subscription.ID] {
+			unnoticed = append(unnoticed, subscription)
+		}
+	}
+	changes := s.priceChanges(migration, unnoticed)
+
+	err = s.withinTx(ctx, func(tx *Service) error {
+		if err := tx.savePriceChanges(ctx, changes); err != nil {
+			return err
+		}
+		productID := migration.ProductID.String()
+		err := tx.repository.UpdateProductPrice(ctx, productID, migration.Price, migration.Tax, migration.TotalPrice)
+		if err != nil {
+			return fmt.Errorf("failed to update product price: %w", err)
+		}
+		return tx.repository.MarkPriceMigrationApplied(ctx, migration.ID.String(), tx.now())
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		s.notifyPriceChange(ctx, product.Name, change)
+	}
+
+	return nil
+}
+
+// priceChanges schedules the price of the migration for the subscriptions
+// that don't pay it yet, from their first renewal after the notice period.
+func (s *Service) priceChanges(migration model.PriceMigration, subscriptions []model.Subscription) []model.PriceChange {
+	var changes []model.PriceChange
+	for _, subscription := range subscriptions {
+		if subscription.Price == migration.Price && subscription.Tax == migration.Tax {
+			continue
+		}
+
+		noticeEnd := s.today(subscription.Location()).AddDate(0, 0, migration.NoticeDays)
+		changes = append(changes, model.PriceChange{
+			ID:             uuid.New(),
+			MigrationID:    migration.ID,
+			SubscriptionID: subscription.ID,
+			UserID:         subscription.UserID,
+			DurationDays:   subscription.DurationDays,
+			OldPrice:       subscription.Price,
+			OldTax:         subscription.Tax,
+			OldTotalPrice:  subscription.TotalPrice,
+			Price:          migration.Price,
+			Tax:            migration.Tax,
+			TotalPrice:     migration.TotalPrice,
+			EffectiveDate:  firstRenewalFrom(subscription, noticeEnd),
+			Status:         model.PriceChangeScheduled,
+		})
+	}
+	return changes
+}
+
+func (s *Service) savePriceChanges(ctx context.Context, changes []model.PriceChange) error {
+	for _, change := range changes {
+		if err := s.repository.SavePriceChange(ctx, change); err != nil {
+			return fmt.Errorf("failed to save price change: %w", err)
+		}
+	}
+	return nil
+}
+
+// firstRenewalFrom returns the first renewal of the subscription on or after
+// the given day.
+func firstRenewalFrom(subscription model.Subscription, date time.Time) time.Time {
+	renewal := subscription.EndDate.In(subscription.Location())
+	for subscription.DurationDays > 0 && renewal.Before(date) {
+		renewal = renewal.AddDate(0, 0, subscription.DurationDays)
+	}
+	return renewal
+}
+
+// priceMigrationReport counts the price changes by status and sums up the
+// net revenue per year of their subscriptions before and after them.
+func priceMigrationReport(changes []model.PriceChange) model.PriceMigrationReport {
+	var report model.PriceMigrationReport
+	for _, change := range changes {
+		periodsPerYear := 0.0
+		if change.DurationDays > 0 {
+			periodsPerYear = 365 / float64(change.DurationDays)
+		}
+
+		report.Subscriptions++
+		report.CurrentAnnualRevenue += change.OldPrice * periodsPerYear
+		switch change.Status {
+		case model.PriceChangeScheduled:
+			report.Scheduled++
+			report.NewAnnualRevenue += change.Price * periodsPerYear
+		case model.PriceChangeApplied:
+			report.Applied++
+			report.NewAnnualRevenue += change.Price * periodsPerYear
+		case model.PriceChangeOptedOut:
+			report.OptedOut++
+		}
+	}
+
+	report.CurrentAnnualRevenue = math.Round(report.CurrentAnnualRevenue*100) / 100
+	report.NewAnnualRevenue = math.Round(report.NewAnnualRevenue*100) / 100
+	report.AnnualRevenueImpact = math.Round((report.NewAnnualRevenue-report.CurrentAnnualRevenue)*100) / 100
+
+	return report
+}
+
+// FindPriceMigration returns the price migration with its price changes
+// counted by status, so opt-outs can be followed as they come in.
+func (s *Service) FindPriceMigration(ctx context.Context, migrationID string) (model.PriceMigration, error) {
+	migration, err := s.repository.GetPriceMigration(ctx, migrationID)
+	if err != nil {
+		return model.PriceMigration{}, fmt.Errorf("failed to fetch price migration: %w", err)
+	}
+
+	changes, err := s.repository.GetPriceChanges(ctx, migrationID)
+	if err != nil {
+		return model.PriceMigration{}, fmt.Errorf("failed to fetch price changes: %w", err)
+	}
+	migration.PriceMigrationReport = priceMigrationReport(changes)
+
+	return migration, nil
+}
+
+// DeclinePriceChange records that the subscriber doesn't accept the new
+// price scheduled for their subscription. The subscription then ends at the
+// renewal the new price would have applied to.
+func (s *Service) DeclinePriceChange(ctx context.Context, subscriptionID string, version int) (model.PriceChange, error) {
+	subscription, err := s.repository.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		return model.PriceChange{}, fmt.Errorf("failed to find subscription with ID %s: %w", subscriptionID, err)
+	}
+	if err := checkVersion(subscription, version); err != nil {
+		return model.PriceChange{}, err
+	}
+	if subscription.Status == model.Canceled {
+		return model.PriceChange{}, fmt.Errorf("subscription is canceled")
+	}
+
+	change, found, err := s.repository.GetPendingPriceChange(ctx, subscriptionID)
+	if err != nil {
+		return model.PriceChange{}, fmt.Errorf("failed to fetch price change: %w", err)
+	}
+	if !found || change.Status != model.PriceChangeScheduled {
+		return model.PriceChange{}, fmt.Errorf("subscription has no scheduled price change")
+	}
+
+	now := s.now()
+	change.Status = model.PriceChangeOptedOut
+	change.OptedOutAt = &now
+	if err := s.repository.UpdatePriceChange(ctx, change); err != nil {
+		return model.PriceChange{}, fmt.Errorf("failed to decline price change: %w", err)
+	}
+
+	return change, nil
+}
+
+// duePriceChange returns the price change that the renewal of the
+// subscription at the end of its current period is due for, if any.
+func (s *Service) duePriceChange(ctx context.Context, subscription model.Subscription) (model.PriceChange, bool, error) {
+	change, found, err := s.repository.GetPendingPriceChange(ctx, subscription.ID.String())
+	if err != nil {
+		return model.PriceChange{}, false, fmt.Errorf("failed to fetch price change: %w", err)
+	}
+	if !found || subscription.EndDate.Before(change.EffectiveDate) {
+		return model.PriceChange{}, false, nil
+	}
+
+	return change, true, nil
+}
+
+// endDeclinedSubscription cancels a subscription whose subscriber declined
+// its new price at the end of the last period paid at the old price. Nothing
+// is refunded, that period is over.
+func (s *Service) endDeclinedSubscription(ctx context.Context, subscription model.Subscription) error {
+	canceledDate := subscription.EndDate.In(subscription.Location())
+	subscription.Status = model.Canceled
+	subscription.CanceledDate = &canceledDate
+	subscription.PauseFrom = nil
+	subscription.ResumeOn = nil
+
+	if err := s.updateSubscriptionWithEvent(ctx, model.SubscriptionCanceled, subscription); err != nil {
+		return fmt.Errorf("failed to cancel subscription: %w", err)
+	}
+
+	s.notifySubscription(ctx, model.NotificationCancellationConfirmation, subscription)
+
+	return nil
+}
+
+// notifyPriceChange tells the subscriber about the new price of their
+// subscription. A notification that can't be sent is logged.
+func (s *Service) notifyPriceChange(ctx context.Context, productName string, change model.PriceChange) {
+	user, err := s.repository.GetUser(ctx, change.UserID.String())
+	if err != nil {
+		log.Printf("Error sending %s notification for subscription %s: %v",
+			model.NotificationPriceChange, change.SubscriptionID, err)
+		return
+	}
+
+	notification := model.Notification{
+		Kind:        model.NotificationPriceChange,
+		User:        user,
+		ProductName: productName,
+		PriceChange: change,
+	}
+	if err := s.notifier.Notify(ctx, notification); err != nil {
+		log.Printf("Error sending %s notification for subscription %s: %v",
+			model.NotificationPriceChange, change.SubscriptionID, err)
+	}
+}
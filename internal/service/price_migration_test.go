@@ -0,0 +1,544 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gymondo/internal/clock"
+	"gymondo/internal/model"
+	"testing"
+	"time"
+)
+
+func Test_Service_MigratePrice(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.January, 10, 6, 0, 0, 0, time.UTC)
+	productID := uuid.New()
+	product := model.Product{ID: productID, Name: "basic plan", DurationDays: 30, Price: 10, Tax: 1, TotalPrice: 11}
+
+	newSubscriptions := func() []model.Subscription {
+		return []model.Subscription{
+			{
+				ID:           uuid.New(),
+				UserID:       uuid.New(),
+				ProductID:    productID,
+				EndDate:      time.Date(2025, time.January, 20, 0, 0, 0, 0, time.UTC),
+				DurationDays: 30,
+				Price:        10,
+				Tax:          1,
+				TotalPrice:   11,
+				Status:       model.Active,
+				TimeZone:     "UTC",
+			},
+			{
+				ID:           uuid.New(),
+				UserID:       uuid.New(),
+				ProductID:    productID,
+				EndDate:      time.Date(2025, time.February, 12, 0, 0, 0, 0, time.UTC),
+				DurationDays: 30,
+				Price:        9,
+				Tax:          0.9,
+				TotalPrice:   9.9,
+				Status:       model.Active,
+				TimeZone:     "UTC",
+			},
+			{
+				ID:           uuid.New(),
+				UserID:       uuid.New(),
+				ProductID:    productID,
+				EndDate:      time.Date(2025, time.January, 15, 0, 0, 0, 0, time.UTC),
+				DurationDays: 30,
+				Price:        12,
+				Tax:          2,
+				TotalPrice:   14,
+				Status:       model.Active,
+				TimeZone:     "UTC",
+			},
+		}
+	}
+
+	t.Run("invalid price", func(t *testing.T) {
+		t.Parallel()
+
+		service := &Service{clock: clock.Fixed(now), config: DefaultConfig()}
+
+		_, err := service.MigratePrice(context.Background(), productID.String(), 0, 1, false)
+		assert.ErrorContains(t, err, "price must be positive")
+	})
+
+	t.Run("earlier migration still pending", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		mockRepo.EXPECT().GetProduct(gomock.Any(), productID.String()).Return(product, nil)
+		mockRepo.EXPECT().CountPendingPriceChanges(gomock.Any(), productID.String()).Return(3, nil)
+
+		_, err := service.MigratePrice(context.Background(), productID.String(), 12, 2, false)
+		assert.ErrorIs(t, err, model.ErrPriceMigrationInProgress)
+	})
+
+	t.Run("add-on prices can't be migrated", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		addOn := product
+		addOn.Kind = model.AddOnProduct
+		mockRepo.EXPECT().GetProduct(gomock.Any(), productID.String()).Return(addOn, nil)
+
+		_, err := service.MigratePrice(context.Background(), productID.String(), 12, 2, false)
+		assert.ErrorContains(t, err, "can't be migrated")
+	})
+
+	t.Run("dry run only reports", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		mockRepo.EXPECT().GetProduct(gomock.Any(), productID.String()).Return(product, nil)
+		mockRepo.EXPECT().CountPendingPriceChanges(gomock.Any(), productID.String()).Return(0, nil)
+		mockRepo.EXPECT().GetProductSubscriptions(gomock.Any(), productID.String()).Return(newSubscriptions(), nil)
+
+		migration, err := service.MigratePrice(context.Background(), productID.String(), 12, 2, true)
+		assert.NoError(t, err)
+		assert.True(t, migration.DryRun)
+		assert.Equal(t, 14.0, migration.TotalPrice)
+		assert.Equal(t, 30, migration.NoticeDays)
+		assert.Equal(t, 2, migration.Subscriptions)
+		assert.Equal(t, 2, migration.Scheduled)
+		assert.Equal(t, 231.17, migration.CurrentAnnualRevenue)
+		assert.Equal(t, 292.0, migration.NewAnnualRevenue)
+		assert.Equal(t, 60.83, migration.AnnualRevenueImpact)
+	})
+
+	t.Run("new price is scheduled after the notice period and the product keeps its price", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockNotifier := NewMockNotifier(ctrl)
+		service := &Service{repository: mockRepo, notifier: mockNotifier, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		subscriptions := newSubscriptions()
+		effectiveDates := map[uuid.UUID]time.Time{
+			subscriptions[0].ID: time.Date(2025, time.February, 19, 0, 0, 0, 0, time.UTC),
+			subscriptions[1].ID: time.Date(2025, time.February, 12, 0, 0, 0, 0, time.UTC),
+		}
+
+		mockRepo.EXPECT().GetProduct(gomock.Any(), productID.String()).Return(product, nil)
+		mockRepo.EXPECT().CountPendingPriceChanges(gomock.Any(), productID.String()).Return(0, nil)
+		mockRepo.EXPECT().GetProductSubscriptions(gomock.Any(), productID.String()).Return(subscriptions, nil)
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().SavePriceMigration(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, migration model.PriceMigration) error {
+				assert.False(t, migration.DryRun)
+				assert.Equal(t, 12.0, migration.Price)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SavePriceChange(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, change model.PriceChange) error {
+				assert.Equal(t, model.PriceChangeScheduled, change.Status)
+				assert.Equal(t, effectiveDates[change.SubscriptionID], change.EffectiveDate)
+				assert.Equal(t, 14.0, change.TotalPrice)
+				return nil
+			},
+		).Times(2)
+		mockRepo.EXPECT().GetUser(gomock.Any(), gomock.Any()).Return(model.User{}, nil).Times(2)
+		mockNotifier.EXPECT().Notify(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, notification model.Notification) error {
+				assert.Equal(t, model.NotificationPriceChange, notification.Kind)
+				assert.Equal(t, "basic plan", notification.ProductName)
+				assert.Equal(t, effectiveDates[notification.PriceChange.SubscriptionID], notification.PriceChange.EffectiveDate)
+				return nil
+			},
+		).Times(2)
+
+		migration, err := service.MigratePrice(context.Background(), productID.String(), 12, 2, false)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, migration.Scheduled)
+	})
+
+	t.Run("failed to save price change", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		mockRepo.EXPECT().GetProduct(gomock.Any(), productID.String()).Return(product, nil)
+		mockRepo.EXPECT().CountPendingPriceChanges(gomock.Any(), productID.String()).Return(0, nil)
+		mockRepo.EXPECT().GetProductSubscriptions(gomock.Any(), productID.String()).Return(newSubscriptions(), nil)
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().SavePriceMigration(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().SavePriceChange(gomock.Any(), gomock.Any()).Return(fmt.Errorf("database error"))
+
+		_, err := service.MigratePrice(context.Background(), productID.String(), 12, 2, false)
+		assert.ErrorContains(t, err, "failed to save price change")
+	})
+}
+
+func Test_Service_ApplyPriceMigrations(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, time.February, 10, 6, 0, 0, 0, time.UTC)
+	mockRepo := NewMockRepository(ctrl)
+	mockNotifier := NewMockNotifier(ctrl)
+	service := &Service{repository: mockRepo, notifier: mockNotifier, clock: clock.Fixed(now), config: DefaultConfig()}
+
+	productID := uuid.New()
+	migration := model.PriceMigration{
+		ID:         uuid.New(),
+		ProductID:  productID,
+		Price:      12,
+		Tax:        2,
+		TotalPrice: 14,
+		NoticeDays: 30,
+		CreatedAt:  now.AddDate(0, 0, -31),
+	}
+	newSubscription := func(price, tax float64) model.Subscription {
+		return model.Subscription{
+			ID:           uuid.New(),
+			UserID:       uuid.New(),
+			ProductID:    productID,
+			EndDate:      time.Date(2025, time.February, 20, 0, 0, 0, 0, time.UTC),
+			DurationDays: 30,
+			Price:        price,
+			Tax:          tax,
+			TotalPrice:   price + tax,
+			Status:       model.Active,
+			TimeZone:     "UTC",
+		}
+	}
+	noticed := newSubscription(10, 1)
+	joinedDuringNotice := newSubscription(10, 1)
+	joinedAfterwards := newSubscription(12, 2)
+
+	mockRepo.EXPECT().GetPriceMigrationsDue(gomock.Any(), now).Return([]model.PriceMigration{migration}, nil)
+	mockRepo.EXPECT().GetProduct(gomock.Any(), productID.String()).Return(model.Product{ID: productID, Name: "basic plan"}, nil)
+	mockRepo.EXPECT().GetProductSubscriptions(gomock.Any(), productID.String()).Return(
+		[]model.Subscription{noticed, joinedDuringNotice, joinedAfterwards}, nil)
+	mockRepo.EXPECT().GetPriceChanges(gomock.Any(), migration.ID.String()).Return(
+		[]model.PriceChange{{SubscriptionID: noticed.ID, Status: model.PriceChangeScheduled}}, nil)
+	expectWithinTx(mockRepo)
+	mockRepo.EXPECT().SavePriceChange(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, change model.PriceChange) error {
+			assert.Equal(t, joinedDuringNotice.ID, change.SubscriptionID)
+			assert.Equal(t, migration.ID, change.MigrationID)
+			// the first renewal at least 30 days from now
+			assert.Equal(t, time.Date(2025, time.March, 22, 0, 0, 0, 0, time.UTC), change.EffectiveDate)
+			return nil
+		},
+	)
+	mockRepo.EXPECT().UpdateProductPrice(gomock.Any(), productID.String(), 12.0, 2.0, 14.0).Return(nil)
+	mockRepo.EXPECT().MarkPriceMigrationApplied(gomock.Any(), migration.ID.String(), now).Return(nil)
+	mockRepo.EXPECT().GetUser(gomock.Any(), joinedDuringNotice.UserID.String()).Return(model.User{ID: joinedDuringNotice.UserID}, nil)
+	mockNotifier.EXPECT().Notify(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, notification model.Notification) error {
+			assert.Equal(t, model.NotificationPriceChange, notification.Kind)
+			assert.Equal(t, joinedDuringNotice.ID, notification.PriceChange.SubscriptionID)
+			return nil
+		},
+	)
+
+	err := service.ApplyPriceMigrations(context.Background())
+	assert.NoError(t, err)
+}
+
+func Test_Service_FindPriceMigration(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	service := &Service{repository: mockRepo}
+
+	migrationID := uuid.New()
+	changes := []model.PriceChange{
+		{DurationDays: 30, OldPrice: 10, Price: 12, Status: model.PriceChangeApplied},
+		{DurationDays: 30, OldPrice: 10, Price: 12, Status: model.PriceChangeScheduled},
+		{DurationDays: 365, OldPrice: 100, Price: 12, Status: model.PriceChangeOptedOut},
+	}
+
+	mockRepo.EXPECT().GetPriceMigration(gomock.Any(), migrationID.String()).Return(model.PriceMigration{ID: migrationID}, nil)
+	mockRepo.EXPECT().GetPriceChanges(gomock.Any(), migrationID.String()).Return(changes, nil)
+
+	migration, err := service.FindPriceMigration(context.Background(), migrationID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, migration.Subscriptions)
+	assert.Equal(t, 1, migration.Applied)
+	assert.Equal(t, 1, migration.Scheduled)
+	assert.Equal(t, 1, migration.OptedOut)
+	assert.Equal(t, 343.33, migration.CurrentAnnualRevenue)
+	assert.Equal(t, 292.0, migration.NewAnnualRevenue)
+	assert.Equal(t, -51.33, migration.AnnualRevenueImpact)
+}
+
+func Test_Service_DeclinePriceChange(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.January, 10, 6, 0, 0, 0, time.UTC)
+
+	newSubscription := func() model.Subscription {
+		return model.Subscription{
+			ID:      uuid.New(),
+			UserID:  uuid.New(),
+			Status:  model.Active,
+			Version: 3,
+		}
+	}
+
+	t.Run("price change is declined", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now)}
+
+		subscription := newSubscription()
+		change := model.PriceChange{ID: uuid.New(), SubscriptionID: subscription.ID, Status: model.PriceChangeScheduled}
+
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+		mockRepo.EXPECT().GetPendingPriceChange(gomock.Any(), subscription.ID.String()).Return(change, true, nil)
+		mockRepo.EXPECT().UpdatePriceChange(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, declined model.PriceChange) error {
+				assert.Equal(t, model.PriceChangeOptedOut, declined.Status)
+				assert.Equal(t, now, *declined.OptedOutAt)
+				return nil
+			},
+		)
+
+		declined, err := service.DeclinePriceChange(context.Background(), subscription.ID.String(), 3)
+		assert.NoError(t, err)
+		assert.Equal(t, model.PriceChangeOptedOut, declined.Status)
+	})
+
+	t.Run("version conflict", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now)}
+
+		subscription := newSubscription()
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+
+		_, err := service.DeclinePriceChange(context.Background(), subscription.ID.String(), 2)
+		assert.ErrorIs(t, err, model.ErrSubscriptionConflict)
+	})
+
+	t.Run("already declined", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now)}
+
+		subscription := newSubscription()
+		change := model.PriceChange{ID: uuid.New(), SubscriptionID: subscription.ID, Status: model.PriceChangeOptedOut}
+
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+		mockRepo.EXPECT().GetPendingPriceChange(gomock.Any(), subscription.ID.String()).Return(change, true, nil)
+
+		_, err := service.DeclinePriceChange(context.Background(), subscription.ID.String(), 0)
+		assert.ErrorContains(t, err, "subscription has no scheduled price change")
+	})
+}
+
+func Test_Service_RenewSubscriptions_WithPriceChange(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.February, 19, 6, 0, 0, 0, time.UTC)
+	endDate := time.Date(2025, time.February, 19, 0, 0, 0, 0, time.UTC)
+
+	newSubscription := func() model.Subscription {
+		return model.Subscription{
+			ID:           uuid.New(),
+			UserID:       uuid.New(),
+			ProductID:    uuid.New(),
+			StartDate:    endDate.AddDate(0, 0, -30),
+			EndDate:      endDate,
+			DurationDays: 30,
+			Price:        10,
+			Tax:          1,
+			TotalPrice:   11,
+			Status:       model.Active,
+			TimeZone:     "UTC",
+		}
+	}
+
+	t.Run("new price is charged from its renewal", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockPayments := NewMockPaymentGateway(ctrl)
+		service := &Service{repository: mockRepo, payments: mockPayments, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		subscription := newSubscription()
+		change := model.PriceChange{
+			ID:             uuid.New(),
+			SubscriptionID: subscription.ID,
+			Price:          12,
+			Tax:            2,
+			TotalPrice:     14,
+			EffectiveDate:  endDate,
+			Status:         model.PriceChangeScheduled,
+		}
+
+		mockRepo.EXPECT().GetSubscriptionsDueForRenewal(gomock.Any(), now).Return([]model.Subscription{subscription}, nil)
+		mockRepo.EXPECT().GetPendingPriceChange(gomock.Any(), subscription.ID.String()).Return(change, true, nil)
+		mockRepo.EXPECT().GetCreditBalance(gomock.Any(), subscription.UserID.String()).Return(0.0, nil)
+		mockPayments.EXPECT().Charge(gomock.Any(), subscription.UserID, 14.0, gomock.Any()).Return("tx-1", nil)
+		expectWithinTx(mockRepo)
+		expectLockSubscription(mockRepo, subscription)
+		mockRepo.EXPECT().UpdatePriceChange(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, applied model.PriceChange) error {
+				assert.Equal(t, model.PriceChangeApplied, applied.Status)
+				assert.Equal(t, now, *applied.AppliedAt)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SavePaymentAttempt(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, renewed model.Subscription) error {
+				assert.Equal(t, 12.0, renewed.Price)
+				assert.Equal(t, 2.0, renewed.Tax)
+				assert.Equal(t, 14.0, renewed.TotalPrice)
+				assert.Equal(t, endDate.AddDate(0, 0, 30), renewed.EndDate)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().GetUser(gomock.Any(), subscription.UserID.String()).Return(model.User{ID: subscription.UserID}, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), subscription.ProductID.String()).Return(model.Product{ID: subscription.ProductID}, nil)
+		mockRepo.EXPECT().SaveInvoice(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, invoice model.Invoice) (model.Invoice, error) {
+				assert.Equal(t, 14.0, invoice.TotalAmount)
+				return invoice, nil
+			},
+		)
+
+		err := service.RenewSubscriptions(context.Background())
+		assert.NoError(t, err)
+	})
+
+	t.Run("old price is kept before the effective date", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockPayments := NewMockPaymentGateway(ctrl)
+		service := &Service{repository: mockRepo, payments: mockPayments, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		subscription := newSubscription()
+		change := model.PriceChange{
+			ID:             uuid.New(),
+			SubscriptionID: subscription.ID,
+			TotalPrice:     14,
+			EffectiveDate:  endDate.AddDate(0, 0, 30),
+			Status:         model.PriceChangeScheduled,
+		}
+
+		mockRepo.EXPECT().GetSubscriptionsDueForRenewal(gomock.Any(), now).Return([]model.Subscription{subscription}, nil)
+		mockRepo.EXPECT().GetPendingPriceChange(gomock.Any(), subscription.ID.String()).Return(change, true, nil)
+		mockRepo.EXPECT().GetCreditBalance(gomock.Any(), subscription.UserID.String()).Return(0.0, nil)
+		mockPayments.EXPECT().Charge(gomock.Any(), subscription.UserID, 11.0, gomock.Any()).Return("tx-1", nil)
+		expectWithinTx(mockRepo)
+		expectLockSubscription(mockRepo, subscription)
+		mockRepo.EXPECT().SavePaymentAttempt(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().GetUser(gomock.Any(), subscription.UserID.String()).Return(model.User{ID: subscription.UserID}, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), subscription.ProductID.String()).Return(model.Product{ID: subscription.ProductID}, nil)
+		mockRepo.EXPECT().SaveInvoice(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, invoice model.Invoice) (model.Invoice, error) {
+				return invoice, nil
+			},
+		)
+
+		err := service.RenewSubscriptions(context.Background())
+		assert.NoError(t, err)
+	})
+
+	t.Run("declined price change ends the subscription", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockNotifier := NewMockNotifier(ctrl)
+		service := &Service{repository: mockRepo, notifier: mockNotifier, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		subscription := newSubscription()
+		change := model.PriceChange{
+			ID:             uuid.New(),
+			SubscriptionID: subscription.ID,
+			TotalPrice:     14,
+			EffectiveDate:  endDate,
+			Status:         model.PriceChangeOptedOut,
+		}
+
+		mockRepo.EXPECT().GetSubscriptionsDueForRenewal(gomock.Any(), now).Return([]model.Subscription{subscription}, nil)
+		mockRepo.EXPECT().GetPendingPriceChange(gomock.Any(), subscription.ID.String()).Return(change, true, nil)
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, canceled model.Subscription) error {
+				assert.Equal(t, model.Canceled, canceled.Status)
+				assert.Equal(t, endDate, *canceled.CanceledDate)
+				assert.Equal(t, 11.0, canceled.TotalPrice)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, message model.OutboxMessage) error {
+				assert.Equal(t, model.SubscriptionCanceled, message.Type)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().GetUser(gomock.Any(), subscription.UserID.String()).Return(model.User{ID: subscription.UserID}, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), subscription.ProductID.String()).Return(model.Product{ID: subscription.ProductID}, nil)
+		mockNotifier.EXPECT().Notify(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, notification model.Notification) error {
+				assert.Equal(t, model.NotificationCancellationConfirmation, notification.Kind)
+				return nil
+			},
+		)
+
+		err := service.RenewSubscriptions(context.Background())
+		assert.NoError(t, err)
+	})
+}
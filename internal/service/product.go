@@ -3,17 +3,67 @@ package service
 import (
 	"context"
 	"fmt"
+	"gymondo/internal/clock"
 	"gymondo/internal/model"
+	"time"
 )
 
 type Service struct {
 	repository Repository
+	payments   PaymentGateway
+	webhooks   WebhookSender
+	notifier   Notifier
+	// clock tells the time all date logic is based on. Without one the
+	// system clock is used.
+	clock  clock.Clock
+	config Config
+	// entitlements caches resolved entitlements, see FindEntitlements.
+	entitlements *entitlementCache
 }
 
-func New(repository Repository) *Service {
+func New(
+	repository Repository,
+	payments PaymentGateway,
+	webhooks WebhookSender,
+	notifier Notifier,
+	clock clock.Clock,
+	config Config,
+) *Service {
 	return &Service{
 		repository: repository,
+		payments:   payments,
+		webhooks:   webhooks,
+		notifier:   notifier,
+		clock:      clock,
+		config:     config,
+
+		entitlements: newEntitlementCache(config.EntitlementCacheTTL),
+	}
+}
+
+func (s *Service) now() time.Time {
+	if s.clock == nil {
+		return time.Now()
 	}
+	return s.clock.Now()
+}
+
+// today returns the start of the current day in the given location.
+// Subscription dates are kept at the start of a day in the time zone of the
+// subscription.
+func (s *Service) today(location *time.Location) time.Time {
+	return model.StartOfDay(s.now(), location)
+}
+
+// withinTx runs fn with a copy of the service whose repository is bound to a
+// single transaction, so everything fn writes is committed or rolled back
+// together.
+func (s *Service) withinTx(ctx context.Context, fn func(tx *Service) error) error {
+	return s.repository.WithinTx(ctx, func(repo Repository) error {
+		tx := *s
+		tx.repository = repo
+		return fn(&tx)
+	})
 }
 
 func (s *Service) FindProduct(ctx context.Context, productID string) (model.Product, error) {
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gymondo/internal/model"
+)
+
+// ReactivateSubscription restores a subscription canceled within the
+// reactivation window. It starts a new period today, charged at the price
+// the product's reactivation pricing calls for, and records the
+// reactivation.
+func (s *Service) ReactivateSubscription(ctx context.Context, subscriptionID string, version int) error {
+	subscription, err := s.repository.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		return fmt.Errorf("failed to find subscription with ID %s: %w", subscriptionID, err)
+	}
+	if err := checkVersion(subscription, version); err != nil {
+		return err
+	}
+
+	if subscription.Status != model.Canceled || subscription.CanceledDate == nil {
+		return fmt.Errorf("subscription is not canceled")
+	}
+
+	today := s.today(subscription.Location())
+	canceledDate := *subscription.CanceledDate
+	if today.After(canceledDate.AddDate(0, 0, s.config.ReactivationWindowDays)) {
+		return fmt.Errorf("subscription can only be reactivated within %d days after its cancellation",
+			s.config.ReactivationWindowDays)
+	}
+
+	user, err := s.repository.GetUser(ctx, subscription.UserID.String())
+	if err != nil {
+		return fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	product, err := s.repository.GetProduct(ctx, subscription.ProductID.String())
+	if err != nil {
+		return fmt.Errorf("failed to fetch product: %w", err)
+	}
+
+	pricing := product.ReactivationPricing
+	if pricing != model.LockedPriceReactivation {
+		pricing = model.CurrentPriceReactivation
+		subscription.Price = product.Price
+		subscription.Tax = product.Tax
+		subscription.TotalPrice = product.TotalPrice
+	}
+
+	subscription.Status = model.Active
+	subscription.StartDate = today
+	subscription.EndDate = today.AddDate(0, 0, subscription.DurationDays)
+	subscription.CanceledDate = nil
+	subscription.PastDueDate = nil
+	subscription.GraceEndDate = nil
+	subscription.NextPaymentRetryDate = nil
+
+	reactivation := model.Reactivation{
+		ID:             uuid.New(),
+		SubscriptionID: subscription.ID,
+		CanceledDate:   canceledDate,
+		ReactivatedAt:  s.now(),
+		Pricing:        pricing,
+		Price:          subscription.Price,
+		Tax:            subscription.Tax,
+		TotalPrice:     subscription.TotalPrice,
+	}
+
+	err = s.withinTx(ctx, func(tx *Service) error {
+		// the subscription is locked before it is charged, so a concurrent
+		// change can't discard the payment
+		if err := tx.lockSubscription(ctx, subscription); err != nil {
+			return err
+		}
+
+		// add-ons and seats used before the cancellation without being
+		// charged are billed with the new period
+		attempt, err := tx.attemptPayment(ctx, subscription, periodTotal(subscription, subscription.StartDate), 1)
+		if err != nil {
+			return fmt.Errorf("failed to charge subscription: %w", err)
+		}
+		if attempt.Status == model.PaymentFailed {
+			return fmt.Errorf("failed to charge subscription: %s", attempt.FailureReason)
+		}
+
+		if err := tx.updateSubscriptionWithEvent(ctx, model.SubscriptionReactivated, subscription); err != nil {
+			return fmt.Errorf("failed to reactivate subscription: %w", err)
+		}
+		if err := tx.repository.SaveReactivation(ctx, reactivation); err != nil {
+			return err
+		}
+		if err := tx.savePaymentAttempt(ctx, attempt); err != nil {
+			return err
+		}
+		if _, err := tx.issueInvoice(ctx, user, product.Name, subscription); err != nil {
+			return fmt.Errorf("failed to issue invoice: %w", err)
+		}
+		return tx.saveBilledAddOns(ctx, &subscription)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.notify(ctx, model.NotificationSubscriptionConfirmation, user, product.Name, subscription)
+
+	return nil
+}
+
+func (s *Service) FindReactivations(ctx context.Context, subscriptionID string) ([]model.Reactivation, error) {
+	reactivations, err := s.repository.GetReactivations(ctx, subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch reactivations for subscription %s: %w", subscriptionID, err)
+	}
+
+	return reactivations, nil
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gymondo/internal/clock"
+	"gymondo/internal/model"
+	"testing"
+	"time"
+)
+
+func Test_Service_ReactivateSubscription(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.June, 20, 10, 0, 0, 0, time.UTC)
+	today := model.StartOfDay(now, time.UTC)
+
+	canceledSubscription := func(canceledDaysAgo int) model.Subscription {
+		canceledDate := today.AddDate(0, 0, -canceledDaysAgo)
+		return model.Subscription{
+			ID:           uuid.New(),
+			UserID:       uuid.New(),
+			ProductID:    uuid.New(),
+			StartDate:    today.AddDate(0, 0, -60),
+			EndDate:      today.AddDate(0, 0, -30),
+			DurationDays: 30,
+			Price:        8,
+			Tax:          0.8,
+			TotalPrice:   8.8,
+			Status:       model.Canceled,
+			CanceledDate: &canceledDate,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		pricing    model.ReactivationPricing
+		totalPrice float64
+	}{
+		{name: "at the current price", pricing: model.CurrentPriceReactivation, totalPrice: 11},
+		{name: "at the locked price", pricing: model.LockedPriceReactivation, totalPrice: 8.8},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := NewMockRepository(ctrl)
+			mockPayments := NewMockPaymentGateway(ctrl)
+			mockNotifier := NewMockNotifier(ctrl)
+			service := &Service{
+				repository: mockRepo,
+				payments:   mockPayments,
+				notifier:   mockNotifier,
+				clock:      clock.Fixed(now),
+				config:     DefaultConfig(),
+			}
+
+			subscription := canceledSubscription(10)
+			product := model.Product{
+				ID:                  subscription.ProductID,
+				Price:               10,
+				Tax:                 1,
+				TotalPrice:          11,
+				ReactivationPricing: test.pricing,
+			}
+
+			mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+			mockRepo.EXPECT().GetUser(gomock.Any(), subscription.UserID.String()).Return(model.User{ID: subscription.UserID}, nil)
+			mockRepo.EXPECT().GetProduct(gomock.Any(), subscription.ProductID.String()).Return(product, nil)
+			mockRepo.EXPECT().GetCreditBalance(gomock.Any(), subscription.UserID.String()).Return(0.0, nil)
+			mockPayments.EXPECT().Charge(gomock.Any(), subscription.UserID, test.totalPrice, gomock.Any()).Return("tx-1", nil)
+			expectWithinTx(mockRepo)
+			expectLockSubscription(mockRepo, subscription)
+			mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, reactivated model.Subscription) error {
+					assert.Equal(t, model.Active, reactivated.Status)
+					assert.Equal(t, today, reactivated.StartDate)
+					assert.Equal(t, today.AddDate(0, 0, 30), reactivated.EndDate)
+					assert.Equal(t, test.totalPrice, reactivated.TotalPrice)
+					assert.Nil(t, reactivated.CanceledDate)
+					return nil
+				},
+			)
+			mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, message model.OutboxMessage) error {
+					assert.Equal(t, model.SubscriptionReactivated, message.Type)
+					return nil
+				},
+			)
+			mockRepo.EXPECT().SaveReactivation(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, reactivation model.Reactivation) error {
+					assert.Equal(t, subscription.ID, reactivation.SubscriptionID)
+					assert.Equal(t, *subscription.CanceledDate, reactivation.CanceledDate)
+					assert.Equal(t, now, reactivation.ReactivatedAt)
+					assert.Equal(t, test.pricing, reactivation.Pricing)
+					assert.Equal(t, test.totalPrice, reactivation.TotalPrice)
+					return nil
+				},
+			)
+			mockRepo.EXPECT().SavePaymentAttempt(gomock.Any(), gomock.Any()).Return(nil)
+			mockRepo.EXPECT().SaveInvoice(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, invoice model.Invoice) (model.Invoice, error) {
+					assert.Equal(t, test.totalPrice, invoice.TotalAmount)
+					assert.Equal(t, today, invoice.PeriodStart)
+					return invoice, nil
+				},
+			)
+			mockNotifier.EXPECT().Notify(gomock.Any(), gomock.Any()).Return(nil)
+
+			err := service.ReactivateSubscription(context.Background(), subscription.ID.String(), 0)
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("subscription is not canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		subscription := model.Subscription{ID: uuid.New(), Status: model.Active}
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+
+		err := service.ReactivateSubscription(context.Background(), subscription.ID.String(), 0)
+		assert.EqualError(t, err, "subscription is not canceled")
+	})
+
+	t.Run("reactivation window has passed", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		subscription := canceledSubscription(31)
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+
+		err := service.ReactivateSubscription(context.Background(), subscription.ID.String(), 0)
+		assert.EqualError(t, err, "subscription can only be reactivated within 30 days after its cancellation")
+	})
+
+	t.Run("payment declined", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockPayments := NewMockPaymentGateway(ctrl)
+		service := &Service{repository: mockRepo, payments: mockPayments, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		subscription := canceledSubscription(30)
+		product := model.Product{ID: subscription.ProductID, ReactivationPricing: model.LockedPriceReactivation}
+
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+		mockRepo.EXPECT().GetUser(gomock.Any(), subscription.UserID.String()).Return(model.User{ID: subscription.UserID}, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), subscription.ProductID.String()).Return(product, nil)
+		expectWithinTx(mockRepo)
+		expectLockSubscription(mockRepo, subscription)
+		mockRepo.EXPECT().GetCreditBalance(gomock.Any(), subscription.UserID.String()).Return(0.0, nil)
+		mockPayments.EXPECT().Charge(gomock.Any(), subscription.UserID, 8.8, gomock.Any()).Return("", errors.New("card declined"))
+
+		err := service.ReactivateSubscription(context.Background(), subscription.ID.String(), 0)
+		assert.EqualError(t, err, "failed to charge subscription: card declined")
+	})
+}
@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/google/uuid"
+	"gymondo/internal/model"
+)
+
+// CreateReferralCode returns the code the user refers friends with, creating
+// it on first use.
+func (s *Service) CreateReferralCode(ctx context.Context, userID string) (model.ReferralCode, error) {
+	user, err := s.repository.GetUser(ctx, userID)
+	if err != nil {
+		return model.ReferralCode{}, fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	code, found, err := s.repository.GetUserReferralCode(ctx, userID)
+	if err != nil {
+		return model.ReferralCode{}, fmt.Errorf("failed to fetch referral code: %w", err)
+	}
+	if found {
+		return code, nil
+	}
+
+	random, err := randomCode(4)
+	if err != nil {
+		return model.ReferralCode{}, fmt.Errorf("failed to generate referral code: %w", err)
+	}
+
+	code = model.ReferralCode{
+		UserID:    user.ID,
+		Code:      referralCodePrefix(user.FirstName) + "-" + random,
+		CreatedAt: s.now(),
+	}
+	if err := s.repository.SaveReferralCode(ctx, code); err != nil {
+		return model.ReferralCode{}, fmt.Errorf("failed to save referral code: %w", err)
+	}
+
+	return code, nil
+}
+
+// referralCodePrefix makes codes recognizable for the friends they are
+// shared with, like ANNA-7K3M. Only the Latin letters of the first name are
+// kept.
+func referralCodePrefix(firstName string) string {
+	var prefix strings.Builder
+	for _, r := range strings.ToUpper(firstName) {
+		if r >= 'A' && r <= 'Z' && prefix.Len() < 8 {
+			prefix.WriteRune(r)
+		}
+	}
+	if prefix.Len() == 0 {
+		return "FRIEND"
+	}
+	return prefix.String()
+}
+
+// FindReferralStats counts the referrals the user made by status and sums up
+// the rewards they got for referring and for being referred.
+func (s *Service) FindReferralStats(ctx context.Context, userID string) (model.ReferralStats, error) {
+	user, err := s.repository.GetUser(ctx, userID)
+	if err != nil {
+		return model.ReferralStats{}, fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	code, _, err := s.repository.GetUserReferralCode(ctx, userID)
+	if err != nil {
+		return model.ReferralStats{}, fmt.Errorf("failed to fetch referral code: %w", err)
+	}
+
+	referrals, err := s.repository.GetReferrals(ctx, userID)
+	if err != nil {
+		return model.ReferralStats{}, fmt.Errorf("failed to fetch referrals: %w", err)
+	}
+
+	rewards, err := s.repository.GetReferralRewards(ctx, userID)
+	if err != nil {
+		return model.ReferralStats{}, fmt.Errorf("failed to fetch referral rewards: %w", err)
+	}
+
+	stats := model.ReferralStats{
+		UserID:    user.ID,
+		Code:      code.Code,
+		Referrals: len(referrals),
+	}
+	for _, referral := range referrals {
+		switch referral.Status {
+		case model.ReferralPending:
+			stats.Pending++
+		case model.ReferralRewarded:
+			stats.Rewarded++
+		case model.ReferralVoid:
+			stats.Void++
+		}
+	}
+	for _, reward := range rewards {
+		stats.CreditEarned += reward.Credit
+		stats.DaysEarned += reward.Days
+	}
+	stats.CreditEarned = math.Round(stats.CreditEarned*100) / 100
+
+	return stats, nil
+}
+
+// attributeReferral checks that the user may subscribe with the referral
+// code and returns the referral of the new subscription. Referrers can't
+// refer themselves, also not through a variant of their email address, and
+// only users without any subscription can be referred.
+func (s *Service) attributeReferral(
+	ctx context.Context,
+	referee model.User,
+	code string,
+	subscriptionID uuid.UUID,
+) (model.Referral, error) {
+	referralCode, err := s.repository.GetReferralCode(ctx, code)
+	if err != nil {
+		return model.Referral{}, fmt.Errorf("failed to fetch referral code: %w", err)
+	}
+
+	referrer, err := s.repository.GetUser(ctx, referralCode.UserID.String())
+	if err != nil {
+		return model.Referral{}, fmt.Errorf("failed to fetch referrer: %w", err)
+	}
+
+	if referrer.ID == referee.ID || normalizeEmail(referrer.Email) == normalizeEmail(referee.Email) {
+		return model.Referral{}, fmt.Errorf("users can't refer themselves: %w", model.ErrReferralRejected)
+	}
+
+	subscriptions, err := s.repository.GetUserSubscriptions(ctx, referee.ID.String())
+	if err != nil {
+		return model.Referral{}, fmt.Errorf("failed to fetch subscriptions of user %s: %w", referee.ID, err)
+	}
+	if len(subscriptions) > 0 {
+		return model.Referral{}, fmt.Errorf("only new customers can be referred: %w", model.ErrReferralRejected)
+	}
+
+	return model.Referral{
+		ID:             uuid.New(),
+		Code:           referralCode.Code,
+		ReferrerID:     referrer.ID,
+		RefereeID:      referee.ID,
+		SubscriptionID: subscriptionID,
+		Status:         model.ReferralPending,
+		CreatedAt:      s.now(),
+	}, nil
+}
+
+// normalizeEmail reduces an email address to the mailbox it is delivered to,
+// so variants of one address compare equal: case and "+tags" are ignored, and
+// so are dots in Gmail addresses.
+func normalizeEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return email
+	}
+
+	local, domain := email[:at], email[at+1:]
+	local, _, _ = strings.Cut(local, "+")
+	if domain == "googlemail.com" {
+		domain = "gmail.com"
+	}
+	if domain == "gmail.com" {
+		local = strings.ReplaceAll(local, ".", "")
+	}
+
+	return local + "@" + domain
+}
+
+// GrantReferralRewards rewards both sides of the referrals whose first paid
+// period is over and voids the ones whose subscription was canceled before.
+// A referral that can't be settled now is picked up again on the next run.
+func (s *Service) GrantReferralRewards(ctx context.Context) error {
+	referrals, err := s.repository.GetPendingReferrals(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch pending referrals: %w", err)
+	}
+
+	for _, referral := range referrals {
+		if err := s.settleReferral(ctx, referral); err != nil {
+			log.Printf("Error settling referral %s: %v", referral.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) settleReferral(ctx context.Context, referral model.Referral) error {
+	subscription, err := s.repository.GetSubscription(ctx, referral.SubscriptionID.String())
+	if err != nil {
+		return fmt.Errorf("failed to fetch subscription: %w", err)
+	}
+
+	// trials and gifted days are not invoiced, so the first invoice is the
+	// first paid period
+	invoices, err := s.repository.GetSubscriptionInvoices(ctx, subscription.ID.String())
+	if err != nil {
+		return fmt.Errorf("failed to fetch invoices: %w", err)
+	}
+
+	now := s.now()
+	referral.SettledAt = &now
+
+	if subscription.Status == model.Canceled &&
+		(len(invoices) == 0 || subscription.CanceledDate == nil || subscription.CanceledDate.Before(invoices[0].PeriodEnd)) {
+		referral.Status = model.ReferralVoid
+		return s.repository.UpdateReferral(ctx, referral)
+	}
+	if len(invoices) == 0 || now.Before(invoices[0].PeriodEnd) {
+		return nil
+	}
+
+	referral.Status = model.ReferralRewarded
+	return s.withinTx(ctx, func(tx *Service) error {
+		for _, userID := range []uuid.UUID{referral.ReferrerID, referral.RefereeID} {
+			if err := tx.grantReferralReward(ctx, referral, userID); err != nil {
+				return err
+			}
+		}
+		return tx.repository.UpdateReferral(ctx, referral)
+	})
+}
+
+// grantReferralReward gives the user the configured reward for the
+// referral. Extra days postpone the next charge of their active subscription;
+// without one they get credit instead.
+func (s *Service) grantReferralReward(ctx context.Context, referral model.Referral, userID uuid.UUID) error {
+	reward := model.ReferralReward{
+		ID:         uuid.New(),
+		ReferralID: referral.ID,
+		UserID:     userID,
+		Kind:       s.config.ReferralReward,
+		CreatedAt:  s.now(),
+	}
+
+	if reward.Kind == model.ReferralRewardDays {
+		subscriptions, err := s.repository.GetUserSubscriptions(ctx, userID.String())
+		if err != nil {
+			return fmt.Errorf("failed to fetch subscriptions of user %s: %w", userID, err)
+		}
+
+		if subscription, ok := activeSubscription(subscriptions); ok {
+			subscription.EndDate = subscription.EndDate.AddDate(0, 0, s.config.ReferralRewardDays)
+			if err := s.updateSubscriptionWithEvent(ctx, model.SubscriptionUpdated, subscription); err != nil {
+				return fmt.Errorf("failed to extend subscription %s: %w", subscription.ID, err)
+			}
+
+			reward.Days = s.config.ReferralRewardDays
+			reward.SubscriptionID = &subscription.ID
+			if err := s.repository.SaveReferralReward(ctx, reward); err != nil {
+				return fmt.Errorf("failed to save referral reward: %w", err)
+			}
+			return nil
+		}
+
+		reward.Kind = model.ReferralRewardCredit
+	}
+
+	reward.Credit = s.config.ReferralRewardCredit
+	transaction := s.newCreditTransaction(
+		userID,
+		model.CreditReferral,
+		model.ReferralsAccount,
+		reward.Credit,
+		fmt.Sprintf("Reward of referral %s", referral.ID),
+	)
+	if err := s.repository.SaveCreditTransaction(ctx, transaction); err != nil {
+		return fmt.Errorf("failed to save credit transaction: %w", err)
+	}
+	if err := s.repository.SaveReferralReward(ctx, reward); err != nil {
+		return fmt.Errorf("failed to save referral reward: %w", err)
+	}
+
+	return nil
+}
+
+// activeSubscription returns the first active personal subscription of the
+// user, if they have one.
+func activeSubscription(subscriptions []model.Subscription) (model.Subscription, bool) {
+	for _, subscription := range subscriptions {
+		if subscription.Status == model.Active && subscription.OrganizationID == nil {
+			return subscription, true
+		}
+	}
+	return model.Subscription{}, false
+}
@@ -0,0 +1,407 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gymondo/internal/clock"
+	"gymondo/internal/model"
+	"testing"
+	"time"
+)
+
+func Test_normalizeEmail(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		email      string
+		normalized string
+	}{
+		{email: "anna@example.com", normalized: "anna@example.com"},
+		{email: " Anna@Example.COM ", normalized: "anna@example.com"},
+		{email: "anna+referral@example.com", normalized: "anna@example.com"},
+		{email: "an.na@example.com", normalized: "an.na@example.com"},
+		{email: "A.n.n.a+1@gmail.com", normalized: "anna@gmail.com"},
+		{email: "anna@googlemail.com", normalized: "anna@gmail.com"},
+		{email: "not an email", normalized: "not an email"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.normalized, normalizeEmail(test.email), test.email)
+	}
+}
+
+func Test_Service_CreateReferralCode(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.June, 20, 10, 0, 0, 0, time.UTC)
+	user := model.User{ID: uuid.New(), FirstName: "Jörg-Anna"}
+
+	t.Run("creates a code on first use", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		mockRepo.EXPECT().GetUser(gomock.Any(), user.ID.String()).Return(user, nil)
+		mockRepo.EXPECT().GetUserReferralCode(gomock.Any(), user.ID.String()).Return(model.ReferralCode{}, false, nil)
+		mockRepo.EXPECT().SaveReferralCode(gomock.Any(), gomock.Any()).Return(nil)
+
+		code, err := service.CreateReferralCode(context.Background(), user.ID.String())
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, code.UserID)
+		assert.Regexp(t, `^JRGANNA-[2-9A-HJ-NP-Z]{4}$`, code.Code)
+		assert.Equal(t, now, code.CreatedAt)
+	})
+
+	t.Run("returns the existing code", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		existing := model.ReferralCode{UserID: user.ID, Code: "ANNA-7K3M"}
+		mockRepo.EXPECT().GetUser(gomock.Any(), user.ID.String()).Return(user, nil)
+		mockRepo.EXPECT().GetUserReferralCode(gomock.Any(), user.ID.String()).Return(existing, true, nil)
+
+		code, err := service.CreateReferralCode(context.Background(), user.ID.String())
+		assert.NoError(t, err)
+		assert.Equal(t, existing, code)
+	})
+}
+
+func Test_Service_Subscribe_Referral(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.June, 20, 10, 0, 0, 0, time.UTC)
+	product := model.Product{ID: uuid.New(), DurationDays: 30, Price: 100, Tax: 10, TotalPrice: 110}
+	referrer := model.User{ID: uuid.New(), Email: "anna.schmidt@gmail.com"}
+	referralCode := model.ReferralCode{UserID: referrer.ID, Code: "ANNA-7K3M"}
+
+	t.Run("attributes the subscription to the referrer", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockPayments := NewMockPaymentGateway(ctrl)
+		mockNotifier := NewMockNotifier(ctrl)
+		service := &Service{
+			repository: mockRepo,
+			payments:   mockPayments,
+			notifier:   mockNotifier,
+			clock:      clock.Fixed(now),
+			config:     DefaultConfig(),
+		}
+
+		referee := model.User{ID: uuid.New(), Email: "ben@example.com"}
+
+		mockRepo.EXPECT().GetUser(gomock.Any(), referee.ID.String()).Return(referee, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), product.ID.String()).Return(product, nil)
+		mockRepo.EXPECT().GetReferralCode(gomock.Any(), "ANNA-7K3M").Return(referralCode, nil)
+		mockRepo.EXPECT().GetUser(gomock.Any(), referrer.ID.String()).Return(referrer, nil)
+		mockRepo.EXPECT().GetUserSubscriptions(gomock.Any(), referee.ID.String()).Return(nil, nil)
+		mockRepo.EXPECT().GetCreditBalance(gomock.Any(), referee.ID.String()).Return(0.0, nil)
+		mockPayments.EXPECT().Charge(gomock.Any(), referee.ID, 110.0, gomock.Any()).Return("tx-1", nil)
+		expectWithinTx(mockRepo)
+		var subscriptionID uuid.UUID
+		mockRepo.EXPECT().SaveSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, subscription model.Subscription) error {
+				subscriptionID = subscription.ID
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().SaveReferral(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, referral model.Referral) error {
+				assert.Equal(t, "ANNA-7K3M", referral.Code)
+				assert.Equal(t, referrer.ID, referral.ReferrerID)
+				assert.Equal(t, referee.ID, referral.RefereeID)
+				assert.Equal(t, subscriptionID, referral.SubscriptionID)
+				assert.Equal(t, model.ReferralPending, referral.Status)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SavePaymentAttempt(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().SaveInvoice(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, invoice model.Invoice) (model.Invoice, error) {
+				return invoice, nil
+			},
+		)
+		mockNotifier.EXPECT().Notify(gomock.Any(), gomock.Any()).Return(nil)
+
+		_, err := service.Subscribe(context.Background(), referee.ID.String(), product.ID.String(), "", "ANNA-7K3M", nil, false)
+		assert.NoError(t, err)
+	})
+
+	t.Run("self referral through a variant of the email address", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		referee := model.User{ID: uuid.New(), Email: "Anna.Schmidt+2@googlemail.com"}
+
+		mockRepo.EXPECT().GetUser(gomock.Any(), referee.ID.String()).Return(referee, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), product.ID.String()).Return(product, nil)
+		mockRepo.EXPECT().GetReferralCode(gomock.Any(), "ANNA-7K3M").Return(referralCode, nil)
+		mockRepo.EXPECT().GetUser(gomock.Any(), referrer.ID.String()).Return(referrer, nil)
+
+		_, err := service.Subscribe(context.Background(), referee.ID.String(), product.ID.String(), "", "ANNA-7K3M", nil, false)
+		assert.EqualError(t, err, "users can't refer themselves: referral was rejected")
+		assert.True(t, errors.Is(err, model.ErrReferralRejected))
+	})
+
+	t.Run("existing customer", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		referee := model.User{ID: uuid.New(), Email: "ben@example.com"}
+
+		mockRepo.EXPECT().GetUser(gomock.Any(), referee.ID.String()).Return(referee, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), product.ID.String()).Return(product, nil)
+		mockRepo.EXPECT().GetReferralCode(gomock.Any(), "ANNA-7K3M").Return(referralCode, nil)
+		mockRepo.EXPECT().GetUser(gomock.Any(), referrer.ID.String()).Return(referrer, nil)
+		mockRepo.EXPECT().GetUserSubscriptions(gomock.Any(), referee.ID.String()).
+			Return([]model.Subscription{{ID: uuid.New(), Status: model.Canceled}}, nil)
+
+		_, err := service.Subscribe(context.Background(), referee.ID.String(), product.ID.String(), "", "ANNA-7K3M", nil, false)
+		assert.EqualError(t, err, "only new customers can be referred: referral was rejected")
+	})
+}
+
+func Test_Service_GrantReferralRewards(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.June, 20, 10, 0, 0, 0, time.UTC)
+	today := model.StartOfDay(now, time.UTC)
+
+	newReferral := func() (model.Referral, model.Subscription) {
+		subscription := model.Subscription{
+			ID:           uuid.New(),
+			UserID:       uuid.New(),
+			StartDate:    today,
+			EndDate:      today.AddDate(0, 0, 30),
+			DurationDays: 30,
+			Status:       model.Active,
+		}
+		referral := model.Referral{
+			ID:             uuid.New(),
+			ReferrerID:     uuid.New(),
+			RefereeID:      subscription.UserID,
+			SubscriptionID: subscription.ID,
+			Status:         model.ReferralPending,
+		}
+		return referral, subscription
+	}
+	firstInvoice := func(periodEnd time.Time) []model.Invoice {
+		return []model.Invoice{{PeriodStart: periodEnd.AddDate(0, 0, -30), PeriodEnd: periodEnd}}
+	}
+
+	t.Run("extra days for both once the first paid period is over", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		referral, refereeSubscription := newReferral()
+		referrerSubscription := model.Subscription{
+			ID:      uuid.New(),
+			UserID:  referral.ReferrerID,
+			EndDate: today.AddDate(0, 0, 5),
+			Status:  model.Active,
+		}
+
+		mockRepo.EXPECT().GetPendingReferrals(gomock.Any()).Return([]model.Referral{referral}, nil)
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), refereeSubscription.ID.String()).Return(refereeSubscription, nil)
+		mockRepo.EXPECT().GetSubscriptionInvoices(gomock.Any(), refereeSubscription.ID.String()).Return(firstInvoice(today), nil)
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().GetUserSubscriptions(gomock.Any(), referral.ReferrerID.String()).
+			Return([]model.Subscription{{ID: uuid.New(), Status: model.Canceled}, referrerSubscription}, nil)
+		mockRepo.EXPECT().GetUserSubscriptions(gomock.Any(), referral.RefereeID.String()).
+			Return([]model.Subscription{refereeSubscription}, nil)
+		var extended []model.Subscription
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, subscription model.Subscription) error {
+				extended = append(extended, subscription)
+				return nil
+			},
+		).Times(2)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+		var rewards []model.ReferralReward
+		mockRepo.EXPECT().SaveReferralReward(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, reward model.ReferralReward) error {
+				rewards = append(rewards, reward)
+				return nil
+			},
+		).Times(2)
+		mockRepo.EXPECT().UpdateReferral(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, updated model.Referral) error {
+				assert.Equal(t, model.ReferralRewarded, updated.Status)
+				assert.Equal(t, now, *updated.SettledAt)
+				return nil
+			},
+		)
+
+		assert.NoError(t, service.GrantReferralRewards(context.Background()))
+
+		assert.Equal(t, referrerSubscription.ID, extended[0].ID)
+		assert.Equal(t, today.AddDate(0, 0, 35), extended[0].EndDate)
+		assert.Equal(t, refereeSubscription.ID, extended[1].ID)
+		assert.Equal(t, today.AddDate(0, 0, 60), extended[1].EndDate)
+		assert.Equal(t, referral.ReferrerID, rewards[0].UserID)
+		assert.Equal(t, model.ReferralRewardDays, rewards[0].Kind)
+		assert.Equal(t, 30, rewards[0].Days)
+		assert.Equal(t, &referrerSubscription.ID, rewards[0].SubscriptionID)
+		assert.Equal(t, referral.RefereeID, rewards[1].UserID)
+	})
+
+	t.Run("credit for a referrer without an active subscription", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		config := DefaultConfig()
+		config.ReferralReward = model.ReferralRewardDays
+		config.ReferralRewardCredit = 12.5
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: config}
+
+		referral, refereeSubscription := newReferral()
+
+		mockRepo.EXPECT().GetPendingReferrals(gomock.Any()).Return([]model.Referral{referral}, nil)
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), refereeSubscription.ID.String()).Return(refereeSubscription, nil)
+		mockRepo.EXPECT().GetSubscriptionInvoices(gomock.Any(), refereeSubscription.ID.String()).Return(firstInvoice(today), nil)
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().GetUserSubscriptions(gomock.Any(), referral.ReferrerID.String()).Return(nil, nil)
+		mockRepo.EXPECT().SaveCreditTransaction(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, transaction model.CreditTransaction) error {
+				assert.Equal(t, referral.ReferrerID, transaction.UserID)
+				assert.Equal(t, model.CreditReferral, transaction.Kind)
+				assert.Equal(t, 12.5, transaction.CustomerAmount())
+				assert.Equal(t, model.ReferralsAccount, transaction.Entries[1].Account)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SaveReferralReward(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, reward model.ReferralReward) error {
+				assert.Equal(t, model.ReferralRewardCredit, reward.Kind)
+				assert.Equal(t, 12.5, reward.Credit)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().GetUserSubscriptions(gomock.Any(), referral.RefereeID.String()).
+			Return([]model.Subscription{refereeSubscription}, nil)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().SaveReferralReward(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().UpdateReferral(gomock.Any(), gomock.Any()).Return(nil)
+
+		assert.NoError(t, service.GrantReferralRewards(context.Background()))
+	})
+
+	t.Run("first paid period not over yet", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		referral, subscription := newReferral()
+
+		mockRepo.EXPECT().GetPendingReferrals(gomock.Any()).Return([]model.Referral{referral}, nil)
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+		mockRepo.EXPECT().GetSubscriptionInvoices(gomock.Any(), subscription.ID.String()).Return(firstInvoice(today.AddDate(0, 0, 1)), nil)
+
+		assert.NoError(t, service.GrantReferralRewards(context.Background()))
+	})
+
+	t.Run("canceled in the trial", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		referral, subscription := newReferral()
+		subscription.Status = model.Canceled
+		subscription.CanceledDate = &today
+
+		mockRepo.EXPECT().GetPendingReferrals(gomock.Any()).Return([]model.Referral{referral}, nil)
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+		mockRepo.EXPECT().GetSubscriptionInvoices(gomock.Any(), subscription.ID.String()).Return(nil, nil)
+		mockRepo.EXPECT().UpdateReferral(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, updated model.Referral) error {
+				assert.Equal(t, model.ReferralVoid, updated.Status)
+				return nil
+			},
+		)
+
+		assert.NoError(t, service.GrantReferralRewards(context.Background()))
+	})
+}
+
+func Test_Service_FindReferralStats(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	service := &Service{repository: mockRepo, config: DefaultConfig()}
+
+	user := model.User{ID: uuid.New()}
+
+	mockRepo.EXPECT().GetUser(gomock.Any(), user.ID.String()).Return(user, nil)
+	mockRepo.EXPECT().GetUserReferralCode(gomock.Any(), user.ID.String()).
+		Return(model.ReferralCode{UserID: user.ID, Code: "ANNA-7K3M"}, true, nil)
+	mockRepo.EXPECT().GetReferrals(gomock.Any(), user.ID.String()).Return([]model.Referral{
+		{Status: model.ReferralRewarded},
+		{Status: model.ReferralRewarded},
+		{Status: model.ReferralPending},
+		{Status: model.ReferralVoid},
+	}, nil)
+	mockRepo.EXPECT().GetReferralRewards(gomock.Any(), user.ID.String()).Return([]model.ReferralReward{
+		{Kind: model.ReferralRewardDays, Days: 30},
+		{Kind: model.ReferralRewardCredit, Credit: 10.1},
+		{Kind: model.ReferralRewardCredit, Credit: 10.2},
+	}, nil)
+
+	stats, err := service.FindReferralStats(context.Background(), user.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, model.ReferralStats{
+		UserID:       user.ID,
+		Code:         "ANNA-7K3M",
+		Referrals:    4,
+		Pending:      1,
+		Rewarded:     2,
+		Void:         1,
+		CreditEarned: 20.3,
+		DaysEarned:   30,
+	}, stats)
+}
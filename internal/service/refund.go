@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"gymondo/internal/model"
+)
+
+// pendingRefund is a refund recorded with a cancellation whose part for the
+// payment method is paid back once the cancellation has been committed.
+type pendingRefund struct {
+	refund              model.Refund
+	userID              uuid.UUID
+	gatewayAmount       float64
+	chargeTransactionID string
+	reference           string
+}
+
+// recordRefund applies the product's refund policy to a subscription that is
+// being canceled. A non-zero refund is documented by a credit note against
+// the invoice of the current period. The part paid with credit, or all of it
+// when nothing was charged to the payment method, goes back to the credit
+// balance right away. The rest is recorded as pending and paid back by
+// settleRefund. Runs within the cancellation transaction, so nothing is
+// recorded unless the cancellation is.
+func (s *Service) recordRefund(
+	ctx context.Context,
+	subscription model.Subscription,
+	today time.Time,
+) (pendingRefund, error) {
+	product, err := s.repository.GetProduct(ctx, subscription.ProductID.String())
+	if err != nil {
+		return pendingRefund{}, fmt.Errorf("failed to fetch product: %w", err)
+	}
+
+	invoices, err := s.repository.GetSubscriptionInvoices(ctx, subscription.ID.String())
+	if err != nil {
+		return pendingRefund{}, fmt.Errorf("failed to fetch invoices: %w", err)
+	}
+	if len(invoices) == 0 {
+		return pendingRefund{}, nil
+	}
+
+	invoice := invoices[len(invoices)-1]
+	share := refundShare(product, invoice, len(invoices) == 1, today)
+	if share == 0 {
+		return pendingRefund{}, nil
+	}
+
+	totalAmount := math.Floor(invoice.TotalAmount*share*100) / 100
+	netAmount := math.Floor(invoice.NetAmount*share*100) / 100
+	creditNote := model.CreditNote{
+		ID:             uuid.New(),
+		InvoiceID:      invoice.ID,
+		SubscriptionID: subscription.ID,
+		UserID:         subscription.UserID,
+		IssueDate:      today,
+		Reason:         fmt.Sprintf("Cancellation of subscription %s", subscription.ID),
+		NetAmount:      netAmount,
+		TaxAmount:      math.Round((totalAmount-netAmount)*100) / 100,
+		TotalAmount:    totalAmount,
+		Currency:       invoice.Currency,
+	}
+
+	creditNote, err = s.repository.SaveCreditNote(ctx, creditNote)
+	if err != nil {
+		return pendingRefund{}, fmt.Errorf("failed to save credit note: %w", err)
+	}
+
+	// without a successful charge the period was paid with credit only
+	charge, err := s.lastSuccessfulPayment(ctx, subscription.ID.String())
+	if err != nil {
+		return pendingRefund{}, err
+	}
+
+	// money goes back the way it came: to the payment method up to what was
+	// charged there, the rest back to the credit balance
+	pending := pendingRefund{
+		userID:              subscription.UserID,
+		gatewayAmount:       math.Min(creditNote.TotalAmount, charge.Amount),
+		chargeTransactionID: charge.TransactionID,
+		reference:           fmt.Sprintf("credit note %s", creditNote.Number),
+	}
+	pending.refund = model.Refund{
+		ID:             uuid.New(),
+		SubscriptionID: subscription.ID,
+		CreditNoteID:   creditNote.ID,
+		Amount:         creditNote.TotalAmount,
+		CreditAmount:   math.Round((creditNote.TotalAmount-pending.gatewayAmount)*100) / 100,
+		Status:         model.RefundSucceeded,
+		CreatedAt:      s.now(),
+	}
+	if pending.gatewayAmount > 0 {
+		pending.refund.Status = model.RefundPending
+	}
+
+	if pending.refund.CreditAmount > 0 {
+		creditRefund := s.newCreditTransaction(
+			subscription.UserID,
+			model.CreditRefund,
+			model.RefundsAccount,
+			pending.refund.CreditAmount,
+			fmt.Sprintf("Refund of credit note %s", creditNote.Number),
+		)
+		creditRefund.SubscriptionID = &subscription.ID
+		if err := s.repository.SaveCreditTransaction(ctx, creditRefund); err != nil {
+			return pendingRefund{}, fmt.Errorf("failed to refund credit: %w", err)
+		}
+	}
+
+	if err := s.repository.SaveRefund(ctx, pending.refund); err != nil {
+		return pendingRefund{}, fmt.Errorf("failed to save refund: %w", err)
+	}
+
+	return pending, nil
+}
+
+// settleRefund pays the pending part of a refund back through the payment
+// gateway and records the outcome. A declined refund is returned with a failed
+// status, the cancellation and the credit note stand either way. A failure to
+// record the outcome is only logged since the gateway has already answered.
+func (s *Service) settleRefund(ctx context.Context, pending pendingRefund) model.Refund {
+	refund := pending.refund
+	if refund.Status != model.RefundPending {
+		return refund
+	}
+
+	transactionID, err := s.payments.Refund(ctx, pending.userID, pending.gatewayAmount, pending.chargeTransactionID, pending.reference)
+	if err != nil {
+		refund.Status = model.RefundFailed
+		refund.FailureReason = err.Error()
+	} else {
+		refund.Status = model.RefundSucceeded
+		refund.TransactionID = transactionID
+	}
+
+	if err := s.repository.UpdateRefund(ctx, refund); err != nil {
+		log.Printf("Error recording %s refund %s: %v", refund.Status, refund.ID, err)
+	}
+
+	return refund
+}
+
+// lastSuccessfulPayment returns the latest successful payment attempt of the
+// subscription, or an empty attempt when nothing was charged.
+func (s *Service) lastSuccessfulPayment(ctx context.Context, subscriptionID string) (model.PaymentAttempt, error) {
+	attempts, err := s.repository.GetPaymentAttempts(ctx, subscriptionID)
+	if err != nil {
+		return model.PaymentAttempt{}, fmt.Errorf("failed to fetch payment attempts: %w", err)
+	}
+
+	for i := len(attempts) - 1; i >= 0; i-- {
+		if attempts[i].Status == model.PaymentSucceeded {
+			return attempts[i], nil
+		}
+	}
+
+	return model.PaymentAttempt{}, nil
+}
+
+// refundShare returns the part of the invoiced period, between 0 and 1, that
+// the product's refund policy pays back when canceling on the given day.
+// Cancellations within the withdrawal period only count for the first period.
+// Days are counted in the location of today.
+func refundShare(product model.Product, invoice model.Invoice, firstPeriod bool, today time.Time) float64 {
+	withdrawalEnd := invoice.PeriodStart.In(today.Location()).AddDate(0, 0, product.WithdrawalPeriodDays)
+	withinWithdrawalPeriod := firstPeriod && today.Before(withdrawalEnd)
+
+	switch product.RefundPolicy {
+	case model.WithdrawalPeriodRefund:
+		if withinWithdrawalPeriod {
+			return 1
+		}
+	case model.ProratedRefund:
+		if withinWithdrawalPeriod {
+			return 1
+		}
+
+		periodDays := math.Round(invoice.PeriodEnd.Sub(invoice.PeriodStart).Hours() / 24)
+		unusedDays := math.Round(invoice.PeriodEnd.Sub(today).Hours() / 24)
+		if periodDays <= 0 || unusedDays <= 0 {
+			return 0
+		}
+
+		return math.Min(unusedDays/periodDays, 1)
+	}
+
+	return 0
+}
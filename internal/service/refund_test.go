@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gymondo/internal/model"
+	"testing"
+	"time"
+)
+
+func Test_refundShare(t *testing.T) {
+	t.Parallel()
+
+	periodStart := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	invoice := model.Invoice{PeriodStart: periodStart, PeriodEnd: periodStart.AddDate(0, 0, 365)}
+
+	testCases := []struct {
+		name        string
+		policy      model.RefundPolicy
+		firstPeriod bool
+		today       time.Time
+		expected    float64
+	}{
+		{"no refund policy", model.NoRefund, true, periodStart.AddDate(0, 0, 1), 0},
+		{"withdrawal period, day 2", model.WithdrawalPeriodRefund, true, periodStart.AddDate(0, 0, 1), 1},
+		{"withdrawal period, after 14 days", model.WithdrawalPeriodRefund, true, periodStart.AddDate(0, 0, 14), 0},
+		{"withdrawal period, renewed period", model.WithdrawalPeriodRefund, false, periodStart.AddDate(0, 0, 1), 0},
+		{"prorated, within withdrawal period", model.ProratedRefund, true, periodStart.AddDate(0, 0, 1), 1},
+		{"prorated, after withdrawal period", model.ProratedRefund, true, periodStart.AddDate(0, 0, 73), 292.0 / 365.0},
+		{"prorated, renewed period", model.ProratedRefund, false, periodStart.AddDate(0, 0, 1), 364.0 / 365.0},
+		{"prorated, after period end", model.ProratedRefund, false, periodStart.AddDate(0, 0, 366), 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			product := model.Product{RefundPolicy: tc.policy, WithdrawalPeriodDays: 14}
+			assert.InDelta(t, tc.expected, refundShare(product, invoice, tc.firstPeriod, tc.today), 0.0001)
+		})
+	}
+}
+
+func Test_Service_recordRefund(t *testing.T) {
+	t.Parallel()
+
+	today := time.Now().Truncate(24 * time.Hour)
+	subscription := model.Subscription{ID: uuid.New(), UserID: uuid.New(), ProductID: uuid.New()}
+	invoice := model.Invoice{
+		ID:          uuid.New(),
+		PeriodStart: today.AddDate(0, 0, -1),
+		PeriodEnd:   today.AddDate(0, 0, 364),
+		NetAmount:   80,
+		TaxAmount:   8,
+		TotalAmount: 88,
+		Currency:    "EUR",
+	}
+	product := model.Product{ID: subscription.ProductID, RefundPolicy: model.WithdrawalPeriodRefund, WithdrawalPeriodDays: 14}
+	attempts := []model.PaymentAttempt{{Status: model.PaymentSucceeded, TransactionID: "tx-1", Amount: 88}}
+
+	t.Run("full refund within withdrawal period is pending", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo}
+
+		mockRepo.EXPECT().GetProduct(gomock.Any(), subscription.ProductID.String()).Return(product, nil)
+		mockRepo.EXPECT().GetSubscriptionInvoices(gomock.Any(), subscription.ID.String()).Return([]model.Invoice{invoice}, nil)
+		mockRepo.EXPECT().SaveCreditNote(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, creditNote model.CreditNote) (model.CreditNote, error) {
+				assert.Equal(t, invoice.ID, creditNote.InvoiceID)
+				assert.Equal(t, 80.0, creditNote.NetAmount)
+				assert.Equal(t, 8.0, creditNote.TaxAmount)
+				assert.Equal(t, 88.0, creditNote.TotalAmount)
+				creditNote.Number = "CN-2026-000001"
+				return creditNote, nil
+			},
+		)
+		mockRepo.EXPECT().GetPaymentAttempts(gomock.Any(), subscription.ID.String()).Return(attempts, nil)
+		mockRepo.EXPECT().SaveRefund(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, refund model.Refund) error {
+				assert.Equal(t, model.RefundPending, refund.Status)
+				return nil
+			},
+		)
+
+		pending, err := service.recordRefund(context.Background(), subscription, today)
+		assert.NoError(t, err)
+		assert.Equal(t, 88.0, pending.refund.Amount)
+		assert.Equal(t, model.RefundPending, pending.refund.Status)
+		assert.Equal(t, 88.0, pending.gatewayAmount)
+		assert.Equal(t, "tx-1", pending.chargeTransactionID)
+		assert.Equal(t, "credit note CN-2026-000001", pending.reference)
+	})
+
+	t.Run("no refund outside withdrawal period", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo}
+
+		renewedInvoice := invoice
+		renewedInvoice.ID = uuid.New()
+
+		mockRepo.EXPECT().GetProduct(gomock.Any(), subscription.ProductID.String()).Return(product, nil)
+		mockRepo.EXPECT().GetSubscriptionInvoices(gomock.Any(), subscription.ID.String()).Return([]model.Invoice{invoice, renewedInvoice}, nil)
+
+		pending, err := service.recordRefund(context.Background(), subscription, today)
+		assert.NoError(t, err)
+		assert.Equal(t, pendingRefund{}, pending)
+	})
+
+	t.Run("part paid with credit goes back to the credit balance", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo}
+
+		partlyCredited := []model.PaymentAttempt{{Status: model.PaymentSucceeded, TransactionID: "tx-1", Amount: 68, CreditApplied: 20}}
+
+		mockRepo.EXPECT().GetProduct(gomock.Any(), subscription.ProductID.String()).Return(product, nil)
+		mockRepo.EXPECT().GetSubscriptionInvoices(gomock.Any(), subscription.ID.String()).Return([]model.Invoice{invoice}, nil)
+		mockRepo.EXPECT().SaveCreditNote(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, creditNote model.CreditNote) (model.CreditNote, error) {
+				return creditNote, nil
+			},
+		)
+		mockRepo.EXPECT().GetPaymentAttempts(gomock.Any(), subscription.ID.String()).Return(partlyCredited, nil)
+		mockRepo.EXPECT().SaveCreditTransaction(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, transaction model.CreditTransaction) error {
+				assert.Equal(t, model.CreditRefund, transaction.Kind)
+				assert.Equal(t, 20.0, transaction.CustomerAmount())
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SaveRefund(gomock.Any(), gomock.Any()).Return(nil)
+
+		pending, err := service.recordRefund(context.Background(), subscription, today)
+		assert.NoError(t, err)
+		assert.Equal(t, 88.0, pending.refund.Amount)
+		assert.Equal(t, 20.0, pending.refund.CreditAmount)
+		assert.Equal(t, 68.0, pending.gatewayAmount)
+	})
+
+	t.Run("period paid with credit only is refunded to the credit balance", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo}
+
+		mockRepo.EXPECT().GetProduct(gomock.Any(), subscription.ProductID.String()).Return(product, nil)
+		mockRepo.EXPECT().GetSubscriptionInvoices(gomock.Any(), subscription.ID.String()).Return([]model.Invoice{invoice}, nil)
+		mockRepo.EXPECT().SaveCreditNote(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, creditNote model.CreditNote) (model.CreditNote, error) {
+				return creditNote, nil
+			},
+		)
+		mockRepo.EXPECT().GetPaymentAttempts(gomock.Any(), subscription.ID.String()).Return(nil, nil)
+		mockRepo.EXPECT().SaveCreditTransaction(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, transaction model.CreditTransaction) error {
+				assert.Equal(t, 88.0, transaction.CustomerAmount())
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SaveRefund(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, refund model.Refund) error {
+				assert.Equal(t, model.RefundSucceeded, refund.Status)
+				return nil
+			},
+		)
+
+		pending, err := service.recordRefund(context.Background(), subscription, today)
+		assert.NoError(t, err)
+		assert.Equal(t, 88.0, pending.refund.CreditAmount)
+		assert.Equal(t, 0.0, pending.gatewayAmount)
+	})
+}
+
+func Test_Service_settleRefund(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	pending := pendingRefund{
+		refund:              model.Refund{ID: uuid.New(), Amount: 88, Status: model.RefundPending},
+		userID:              userID,
+		gatewayAmount:       88,
+		chargeTransactionID: "tx-1",
+		reference:           "credit note CN-2026-000001",
+	}
+
+	t.Run("refund paid back through the payment method", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockPayments := NewMockPaymentGateway(ctrl)
+		service := &Service{repository: mockRepo, payments: mockPayments}
+
+		mockPayments.EXPECT().Refund(gomock.Any(), userID, 88.0, "tx-1", "credit note CN-2026-000001").Return("tx-2", nil)
+		mockRepo.EXPECT().UpdateRefund(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, refund model.Refund) error {
+				assert.Equal(t, model.RefundSucceeded, refund.Status)
+				assert.Equal(t, "tx-2", refund.TransactionID)
+				return nil
+			},
+		)
+
+		refund := service.settleRefund(context.Background(), pending)
+		assert.Equal(t, model.RefundSucceeded, refund.Status)
+		assert.Equal(t, "tx-2", refund.TransactionID)
+	})
+
+	t.Run("declined refund is recorded as failed", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockPayments := NewMockPaymentGateway(ctrl)
+		service := &Service{repository: mockRepo, payments: mockPayments}
+
+		mockPayments.EXPECT().Refund(gomock.Any(), userID, 88.0, "tx-1", gomock.Any()).Return("", errors.New("card expired"))
+		mockRepo.EXPECT().UpdateRefund(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, refund model.Refund) error {
+				assert.Equal(t, model.RefundFailed, refund.Status)
+				assert.Equal(t, "card expired", refund.FailureReason)
+				return nil
+			},
+		)
+
+		refund := service.settleRefund(context.Background(), pending)
+		assert.Equal(t, model.RefundFailed, refund.Status)
+	})
+
+	t.Run("failing to record the outcome still returns it", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockPayments := NewMockPaymentGateway(ctrl)
+		service := &Service{repository: mockRepo, payments: mockPayments}
+
+		mockPayments.EXPECT().Refund(gomock.Any(), userID, 88.0, "tx-1", gomock.Any()).Return("tx-2", nil)
+		mockRepo.EXPECT().UpdateRefund(gomock.Any(), gomock.Any()).Return(errors.New("connection lost"))
+
+		refund := service.settleRefund(context.Background(), pending)
+		assert.Equal(t, model.RefundSucceeded, refund.Status)
+	})
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gymondo/internal/model"
+)
+
+// remindersLockKey is the advisory lock that lets only one replica send
+// reminders at a time.
+const remindersLockKey int64 = 0x72656d696e646572
+
+// SendReminders reminds users of trials that are about to end and of
+// renewals that are coming up, once per trial end and renewal date. When
+// another replica is already sending reminders, it does nothing.
+func (s *Service) SendReminders(ctx context.Context) error {
+	return s.repository.WithinTx(ctx, func(repo Repository) error {
+		locked, err := repo.TryAdvisoryLock(ctx, remindersLockKey)
+		if err != nil {
+			return err
+		}
+		if !locked {
+			log.Printf("Reminders are being sent by another replica, skipping")
+			return nil
+		}
+
+		// the lock only needs to be held, reminders are recorded outside of
+		// its transaction so every sent reminder is kept
+		return s.sendDueReminders(ctx)
+	})
+}
+
+func (s *Service) sendDueReminders(ctx context.Context) error {
+	now := s.now()
+
+	trialEnding, err := s.repository.GetSubscriptionsDueForTrialReminder(
+		ctx,
+		now,
+		now.AddDate(0, 0, s.config.TrialReminderLeadDays),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to fetch subscriptions due for a trial reminder: %w", err)
+	}
+
+	for _, subscription := range trialEnding {
+		s.sendReminder(ctx, model.NotificationTrialEnding, subscription, *subscription.TrialEndDate)
+	}
+
+	renewing, err := s.repository.GetSubscriptionsDueForRenewalReminder(
+		ctx,
+		now,
+		now.AddDate(0, 0, s.config.RenewalReminderLeadDays),
+		s.config.RenewalReminderMinDurationDays,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to fetch subscriptions due for a renewal reminder: %w", err)
+	}
+
+	for _, subscription := range renewing {
+		s.sendReminder(ctx, model.NotificationRenewalUpcoming, subscription, subscription.EndDate)
+	}
+
+	return nil
+}
+
+// sendReminder sends the reminder and records it. A reminder that could not
+// be sent is not recorded and is sent again on the next run.
+func (s *Service) sendReminder(
+	ctx context.Context,
+	kind model.NotificationKind,
+	subscription model.Subscription,
+	eventDate time.Time,
+) {
+	if err := s.sendSubscriptionNotification(ctx, kind, subscription); err != nil {
+		log.Printf("Error sending %s reminder for subscription %s: %v", kind, subscription.ID, err)
+		return
+	}
+
+	reminder := model.Reminder{
+		ID:             uuid.New(),
+		SubscriptionID: subscription.ID,
+		Kind:           kind,
+		EventDate:      eventDate,
+		SentAt:         s.now(),
+	}
+	if err := s.repository.SaveReminder(ctx, reminder); err != nil {
+		log.Printf("Error recording %s reminder for subscription %s: %v", kind, subscription.ID, err)
+	}
+}
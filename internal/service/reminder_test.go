@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gymondo/internal/clock"
+	"gymondo/internal/model"
+	"testing"
+	"time"
+)
+
+func Test_Service_SendReminders(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.March, 10, 9, 30, 0, 0, time.UTC)
+	today := model.StartOfDay(now, time.UTC)
+
+	t.Run("lock held by another replica", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, config: DefaultConfig()}
+
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().TryAdvisoryLock(gomock.Any(), remindersLockKey).Return(false, nil)
+
+		err := service.SendReminders(context.Background())
+		assert.NoError(t, err)
+	})
+
+	t.Run("sends and records due reminders", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockNotifier := NewMockNotifier(ctrl)
+		service := &Service{repository: mockRepo, notifier: mockNotifier, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		trialEndDate := today.AddDate(0, 0, 3)
+		trial := model.Subscription{ID: uuid.New(), UserID: uuid.New(), TrialEndDate: &trialEndDate}
+		annual := model.Subscription{ID: uuid.New(), UserID: uuid.New(), EndDate: today.AddDate(0, 0, 30), DurationDays: 365}
+
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().TryAdvisoryLock(gomock.Any(), remindersLockKey).Return(true, nil)
+		mockRepo.EXPECT().GetSubscriptionsDueForTrialReminder(gomock.Any(), now, now.AddDate(0, 0, 3)).
+			Return([]model.Subscription{trial}, nil)
+		mockRepo.EXPECT().GetSubscriptionsDueForRenewalReminder(gomock.Any(), now, now.AddDate(0, 0, 30), 365).
+			Return([]model.Subscription{annual}, nil)
+		mockRepo.EXPECT().GetUser(gomock.Any(), gomock.Any()).Return(model.User{}, nil).Times(2)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), gomock.Any()).Return(model.Product{}, nil).Times(2)
+		gomock.InOrder(
+			mockNotifier.EXPECT().Notify(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, notification model.Notification) error {
+					assert.Equal(t, model.NotificationTrialEnding, notification.Kind)
+					assert.Equal(t, trial.ID, notification.Subscription.ID)
+					return nil
+				},
+			),
+			mockNotifier.EXPECT().Notify(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, notification model.Notification) error {
+					assert.Equal(t, model.NotificationRenewalUpcoming, notification.Kind)
+					assert.Equal(t, annual.ID, notification.Subscription.ID)
+					return nil
+				},
+			),
+		)
+		gomock.InOrder(
+			mockRepo.EXPECT().SaveReminder(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, reminder model.Reminder) error {
+					assert.Equal(t, trial.ID, reminder.SubscriptionID)
+					assert.Equal(t, model.NotificationTrialEnding, reminder.Kind)
+					assert.Equal(t, trialEndDate, reminder.EventDate)
+					return nil
+				},
+			),
+			mockRepo.EXPECT().SaveReminder(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, reminder model.Reminder) error {
+					assert.Equal(t, annual.ID, reminder.SubscriptionID)
+					assert.Equal(t, model.NotificationRenewalUpcoming, reminder.Kind)
+					assert.Equal(t, annual.EndDate, reminder.EventDate)
+					return nil
+				},
+			),
+		)
+
+		err := service.SendReminders(context.Background())
+		assert.NoError(t, err)
+	})
+
+	t.Run("failed send is not recorded", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockNotifier := NewMockNotifier(ctrl)
+		service := &Service{repository: mockRepo, notifier: mockNotifier, config: DefaultConfig()}
+
+		trialEndDate := today.AddDate(0, 0, 1)
+		trial := model.Subscription{ID: uuid.New(), UserID: uuid.New(), TrialEndDate: &trialEndDate}
+
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().TryAdvisoryLock(gomock.Any(), remindersLockKey).Return(true, nil)
+		mockRepo.EXPECT().GetSubscriptionsDueForTrialReminder(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return([]model.Subscription{trial}, nil)
+		mockRepo.EXPECT().GetSubscriptionsDueForRenewalReminder(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, nil)
+		mockRepo.EXPECT().GetUser(gomock.Any(), trial.UserID.String()).Return(model.User{}, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), gomock.Any()).Return(model.Product{}, nil)
+		mockNotifier.EXPECT().Notify(gomock.Any(), gomock.Any()).Return(errors.New("connection refused"))
+
+		err := service.SendReminders(context.Background())
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"gymondo/internal/model"
+)
+
+// RenewSubscriptions charges every active subscription whose period or trial
+// has ended.
+// Successful charges start the next period, declined ones move the
+// subscription into dunning. A price change due with the renewal is applied
+// before the charge; a declined one ends the subscription instead.
+func (s *Service) RenewSubscriptions(ctx context.Context) error {
+	subscriptions, err := s.repository.GetSubscriptionsDueForRenewal(ctx, s.now())
+	if err != nil {
+		return fmt.Errorf("failed to fetch subscriptions due for renewal: %w", err)
+	}
+
+	for _, subscription := range subscriptions {
+		if err := s.renewSubscription(ctx, subscription); err != nil {
+			log.Printf("Error renewing subscription %s: %v", subscription.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// renewSubscription charges the renewal and records its outcome in one
+// transaction that holds the lock on the subscription, so the payment can't
+// be lost to a concurrent change of the subscription.
+func (s *Service) renewSubscription(ctx context.Context, subscription model.Subscription) error {
+	// the period a subscription starts with a trial in ends with the trial,
+	// its first charge is due then
+	if inFirstTrialPeriod(subscription) {
+		trialEnd := firstPaidPeriodStart(subscription)
+		if trialEnd.After(s.now()) {
+			return nil
+		}
+		subscription.EndDate = trialEnd
+	}
+
+	change, due, err := s.duePriceChange(ctx, subscription)
+	if err != nil {
+		return err
+	}
+	if due && change.Status == model.PriceChangeOptedOut {
+		return s.endDeclinedSubscription(ctx, subscription)
+	}
+	if due {
+		appliedAt := s.now()
+		change.Status = model.PriceChangeApplied
+		change.AppliedAt = &appliedAt
+		subscription.Price = change.Price
+		subscription.Tax = change.Tax
+		subscription.TotalPrice = change.TotalPrice
+	}
+
+	var attempt model.PaymentAttempt
+	err = s.withinTx(ctx, func(tx *Service) error {
+		if err := tx.lockSubscription(ctx, subscription); err != nil {
+			return err
+		}
+
+		attempt, err = tx.attemptPayment(ctx, subscription, renewalTotal(subscription), 1)
+		if err != nil {
+			return err
+		}
+
+		if attempt.Status == model.PaymentSucceeded {
+			startNextPeriod(&subscription)
+		} else {
+			tx.startDunning(&subscription, tx.today(subscription.Location()))
+			attempt.NextRetryDate = subscription.NextPaymentRetryDate
+		}
+
+		if due {
+			if err := tx.repository.UpdatePriceChange(ctx, change); err != nil {
+				return fmt.Errorf("failed to apply price change: %w", err)
+			}
+		}
+		return tx.recordPayment(ctx, subscription, attempt)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.notifyDeclinedPayment(ctx, subscription, attempt)
+
+	return nil
+}
+
+// recordPayment stores a renewal payment attempt together with the
+// subscription state it led to and, for a successful payment, the invoice.
+func (s *Service) recordPayment(ctx context.Context, subscription model.Subscription, attempt model.PaymentAttempt) error {
+	if err := s.savePaymentAttempt(ctx, attempt); err != nil {
+		return err
+	}
+
+	var err error
+	if subscription.Status == model.Canceled {
+		err = s.updateSubscriptionWithEvent(ctx, model.SubscriptionCanceled, subscription)
+	} else {
+		err = s.repository.UpdateSubscription(ctx, subscription)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update subscription: %w", err)
+	}
+
+	if attempt.Status == model.PaymentSucceeded {
+		if err := s.issueRenewalInvoice(ctx, subscription); err != nil {
+			return fmt.Errorf("failed to issue invoice: %w", err)
+		}
+		if err := s.saveBilledAddOns(ctx, &subscription); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// notifyDeclinedPayment tells the user about a declined renewal charge, or
+// about the cancellation it led to once no retries are left.
+func (s *Service) notifyDeclinedPayment(ctx context.Context, subscription model.Subscription, attempt model.PaymentAttempt) {
+	switch {
+	case attempt.Status != model.PaymentFailed:
+	case subscription.Status == model.Canceled:
+		s.notifySubscription(ctx, model.NotificationCancellationConfirmation, subscription)
+	default:
+		s.notifySubscription(ctx, model.NotificationPaymentFailed, subscription)
+	}
+}
+
+// attemptPayment charges the amount for the subscription, using up the
+// user's credit balance first and charging only the rest through the payment
+// gateway. The credit is taken before the charge; when the balance was spent
+// in the meantime the full amount is charged instead, and a declined charge
+// gives the credit back. A declined charge is not an error, it is reported
+// through the status of the returned attempt. Amounts below zero charge
+// nothing.
+func (s *Service) attemptPayment(
+	ctx context.Context,
+	subscription model.Subscription,
+	amount float64,
+	attemptNumber int,
+) (model.PaymentAttempt, error) {
+	amount = math.Max(0, amount)
+	creditApplied, err := s.applicableCredit(ctx, subscription.UserID, amount)
+	if err != nil {
+		return model.PaymentAttempt{}, err
+	}
+
+	if creditApplied > 0 {
+		reason := fmt.Sprintf("Payment of subscription %s", subscription.ID)
+		err := s.saveCreditConsumption(ctx, subscription, -creditApplied, reason)
+		switch {
+		case errors.Is(err, model.ErrInsufficientCredit):
+			creditApplied = 0
+		case err != nil:
+			return model.PaymentAttempt{}, fmt.Errorf("failed to consume credit: %w", err)
+		}
+	}
+
+	attempt := model.PaymentAttempt{
+		ID:             uuid.New(),
+		SubscriptionID: subscription.ID,
+		AttemptNumber:  attemptNumber,
+		Amount:         math.Round((amount-creditApplied)*100) / 100,
+		CreditApplied:  creditApplied,
+		Status:         model.PaymentSucceeded,
+		AttemptedAt:    s.now(),
+	}
+	if attempt.Amount == 0 {
+		return attempt, nil
+	}
+
+	reference := fmt.Sprintf("subscription %s", subscription.ID)
+	transactionID, err := s.payments.Charge(ctx, subscription.UserID, attempt.Amount, reference)
+	if err != nil {
+		attempt.Status = model.PaymentFailed
+		attempt.FailureReason = err.Error()
+		if attempt.CreditApplied > 0 {
+			reason := fmt.Sprintf("Declined payment of subscription %s", subscription.ID)
+			err := s.saveCreditConsumption(ctx, subscription, attempt.CreditApplied, reason)
+			if err != nil {
+				return model.PaymentAttempt{}, fmt.Errorf("failed to restore credit: %w", err)
+			}
+		}
+		attempt.CreditApplied = 0
+		return attempt, nil
+	}
+	attempt.TransactionID = transactionID
+
+	return attempt, nil
+}
+
+// saveCreditConsumption moves amount between the user's credit balance and
+// the revenue account for a payment of the subscription. A negative amount
+// consumes credit, a positive one gives it back.
+func (s *Service) saveCreditConsumption(ctx context.Context, subscription model.Subscription, amount float64, reason string) error {
+	transaction := s.newCreditTransaction(subscription.UserID, model.CreditConsumption, model.RevenueAccount, amount, reason)
+	transaction.SubscriptionID = &subscription.ID
+	return s.repository.SaveCreditTransaction(ctx, transaction)
+}
+
+// savePaymentAttempt records the attempt. The credit it applied was
+// consumed when the payment was attempted.
+func (s *Service) savePaymentAttempt(ctx context.Context, attempt model.PaymentAttempt) error {
+	if err := s.repository.SavePaymentAttempt(ctx, attempt); err != nil {
+		return fmt.Errorf("failed to save payment attempt: %w", err)
+	}
+
+	return nil
+}
+
+// inFirstTrialPeriod reports whether the subscription is still in the period
+// it started with a trial in, i.e. it has never been charged.
+func inFirstTrialPeriod(subscription model.Subscription) bool {
+	return subscription.TrialEndDate != nil && subscription.StartDate.Before(*subscription.TrialEndDate)
+}
+
+// firstPaidPeriodStart returns the day the first paid period of a subscription
+// that started with a trial begins: the end of the trial, pushed back by the
+// days added to the period the subscription started with, e.g. by a gift.
+func firstPaidPeriodStart(subscription model.Subscription) time.Time {
+	location := subscription.Location()
+	start := subscription.TrialEndDate.In(location)
+
+	firstPeriodEnd := subscription.StartDate.In(location).AddDate(0, 0, subscription.DurationDays)
+	if addedDays := daysBetween(firstPeriodEnd, subscription.EndDate); addedDays > 0 {
+		start = start.AddDate(0, 0, addedDays)
+	}
+	return start
+}
+
+// startNextPeriod moves the subscription to the period that follows its
+// current one and clears any dunning state.
+func startNextPeriod(subscription *model.Subscription) {
+	subscription.Status = model.Active
+	subscription.StartDate = subscription.EndDate
+	subscription.EndDate = subscription.EndDate.AddDate(0, 0, subscription.DurationDays)
+	subscription.PastDueDate = nil
+	subscription.GraceEndDate = nil
+	subscription.NextPaymentRetryDate = nil
+}
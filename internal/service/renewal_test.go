@@ -0,0 +1,318 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gymondo/internal/clock"
+	"gymondo/internal/model"
+	"testing"
+	"time"
+)
+
+func Test_Service_RenewSubscriptions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("failed to fetch subscriptions", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, config: DefaultConfig()}
+
+		mockRepo.EXPECT().GetSubscriptionsDueForRenewal(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("database error"))
+
+		err := service.RenewSubscriptions(context.Background())
+		assert.ErrorContains(t, err, "failed to fetch subscriptions due for renewal")
+	})
+
+	t.Run("successful renewal starts next period", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockPayments := NewMockPaymentGateway(ctrl)
+		service := &Service{repository: mockRepo, payments: mockPayments, config: DefaultConfig()}
+
+		endDate := time.Now().Truncate(24 * time.Hour)
+		subscription := model.Subscription{
+			ID:           uuid.New(),
+			UserID:       uuid.New(),
+			StartDate:    endDate.AddDate(0, 0, -30),
+			EndDate:      endDate,
+			DurationDays: 30,
+			TotalPrice:   11,
+			Status:       model.Active,
+		}
+
+		mockRepo.EXPECT().GetSubscriptionsDueForRenewal(gomock.Any(), gomock.Any()).Return([]model.Subscription{subscription}, nil)
+		mockRepo.EXPECT().GetPendingPriceChange(gomock.Any(), subscription.ID.String()).Return(model.PriceChange{}, false, nil)
+		mockRepo.EXPECT().GetCreditBalance(gomock.Any(), subscription.UserID.String()).Return(0.0, nil)
+		mockPayments.EXPECT().Charge(gomock.Any(), subscription.UserID, 11.0, gomock.Any()).Return("tx-1", nil)
+		expectWithinTx(mockRepo)
+		expectLockSubscription(mockRepo, subscription)
+		mockRepo.EXPECT().SavePaymentAttempt(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, renewed model.Subscription) error {
+				assert.Equal(t, model.Active, renewed.Status)
+				assert.Equal(t, endDate, renewed.StartDate)
+				assert.Equal(t, endDate.AddDate(0, 0, 30), renewed.EndDate)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().GetUser(gomock.Any(), subscription.UserID.String()).Return(model.User{ID: subscription.UserID}, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), subscription.ProductID.String()).Return(model.Product{ID: subscription.ProductID, Name: "basic plan"}, nil)
+		mockRepo.EXPECT().SaveInvoice(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, invoice model.Invoice) (model.Invoice, error) {
+				assert.Equal(t, endDate, invoice.PeriodStart)
+				assert.Equal(t, endDate.AddDate(0, 0, 30), invoice.PeriodEnd)
+				return invoice, nil
+			},
+		)
+
+		err := service.RenewSubscriptions(context.Background())
+		assert.NoError(t, err)
+	})
+
+	t.Run("add-on attached on the first day of the period is charged for it", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockPayments := NewMockPaymentGateway(ctrl)
+		service := &Service{repository: mockRepo, payments: mockPayments, config: DefaultConfig()}
+
+		endDate := time.Now().Truncate(24 * time.Hour)
+		addOn := model.SubscriptionAddOn{
+			ID:         uuid.New(),
+			Name:       "live classes",
+			Price:      8,
+			Tax:        0.8,
+			TotalPrice: 8.8,
+			Status:     model.AddOnActive,
+			StartDate:  endDate.AddDate(0, 0, -30),
+		}
+		subscription := model.Subscription{
+			ID:           uuid.New(),
+			UserID:       uuid.New(),
+			StartDate:    endDate.AddDate(0, 0, -30),
+			EndDate:      endDate,
+			DurationDays: 30,
+			TotalPrice:   11,
+			Status:       model.Active,
+			AddOns:       []model.SubscriptionAddOn{addOn},
+		}
+
+		mockRepo.EXPECT().GetSubscriptionsDueForRenewal(gomock.Any(), gomock.Any()).Return([]model.Subscription{subscription}, nil)
+		mockRepo.EXPECT().GetPendingPriceChange(gomock.Any(), subscription.ID.String()).Return(model.PriceChange{}, false, nil)
+		mockRepo.EXPECT().GetCreditBalance(gomock.Any(), subscription.UserID.String()).Return(0.0, nil)
+		mockPayments.EXPECT().Charge(gomock.Any(), subscription.UserID, 28.6, gomock.Any()).Return("tx-1", nil)
+		expectWithinTx(mockRepo)
+		expectLockSubscription(mockRepo, subscription)
+		mockRepo.EXPECT().SavePaymentAttempt(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().GetUser(gomock.Any(), subscription.UserID.String()).Return(model.User{ID: subscription.UserID}, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), subscription.ProductID.String()).Return(model.Product{ID: subscription.ProductID, Name: "basic plan"}, nil)
+		mockRepo.EXPECT().SaveInvoice(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, invoice model.Invoice) (model.Invoice, error) {
+				assert.Len(t, invoice.LineItems, 3)
+				assert.Equal(t, 28.6, invoice.TotalAmount)
+				return invoice, nil
+			},
+		)
+		mockRepo.EXPECT().UpdateSubscriptionAddOn(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, billed model.SubscriptionAddOn) error {
+				assert.Equal(t, addOn.ID, billed.ID)
+				assert.Equal(t, endDate.AddDate(0, 0, 30), *billed.BilledUntil)
+				return nil
+			},
+		)
+
+		err := service.RenewSubscriptions(context.Background())
+		assert.NoError(t, err)
+	})
+
+	t.Run("first period is charged when the trial ends", func(t *testing.T) {
+		t.Parallel()
+
+		now := time.Date(2025, time.January, 31, 6, 0, 0, 0, time.UTC)
+		startDate := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+		trialEndDate := startDate.AddDate(0, 0, 30)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockPayments := NewMockPaymentGateway(ctrl)
+		service := &Service{repository: mockRepo, payments: mockPayments, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		subscription := model.Subscription{
+			ID:             uuid.New(),
+			UserID:         uuid.New(),
+			StartDate:      startDate,
+			EndDate:        startDate.AddDate(0, 0, 365),
+			TrialStartDate: &startDate,
+			TrialEndDate:   &trialEndDate,
+			DurationDays:   365,
+			TotalPrice:     119,
+			Status:         model.Active,
+			TimeZone:       "UTC",
+		}
+
+		mockRepo.EXPECT().GetSubscriptionsDueForRenewal(gomock.Any(), now).Return([]model.Subscription{subscription}, nil)
+		mockRepo.EXPECT().GetPendingPriceChange(gomock.Any(), subscription.ID.String()).Return(model.PriceChange{}, false, nil)
+		mockRepo.EXPECT().GetCreditBalance(gomock.Any(), subscription.UserID.String()).Return(0.0, nil)
+		mockPayments.EXPECT().Charge(gomock.Any(), subscription.UserID, 119.0, gomock.Any()).Return("tx-1", nil)
+		expectWithinTx(mockRepo)
+		expectLockSubscription(mockRepo, subscription)
+		mockRepo.EXPECT().SavePaymentAttempt(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, renewed model.Subscription) error {
+				assert.Equal(t, model.Active, renewed.Status)
+				assert.Equal(t, trialEndDate, renewed.StartDate)
+				assert.Equal(t, trialEndDate.AddDate(0, 0, 365), renewed.EndDate)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().GetUser(gomock.Any(), subscription.UserID.String()).Return(model.User{ID: subscription.UserID}, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), subscription.ProductID.String()).Return(model.Product{ID: subscription.ProductID}, nil)
+		mockRepo.EXPECT().SaveInvoice(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, invoice model.Invoice) (model.Invoice, error) {
+				assert.Equal(t, trialEndDate, invoice.PeriodStart)
+				assert.Equal(t, trialEndDate.AddDate(0, 0, 365), invoice.PeriodEnd)
+				return invoice, nil
+			},
+		)
+
+		err := service.RenewSubscriptions(context.Background())
+		assert.NoError(t, err)
+	})
+
+	t.Run("trial extended by a gift is not charged before the added days", func(t *testing.T) {
+		t.Parallel()
+
+		now := time.Date(2025, time.January, 31, 6, 0, 0, 0, time.UTC)
+		startDate := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+		trialEndDate := startDate.AddDate(0, 0, 30)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		subscription := model.Subscription{
+			ID:             uuid.New(),
+			UserID:         uuid.New(),
+			StartDate:      startDate,
+			EndDate:        startDate.AddDate(0, 0, 365+90),
+			TrialStartDate: &startDate,
+			TrialEndDate:   &trialEndDate,
+			DurationDays:   365,
+			TotalPrice:     119,
+			Status:         model.Active,
+			TimeZone:       "UTC",
+		}
+
+		mockRepo.EXPECT().GetSubscriptionsDueForRenewal(gomock.Any(), now).Return([]model.Subscription{subscription}, nil)
+
+		err := service.RenewSubscriptions(context.Background())
+		assert.NoError(t, err)
+	})
+
+	t.Run("declined renewal starts dunning", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockPayments := NewMockPaymentGateway(ctrl)
+		mockNotifier := NewMockNotifier(ctrl)
+		service := &Service{repository: mockRepo, payments: mockPayments, notifier: mockNotifier, config: DefaultConfig()}
+
+		today := model.StartOfDay(time.Now(), time.UTC)
+		subscription := model.Subscription{
+			ID:           uuid.New(),
+			UserID:       uuid.New(),
+			EndDate:      today,
+			DurationDays: 30,
+			TotalPrice:   11,
+			Status:       model.Active,
+		}
+
+		mockRepo.EXPECT().GetSubscriptionsDueForRenewal(gomock.Any(), gomock.Any()).Return([]model.Subscription{subscription}, nil)
+		mockRepo.EXPECT().GetPendingPriceChange(gomock.Any(), subscription.ID.String()).Return(model.PriceChange{}, false, nil)
+		mockRepo.EXPECT().GetCreditBalance(gomock.Any(), subscription.UserID.String()).Return(0.0, nil)
+		mockPayments.EXPECT().Charge(gomock.Any(), subscription.UserID, 11.0, gomock.Any()).Return("", errors.New("card declined"))
+		expectWithinTx(mockRepo)
+		expectLockSubscription(mockRepo, subscription)
+		mockRepo.EXPECT().SavePaymentAttempt(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, attempt model.PaymentAttempt) error {
+				assert.Equal(t, model.PaymentFailed, attempt.Status)
+				assert.Equal(t, "card declined", attempt.FailureReason)
+				assert.Equal(t, today.AddDate(0, 0, 1), *attempt.NextRetryDate)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, pastDue model.Subscription) error {
+				assert.Equal(t, model.PastDue, pastDue.Status)
+				assert.Equal(t, today, *pastDue.PastDueDate)
+				assert.Equal(t, today.AddDate(0, 0, 7), *pastDue.GraceEndDate)
+				assert.Equal(t, today, pastDue.EndDate)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().GetUser(gomock.Any(), subscription.UserID.String()).Return(model.User{ID: subscription.UserID}, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), subscription.ProductID.String()).Return(model.Product{ID: subscription.ProductID}, nil)
+		mockNotifier.EXPECT().Notify(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, notification model.Notification) error {
+				assert.Equal(t, model.NotificationPaymentFailed, notification.Kind)
+				return nil
+			},
+		)
+
+		err := service.RenewSubscriptions(context.Background())
+		assert.NoError(t, err)
+	})
+	t.Run("subscription changed since it was read is not charged", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockPayments := NewMockPaymentGateway(ctrl)
+		service := &Service{repository: mockRepo, payments: mockPayments, config: DefaultConfig()}
+
+		today := model.StartOfDay(time.Now(), time.UTC)
+		subscription := model.Subscription{
+			ID:           uuid.New(),
+			UserID:       uuid.New(),
+			EndDate:      today,
+			DurationDays: 30,
+			TotalPrice:   11,
+			Status:       model.Active,
+			Version:      3,
+		}
+		changed := subscription
+		changed.Version = 4
+
+		mockRepo.EXPECT().GetPendingPriceChange(gomock.Any(), subscription.ID.String()).Return(model.PriceChange{}, false, nil)
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().LockSubscription(gomock.Any(), subscription.ID.String()).Return(changed, nil)
+
+		err := service.renewSubscription(context.Background(), subscription)
+		assert.ErrorIs(t, err, model.ErrSubscriptionConflict)
+	})
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gymondo/internal/model"
+)
+
+// FindRetentionOffers returns the offers configured for the cancellation
+// reason that the subscription can take instead of canceling.
+func (s *Service) FindRetentionOffers(
+	ctx context.Context,
+	subscriptionID string,
+	reason model.CancellationReason,
+) ([]model.RetentionOffer, error) {
+	if !reason.Valid() {
+		return nil, fmt.Errorf("unknown cancellation reason %s", reason)
+	}
+
+	subscription, err := s.repository.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find subscription with ID %s: %w", subscriptionID, err)
+	}
+	if subscription.Status == model.Canceled {
+		return nil, fmt.Errorf("subscription is already canceled")
+	}
+
+	offers, err := s.repository.GetRetentionOffers(ctx, reason)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch retention offers: %w", err)
+	}
+
+	applicable := []model.RetentionOffer{}
+	for _, offer := range offers {
+		_, ok, err := s.retainedSubscription(ctx, subscription, offer)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			applicable = append(applicable, offer)
+		}
+	}
+
+	return applicable, nil
+}
+
+// AcceptRetentionOffer applies the offer to the subscription and records
+// that it was accepted. A pause starts today, a downgrade or voucher changes
+// the price charged from the next renewal on.
+func (s *Service) AcceptRetentionOffer(
+	ctx context.Context,
+	subscriptionID string,
+	version int,
+	offerID string,
+) error {
+	subscription, err := s.repository.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		return fmt.Errorf("failed to find subscription with ID %s: %w", subscriptionID, err)
+	}
+	if err := checkVersion(subscription, version); err != nil {
+		return err
+	}
+	if subscription.Status == model.Canceled {
+		return fmt.Errorf("subscription is already canceled")
+	}
+
+	offer, err := s.repository.GetRetentionOffer(ctx, offerID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch retention offer: %w", err)
+	}
+
+	retained, ok, err := s.retainedSubscription(ctx, subscription, offer)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("retention offer %s is not available for the subscription", offerID)
+	}
+
+	acceptance := model.RetentionOfferAcceptance{
+		ID:             uuid.New(),
+		SubscriptionID: subscription.ID,
+		OfferID:        offer.ID,
+		Reason:         offer.Reason,
+		Kind:           offer.Kind,
+		AcceptedAt:     s.now(),
+	}
+
+	return s.withinTx(ctx, func(tx *Service) error {
+		if offer.Kind == model.PauseOffer {
+			resumeOn := s.today(subscription.Location()).AddDate(0, 0, offer.PauseDays)
+			schedule := model.PauseSchedule{ResumeOn: &resumeOn}
+			if err := tx.PauseSubscription(ctx, subscriptionID, version, schedule); err != nil {
+				return err
+			}
+		} else {
+			if err := tx.updateSubscriptionWithEvent(ctx, model.SubscriptionUpdated, retained); err != nil {
+				return fmt.Errorf("failed to apply retention offer: %w", err)
+			}
+		}
+
+		return tx.repository.SaveRetentionOfferAcceptance(ctx, acceptance)
+	})
+}
+
+// retainedSubscription returns the subscription as it would be after taking
+// the offer, and false when the offer does not apply to it. Pauses are only
+// offered to active subscriptions after their trial, downgrades and vouchers
+// only when they lower the price.
+func (s *Service) retainedSubscription(
+	ctx context.Context,
+	subscription model.Subscription,
+	offer model.RetentionOffer,
+) (model.Subscription, bool, error) {
+	switch offer.Kind {
+	case model.PauseOffer:
+		today := s.today(subscription.Location())
+		inTrial := subscription.TrialEndDate != nil && subscription.TrialEndDate.After(today)
+		return subscription, subscription.Status == model.Active && !inTrial && offer.PauseDays > 0, nil
+	case model.DowngradeOffer:
+		if offer.ProductID == nil || *offer.ProductID == subscription.ProductID {
+			return subscription, false, nil
+		}
+
+		product, err := s.repository.GetProduct(ctx, offer.ProductID.String())
+		if err != nil {
+			return subscription, false, fmt.Errorf("failed to fetch product: %w", err)
+		}
+		if product.TotalPrice >= subscription.TotalPrice {
+			return subscription, false, nil
+		}
+
+		subscription.ProductID = product.ID
+		subscription.DurationDays = product.DurationDays
+		subscription.Price = product.Price
+		subscription.Tax = product.Tax
+		subscription.TotalPrice = product.TotalPrice
+		return subscription, true, nil
+	case model.VoucherOffer:
+		voucher, err := s.repository.GetVoucherByCode(ctx, offer.VoucherCode)
+		if err != nil {
+			return subscription, false, fmt.Errorf("failed to fetch voucher: %w", err)
+		}
+
+		product, err := s.repository.GetProduct(ctx, subscription.ProductID.String())
+		if err != nil {
+			return subscription, false, fmt.Errorf("failed to fetch product: %w", err)
+		}
+
+		discounted, err := calculatePriceWithVoucher(product, voucher)
+		if err != nil {
+			return subscription, false, fmt.Errorf("failed to apply voucher: %w", err)
+		}
+		if discounted.TotalPrice >= subscription.TotalPrice {
+			return subscription, false, nil
+		}
+
+		subscription.Price = discounted.Price
+		subscription.Tax = discounted.Tax
+		subscription.TotalPrice = discounted.TotalPrice
+		return subscription, true, nil
+	}
+
+	return subscription, false, nil
+}
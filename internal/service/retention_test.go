@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gymondo/internal/clock"
+	"gymondo/internal/model"
+	"testing"
+	"time"
+)
+
+func Test_Service_FindRetentionOffers(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.June, 20, 10, 0, 0, 0, time.UTC)
+	basicPlanID := uuid.New()
+	premiumPlanID := uuid.New()
+
+	t.Run("only offers that lower the price", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		subscription := model.Subscription{
+			ID:         uuid.New(),
+			ProductID:  premiumPlanID,
+			Status:     model.Active,
+			Price:      25,
+			Tax:        2.5,
+			TotalPrice: 27.5,
+		}
+		downgrade := model.RetentionOffer{ID: uuid.New(), Reason: model.TooExpensive, Kind: model.DowngradeOffer, ProductID: &basicPlanID}
+		sameProduct := model.RetentionOffer{ID: uuid.New(), Reason: model.TooExpensive, Kind: model.DowngradeOffer, ProductID: &premiumPlanID}
+		voucher := model.RetentionOffer{ID: uuid.New(), Reason: model.TooExpensive, Kind: model.VoucherOffer, VoucherCode: "discount10"}
+
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+		mockRepo.EXPECT().GetRetentionOffers(gomock.Any(), model.TooExpensive).Return(
+			[]model.RetentionOffer{downgrade, sameProduct, voucher}, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), basicPlanID.String()).Return(
+			model.Product{ID: basicPlanID, Price: 10, Tax: 1, TotalPrice: 11}, nil)
+		mockRepo.EXPECT().GetVoucherByCode(gomock.Any(), "discount10").Return(
+			model.Voucher{Code: "discount10", DiscountType: model.Percentage, DiscountValue: 0.1}, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), premiumPlanID.String()).Return(
+			model.Product{ID: premiumPlanID, Price: 25, Tax: 2.5, TotalPrice: 27.5}, nil)
+
+		offers, err := service.FindRetentionOffers(context.Background(), subscription.ID.String(), model.TooExpensive)
+		assert.NoError(t, err)
+		assert.Equal(t, []model.RetentionOffer{downgrade, voucher}, offers)
+	})
+
+	t.Run("no pause during the trial", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		trialEndDate := now.AddDate(0, 0, 10)
+		subscription := model.Subscription{ID: uuid.New(), Status: model.Active, TrialEndDate: &trialEndDate}
+		pause := model.RetentionOffer{ID: uuid.New(), Reason: model.NotUsing, Kind: model.PauseOffer, PauseDays: 30}
+
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+		mockRepo.EXPECT().GetRetentionOffers(gomock.Any(), model.NotUsing).Return([]model.RetentionOffer{pause}, nil)
+
+		offers, err := service.FindRetentionOffers(context.Background(), subscription.ID.String(), model.NotUsing)
+		assert.NoError(t, err)
+		assert.Empty(t, offers)
+	})
+
+	t.Run("unknown reason", func(t *testing.T) {
+		t.Parallel()
+
+		service := &Service{}
+
+		_, err := service.FindRetentionOffers(context.Background(), uuid.NewString(), "bored")
+		assert.EqualError(t, err, "unknown cancellation reason bored")
+	})
+}
+
+func Test_Service_AcceptRetentionOffer(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, time.June, 20, 10, 0, 0, 0, time.UTC)
+	today := model.StartOfDay(now, time.UTC)
+
+	activeSubscription := func() model.Subscription {
+		return model.Subscription{
+			ID:           uuid.New(),
+			UserID:       uuid.New(),
+			ProductID:    uuid.New(),
+			StartDate:    today.AddDate(0, 0, -10),
+			EndDate:      today.AddDate(0, 0, 80),
+			DurationDays: 90,
+			Price:        25,
+			Tax:          2.5,
+			TotalPrice:   27.5,
+			Status:       model.Active,
+		}
+	}
+
+	expectAcceptance := func(t *testing.T, mockRepo *MockRepository, offer model.RetentionOffer) {
+		mockRepo.EXPECT().SaveRetentionOfferAcceptance(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, acceptance model.RetentionOfferAcceptance) error {
+				assert.Equal(t, offer.ID, acceptance.OfferID)
+				assert.Equal(t, offer.Reason, acceptance.Reason)
+				assert.Equal(t, offer.Kind, acceptance.Kind)
+				assert.Equal(t, now, acceptance.AcceptedAt)
+				return nil
+			},
+		)
+	}
+
+	t.Run("downgrade applies from the next renewal", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		subscription := activeSubscription()
+		basicPlanID := uuid.New()
+		offer := model.RetentionOffer{ID: uuid.New(), Reason: model.TooExpensive, Kind: model.DowngradeOffer, ProductID: &basicPlanID}
+
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+		mockRepo.EXPECT().GetRetentionOffer(gomock.Any(), offer.ID.String()).Return(offer, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), basicPlanID.String()).Return(
+			model.Product{ID: basicPlanID, DurationDays: 30, Price: 10, Tax: 1, TotalPrice: 11}, nil)
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, updated model.Subscription) error {
+				assert.Equal(t, basicPlanID, updated.ProductID)
+				assert.Equal(t, 30, updated.DurationDays)
+				assert.Equal(t, 11.0, updated.TotalPrice)
+				assert.Equal(t, subscription.EndDate, updated.EndDate)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, message model.OutboxMessage) error {
+				assert.Equal(t, model.SubscriptionUpdated, message.Type)
+				return nil
+			},
+		)
+		expectAcceptance(t, mockRepo, offer)
+
+		err := service.AcceptRetentionOffer(context.Background(), subscription.ID.String(), 0, offer.ID.String())
+		assert.NoError(t, err)
+	})
+
+	t.Run("voucher discounts the price", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		subscription := activeSubscription()
+		offer := model.RetentionOffer{ID: uuid.New(), Reason: model.FoundAlternative, Kind: model.VoucherOffer, VoucherCode: "summer25"}
+
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+		mockRepo.EXPECT().GetRetentionOffer(gomock.Any(), offer.ID.String()).Return(offer, nil)
+		mockRepo.EXPECT().GetVoucherByCode(gomock.Any(), "summer25").Return(
+			model.Voucher{Code: "summer25", DiscountType: model.Percentage, DiscountValue: 0.25}, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), subscription.ProductID.String()).Return(
+			model.Product{ID: subscription.ProductID, Price: 25, Tax: 2.5, TotalPrice: 27.5}, nil)
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, updated model.Subscription) error {
+				assert.Equal(t, 18.75, updated.Price)
+				assert.Equal(t, 1.88, updated.Tax)
+				assert.Equal(t, subscription.ProductID, updated.ProductID)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).Return(nil)
+		expectAcceptance(t, mockRepo, offer)
+
+		err := service.AcceptRetentionOffer(context.Background(), subscription.ID.String(), 0, offer.ID.String())
+		assert.NoError(t, err)
+	})
+
+	t.Run("pause resumes after the offered days", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		subscription := activeSubscription()
+		offer := model.RetentionOffer{ID: uuid.New(), Reason: model.TemporaryBreak, Kind: model.PauseOffer, PauseDays: 60}
+
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil).Times(2)
+		mockRepo.EXPECT().GetRetentionOffer(gomock.Any(), offer.ID.String()).Return(offer, nil)
+		expectWithinTx(mockRepo)
+		expectPausePolicy(mockRepo, model.PausePolicy{})
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, paused model.Subscription) error {
+				assert.Equal(t, model.Paused, paused.Status)
+				assert.Equal(t, today.AddDate(0, 0, 60), *paused.ResumeOn)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().SavePause(gomock.Any(), gomock.Any()).Return(nil)
+		expectAcceptance(t, mockRepo, offer)
+
+		err := service.AcceptRetentionOffer(context.Background(), subscription.ID.String(), 0, offer.ID.String())
+		assert.NoError(t, err)
+	})
+
+	t.Run("offer that doesn't lower the price", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		subscription := activeSubscription()
+		enterprisePlanID := uuid.New()
+		offer := model.RetentionOffer{ID: uuid.New(), Reason: model.TooExpensive, Kind: model.DowngradeOffer, ProductID: &enterprisePlanID}
+
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+		mockRepo.EXPECT().GetRetentionOffer(gomock.Any(), offer.ID.String()).Return(offer, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), enterprisePlanID.String()).Return(
+			model.Product{ID: enterprisePlanID, TotalPrice: 55}, nil)
+
+		err := service.AcceptRetentionOffer(context.Background(), subscription.ID.String(), 0, offer.ID.String())
+		assert.ErrorContains(t, err, "is not available for the subscription")
+	})
+
+	t.Run("version conflict", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now), config: DefaultConfig()}
+
+		subscription := activeSubscription()
+		subscription.Version = 3
+
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+
+		err := service.AcceptRetentionOffer(context.Background(), subscription.ID.String(), 2, uuid.NewString())
+		assert.ErrorIs(t, err, model.ErrSubscriptionConflict)
+	})
+}
@@ -9,11 +9,16 @@ import (
 	"gymondo/internal/model"
 )
 
+// Subscribe subscribes the user to the product. Without a start date, or
+// with today's, the subscription starts right away; a later start date
+// schedules it, and it is charged and grants access from that day on.
 func (s *Service) Subscribe(
 	ctx context.Context,
 	userID string,
 	productID string,
 	voucherCode string,
+	referralCode string,
+	startDate *time.Time,
 	trialPeriod bool,
 ) (string, error) {
 	user, err := s.repository.GetUser(ctx, userID)
@@ -26,21 +31,41 @@ func (s *Service) Subscribe(
 		return "", fmt.Errorf("failed to fetch product: %w", err)
 	}
 
-	startDate := time.Now().Truncate(24 * time.Hour)
-	endDate := time.Now().AddDate(0, 0, product.DurationDays).Truncate(24 * time.Hour)
+	if product.Kind == model.AddOnProduct {
+		return "", fmt.Errorf("product %s is an add-on and can only be attached to a subscription", product.Name)
+	}
+	if product.SeatBased {
+		return "", fmt.Errorf("product %s can only be subscribed to by an organization", product.Name)
+	}
+
+	location := model.LoadLocation(user.TimeZone)
+	today := s.today(location)
+	start, status := today, model.Active
+	if startDate != nil {
+		start = model.DateIn(*startDate, location)
+		if start.Before(today) {
+			return "", fmt.Errorf("subscription can't start in the past")
+		}
+		if start.After(today) {
+			status = model.Scheduled
+		}
+	}
+	endDate := start.AddDate(0, 0, product.DurationDays)
 
-	subscriptionID := uuid.New()
 	subscription := model.Subscription{
-		ID:           subscriptionID,
+		ID:           uuid.New(),
 		UserID:       user.ID,
 		ProductID:    product.ID,
-		StartDate:    startDate,
+		StartDate:    start,
 		EndDate:      endDate,
 		DurationDays: product.DurationDays,
 		Price:        product.Price,
 		Tax:          product.Tax,
 		TotalPrice:   product.TotalPrice,
-		Status:       model.Active,
+		Status:       status,
+		Version:      1,
+		TimeZone:     location.String(),
+		Seats:        1,
 	}
 
 	if voucherCode != "" {
@@ -59,16 +84,85 @@ func (s *Service) Subscribe(
 		subscription.TotalPrice = productWithVoucher.TotalPrice
 	}
 	if trialPeriod {
-		subscription.TrialStartDate = &startDate
-		trialEndDate := time.Now().AddDate(0, 0, 30).Truncate(24 * time.Hour)
+		subscription.TrialStartDate = &start
+		trialEndDate := start.AddDate(0, 0, 30)
 		subscription.TrialEndDate = &trialEndDate
 	}
 
-	if err := s.repository.SaveSubscription(ctx, subscription); err != nil {
-		return "", fmt.Errorf("failed to save subscription: %w", err)
+	var referral *model.Referral
+	if referralCode != "" {
+		attributed, err := s.attributeReferral(ctx, user, referralCode, subscription.ID)
+		if err != nil {
+			return "", err
+		}
+		referral = &attributed
 	}
 
-	return subscriptionID.String(), nil
+	return s.startSubscription(ctx, user, product, subscription, referral)
+}
+
+// startSubscription charges the first period of the new subscription unless
+// it starts with a trial or later on, stores it together with the referral it was made
+// through, if any, and confirms it to the user.
+func (s *Service) startSubscription(
+	ctx context.Context,
+	user model.User,
+	product model.Product,
+	subscription model.Subscription,
+	referral *model.Referral,
+) (string, error) {
+	message, err := s.newOutboxMessage(model.SubscriptionCreated, subscription)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.withinTx(ctx, func(tx *Service) error {
+		if err := tx.repository.SaveSubscription(ctx, subscription); err != nil {
+			return fmt.Errorf("failed to save subscription: %w", err)
+		}
+		if err := tx.repository.SaveOutboxMessage(ctx, message); err != nil {
+			return fmt.Errorf("failed to save subscription event: %w", err)
+		}
+		if referral != nil {
+			if err := tx.repository.SaveReferral(ctx, *referral); err != nil {
+				return fmt.Errorf("failed to save referral: %w", err)
+			}
+		}
+
+		// a trial defers the first charge to the first renewal, a scheduled
+		// start to the day the subscription is activated. The charge is made
+		// once the subscription is stored, so a subscription that can't be
+		// stored is never charged.
+		if subscription.TrialEndDate != nil || subscription.Status == model.Scheduled {
+			return nil
+		}
+
+		attempt, err := tx.attemptPayment(ctx, subscription, subscription.TotalPrice, 1)
+		if err != nil {
+			return fmt.Errorf("failed to charge subscription: %w", err)
+		}
+		if attempt.Status == model.PaymentFailed {
+			return fmt.Errorf("failed to charge subscription: %s", attempt.FailureReason)
+		}
+
+		if err := tx.savePaymentAttempt(ctx, attempt); err != nil {
+			return err
+		}
+
+		if _, err := tx.issueInvoice(ctx, user, product.Name, subscription); err != nil {
+			return fmt.Errorf("failed to issue invoice: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	s.entitlements.invalidate(subscription.UserID.String())
+
+	s.notify(ctx, model.NotificationSubscriptionConfirmation, user, product.Name, subscription)
+
+	return subscription.ID.String(), nil
 }
 
 func (s *Service) FindSubscription(ctx context.Context, subscriptionID string) (model.Subscription, error) {
@@ -80,30 +174,68 @@ func (s *Service) FindSubscription(ctx context.Context, subscriptionID string) (
 	return subscription, nil
 }
 
-func (s *Service) PauseSubscription(ctx context.Context, subscriptionID string) error {
+// PauseSubscription pauses the subscription as the schedule says. A pause
+// starting today is applied right away, a later one is scheduled and
+// replaces any pause scheduled before. For a paused subscription only the
+// resume date can be changed.
+func (s *Service) PauseSubscription(
+	ctx context.Context,
+	subscriptionID string,
+	version int,
+	schedule model.PauseSchedule,
+) error {
 	subscription, err := s.repository.GetSubscription(ctx, subscriptionID)
 	if err != nil {
 		return fmt.Errorf("failed to find subscription with ID %s: %w", subscriptionID, err)
 	}
+	if err := checkVersion(subscription, version); err != nil {
+		return err
+	}
 
 	switch subscription.Status {
 	case model.Paused:
-		return fmt.Errorf("subscription is already paused")
+		return s.rescheduleResume(ctx, subscription, schedule)
 	case model.Canceled:
 		return fmt.Errorf("subscription is canceled")
+	case model.PastDue:
+		return fmt.Errorf("subscription is past due")
+	case model.Scheduled:
+		return fmt.Errorf("subscription has not started yet")
+	}
+
+	today := s.today(subscription.Location())
+	pauseFrom, resumeOn, err := pauseDates(subscription, schedule, today)
+	if err != nil {
+		return err
 	}
 
 	if subscription.TrialEndDate != nil {
-		if subscription.TrialEndDate.After(time.Now().Truncate(24 * time.Hour)) {
+		if subscription.TrialEndDate.After(pauseFrom) {
 			return fmt.Errorf("can't pause subscription during trial period")
 		}
 	}
 
+	resumeOn, err = s.checkPausePolicy(ctx, subscription, pauseFrom, resumeOn)
+	if err != nil {
+		return err
+	}
+	subscription.ResumeOn = resumeOn
+	if pauseFrom.After(today) {
+		subscription.PauseFrom = &pauseFrom
+
+		err = s.updateSubscriptionWithEvent(ctx, model.SubscriptionPauseScheduled, subscription)
+		if err != nil {
+			return fmt.Errorf("failed to schedule pause: %w", err)
+		}
+
+		return nil
+	}
+
 	subscription.Status = model.Paused
-	pausedDate := time.Now().Truncate(24 * time.Hour)
-	subscription.PausedDate = &pausedDate
+	subscription.PausedDate = &today
+	subscription.PauseFrom = nil
 
-	err = s.repository.UpdateSubscription(ctx, subscription)
+	err = s.startPause(ctx, subscription)
 	if err != nil {
 		return fmt.Errorf("failed to pause subscription: %w", err)
 	}
@@ -111,24 +243,32 @@ func (s *Service) PauseSubscription(ctx context.Context, subscriptionID string)
 	return nil
 }
 
-func (s *Service) UnpauseSubscription(ctx context.Context, subscriptionID string) error {
+func (s *Service) UnpauseSubscription(ctx context.Context, subscriptionID string, version int) error {
 	subscription, err := s.repository.GetSubscription(ctx, subscriptionID)
 	if err != nil {
 		return fmt.Errorf("failed to find subscription with ID %s: %w", subscriptionID, err)
 	}
+	if err := checkVersion(subscription, version); err != nil {
+		return err
+	}
 
 	switch subscription.Status {
 	case model.Active:
 		return fmt.Errorf("subscription is already active")
 	case model.Canceled:
 		return fmt.Errorf("subscription is canceled")
+	case model.PastDue:
+		return fmt.Errorf("subscription is past due")
+	case model.Scheduled:
+		return fmt.Errorf("subscription has not started yet")
 	}
 
 	subscription.Status = model.Active
-	unpausedDate := time.Now().Truncate(24 * time.Hour)
+	unpausedDate := s.today(subscription.Location())
 	subscription.UnpausedDate = &unpausedDate
+	subscription.ResumeOn = nil
 
-	err = s.repository.UpdateSubscription(ctx, subscription)
+	err = s.endPause(ctx, subscription)
 	if err != nil {
 		return fmt.Errorf("failed to pause subscription: %w", err)
 	}
@@ -136,27 +276,155 @@ func (s *Service) UnpauseSubscription(ctx context.Context, subscriptionID string
 	return nil
 }
 
-func (s *Service) CancelSubscription(ctx context.Context, subscriptionID string) error {
+// CancelSubscription cancels the subscription, refunds what the refund
+// policy of its product allows and stores the survey answers given for the
+// cancellation. Past due subscriptions are never refunded since their
+// current period was not paid, scheduled ones since they were not charged
+// yet. The refund is recorded together with the cancellation; whether the
+// payment gateway paid it back is reported through its status, not as an
+// error.
+func (s *Service) CancelSubscription(
+	ctx context.Context,
+	subscriptionID string,
+	version int,
+	survey model.CancellationSurvey,
+) (model.Refund, error) {
+	if survey.Reason != "" && !survey.Reason.Valid() {
+		return model.Refund{}, fmt.Errorf("unknown cancellation reason %s", survey.Reason)
+	}
+
 	subscription, err := s.repository.GetSubscription(ctx, subscriptionID)
 	if err != nil {
-		return fmt.Errorf("failed to find subscription with ID %s: %w", subscriptionID, err)
+		return model.Refund{}, fmt.Errorf("failed to find subscription with ID %s: %w", subscriptionID, err)
+	}
+	if err := checkVersion(subscription, version); err != nil {
+		return model.Refund{}, err
 	}
 
 	switch subscription.Status {
 	case model.Paused:
-		return fmt.Errorf("subscription is paused")
+		return model.Refund{}, fmt.Errorf("subscription is paused")
 	case model.Canceled:
-		return fmt.Errorf("subscription is already canceled")
+		return model.Refund{}, fmt.Errorf("subscription is already canceled")
 	}
 
+	unpaid := subscription.Status == model.PastDue || subscription.Status == model.Scheduled
+
 	subscription.Status = model.Canceled
-	canceledDate := time.Now().Truncate(24 * time.Hour)
+	canceledDate := s.today(subscription.Location())
 	subscription.CanceledDate = &canceledDate
+	subscription.NextPaymentRetryDate = nil
+	subscription.PauseFrom = nil
+	subscription.ResumeOn = nil
+
+	cancellation := model.Cancellation{
+		ID:             uuid.New(),
+		SubscriptionID: subscription.ID,
+		Reason:         survey.Reason,
+		ReasonText:     survey.Text,
+		CanceledAt:     s.now(),
+	}
+
+	var pending pendingRefund
+	err = s.withinTx(ctx, func(tx *Service) error {
+		if err := tx.updateSubscriptionWithEvent(ctx, model.SubscriptionCanceled, subscription); err != nil {
+			return err
+		}
+		if err := tx.repository.SaveCancellation(ctx, cancellation); err != nil {
+			return err
+		}
+		if unpaid {
+			return nil
+		}
 
-	err = s.repository.UpdateSubscription(ctx, subscription)
+		pending, err = tx.recordRefund(ctx, subscription, canceledDate)
+		if err != nil {
+			return fmt.Errorf("failed to refund subscription: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to pause subscription: %w", err)
+		return model.Refund{}, fmt.Errorf("failed to cancel subscription: %w", err)
+	}
+
+	refund := s.settleRefund(ctx, pending)
+
+	s.notifySubscription(ctx, model.NotificationCancellationConfirmation, subscription)
+
+	return refund, nil
+}
+
+// checkVersion fails with model.ErrSubscriptionConflict when the caller
+// expects a version other than the current one. A version of 0 expects none.
+func checkVersion(subscription model.Subscription, version int) error {
+	if version != 0 && version != subscription.Version {
+		return fmt.Errorf("subscription is at version %d, not %d: %w",
+			subscription.Version, version, model.ErrSubscriptionConflict)
+	}
+	return nil
+}
+
+// lockSubscription locks the subscription row for the rest of the
+// transaction and fails with model.ErrSubscriptionConflict when the
+// subscription was changed since it was read. Charges are made only after
+// taking the lock, so a concurrent change can't discard a payment that was
+// already taken.
+func (s *Service) lockSubscription(ctx context.Context, subscription model.Subscription) error {
+	locked, err := s.repository.LockSubscription(ctx, subscription.ID.String())
+	if err != nil {
+		return fmt.Errorf("failed to lock subscription: %w", err)
+	}
+	if locked.Version != subscription.Version {
+		return fmt.Errorf("subscription is at version %d, not %d: %w",
+			locked.Version, subscription.Version, model.ErrSubscriptionConflict)
+	}
+	return nil
+}
+
+// updateSubscriptionWithEvent stores the subscription change together with
+// the outbox message announcing it. The message carries the version the
+// subscription has after the update.
+func (s *Service) updateSubscriptionWithEvent(
+	ctx context.Context,
+	eventType model.WebhookEventType,
+	subscription model.Subscription,
+) error {
+	updated := subscription
+	updated.Version++
+
+	message, err := s.newOutboxMessage(eventType, updated)
+	if err != nil {
+		return err
 	}
 
+	err = s.repository.WithinTx(ctx, func(repo Repository) error {
+		if err := repo.UpdateSubscription(ctx, subscription); err != nil {
+			return err
+		}
+		return repo.SaveOutboxMessage(ctx, message)
+	})
+	if err != nil {
+		return err
+	}
+
+	// members derive their entitlements from the subscription
+	s.entitlements.invalidate(subscription.UserID.String())
+	for _, member := range subscription.Members {
+		if member.Status == model.MemberActive {
+			s.entitlements.invalidate(member.UserID.String())
+		}
+	}
 	return nil
 }
+
+func (s *Service) FindPaymentAttempts(ctx context.Context, subscriptionID string) ([]model.PaymentAttempt, error) {
+	attempts, err := s.repository.GetPaymentAttempts(ctx, subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch payment attempts for subscription %s: %w", subscriptionID, err)
+	}
+	if attempts == nil {
+		return []model.PaymentAttempt{}, nil
+	}
+
+	return attempts, nil
+}
@@ -2,16 +2,31 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
+	"gymondo/internal/clock"
 	"gymondo/internal/model"
 	"testing"
 	"time"
 )
 
+// expectWithinTx lets WithinTx run its function directly on the mock.
+func expectWithinTx(mockRepo *MockRepository) {
+	mockRepo.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, fn func(repo Repository) error) error {
+			return fn(mockRepo)
+		},
+	).AnyTimes()
+}
+
+func expectLockSubscription(mockRepo *MockRepository, subscription model.Subscription) {
+	mockRepo.EXPECT().LockSubscription(gomock.Any(), subscription.ID.String()).Return(subscription, nil)
+}
+
 func Test_Service_Subscribe(t *testing.T) {
 	t.Parallel()
 
@@ -22,7 +37,8 @@ func Test_Service_Subscribe(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := NewMockRepository(ctrl)
-		service := &Service{repository: mockRepo}
+		mockPayments := NewMockPaymentGateway(ctrl)
+		service := &Service{repository: mockRepo, payments: mockPayments}
 
 		userID := uuid.New().String()
 		productID := uuid.New().String()
@@ -30,7 +46,7 @@ func Test_Service_Subscribe(t *testing.T) {
 		mockRepo.EXPECT().GetUser(gomock.Any(), userID).Return(model.User{}, fmt.Errorf("database error"))
 
 		expectedError := "failed to fetch user"
-		_, err := service.Subscribe(context.Background(), userID, productID, "", false)
+		_, err := service.Subscribe(context.Background(), userID, productID, "", "", nil, false)
 		assert.Errorf(t, err, expectedError)
 	})
 
@@ -41,7 +57,8 @@ func Test_Service_Subscribe(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := NewMockRepository(ctrl)
-		service := &Service{repository: mockRepo}
+		mockPayments := NewMockPaymentGateway(ctrl)
+		service := &Service{repository: mockRepo, payments: mockPayments}
 
 		userID := uuid.New()
 		productID := uuid.New().String()
@@ -50,7 +67,7 @@ func Test_Service_Subscribe(t *testing.T) {
 		mockRepo.EXPECT().GetProduct(gomock.Any(), productID).Return(model.Product{}, fmt.Errorf("database error"))
 
 		expectedError := "failed to fetch product"
-		_, err := service.Subscribe(context.Background(), userID.String(), productID, "", false)
+		_, err := service.Subscribe(context.Background(), userID.String(), productID, "", "", nil, false)
 		assert.Errorf(t, err, expectedError)
 	})
 
@@ -61,7 +78,8 @@ func Test_Service_Subscribe(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := NewMockRepository(ctrl)
-		service := &Service{repository: mockRepo}
+		mockPayments := NewMockPaymentGateway(ctrl)
+		service := &Service{repository: mockRepo, payments: mockPayments}
 
 		userID := uuid.New()
 		productID := uuid.New()
@@ -72,7 +90,7 @@ func Test_Service_Subscribe(t *testing.T) {
 		mockRepo.EXPECT().GetVoucherByCode(gomock.Any(), voucherCode).Return(model.Voucher{}, fmt.Errorf("voucher not found"))
 
 		expectedError := "failed to fetch voucher"
-		_, err := service.Subscribe(context.Background(), userID.String(), productID.String(), voucherCode, false)
+		_, err := service.Subscribe(context.Background(), userID.String(), productID.String(), voucherCode, "", nil, false)
 		assert.Errorf(t, err, expectedError)
 	})
 
@@ -83,16 +101,44 @@ func Test_Service_Subscribe(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := NewMockRepository(ctrl)
-		service := &Service{repository: mockRepo}
+		mockPayments := NewMockPaymentGateway(ctrl)
+		mockNotifier := NewMockNotifier(ctrl)
+		service := &Service{repository: mockRepo, payments: mockPayments, notifier: mockNotifier}
 
 		userID := uuid.New()
 		productID := uuid.New()
 
 		mockRepo.EXPECT().GetUser(gomock.Any(), userID.String()).Return(model.User{ID: userID}, nil)
 		mockRepo.EXPECT().GetProduct(gomock.Any(), productID.String()).Return(model.Product{ID: productID, DurationDays: 30, Price: 100, Tax: 10, TotalPrice: 110}, nil)
+		mockRepo.EXPECT().GetCreditBalance(gomock.Any(), userID.String()).Return(0.0, nil)
+		mockPayments.EXPECT().Charge(gomock.Any(), userID, 110.0, gomock.Any()).Return("tx-1", nil)
+		expectWithinTx(mockRepo)
 		mockRepo.EXPECT().SaveSubscription(gomock.Any(), gomock.Any()).Return(nil)
-
-		subscriptionID, err := service.Subscribe(context.Background(), userID.String(), productID.String(), "", false)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().SavePaymentAttempt(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, attempt model.PaymentAttempt) error {
+				assert.Equal(t, model.PaymentSucceeded, attempt.Status)
+				assert.Equal(t, "tx-1", attempt.TransactionID)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SaveInvoice(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, invoice model.Invoice) (model.Invoice, error) {
+				assert.Equal(t, 110.0, invoice.TotalAmount)
+				assert.Len(t, invoice.LineItems, 1)
+				return invoice, nil
+			},
+		)
+
+		mockNotifier.EXPECT().Notify(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, notification model.Notification) error {
+				assert.Equal(t, model.NotificationSubscriptionConfirmation, notification.Kind)
+				assert.Equal(t, userID, notification.User.ID)
+				return nil
+			},
+		)
+
+		subscriptionID, err := service.Subscribe(context.Background(), userID.String(), productID.String(), "", "", nil, false)
 		assert.NoError(t, err)
 		assert.NotEmpty(t, subscriptionID)
 	})
@@ -104,7 +150,9 @@ func Test_Service_Subscribe(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := NewMockRepository(ctrl)
-		service := &Service{repository: mockRepo}
+		mockPayments := NewMockPaymentGateway(ctrl)
+		mockNotifier := NewMockNotifier(ctrl)
+		service := &Service{repository: mockRepo, payments: mockPayments, notifier: mockNotifier}
 
 		userID := uuid.New()
 		productID := uuid.New()
@@ -113,9 +161,28 @@ func Test_Service_Subscribe(t *testing.T) {
 		mockRepo.EXPECT().GetUser(gomock.Any(), userID.String()).Return(model.User{ID: userID}, nil)
 		mockRepo.EXPECT().GetProduct(gomock.Any(), productID.String()).Return(model.Product{ID: productID, DurationDays: 30, Price: 100, Tax: 10, TotalPrice: 110}, nil)
 		mockRepo.EXPECT().GetVoucherByCode(gomock.Any(), voucherCode).Return(model.Voucher{DiscountType: model.Fixed, DiscountValue: 10}, nil)
+		mockRepo.EXPECT().GetCreditBalance(gomock.Any(), userID.String()).Return(0.0, nil)
+		mockPayments.EXPECT().Charge(gomock.Any(), userID, 100.0, gomock.Any()).Return("tx-1", nil)
+		expectWithinTx(mockRepo)
 		mockRepo.EXPECT().SaveSubscription(gomock.Any(), gomock.Any()).Return(nil)
-
-		subscriptionID, err := service.Subscribe(context.Background(), userID.String(), productID.String(), voucherCode, false)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().SavePaymentAttempt(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().SaveInvoice(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, invoice model.Invoice) (model.Invoice, error) {
+				assert.Equal(t, 100.0, invoice.TotalAmount)
+				return invoice, nil
+			},
+		)
+
+		mockNotifier.EXPECT().Notify(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, notification model.Notification) error {
+				assert.Equal(t, model.NotificationSubscriptionConfirmation, notification.Kind)
+				assert.Equal(t, userID, notification.User.ID)
+				return nil
+			},
+		)
+
+		subscriptionID, err := service.Subscribe(context.Background(), userID.String(), productID.String(), voucherCode, "", nil, false)
 		assert.NoError(t, err)
 		assert.NotEmpty(t, subscriptionID)
 	})
@@ -127,19 +194,216 @@ func Test_Service_Subscribe(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := NewMockRepository(ctrl)
-		service := &Service{repository: mockRepo}
+		mockPayments := NewMockPaymentGateway(ctrl)
+		mockNotifier := NewMockNotifier(ctrl)
+		service := &Service{repository: mockRepo, payments: mockPayments, notifier: mockNotifier}
 
 		userID := uuid.New()
 		productID := uuid.New()
 
 		mockRepo.EXPECT().GetUser(gomock.Any(), userID.String()).Return(model.User{ID: userID}, nil)
 		mockRepo.EXPECT().GetProduct(gomock.Any(), productID.String()).Return(model.Product{ID: productID, DurationDays: 30, Price: 100, Tax: 10, TotalPrice: 110}, nil)
+		expectWithinTx(mockRepo)
 		mockRepo.EXPECT().SaveSubscription(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).Return(nil)
 
-		subscriptionID, err := service.Subscribe(context.Background(), userID.String(), productID.String(), "", true)
+		mockNotifier.EXPECT().Notify(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, notification model.Notification) error {
+				assert.Equal(t, model.NotificationSubscriptionConfirmation, notification.Kind)
+				assert.Equal(t, userID, notification.User.ID)
+				return nil
+			},
+		)
+
+		subscriptionID, err := service.Subscribe(context.Background(), userID.String(), productID.String(), "", "", nil, true)
 		assert.NoError(t, err)
 		assert.NotEmpty(t, subscriptionID)
 	})
+
+	t.Run("dates start at midnight in the time zone of the user", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		berlin, err := time.LoadLocation("Europe/Berlin")
+		assert.NoError(t, err)
+
+		mockRepo := NewMockRepository(ctrl)
+		mockNotifier := NewMockNotifier(ctrl)
+		// 00:30 in Berlin is still the previous day in UTC
+		now := time.Date(2024, time.March, 15, 0, 30, 0, 0, berlin).UTC()
+		service := &Service{repository: mockRepo, notifier: mockNotifier, clock: clock.Fixed(now)}
+
+		userID := uuid.New()
+		productID := uuid.New()
+
+		mockRepo.EXPECT().GetUser(gomock.Any(), userID.String()).Return(model.User{ID: userID, TimeZone: "Europe/Berlin"}, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), productID.String()).Return(model.Product{ID: productID, DurationDays: 30, Price: 100, Tax: 10, TotalPrice: 110}, nil)
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().SaveSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, subscription model.Subscription) error {
+				assert.Equal(t, "Europe/Berlin", subscription.TimeZone)
+				assert.Equal(t, time.Date(2024, time.March, 15, 0, 0, 0, 0, berlin), subscription.StartDate)
+				// the period spans the switch to summer time
+				assert.Equal(t, time.Date(2024, time.April, 14, 0, 0, 0, 0, berlin), subscription.EndDate)
+				assert.Equal(t, time.Date(2024, time.April, 14, 0, 0, 0, 0, berlin), *subscription.TrialEndDate)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).Return(nil)
+		mockNotifier.EXPECT().Notify(gomock.Any(), gomock.Any()).Return(nil)
+
+		_, err = service.Subscribe(context.Background(), userID.String(), productID.String(), "", "", nil, true)
+		assert.NoError(t, err)
+	})
+
+	t.Run("later start date schedules the subscription without a charge", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockNotifier := NewMockNotifier(ctrl)
+		now := time.Date(2024, time.December, 10, 12, 0, 0, 0, time.UTC)
+		service := &Service{repository: mockRepo, notifier: mockNotifier, clock: clock.Fixed(now)}
+
+		userID := uuid.New()
+		productID := uuid.New()
+		startDate := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+		mockRepo.EXPECT().GetUser(gomock.Any(), userID.String()).Return(model.User{ID: userID, TimeZone: "UTC"}, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), productID.String()).Return(model.Product{ID: productID, DurationDays: 30, Price: 100, Tax: 10, TotalPrice: 110}, nil)
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().SaveSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, subscription model.Subscription) error {
+				assert.Equal(t, model.Scheduled, subscription.Status)
+				assert.Equal(t, startDate, subscription.StartDate)
+				assert.Equal(t, startDate.AddDate(0, 0, 30), subscription.EndDate)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).Return(nil)
+		mockNotifier.EXPECT().Notify(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, notification model.Notification) error {
+				assert.Equal(t, model.NotificationSubscriptionConfirmation, notification.Kind)
+				assert.Equal(t, model.Scheduled, notification.Subscription.Status)
+				return nil
+			},
+		)
+
+		_, err := service.Subscribe(context.Background(), userID.String(), productID.String(), "", "", &startDate, false)
+		assert.NoError(t, err)
+	})
+
+	t.Run("start date of today starts right away", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockNotifier := NewMockNotifier(ctrl)
+		now := time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC)
+		service := &Service{repository: mockRepo, notifier: mockNotifier, clock: clock.Fixed(now)}
+
+		userID := uuid.New()
+		productID := uuid.New()
+		startDate := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+		mockRepo.EXPECT().GetUser(gomock.Any(), userID.String()).Return(model.User{ID: userID, TimeZone: "UTC"}, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), productID.String()).Return(model.Product{ID: productID, DurationDays: 30, Price: 100, Tax: 10, TotalPrice: 110}, nil)
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().SaveSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, subscription model.Subscription) error {
+				assert.Equal(t, model.Active, subscription.Status)
+				assert.Equal(t, startDate, *subscription.TrialStartDate)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).Return(nil)
+		mockNotifier.EXPECT().Notify(gomock.Any(), gomock.Any()).Return(nil)
+
+		_, err := service.Subscribe(context.Background(), userID.String(), productID.String(), "", "", &startDate, true)
+		assert.NoError(t, err)
+	})
+
+	t.Run("start date in the past", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		now := time.Date(2025, time.January, 2, 12, 0, 0, 0, time.UTC)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now)}
+
+		userID := uuid.New()
+		productID := uuid.New()
+		startDate := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+		mockRepo.EXPECT().GetUser(gomock.Any(), userID.String()).Return(model.User{ID: userID, TimeZone: "UTC"}, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), productID.String()).Return(model.Product{ID: productID, DurationDays: 30}, nil)
+
+		_, err := service.Subscribe(context.Background(), userID.String(), productID.String(), "", "", &startDate, false)
+		assert.EqualError(t, err, "subscription can't start in the past")
+	})
+
+	t.Run("payment declined", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockPayments := NewMockPaymentGateway(ctrl)
+		service := &Service{repository: mockRepo, payments: mockPayments}
+
+		userID := uuid.New()
+		productID := uuid.New()
+
+		mockRepo.EXPECT().GetUser(gomock.Any(), userID.String()).Return(model.User{ID: userID}, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), productID.String()).Return(model.Product{ID: productID, DurationDays: 30, Price: 100, Tax: 10, TotalPrice: 110}, nil)
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().SaveSubscription(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().GetCreditBalance(gomock.Any(), userID.String()).Return(0.0, nil)
+		mockPayments.EXPECT().Charge(gomock.Any(), userID, 110.0, gomock.Any()).Return("", errors.New("card declined"))
+
+		_, err := service.Subscribe(context.Background(), userID.String(), productID.String(), "", "", nil, false)
+		assert.EqualError(t, err, "failed to charge subscription: card declined")
+	})
+
+	t.Run("failed event write aborts the transaction before the charge", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockPayments := NewMockPaymentGateway(ctrl)
+		service := &Service{repository: mockRepo, payments: mockPayments}
+
+		userID := uuid.New()
+		productID := uuid.New()
+		expectedError := errors.New("test error")
+
+		mockRepo.EXPECT().GetUser(gomock.Any(), userID.String()).Return(model.User{ID: userID}, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), productID.String()).Return(model.Product{ID: productID, DurationDays: 30, Price: 100, Tax: 10, TotalPrice: 110}, nil)
+		mockRepo.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, fn func(repo Repository) error) error {
+				err := fn(mockRepo)
+				assert.ErrorIs(t, err, expectedError)
+				return err
+			},
+		)
+		mockRepo.EXPECT().SaveSubscription(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).Return(expectedError)
+
+		_, err := service.Subscribe(context.Background(), userID.String(), productID.String(), "", "", nil, false)
+		assert.ErrorIs(t, err, expectedError)
+	})
 }
 
 func Test_Service_FindSubscription(t *testing.T) {
@@ -200,7 +464,7 @@ func Test_Service_PauseSubscription(t *testing.T) {
 		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscriptionID.String()).Return(model.Subscription{}, fmt.Errorf("database error"))
 
 		expectedError := "failed to find subscription"
-		err := service.PauseSubscription(context.Background(), subscriptionID.String())
+		err := service.PauseSubscription(context.Background(), subscriptionID.String(), 0, model.PauseSchedule{})
 		assert.Errorf(t, err, expectedError)
 	})
 
@@ -221,7 +485,7 @@ func Test_Service_PauseSubscription(t *testing.T) {
 		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscriptionID.String()).Return(subscription, nil)
 
 		expectedError := "subscription is already paused"
-		err := service.PauseSubscription(context.Background(), subscriptionID.String())
+		err := service.PauseSubscription(context.Background(), subscriptionID.String(), 0, model.PauseSchedule{})
 		assert.EqualError(t, err, expectedError)
 	})
 
@@ -242,7 +506,48 @@ func Test_Service_PauseSubscription(t *testing.T) {
 		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscriptionID.String()).Return(subscription, nil)
 
 		expectedError := "subscription is canceled"
-		err := service.PauseSubscription(context.Background(), subscriptionID.String())
+		err := service.PauseSubscription(context.Background(), subscriptionID.String(), 0, model.PauseSchedule{})
+		assert.EqualError(t, err, expectedError)
+	})
+
+	t.Run("subscription has not started yet", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo}
+
+		subscriptionID := uuid.New()
+		subscription := model.Subscription{
+			ID:     subscriptionID,
+			Status: model.Scheduled,
+		}
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscriptionID.String()).Return(subscription, nil)
+
+		err := service.PauseSubscription(context.Background(), subscriptionID.String(), 0, model.PauseSchedule{})
+		assert.EqualError(t, err, "subscription has not started yet")
+	})
+
+	t.Run("subscription is past due", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo}
+
+		subscriptionID := uuid.New()
+		subscription := model.Subscription{
+			ID:     subscriptionID,
+			Status: model.PastDue,
+		}
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscriptionID.String()).Return(subscription, nil)
+
+		expectedError := "subscription is past due"
+		err := service.PauseSubscription(context.Background(), subscriptionID.String(), 0, model.PauseSchedule{})
 		assert.EqualError(t, err, expectedError)
 	})
 
@@ -262,9 +567,19 @@ func Test_Service_PauseSubscription(t *testing.T) {
 		}
 
 		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscriptionID.String()).Return(subscription, nil)
+		expectPausePolicy(mockRepo, model.PausePolicy{})
+		expectWithinTx(mockRepo)
 		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).Return(nil)
-
-		err := service.PauseSubscription(context.Background(), subscriptionID.String())
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, message model.OutboxMessage) error {
+				assert.Equal(t, model.SubscriptionPaused, message.Type)
+				assert.Equal(t, subscriptionID, message.SubscriptionID)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SavePause(gomock.Any(), gomock.Any()).Return(nil)
+
+		err := service.PauseSubscription(context.Background(), subscriptionID.String(), 0, model.PauseSchedule{})
 		assert.NoError(t, err)
 	})
 
@@ -284,11 +599,13 @@ func Test_Service_PauseSubscription(t *testing.T) {
 		}
 
 		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscriptionID.String()).Return(subscription, nil)
+		expectPausePolicy(mockRepo, model.PausePolicy{})
 
 		expectedError := errors.New("test error")
+		expectWithinTx(mockRepo)
 		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).Return(expectedError)
 
-		err := service.PauseSubscription(context.Background(), subscriptionID.String())
+		err := service.PauseSubscription(context.Background(), subscriptionID.String(), 0, model.PauseSchedule{})
 		assert.ErrorIs(t, err, expectedError)
 	})
 
@@ -311,9 +628,130 @@ func Test_Service_PauseSubscription(t *testing.T) {
 		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscriptionID.String()).Return(subscription, nil)
 
 		expectedError := errors.New("can't pause subscription during trial period")
-		err := service.PauseSubscription(context.Background(), subscriptionID.String())
+		err := service.PauseSubscription(context.Background(), subscriptionID.String(), 0, model.PauseSchedule{})
 		assert.EqualError(t, err, expectedError.Error())
 	})
+
+	t.Run("trial ended on the clock of the service", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		trialEndDate := time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC)
+		now := trialEndDate.Add(26 * time.Hour)
+		service := &Service{repository: mockRepo, clock: clock.Fixed(now)}
+
+		subscriptionID := uuid.New()
+		subscription := model.Subscription{
+			ID:           subscriptionID,
+			Status:       model.Active,
+			TrialEndDate: &trialEndDate,
+		}
+
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscriptionID.String()).Return(subscription, nil)
+		expectPausePolicy(mockRepo, model.PausePolicy{})
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, subscription model.Subscription) error {
+				assert.Equal(t, trialEndDate.AddDate(0, 0, 1), *subscription.PausedDate)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().SavePause(gomock.Any(), gomock.Any()).Return(nil)
+
+		err := service.PauseSubscription(context.Background(), subscriptionID.String(), 0, model.PauseSchedule{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo}
+
+		subscriptionID := uuid.New()
+		subscription := model.Subscription{
+			ID:      subscriptionID,
+			Status:  model.Active,
+			Version: 4,
+		}
+
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscriptionID.String()).Return(subscription, nil)
+
+		err := service.PauseSubscription(context.Background(), subscriptionID.String(), 3, model.PauseSchedule{})
+		assert.ErrorIs(t, err, model.ErrSubscriptionConflict)
+	})
+
+	t.Run("matching version", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo}
+
+		subscriptionID := uuid.New()
+		subscription := model.Subscription{
+			ID:      subscriptionID,
+			Status:  model.Active,
+			Version: 4,
+		}
+
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscriptionID.String()).Return(subscription, nil)
+		expectPausePolicy(mockRepo, model.PausePolicy{})
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, updated model.Subscription) error {
+				assert.Equal(t, 4, updated.Version)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, message model.OutboxMessage) error {
+				var payload webhookPayload
+				assert.NoError(t, json.Unmarshal(message.Payload, &payload))
+				assert.Equal(t, 5, payload.Data.Subscription.Version)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SavePause(gomock.Any(), gomock.Any()).Return(nil)
+
+		err := service.PauseSubscription(context.Background(), subscriptionID.String(), 4, model.PauseSchedule{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("concurrent update", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo}
+
+		subscriptionID := uuid.New()
+		subscription := model.Subscription{
+			ID:      subscriptionID,
+			Status:  model.Active,
+			Version: 1,
+		}
+
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscriptionID.String()).Return(subscription, nil)
+		expectPausePolicy(mockRepo, model.PausePolicy{})
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).
+			Return(fmt.Errorf("failed to update subscription: %w", model.ErrSubscriptionConflict))
+
+		err := service.PauseSubscription(context.Background(), subscriptionID.String(), 0, model.PauseSchedule{})
+		assert.ErrorIs(t, err, model.ErrSubscriptionConflict)
+	})
 }
 
 func Test_Service_UnpauseSubscription(t *testing.T) {
@@ -332,7 +770,7 @@ func Test_Service_UnpauseSubscription(t *testing.T) {
 		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscriptionID.String()).Return(model.Subscription{}, fmt.Errorf("database error"))
 
 		expectedError := "failed to find subscription"
-		err := service.UnpauseSubscription(context.Background(), subscriptionID.String())
+		err := service.UnpauseSubscription(context.Background(), subscriptionID.String(), 0)
 		assert.Errorf(t, err, expectedError)
 	})
 
@@ -353,7 +791,7 @@ func Test_Service_UnpauseSubscription(t *testing.T) {
 		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscriptionID.String()).Return(subscription, nil)
 
 		expectedError := "subscription is already active"
-		err := service.UnpauseSubscription(context.Background(), subscriptionID.String())
+		err := service.UnpauseSubscription(context.Background(), subscriptionID.String(), 0)
 		assert.EqualError(t, err, expectedError)
 	})
 
@@ -374,7 +812,7 @@ func Test_Service_UnpauseSubscription(t *testing.T) {
 		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscriptionID.String()).Return(subscription, nil)
 
 		expectedError := "subscription is canceled"
-		err := service.UnpauseSubscription(context.Background(), subscriptionID.String())
+		err := service.UnpauseSubscription(context.Background(), subscriptionID.String(), 0)
 		assert.EqualError(t, err, expectedError)
 	})
 
@@ -394,9 +832,12 @@ func Test_Service_UnpauseSubscription(t *testing.T) {
 		}
 
 		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscriptionID.String()).Return(subscription, nil)
+		expectWithinTx(mockRepo)
 		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().EndPause(gomock.Any(), subscriptionID.String(), gomock.Any()).Return(nil)
 
-		err := service.UnpauseSubscription(context.Background(), subscriptionID.String())
+		err := service.UnpauseSubscription(context.Background(), subscriptionID.String(), 0)
 		assert.NoError(t, err)
 	})
 
@@ -418,9 +859,10 @@ func Test_Service_UnpauseSubscription(t *testing.T) {
 		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscriptionID.String()).Return(subscription, nil)
 
 		expectedError := errors.New("test error")
+		expectWithinTx(mockRepo)
 		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).Return(expectedError)
 
-		err := service.UnpauseSubscription(context.Background(), subscriptionID.String())
+		err := service.UnpauseSubscription(context.Background(), subscriptionID.String(), 0)
 		assert.ErrorIs(t, err, expectedError)
 	})
 }
@@ -441,10 +883,24 @@ func Test_Service_CancelSubscription(t *testing.T) {
 		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscriptionID.String()).Return(model.Subscription{}, fmt.Errorf("database error"))
 
 		expectedError := "failed to find subscription"
-		err := service.CancelSubscription(context.Background(), subscriptionID.String())
+		_, err := service.CancelSubscription(context.Background(), subscriptionID.String(), 0, model.CancellationSurvey{})
 		assert.Errorf(t, err, expectedError)
 	})
 
+	t.Run("unknown cancellation reason", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo}
+
+		survey := model.CancellationSurvey{Reason: "bored"}
+		_, err := service.CancelSubscription(context.Background(), uuid.NewString(), 0, survey)
+		assert.EqualError(t, err, "unknown cancellation reason bored")
+	})
+
 	t.Run("subscription is paused", func(t *testing.T) {
 		t.Parallel()
 
@@ -462,7 +918,7 @@ func Test_Service_CancelSubscription(t *testing.T) {
 		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscriptionID.String()).Return(subscription, nil)
 
 		expectedError := "subscription is paused"
-		err := service.CancelSubscription(context.Background(), subscriptionID.String())
+		_, err := service.CancelSubscription(context.Background(), subscriptionID.String(), 0, model.CancellationSurvey{})
 		assert.EqualError(t, err, expectedError)
 	})
 
@@ -483,7 +939,7 @@ func Test_Service_CancelSubscription(t *testing.T) {
 		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscriptionID.String()).Return(subscription, nil)
 
 		expectedError := "subscription is already canceled"
-		err := service.CancelSubscription(context.Background(), subscriptionID.String())
+		_, err := service.CancelSubscription(context.Background(), subscriptionID.String(), 0, model.CancellationSurvey{})
 		assert.EqualError(t, err, expectedError)
 	})
 
@@ -494,19 +950,84 @@ func Test_Service_CancelSubscription(t *testing.T) {
 		defer ctrl.Finish()
 
 		mockRepo := NewMockRepository(ctrl)
-		service := &Service{repository: mockRepo}
+		mockNotifier := NewMockNotifier(ctrl)
+		service := &Service{repository: mockRepo, notifier: mockNotifier}
 
 		subscriptionID := uuid.New()
 		subscription := model.Subscription{
 			ID:     subscriptionID,
+			UserID: uuid.New(),
 			Status: model.Active,
 		}
 
 		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscriptionID.String()).Return(subscription, nil)
+		expectWithinTx(mockRepo)
 		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().SaveCancellation(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, cancellation model.Cancellation) error {
+				assert.Equal(t, subscriptionID, cancellation.SubscriptionID)
+				assert.Equal(t, model.TooExpensive, cancellation.Reason)
+				assert.Equal(t, "too pricey", cancellation.ReasonText)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), gomock.Any()).Return(model.Product{RefundPolicy: model.WithdrawalPeriodRefund, WithdrawalPeriodDays: 14}, nil).Times(2)
+		mockRepo.EXPECT().GetSubscriptionInvoices(gomock.Any(), subscriptionID.String()).Return(nil, nil)
+		mockRepo.EXPECT().GetUser(gomock.Any(), subscription.UserID.String()).Return(model.User{ID: subscription.UserID}, nil)
+		mockNotifier.EXPECT().Notify(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, notification model.Notification) error {
+				assert.Equal(t, model.NotificationCancellationConfirmation, notification.Kind)
+				assert.Equal(t, model.Canceled, notification.Subscription.Status)
+				return nil
+			},
+		)
+
+		survey := model.CancellationSurvey{Reason: model.TooExpensive, Text: "too pricey"}
+		refund, err := service.CancelSubscription(context.Background(), subscriptionID.String(), 0, survey)
+		assert.NoError(t, err)
+		assert.Equal(t, model.Refund{}, refund)
+	})
+
+	t.Run("scheduled subscription is canceled without a refund", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockNotifier := NewMockNotifier(ctrl)
+		service := &Service{repository: mockRepo, notifier: mockNotifier}
 
-		err := service.CancelSubscription(context.Background(), subscriptionID.String())
+		subscriptionID := uuid.New()
+		subscription := model.Subscription{
+			ID:     subscriptionID,
+			UserID: uuid.New(),
+			Status: model.Scheduled,
+		}
+
+		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscriptionID.String()).Return(subscription, nil)
+		expectWithinTx(mockRepo)
+		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, canceled model.Subscription) error {
+				assert.Equal(t, model.Canceled, canceled.Status)
+				return nil
+			},
+		)
+		mockRepo.EXPECT().SaveOutboxMessage(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().SaveCancellation(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().GetUser(gomock.Any(), subscription.UserID.String()).Return(model.User{ID: subscription.UserID}, nil)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), subscription.ProductID.String()).Return(model.Product{ID: subscription.ProductID}, nil)
+		mockNotifier.EXPECT().Notify(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, notification model.Notification) error {
+				assert.Equal(t, model.NotificationCancellationConfirmation, notification.Kind)
+				return nil
+			},
+		)
+
+		refund, err := service.CancelSubscription(context.Background(), subscriptionID.String(), 0, model.CancellationSurvey{})
 		assert.NoError(t, err)
+		assert.Equal(t, model.Refund{}, refund)
 	})
 
 	t.Run("fail update subscription", func(t *testing.T) {
@@ -527,9 +1048,10 @@ func Test_Service_CancelSubscription(t *testing.T) {
 		mockRepo.EXPECT().GetSubscription(gomock.Any(), subscriptionID.String()).Return(subscription, nil)
 
 		expectedError := errors.New("test error")
+		expectWithinTx(mockRepo)
 		mockRepo.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any()).Return(expectedError)
 
-		err := service.CancelSubscription(context.Background(), subscriptionID.String())
+		_, err := service.CancelSubscription(context.Background(), subscriptionID.String(), 0, model.CancellationSurvey{})
 		assert.ErrorIs(t, err, expectedError)
 	})
 }
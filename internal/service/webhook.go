@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"gymondo/internal/model"
+)
+
+var webhookEventTypes = map[model.WebhookEventType]bool{
+	model.SubscriptionCreated:  true,
+	model.SubscriptionPaused:   true,
+	model.SubscriptionUnpaused: true,
+	model.SubscriptionCanceled: true,
+
+	model.SubscriptionPauseScheduled: true,
+	model.SubscriptionReactivated:    true,
+	model.SubscriptionUpdated:        true,
+	model.SubscriptionActivated:      true,
+}
+
+// RegisterWebhookEndpoint adds an endpoint that receives the given event
+// types, or every event when none are given. The returned endpoint carries
+// the generated signing secret, which is not exposed again afterwards.
+func (s *Service) RegisterWebhookEndpoint(
+	ctx context.Context,
+	endpointURL string,
+	eventTypes []model.WebhookEventType,
+) (model.WebhookEndpoint, error) {
+	parsedURL, err := url.Parse(endpointURL)
+	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+		return model.WebhookEndpoint{}, fmt.Errorf("invalid webhook url %q", endpointURL)
+	}
+
+	for _, eventType := range eventTypes {
+		if !webhookEventTypes[eventType] {
+			return model.WebhookEndpoint{}, fmt.Errorf("unknown webhook event type %q", eventType)
+		}
+	}
+	if eventTypes == nil {
+		eventTypes = []model.WebhookEventType{}
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return model.WebhookEndpoint{}, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+
+	endpoint := model.WebhookEndpoint{
+		ID:         uuid.New(),
+		URL:        endpointURL,
+		Secret:     secret,
+		EventTypes: eventTypes,
+		Active:     true,
+		CreatedAt:  s.now(),
+	}
+	if err := s.repository.SaveWebhookEndpoint(ctx, endpoint); err != nil {
+		return model.WebhookEndpoint{}, fmt.Errorf("failed to save webhook endpoint: %w", err)
+	}
+
+	return endpoint, nil
+}
+
+func newWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(secret), nil
+}
+
+// FindWebhookEndpoints returns all registered endpoints without their
+// signing secrets.
+func (s *Service) FindWebhookEndpoints(ctx context.Context) ([]model.WebhookEndpoint, error) {
+	endpoints, err := s.repository.GetWebhookEndpoints(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch webhook endpoints: %w", err)
+	}
+
+	for i := range endpoints {
+		endpoints[i].Secret = ""
+	}
+	if endpoints == nil {
+		return []model.WebhookEndpoint{}, nil
+	}
+
+	return endpoints, nil
+}
+
+// DeleteWebhookEndpoint deactivates the endpoint. Its pending deliveries are
+// dead-lettered on their next attempt.
+func (s *Service) DeleteWebhookEndpoint(ctx context.Context, endpointID string) error {
+	if err := s.repository.DeactivateWebhookEndpoint(ctx, endpointID); err != nil {
+		return fmt.Errorf("failed to delete webhook endpoint: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) FindWebhookDeliveries(
+	ctx context.Context,
+	status model.WebhookDeliveryStatus,
+) ([]model.WebhookDelivery, error) {
+	deliveries, err := s.repository.GetWebhookDeliveries(ctx, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch webhook deliveries: %w", err)
+	}
+	if deliveries == nil {
+		return []model.WebhookDelivery{}, nil
+	}
+
+	return deliveries, nil
+}
+
+// ReplayWebhookDelivery queues a delivery again with a fresh set of
+// attempts, whether it was delivered or dead-lettered before.
+func (s *Service) ReplayWebhookDelivery(ctx context.Context, deliveryID string) (model.WebhookDelivery, error) {
+	delivery, err := s.repository.GetWebhookDelivery(ctx, deliveryID)
+	if err != nil {
+		return model.WebhookDelivery{}, fmt.Errorf("failed to fetch webhook delivery: %w", err)
+	}
+
+	now := s.now()
+	delivery.Status = model.WebhookPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = &now
+	delivery.DeliveredAt = nil
+
+	if err := s.repository.UpdateWebhookDelivery(ctx, delivery); err != nil {
+		return model.WebhookDelivery{}, fmt.Errorf("failed to replay webhook delivery: %w", err)
+	}
+
+	return delivery, nil
+}
+
+type webhookPayload struct {
+	ID        uuid.UUID              `json:"id"`
+	Type      model.WebhookEventType `json:"type"`
+	CreatedAt time.Time              `json:"created_at"`
+	Data      webhookPayloadData     `json:"data"`
+}
+
+type webhookPayloadData struct {
+	Subscription model.Subscription `json:"subscription"`
+}
+
+// newOutboxMessage builds the message announcing a subscription event. It is
+// stored in the same transaction as the subscription change and published by
+// the outbox relay.
+func (s *Service) newOutboxMessage(eventType model.WebhookEventType, subscription model.Subscription) (model.OutboxMessage, error) {
+	message := model.OutboxMessage{
+		EventID:        uuid.New(),
+		SubscriptionID: subscription.ID,
+		Type:           eventType,
+		CreatedAt:      s.now(),
+	}
+
+	payload, err := json.Marshal(webhookPayload{
+		ID:        message.EventID,
+		Type:      message.Type,
+		CreatedAt: message.CreatedAt,
+		Data:      webhookPayloadData{Subscription: subscription},
+	})
+	if err != nil {
+		return model.OutboxMessage{}, fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+	message.Payload = payload
+
+	return message, nil
+}
+
+// PublishWebhooks queues a delivery of the outbox message for every active
+// endpoint that receives its event type. Publishing the same message twice
+// does not queue it again.
+func (s *Service) PublishWebhooks(ctx context.Context, message model.OutboxMessage) error {
+	event := model.WebhookEvent{
+		ID:             message.EventID,
+		Type:           message.Type,
+		SubscriptionID: message.SubscriptionID,
+		Payload:        message.Payload,
+		CreatedAt:      message.CreatedAt,
+	}
+
+	endpoints, err := s.repository.GetWebhookEndpoints(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch webhook endpoints: %w", err)
+	}
+
+	now := s.now()
+	var deliveries []model.WebhookDelivery
+	for _, endpoint := range endpoints {
+		if !endpoint.Active || !endpoint.Accepts(event.Type) {
+			continue
+		}
+		deliveries = append(deliveries, model.WebhookDelivery{
+			ID:            uuid.New(),
+			EventID:       event.ID,
+			EndpointID:    endpoint.ID,
+			Status:        model.WebhookPending,
+			NextAttemptAt: &now,
+			CreatedAt:     now,
+		})
+	}
+
+	if err := s.repository.SaveWebhookEvent(ctx, event, deliveries); err != nil {
+		return fmt.Errorf("failed to save webhook event: %w", err)
+	}
+
+	return nil
+}
+
+// DeliverWebhooks sends every pending delivery that is due. Failed deliveries
+// are retried with exponential backoff and dead-lettered once they run out of
+// attempts.
+func (s *Service) DeliverWebhooks(ctx context.Context) error {
+	deliveries, err := s.repository.GetWebhookDeliveriesDue(ctx, s.now())
+	if err != nil {
+		return fmt.Errorf("failed to fetch webhook deliveries due: %w", err)
+	}
+
+	for _, delivery := range deliveries {
+		if err := s.deliverWebhook(ctx, delivery); err != nil {
+			log.Printf("Error delivering webhook %s: %v", delivery.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) deliverWebhook(ctx context.Context, delivery model.WebhookDelivery) error {
+	endpoint, err := s.repository.GetWebhookEndpoint(ctx, delivery.EndpointID.String())
+	if err != nil {
+		return fmt.Errorf("failed to fetch webhook endpoint: %w", err)
+	}
+
+	event, err := s.repository.GetWebhookEvent(ctx, delivery.EventID.String())
+	if err != nil {
+		return fmt.Errorf("failed to fetch webhook event: %w", err)
+	}
+
+	now := s.now()
+	if !endpoint.Active {
+		delivery.Status = model.WebhookDeadLettered
+		delivery.NextAttemptAt = nil
+		delivery.LastError = "endpoint is deactivated"
+	} else if err := s.webhooks.Send(ctx, endpoint, event, delivery.ID); err != nil {
+		delivery.Attempts++
+		delivery.LastError = err.Error()
+		if delivery.Attempts >= s.config.WebhookMaxAttempts {
+			delivery.Status = model.WebhookDeadLettered
+			delivery.NextAttemptAt = nil
+		} else {
+			nextAttemptAt := now.Add(s.webhookRetryDelay(delivery.Attempts))
+			delivery.NextAttemptAt = &nextAttemptAt
+		}
+	} else {
+		delivery.Attempts++
+		delivery.Status = model.WebhookDelivered
+		delivery.NextAttemptAt = nil
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+	}
+
+	if err := s.repository.UpdateWebhookDelivery(ctx, delivery); err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+
+	return nil
+}
+
+// webhookRetryDelay returns the wait after the given number of failed
+// attempts: the configured delay, doubled for every attempt after the first.
+func (s *Service) webhookRetryDelay(failedAttempts int) time.Duration {
+	return s.config.WebhookRetryDelay << (failedAttempts - 1)
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gymondo/internal/clock"
+	"gymondo/internal/model"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Service_RegisterWebhookEndpoint(t *testing.T) {
+	t.Parallel()
+
+	t.Run("invalid url", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo}
+
+		_, err := service.RegisterWebhookEndpoint(context.Background(), "ftp://crm.example.com", nil)
+		assert.ErrorContains(t, err, "invalid webhook url")
+	})
+
+	t.Run("unknown event type", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo}
+
+		_, err := service.RegisterWebhookEndpoint(
+			context.Background(),
+			"https://crm.example.com/hooks",
+			[]model.WebhookEventType{"subscription.renamed"},
+		)
+		assert.ErrorContains(t, err, "unknown webhook event type")
+	})
+
+	t.Run("successful registration", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		service := &Service{repository: mockRepo}
+
+		mockRepo.EXPECT().SaveWebhookEndpoint(gomock.Any(), gomock.Any()).Return(nil)
+
+		endpoint, err := service.RegisterWebhookEndpoint(
+			context.Background(),
+			"https://crm.example.com/hooks",
+			[]model.WebhookEventType{model.SubscriptionCanceled},
+		)
+		assert.NoError(t, err)
+		assert.True(t, endpoint.Active)
+		assert.True(t, strings.HasPrefix(endpoint.Secret, "whsec_"))
+		assert.Equal(t, []model.WebhookEventType{model.SubscriptionCanceled}, endpoint.EventTypes)
+	})
+}
+
+func Test_Service_FindWebhookEndpoints(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	service := &Service{repository: mockRepo}
+
+	mockRepo.EXPECT().GetWebhookEndpoints(gomock.Any()).Return([]model.WebhookEndpoint{{ID: uuid.New(), Secret: "whsec_1"}}, nil)
+
+	endpoints, err := service.FindWebhookEndpoints(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, endpoints, 1)
+	assert.Empty(t, endpoints[0].Secret)
+}
+
+func Test_Service_PublishWebhooks(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2024, time.March, 5, 10, 0, 0, 0, time.UTC)
+	mockRepo := NewMockRepository(ctrl)
+	service := &Service{repository: mockRepo, clock: clock.Fixed(now)}
+
+	subscription := model.Subscription{ID: uuid.New(), Status: model.Paused}
+	receiving := model.WebhookEndpoint{ID: uuid.New(), Active: true}
+	filtered := model.WebhookEndpoint{ID: uuid.New(), Active: true, EventTypes: []model.WebhookEventType{model.SubscriptionCanceled}}
+	inactive := model.WebhookEndpoint{ID: uuid.New(), Active: false}
+
+	message, err := service.newOutboxMessage(model.SubscriptionPaused, subscription)
+	assert.NoError(t, err)
+	assert.Equal(t, now, message.CreatedAt)
+
+	mockRepo.EXPECT().GetWebhookEndpoints(gomock.Any()).Return([]model.WebhookEndpoint{receiving, filtered, inactive}, nil)
+	mockRepo.EXPECT().SaveWebhookEvent(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, event model.WebhookEvent, deliveries []model.WebhookDelivery) error {
+			assert.Equal(t, message.EventID, event.ID)
+			assert.Equal(t, model.SubscriptionPaused, event.Type)
+			assert.Equal(t, subscription.ID, event.SubscriptionID)
+
+			var payload webhookPayload
+			assert.NoError(t, json.Unmarshal(event.Payload, &payload))
+			assert.Equal(t, event.ID, payload.ID)
+			assert.Equal(t, model.Paused, payload.Data.Subscription.Status)
+
+			assert.Len(t, deliveries, 1)
+			assert.Equal(t, receiving.ID, deliveries[0].EndpointID)
+			assert.Equal(t, model.WebhookPending, deliveries[0].Status)
+			return nil
+		},
+	)
+
+	err = service.PublishWebhooks(context.Background(), message)
+	assert.NoError(t, err)
+}
+
+func Test_Service_DeliverWebhooks(t *testing.T) {
+	t.Parallel()
+
+	endpoint := model.WebhookEndpoint{ID: uuid.New(), URL: "https://crm.example.com/hooks", Active: true}
+	event := model.WebhookEvent{ID: uuid.New(), Type: model.SubscriptionCreated}
+
+	newDelivery := func(attempts int) model.WebhookDelivery {
+		return model.WebhookDelivery{
+			ID:         uuid.New(),
+			EventID:    event.ID,
+			EndpointID: endpoint.ID,
+			Status:     model.WebhookPending,
+			Attempts:   attempts,
+		}
+	}
+
+	t.Run("successful delivery", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockWebhooks := NewMockWebhookSender(ctrl)
+		service := &Service{repository: mockRepo, webhooks: mockWebhooks, config: DefaultConfig()}
+
+		delivery := newDelivery(0)
+
+		mockRepo.EXPECT().GetWebhookDeliveriesDue(gomock.Any(), gomock.Any()).Return([]model.WebhookDelivery{delivery}, nil)
+		mockRepo.EXPECT().GetWebhookEndpoint(gomock.Any(), endpoint.ID.String()).Return(endpoint, nil)
+		mockRepo.EXPECT().GetWebhookEvent(gomock.Any(), event.ID.String()).Return(event, nil)
+		mockWebhooks.EXPECT().Send(gomock.Any(), endpoint, event, delivery.ID).Return(nil)
+		mockRepo.EXPECT().UpdateWebhookDelivery(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, updated model.WebhookDelivery) error {
+				assert.Equal(t, model.WebhookDelivered, updated.Status)
+				assert.Equal(t, 1, updated.Attempts)
+				assert.NotNil(t, updated.DeliveredAt)
+				assert.Nil(t, updated.NextAttemptAt)
+				return nil
+			},
+		)
+
+		err := service.DeliverWebhooks(context.Background())
+		assert.NoError(t, err)
+	})
+
+	t.Run("failed delivery backs off", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockWebhooks := NewMockWebhookSender(ctrl)
+		service := &Service{repository: mockRepo, webhooks: mockWebhooks, config: DefaultConfig()}
+
+		delivery := newDelivery(2)
+
+		mockRepo.EXPECT().GetWebhookDeliveriesDue(gomock.Any(), gomock.Any()).Return([]model.WebhookDelivery{delivery}, nil)
+		mockRepo.EXPECT().GetWebhookEndpoint(gomock.Any(), endpoint.ID.String()).Return(endpoint, nil)
+		mockRepo.EXPECT().GetWebhookEvent(gomock.Any(), event.ID.String()).Return(event, nil)
+		mockWebhooks.EXPECT().Send(gomock.Any(), endpoint, event, delivery.ID).Return(fmt.Errorf("endpoint responded with status 503"))
+		mockRepo.EXPECT().UpdateWebhookDelivery(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, updated model.WebhookDelivery) error {
+				assert.Equal(t, model.WebhookPending, updated.Status)
+				assert.Equal(t, 3, updated.Attempts)
+				assert.Equal(t, "endpoint responded with status 503", updated.LastError)
+				assert.WithinDuration(t, time.Now().Add(4*time.Minute), *updated.NextAttemptAt, time.Second)
+				return nil
+			},
+		)
+
+		err := service.DeliverWebhooks(context.Background())
+		assert.NoError(t, err)
+	})
+
+	t.Run("last failed attempt dead-letters the delivery", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockWebhooks := NewMockWebhookSender(ctrl)
+		service := &Service{repository: mockRepo, webhooks: mockWebhooks, config: DefaultConfig()}
+
+		delivery := newDelivery(DefaultConfig().WebhookMaxAttempts - 1)
+
+		mockRepo.EXPECT().GetWebhookDeliveriesDue(gomock.Any(), gomock.Any()).Return([]model.WebhookDelivery{delivery}, nil)
+		mockRepo.EXPECT().GetWebhookEndpoint(gomock.Any(), endpoint.ID.String()).Return(endpoint, nil)
+		mockRepo.EXPECT().GetWebhookEvent(gomock.Any(), event.ID.String()).Return(event, nil)
+		mockWebhooks.EXPECT().Send(gomock.Any(), endpoint, event, delivery.ID).Return(fmt.Errorf("timeout"))
+		mockRepo.EXPECT().UpdateWebhookDelivery(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, updated model.WebhookDelivery) error {
+				assert.Equal(t, model.WebhookDeadLettered, updated.Status)
+				assert.Nil(t, updated.NextAttemptAt)
+				return nil
+			},
+		)
+
+		err := service.DeliverWebhooks(context.Background())
+		assert.NoError(t, err)
+	})
+
+	t.Run("deactivated endpoint dead-letters the delivery", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockWebhooks := NewMockWebhookSender(ctrl)
+		service := &Service{repository: mockRepo, webhooks: mockWebhooks, config: DefaultConfig()}
+
+		delivery := newDelivery(0)
+		deactivated := endpoint
+		deactivated.Active = false
+
+		mockRepo.EXPECT().GetWebhookDeliveriesDue(gomock.Any(), gomock.Any()).Return([]model.WebhookDelivery{delivery}, nil)
+		mockRepo.EXPECT().GetWebhookEndpoint(gomock.Any(), endpoint.ID.String()).Return(deactivated, nil)
+		mockRepo.EXPECT().GetWebhookEvent(gomock.Any(), event.ID.String()).Return(event, nil)
+		mockRepo.EXPECT().UpdateWebhookDelivery(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, updated model.WebhookDelivery) error {
+				assert.Equal(t, model.WebhookDeadLettered, updated.Status)
+				assert.Equal(t, "endpoint is deactivated", updated.LastError)
+				return nil
+			},
+		)
+
+		err := service.DeliverWebhooks(context.Background())
+		assert.NoError(t, err)
+	})
+}
+
+func Test_Service_ReplayWebhookDelivery(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	service := &Service{repository: mockRepo}
+
+	delivery := model.WebhookDelivery{ID: uuid.New(), Status: model.WebhookDeadLettered, Attempts: 8, LastError: "timeout"}
+
+	mockRepo.EXPECT().GetWebhookDelivery(gomock.Any(), delivery.ID.String()).Return(delivery, nil)
+	mockRepo.EXPECT().UpdateWebhookDelivery(gomock.Any(), gomock.Any()).Return(nil)
+
+	replayed, err := service.ReplayWebhookDelivery(context.Background(), delivery.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, model.WebhookPending, replayed.Status)
+	assert.Equal(t, 0, replayed.Attempts)
+	assert.NotNil(t, replayed.NextAttemptAt)
+}
@@ -0,0 +1,78 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gymondo/internal/model"
+)
+
+const (
+	EventHeader     = "X-Gymondo-Event"
+	DeliveryHeader  = "X-Gymondo-Delivery"
+	SignatureHeader = "X-Gymondo-Signature"
+)
+
+// HTTPSender posts webhook events to their endpoints. Every request carries a
+// signature header of the form "t=<unix timestamp>,v1=<hex HMAC-SHA256>" over
+// "<timestamp>.<body>", keyed with the endpoint's secret.
+type HTTPSender struct {
+	client *http.Client
+}
+
+func NewHTTPSender(timeout time.Duration) *HTTPSender {
+	return &HTTPSender{
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Send delivers the event once. Any response outside the 2xx range counts as
+// a failed delivery.
+func (s *HTTPSender) Send(
+	ctx context.Context,
+	endpoint model.WebhookEndpoint,
+	event model.WebhookEvent,
+	deliveryID uuid.UUID,
+) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(event.Payload))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+
+	timestamp := time.Now().Unix()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(EventHeader, string(event.Type))
+	request.Header.Set(DeliveryHeader, deliveryID.String())
+	request.Header.Set(SignatureHeader, fmt.Sprintf("t=%d,v1=%s", timestamp, Sign(endpoint.Secret, timestamp, event.Payload)))
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 1<<16))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("endpoint responded with status %d", response.StatusCode)
+	}
+
+	return nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of "<timestamp>.<payload>".
+func Sign(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,79 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gymondo/internal/model"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_HTTPSender_Send(t *testing.T) {
+	t.Parallel()
+
+	event := model.WebhookEvent{
+		ID:      uuid.New(),
+		Type:    model.SubscriptionPaused,
+		Payload: []byte(`{"type":"subscription.paused"}`),
+	}
+
+	t.Run("signed delivery", func(t *testing.T) {
+		t.Parallel()
+
+		deliveryID := uuid.New()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			assert.Equal(t, string(event.Payload), string(body))
+			assert.Equal(t, "subscription.paused", r.Header.Get(EventHeader))
+			assert.Equal(t, deliveryID.String(), r.Header.Get(DeliveryHeader))
+
+			var timestamp int64
+			var signature string
+			parts := strings.Split(r.Header.Get(SignatureHeader), ",")
+			assert.Len(t, parts, 2)
+			timestamp, _ = strconv.ParseInt(strings.TrimPrefix(parts[0], "t="), 10, 64)
+			signature = strings.TrimPrefix(parts[1], "v1=")
+			assert.Equal(t, Sign("secret", timestamp, body), signature)
+
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		sender := NewHTTPSender(time.Second)
+		endpoint := model.WebhookEndpoint{URL: server.URL, Secret: "secret"}
+
+		err := sender.Send(context.Background(), endpoint, event, deliveryID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("non 2xx response fails", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		sender := NewHTTPSender(time.Second)
+		endpoint := model.WebhookEndpoint{URL: server.URL, Secret: "secret"}
+
+		err := sender.Send(context.Background(), endpoint, event, uuid.New())
+		assert.ErrorContains(t, err, fmt.Sprintf("status %d", http.StatusServiceUnavailable))
+	})
+}
+
+func Test_Sign(t *testing.T) {
+	t.Parallel()
+
+	signature := Sign("secret", 1700000000, []byte(`{}`))
+	assert.Len(t, signature, 64)
+	assert.Equal(t, signature, Sign("secret", 1700000000, []byte(`{}`)))
+	assert.NotEqual(t, signature, Sign("other", 1700000000, []byte(`{}`)))
+}